@@ -68,7 +68,7 @@ func run() error {
 	cancel()
 
 	// Initialize detector with strategies
-	det := detector.New(plat)
+	det := detector.New(plat, detector.WithConfig(cfg))
 
 	// Initialize catalog manager
 	cat := catalog.NewManager(cfg, store)
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -64,6 +65,11 @@ detailed information about agents.`,
 		newAgentInfoCommand(cfg),
 		newAgentRemoveCommand(cfg),
 		newAgentRefreshCommand(cfg),
+		newAgentPinCommand(cfg),
+		newAgentUnpinCommand(cfg),
+		newAgentRollbackCommand(cfg),
+		newAgentHistoryCommand(cfg),
+		newAgentConflictsCommand(cfg),
 	)
 
 	return cmd
@@ -76,6 +82,7 @@ func newAgentListCommand(cfg *config.Config) *cobra.Command {
 		updatesOnly bool
 		refresh     bool
 		verify      bool
+		project     string
 	)
 
 	cmd := &cobra.Command{
@@ -87,8 +94,21 @@ This command scans for agents installed via various methods (npm, pip, brew,
 native installers, etc.) and displays their current version, installation
 method, and update status.
 
-Results are cached for 1 hour by default. Use --refresh to force re-detection.`,
+Results are cached for 1 hour by default. Use --refresh to force re-detection.
+
+With --project, only agents installed locally in a project are listed:
+npm packages in node_modules/.bin and Python packages in a .venv, found in
+the given directory (the current one by default) or any directory above it
+up to the repository root.
+The directory may follow --project as "--project=<dir>" or "--project <dir>".
+Project detection always runs fresh and does not touch the cache.
+
+Detection strategies that fail are reported as warnings on stderr. With
+--verbose, every strategy's result count and timing are listed too.`,
 		Aliases: []string{"ls"},
+		Args: func(cmd *cobra.Command, args []string) error {
+			return projectDirArg(cmd, &project, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 			defer cancel()
@@ -123,20 +143,25 @@ Results are cached for 1 hour by default. Use --refresh to force re-detection.`,
 			}
 
 			catMgr := catalog.NewManager(cfg, store)
-			det := detector.New(plat)
+			det := detector.New(plat, detector.WithConfig(cfg))
 			instMgr := installer.NewManager(plat)
 			pipeline := orchestrator.NewFromManagers(cfg, plat, store, catMgr, det, instMgr)
 
 			// Update spinner message based on whether we expect to hit the cache.
-			if cfg.Detection.CacheEnabled && !refresh {
+			if cfg.Detection.CacheEnabled && !refresh && project == "" {
 				spinner.UpdateMessage("Loading from cache...")
 			} else {
 				spinner.UpdateMessage("Detecting agents...")
 			}
 
-			pipelineRes, err := pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{
-				ForceRefresh: refresh,
-			})
+			var pipelineRes *orchestrator.Result
+			if project != "" {
+				pipelineRes, err = pipeline.DetectProject(ctx, project, orchestrator.Options{})
+			} else {
+				pipelineRes, err = pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{
+					ForceRefresh: refresh,
+				})
+			}
 			if err != nil {
 				spinner.Error("Agent detection failed")
 				return err
@@ -149,6 +174,12 @@ Results are cached for 1 hour by default. Use --refresh to force re-detection.`,
 			// Stop spinner
 			spinner.Stop()
 
+			if pipelineRes.Detection != nil {
+				printDetectionReport(os.Stderr, printer, pipelineRes.Detection, verboseInstallOutput(cfg))
+			} else if verboseInstallOutput(cfg) {
+				fmt.Fprintln(os.Stderr, "Detection: results from cache (use --refresh to re-detect)")
+			}
+
 			// agent list historically drops version-check errors silently so
 			// the table does not get interrupted by transient registry hiccups;
 			// pipelineRes.VersionCheckErrors is intentionally ignored here.
@@ -191,6 +222,15 @@ Results are cached for 1 hour by default. Use --refresh to force re-detection.`,
 					HasUpdate:     inst.HasUpdate(),
 					Path:          inst.ExecutablePath,
 					Status:        string(inst.GetStatus()),
+					PathStatus:    inst.Metadata["path_status"],
+					ShadowedBy:    inst.Metadata["shadowed_by"],
+				}
+				if cfg.IsAgentPinned(inst.AgentID) {
+					item.Status = string(agent.StatusPinned)
+					item.PinnedVersion = cfg.GetPinnedVersion(inst.AgentID)
+				}
+				if !inst.IsGlobal && project != "" {
+					item.Project = inst.InstallPath
 				}
 
 				// Verify agent health if requested
@@ -218,21 +258,44 @@ Results are cached for 1 hour by default. Use --refresh to force re-detection.`,
 	cmd.Flags().BoolVarP(&updatesOnly, "updates", "u", false, "show only agents with updates")
 	cmd.Flags().BoolVarP(&refresh, "refresh", "r", false, "force re-detection (ignore cache)")
 	cmd.Flags().BoolVar(&verify, "verify", false, "verify agents can execute (health check)")
+	cmd.Flags().StringVar(&project, "project", "", "list agents installed locally in the project at `dir` (default: current directory)")
+	cmd.Flags().Lookup("project").NoOptDefVal = "."
 
 	return cmd
 }
 
+// projectDirArg accepts the directory of "agent list --project <dir>".
+// --project has an optional value, so pflag only binds "--project=<dir>"
+// and leaves a space-separated directory as a positional argument; a
+// lone argument after a bare --project is taken as its directory. Any
+// other argument is an error.
+func projectDirArg(cmd *cobra.Command, project *string, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	flag := cmd.Flags().Lookup("project")
+	if !flag.Changed {
+		return fmt.Errorf("unexpected argument %q; to list a project's agents use --project=<dir>", args[0])
+	}
+	if len(args) > 1 || *project != flag.NoOptDefVal {
+		return fmt.Errorf("unexpected arguments %q; pass the project directory as --project=<dir>", args)
+	}
+	*project = args[0]
+	return nil
+}
+
 func newAgentInstallCommand(cfg *config.Config) *cobra.Command {
 	var (
 		method          string
 		global          bool
 		force           bool
 		continueOnError bool
+		dryRun          bool
 		jsonOutput      bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "install <agent-name> [<agent-name>...]",
+		Use:   "install <agent-name>[@<version>] [<agent-name>...]",
 		Short: "Install one or more agents",
 		Long: `Install one or more AI development agents using the specified or default method.
 
@@ -240,14 +303,26 @@ If no method is specified, the preferred method from the catalog or config
 will be used. The same --method applies to every agent passed; mix-and-match
 requires separate invocations.
 
+Append @<version> to an agent name to install that version instead of the
+latest (npm, pip/pipx/uv, Homebrew and GitHub release binary installs
+only). Installing over an existing installation this way also downgrades it.
+
 By default, the command stops at the first failure. Use --continue-on-error
 to attempt every agent and report a summary at the end.
 
 Pass --json to emit a single structured result document on stdout (one entry
 per agent, plus a summary). In --json mode, --continue-on-error is implied so
 scripts get a complete picture and the exit code reflects whether any agent
-failed.`,
-		Args: cobra.MinimumNArgs(1),
+failed.
+
+Pass --dry-run to print the exact commands each install would run, with
+their working directory, environment and whether they need administrator
+rights, without running them. With --json the plan is included in each
+entry.`,
+		Example: `  agentmgr agent install aider
+  agentmgr agent install aider@0.85.0 --method pipx
+  agentmgr agent install aider --dry-run --json`,
+		Args: cobra.MatchAll(cobra.MinimumNArgs(1), agentVersionArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if jsonOutput {
 				// Keep stdout strictly JSON; suppress cobra's error/usage
@@ -295,8 +370,38 @@ failed.`,
 				entries   []agentBatchEntry
 			)
 
-			for _, agentID := range args {
-				chosenMethod, version, err := installOne(installCtx, cfg, plat, cat, inst, spinner, verbose, agentID, method, force)
+			if dryRun {
+				spinner.Stop()
+				for _, arg := range args {
+					agentID, target, _ := parseAgentVersionArg(arg) // checked by agentVersionArgs
+					entry := planInstallEntry(providers.WithTargetVersion(ctx, target), cfg, plat, cat, inst, agentID, method, force)
+					entries = append(entries, entry)
+					if jsonOutput {
+						continue
+					}
+					if entry.Plan == nil {
+						failed = append(failed, agentID)
+						fmt.Fprintf(os.Stderr, "Cannot install %s: %s\n", agentID, entry.Error)
+						continue
+					}
+					fmt.Printf("Would install %s via %s:\n", agentID, entry.Method)
+					for _, line := range planLines(entry.Plan) {
+						fmt.Println("  " + line)
+					}
+				}
+				if jsonOutput {
+					return emitAgentBatchJSON("install", entries)
+				}
+				if len(failed) > 0 {
+					return fmt.Errorf("%d of %d agent(s) cannot be installed", len(failed), len(args))
+				}
+				return nil
+			}
+
+			for _, arg := range args {
+				agentID, target, _ := parseAgentVersionArg(arg) // checked by agentVersionArgs
+				agentCtx := providers.WithTargetVersion(installCtx, target)
+				chosenMethod, version, err := installOne(agentCtx, cfg, plat, cat, inst, store, spinner, verbose, agentID, method, force)
 				if err != nil {
 					failed = append(failed, agentID)
 					entries = append(entries, agentBatchEntry{
@@ -351,6 +456,7 @@ failed.`,
 	cmd.Flags().BoolVarP(&global, "global", "g", true, "install globally")
 	cmd.Flags().BoolVarP(&force, "force", "F", false, "force installation")
 	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep installing remaining agents after a failure (multi-agent only)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the commands the install would run without running them")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit a single JSON result document on stdout instead of human-readable output")
 
 	return cmd
@@ -382,6 +488,7 @@ func installOne(
 	plat platform.Platform,
 	cat *catalog.Catalog,
 	inst *installer.Manager,
+	store storage.Store,
 	spinner *output.Spinner,
 	verbose bool,
 	agentID string,
@@ -399,34 +506,16 @@ func installOne(
 		return "", "", fmt.Errorf("agent %q not found in catalog", agentID)
 	}
 
-	chosenMethod = method
-	if chosenMethod == "" {
-		if preferred := cfg.GetAgentConfig(agentID).PreferredMethod; preferred != "" {
-			chosenMethod = preferred
-		} else {
-			methods := agentDef.GetSupportedMethods(string(plat.ID()))
-			if len(methods) == 0 {
-				msg := fmt.Sprintf("No installation methods available for %q on %s", agentID, plat.ID())
-				if verbose {
-					fmt.Fprintln(os.Stderr, msg)
-				} else {
-					spinner.Error(msg)
-				}
-				return "", "", fmt.Errorf("no installation methods available for %q on %s", agentID, plat.ID())
-			}
-			chosenMethod = methods[0].Method
-		}
-	}
-
-	methodDef, ok := agentDef.GetInstallMethod(chosenMethod)
-	if !ok {
-		msg := fmt.Sprintf("Installation method %q not available for %q", chosenMethod, agentID)
+	methodDef, chosenMethod, err := installMethodFor(cfg, plat, agentDef, method)
+	if err != nil {
+		msg := err.Error()
+		msg = strings.ToUpper(msg[:1]) + msg[1:]
 		if verbose {
 			fmt.Fprintln(os.Stderr, msg)
 		} else {
 			spinner.Error(msg)
 		}
-		return chosenMethod, "", fmt.Errorf("installation method %q not available for %q", chosenMethod, agentID)
+		return chosenMethod, "", err
 	}
 
 	if verbose {
@@ -439,7 +528,15 @@ func installOne(
 		spinner.UpdateMessage(fmt.Sprintf("Installing %s via %s...", agentDef.Name, chosenMethod))
 	}
 
+	record := storage.RecordEvent(ctx, store, &storage.UpdateEvent{
+		AgentID:       agentDef.ID,
+		AgentName:     agentDef.Name,
+		Action:        storage.UpdateActionInstall,
+		InstallMethod: chosenMethod,
+		ToVersion:     providers.TargetVersion(ctx),
+	}, storage.UpdateStatusRunning)
 	result, err := inst.Install(ctx, agentDef, methodDef, force)
+	storage.FinishEvent(ctx, store, record, result.VersionString(), err)
 	if err != nil {
 		failMsg := fmt.Sprintf("Failed to install %s: %v", agentDef.Name, err)
 		if verbose {
@@ -470,6 +567,30 @@ func installOne(
 	return chosenMethod, version, nil
 }
 
+// installMethodFor resolves the method installing agentDef: method when
+// set, else the agent's preferred method from config, else the first one
+// the catalog supports on the platform. The method name is returned even
+// when the catalog does not define it.
+func installMethodFor(cfg *config.Config, plat platform.Platform, agentDef catalog.AgentDef, method string) (catalog.InstallMethodDef, string, error) {
+	if method == "" {
+		if preferred := cfg.GetAgentConfig(agentDef.ID).PreferredMethod; preferred != "" {
+			method = preferred
+		} else {
+			methods := agentDef.GetSupportedMethods(string(plat.ID()))
+			if len(methods) == 0 {
+				return catalog.InstallMethodDef{}, "", fmt.Errorf("no installation methods available for %q on %s", agentDef.ID, plat.ID())
+			}
+			method = methods[0].Method
+		}
+	}
+
+	methodDef, ok := agentDef.GetInstallMethod(method)
+	if !ok {
+		return catalog.InstallMethodDef{}, method, fmt.Errorf("installation method %q not available for %q", method, agentDef.ID)
+	}
+	return methodDef, method, nil
+}
+
 func newAgentUpdateCommand(cfg *config.Config) *cobra.Command {
 	var (
 		all        bool
@@ -489,6 +610,19 @@ update every installed agent that has an available update.
 When updating, the full changelog is displayed before confirming each
 update.
 
+Agents pinned with 'agentmgr agent pin' are skipped by --all and refused
+when named explicitly unless --force is passed.
+
+After each update the agent's version command (and smoke test, when the
+catalog defines one) is run. An agent that fails it, or still reports its
+old version, is reinstalled at the previous version and reported as
+rolled back.
+
+Pass --dry-run to print the exact commands each update would run, with
+their working directory, environment and whether they need administrator
+rights, without running them. With --json the plan is included in each
+entry.
+
 Pass --json to emit a single structured result document on stdout (one entry
 per (agent, method) updated, plus a summary). Useful for scripting.`,
 		Args: cobra.ArbitraryArgs,
@@ -529,7 +663,7 @@ per (agent, method) updated, plus a summary). Useful for scripting.`,
 			}
 
 			catMgr := catalog.NewManager(cfg, store)
-			det := detector.New(plat)
+			det := detector.New(plat, detector.WithConfig(cfg))
 			inst := installer.NewManager(plat)
 			pipeline := orchestrator.NewFromManagers(cfg, plat, store, catMgr, det, inst)
 
@@ -568,7 +702,7 @@ per (agent, method) updated, plus a summary). Useful for scripting.`,
 				if len(args) > 0 {
 					printer.Warning("--all takes precedence; positional agent names are ignored")
 				}
-				entries, err := updateAllAgents(ctx, cfg, installations, cat, inst, force, dryRun, printer)
+				entries, err := updateAllAgents(ctx, cfg, installations, cat, inst, store, force, dryRun, printer)
 				if jsonOutput {
 					return emitAgentBatchJSON("update", entries)
 				}
@@ -590,7 +724,7 @@ per (agent, method) updated, plus a summary). Useful for scripting.`,
 				allEntries []agentBatchEntry
 			)
 			for _, agentID := range args {
-				entries, err := updateSingleAgent(ctx, cfg, agentID, installations, cat, inst, force, dryRun, printer)
+				entries, err := updateSingleAgent(ctx, cfg, agentID, installations, cat, inst, store, force, dryRun, printer)
 				allEntries = append(allEntries, entries...)
 				if err != nil {
 					lastErr = err
@@ -609,7 +743,7 @@ per (agent, method) updated, plus a summary). Useful for scripting.`,
 
 	cmd.Flags().BoolVar(&all, "all", false, "update all agents")
 	cmd.Flags().BoolVarP(&force, "force", "F", false, "force update")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be updated")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be updated and the commands it would run")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit a single JSON result document on stdout instead of human-readable output")
 
 	return cmd
@@ -620,7 +754,7 @@ per (agent, method) updated, plus a summary). Useful for scripting.`,
 // emit a structured result (--json). Errors are reported on entries; the
 // returned error is the last fatal error, or nil if every installation
 // updated cleanly.
-func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*agent.Installation, cat *catalog.Catalog, inst *installer.Manager, force, dryRun bool, printer *output.Printer) ([]agentBatchEntry, error) {
+func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*agent.Installation, cat *catalog.Catalog, inst *installer.Manager, store storage.Store, force, dryRun bool, printer *output.Printer) ([]agentBatchEntry, error) {
 	styles := printer.Styles()
 	verbose := verboseInstallOutput(cfg)
 
@@ -630,16 +764,59 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 	)
 	spinner.Start()
 
-	var toUpdate []*agent.Installation
+	// Pinned agents are never moved by --all, even with --force; the pin is
+	// an explicit per-agent decision and --force is a blanket flag.
+	// Agents pinned to a constraint are updated to the newest release in
+	// range rather than the registry's latest.
+	var (
+		toUpdate, pinned []*agent.Installation
+		targets          = make(map[*agent.Installation]agent.Version)
+		entries          []agentBatchEntry
+	)
 	for _, installation := range installations {
-		if installation.HasUpdate() || force {
-			toUpdate = append(toUpdate, installation)
+		if !installation.HasUpdate() && !force {
+			continue
+		}
+		if cfg.IsAgentPinned(installation.AgentID) {
+			pinned = append(pinned, installation)
+			continue
 		}
+		if constraints := cfg.GetVersionConstraints(installation.AgentID); constraints != nil {
+			target, reason := constraintTarget(ctx, inst, cat, constraints, installation, force)
+			if reason != "" {
+				entries = append(entries, agentBatchEntry{
+					Agent:           installation.AgentID,
+					Status:          batchStatusSkipped,
+					Method:          string(installation.Method),
+					PreviousVersion: installation.InstalledVersion.String(),
+					Version:         installation.InstalledVersion.String(),
+					Reason:          reason,
+				})
+				continue
+			}
+			targets[installation] = target
+		}
+		toUpdate = append(toUpdate, installation)
 	}
 
 	spinner.Stop()
 
-	var entries []agentBatchEntry
+	for _, entry := range entries {
+		printer.Info("Skipping %s: %s", entry.Agent, entry.Reason)
+	}
+
+	for _, installation := range pinned {
+		pin := cfg.GetPinnedVersion(installation.AgentID)
+		printer.Info("Skipping %s: pinned to %s", installation.AgentName, pin)
+		entries = append(entries, agentBatchEntry{
+			Agent:           installation.AgentID,
+			Status:          batchStatusPinned,
+			Method:          string(installation.Method),
+			PreviousVersion: installation.InstalledVersion.String(),
+			Version:         installation.InstalledVersion.String(),
+			Reason:          fmt.Sprintf("pinned to %s", pin),
+		})
+	}
 
 	if len(toUpdate) == 0 {
 		printer.Info("No updates available")
@@ -648,10 +825,7 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 
 	printer.Print("\nFound %s with updates:", styles.Bold.Render(fmt.Sprintf("%d agent(s)", len(toUpdate))))
 	for _, installation := range toUpdate {
-		latestVer := "unknown"
-		if installation.LatestVersion != nil {
-			latestVer = installation.LatestVersion.String()
-		}
+		latestVer := updateTargetLabel(installation, targets, "unknown")
 		printer.Print("  - %s: %s -> %s",
 			styles.FormatAgentName(installation.AgentName),
 			styles.FormatVersion(installation.InstalledVersion.String(), true),
@@ -660,30 +834,41 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 
 	if dryRun {
 		printer.Info("\nDry run - no changes made")
+		var lastErr error
 		for _, installation := range toUpdate {
-			latestVer := "unknown"
-			if installation.LatestVersion != nil {
-				latestVer = installation.LatestVersion.String()
+			latestVer := updateTargetLabel(installation, targets, "unknown")
+			entry := planUpdateEntry(ctx, cat, inst, installation, targets, latestVer)
+			entries = append(entries, entry)
+			if entry.Plan == nil {
+				printer.Warning("Cannot update %s: %s", installation.AgentName, entry.Error)
+				lastErr = fmt.Errorf("update %s: %s", installation.AgentID, entry.Error)
+				continue
+			}
+			printer.Print("\n%s via %s:", styles.FormatAgentName(installation.AgentName), styles.FormatMethod(string(installation.Method)))
+			for _, line := range planLines(entry.Plan) {
+				printer.Print("  %s", line)
 			}
-			entries = append(entries, agentBatchEntry{
-				Agent:           installation.AgentID,
-				Status:          batchStatusNoop,
-				Method:          string(installation.Method),
-				PreviousVersion: installation.InstalledVersion.String(),
-				Version:         latestVer,
-				Reason:          "dry run",
-			})
 		}
-		return entries, nil
+		return entries, lastErr
 	}
 
 	printer.Print("")
 
+	// Queue the whole batch in the update history up front so an
+	// interrupted run still shows what was planned.
+	records := make(map[*agent.Installation]*storage.UpdateEvent, len(toUpdate))
+	for _, installation := range toUpdate {
+		event := storage.NewInstallationEvent(storage.UpdateActionUpdate, installation, updateTargetLabel(installation, targets, ""))
+		records[installation] = storage.RecordEvent(ctx, store, event, storage.UpdateStatusPending)
+	}
+
 	var lastErr error
 	for _, installation := range toUpdate {
 		previous := installation.InstalledVersion.String()
+		record := records[installation]
 		agentDef, ok := cat.GetAgent(installation.AgentID)
 		if !ok {
+			storage.SetEventStatus(ctx, store, record, storage.UpdateStatusCancelled)
 			printer.Warning("Skipping %s: not found in catalog", installation.AgentName)
 			entries = append(entries, agentBatchEntry{
 				Agent:           installation.AgentID,
@@ -697,6 +882,7 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 
 		methodDef, ok := agentDef.GetInstallMethod(string(installation.Method))
 		if !ok {
+			storage.SetEventStatus(ctx, store, record, storage.UpdateStatusCancelled)
 			printer.Warning("Skipping %s: install method %s not found", installation.AgentName, installation.Method)
 			entries = append(entries, agentBatchEntry{
 				Agent:           installation.AgentID,
@@ -709,6 +895,9 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 		}
 
 		updateCtx := withInstallProgress(ctx, cfg)
+		if target, ok := targets[installation]; ok {
+			updateCtx = providers.WithTargetVersion(updateCtx, target.String())
+		}
 		var spinner *output.Spinner
 		if verbose {
 			fmt.Fprintf(os.Stderr, "Updating %s via %s...\n", installation.AgentName, installation.Method)
@@ -720,7 +909,9 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 			spinner.Start()
 		}
 
+		storage.SetEventStatus(ctx, store, record, storage.UpdateStatusRunning)
 		result, err := inst.Update(updateCtx, installation, agentDef, methodDef)
+		storage.FinishEvent(ctx, store, record, result.VersionString(), err)
 		if err != nil {
 			msg := fmt.Sprintf("Failed to update %s: %v", installation.AgentName, err)
 			if verbose {
@@ -728,13 +919,11 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 			} else {
 				spinner.Error(msg)
 			}
-			entries = append(entries, agentBatchEntry{
+			entries = append(entries, updateFailureEntry(agentBatchEntry{
 				Agent:           installation.AgentID,
-				Status:          batchStatusError,
 				Method:          string(installation.Method),
 				PreviousVersion: previous,
-				Error:           err.Error(),
-			})
+			}, err))
 			lastErr = err
 			continue
 		}
@@ -760,7 +949,7 @@ func updateAllAgents(ctx context.Context, cfg *config.Config, installations []*a
 // batch entry per matching installation so callers can emit a structured
 // result; the returned error is the last fatal error (nil if every
 // installation updated or was a clean noop).
-func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string, installations []*agent.Installation, cat *catalog.Catalog, inst *installer.Manager, force, dryRun bool, printer *output.Printer) ([]agentBatchEntry, error) {
+func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string, installations []*agent.Installation, cat *catalog.Catalog, inst *installer.Manager, store storage.Store, force, dryRun bool, printer *output.Printer) ([]agentBatchEntry, error) {
 	styles := printer.Styles()
 	verbose := verboseInstallOutput(cfg)
 
@@ -790,6 +979,23 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 		}}, fmt.Errorf("agent %q not found in catalog", agentID)
 	}
 
+	if cfg.IsAgentPinned(agentID) && !force {
+		pin := cfg.GetPinnedVersion(agentID)
+		printer.Error("%s is pinned to %s (use --force to update anyway, or 'agentmgr agent unpin %s')", agentDef.Name, pin, agentID)
+		entries := make([]agentBatchEntry, 0, len(agentInstallations))
+		for _, installation := range agentInstallations {
+			entries = append(entries, agentBatchEntry{
+				Agent:           agentID,
+				Status:          batchStatusError,
+				Method:          string(installation.Method),
+				PreviousVersion: installation.InstalledVersion.String(),
+				Reason:          fmt.Sprintf("pinned to %s", pin),
+				Error:           fmt.Sprintf("agent %q is pinned to %s", agentID, pin),
+			})
+		}
+		return entries, fmt.Errorf("agent %q is pinned to %s (use --force to override)", agentID, pin)
+	}
+
 	var hasUpdate bool
 	for _, installation := range agentInstallations {
 		if installation.HasUpdate() || force {
@@ -814,37 +1020,68 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 		return entries, nil
 	}
 
+	// A constraint pin narrows each installation's target to the newest
+	// release in range; installations with nothing newer in range are
+	// reported as noops and left alone.
+	targets := make(map[*agent.Installation]agent.Version)
+	var constrained []agentBatchEntry
+	if constraints := cfg.GetVersionConstraints(agentID); constraints != nil {
+		remaining := agentInstallations[:0:0]
+		for _, installation := range agentInstallations {
+			if !installation.HasUpdate() && !force {
+				continue
+			}
+			target, reason := constraintTarget(ctx, inst, cat, constraints, installation, force)
+			if reason != "" {
+				printer.Info("%s via %s: %s", agentDef.Name, installation.Method, reason)
+				constrained = append(constrained, agentBatchEntry{
+					Agent:           agentID,
+					Status:          batchStatusNoop,
+					Method:          string(installation.Method),
+					PreviousVersion: installation.InstalledVersion.String(),
+					Version:         installation.InstalledVersion.String(),
+					Reason:          reason,
+				})
+				continue
+			}
+			targets[installation] = target
+			remaining = append(remaining, installation)
+		}
+		if len(remaining) == 0 {
+			return constrained, nil
+		}
+		agentInstallations = remaining
+	}
+
 	if dryRun {
 		printer.Info("Would update %s (dry run)", agentDef.Name)
-		var entries []agentBatchEntry
+		entries := constrained
+		var lastErr error
 		for _, installation := range agentInstallations {
 			if installation.HasUpdate() || force {
-				latestVer := "latest"
-				if installation.LatestVersion != nil {
-					latestVer = installation.LatestVersion.String()
-				}
+				latestVer := updateTargetLabel(installation, targets, "latest")
 				printer.Print("  - %s via %s: %s -> %s",
 					styles.FormatAgentName(installation.AgentName),
 					styles.FormatMethod(string(installation.Method)),
 					styles.FormatVersion(installation.InstalledVersion.String(), true),
 					styles.FormatVersion(latestVer, false))
-				entries = append(entries, agentBatchEntry{
-					Agent:           agentID,
-					Status:          batchStatusNoop,
-					Method:          string(installation.Method),
-					PreviousVersion: installation.InstalledVersion.String(),
-					Version:         latestVer,
-					Reason:          "dry run",
-				})
+				entry := planUpdateEntry(ctx, cat, inst, installation, targets, latestVer)
+				entries = append(entries, entry)
+				if entry.Plan == nil {
+					printer.Warning("    cannot update: %s", entry.Error)
+					lastErr = fmt.Errorf("update %s via %s: %s", agentID, installation.Method, entry.Error)
+					continue
+				}
+				for _, line := range planLines(entry.Plan) {
+					printer.Print("    %s", line)
+				}
 			}
 		}
-		return entries, nil
+		return entries, lastErr
 	}
 
-	var (
-		lastErr error
-		entries []agentBatchEntry
-	)
+	var lastErr error
+	entries := constrained
 	for _, installation := range agentInstallations {
 		if !installation.HasUpdate() && !force {
 			continue
@@ -865,6 +1102,9 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 		}
 
 		updateCtx := withInstallProgress(ctx, cfg)
+		if target, ok := targets[installation]; ok {
+			updateCtx = providers.WithTargetVersion(updateCtx, target.String())
+		}
 		var spinner *output.Spinner
 		if verbose {
 			fmt.Fprintf(os.Stderr, "Updating %s via %s...\n", installation.AgentName, installation.Method)
@@ -876,7 +1116,10 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 			spinner.Start()
 		}
 
+		event := storage.NewInstallationEvent(storage.UpdateActionUpdate, installation, updateTargetLabel(installation, targets, ""))
+		record := storage.RecordEvent(ctx, store, event, storage.UpdateStatusRunning)
 		result, err := inst.Update(updateCtx, installation, agentDef, methodDef)
+		storage.FinishEvent(ctx, store, record, result.VersionString(), err)
 		if err != nil {
 			msg := fmt.Sprintf("Failed to update %s via %s: %v", agentDef.Name, installation.Method, err)
 			if verbose {
@@ -884,13 +1127,11 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 			} else {
 				spinner.Error(msg)
 			}
-			entries = append(entries, agentBatchEntry{
+			entries = append(entries, updateFailureEntry(agentBatchEntry{
 				Agent:           agentID,
-				Status:          batchStatusError,
 				Method:          string(installation.Method),
 				PreviousVersion: previous,
-				Error:           err.Error(),
-			})
+			}, err))
 			lastErr = err
 			continue
 		}
@@ -912,6 +1153,33 @@ func updateSingleAgent(ctx context.Context, cfg *config.Config, agentID string,
 	return entries, lastErr
 }
 
+// updateFailureEntry completes entry for an update that failed with err.
+// An update that failed verification and was undone is reported as rolled
+// back, with the previous version installed again.
+func updateFailureEntry(entry agentBatchEntry, err error) agentBatchEntry {
+	entry.Status = batchStatusError
+	entry.Error = err.Error()
+	var verr *installer.VerificationError
+	if errors.As(err, &verr) && verr.RolledBack() {
+		entry.Status = batchStatusRolledBack
+		entry.Version = verr.FromVersion.String()
+	}
+	return entry
+}
+
+// updateTargetLabel returns the version an update will move installation
+// to, for display: the resolved constraint target when there is one,
+// otherwise the registry's latest, otherwise fallback.
+func updateTargetLabel(installation *agent.Installation, targets map[*agent.Installation]agent.Version, fallback string) string {
+	if target, ok := targets[installation]; ok {
+		return target.String()
+	}
+	if installation.LatestVersion != nil {
+		return installation.LatestVersion.String()
+	}
+	return fallback
+}
+
 func newAgentInfoCommand(cfg *config.Config) *cobra.Command {
 	var format string
 
@@ -985,7 +1253,7 @@ func detectInstallationsForAgentInfo(ctx context.Context, cfg *config.Config, st
 		}
 	}
 
-	det := detector.New(plat)
+	det := detector.New(plat, detector.WithConfig(cfg))
 	return det.DetectAgent(ctx, agentDef)
 }
 
@@ -1017,6 +1285,9 @@ func outputAgentInfoText(agentDef catalog.AgentDef, installations []*agent.Insta
 			if inst.HasUpdate() {
 				status = fmt.Sprintf("update available: %s", inst.LatestVersion.String())
 			}
+			if entry := inst.Metadata["custom_path"]; entry != "" {
+				status += fmt.Sprintf(", from custom path %s", entry)
+			}
 			fmt.Printf("  - %s via %s: %s (%s)\n",
 				inst.InstalledVersion.String(),
 				inst.Method,
@@ -1036,6 +1307,7 @@ func outputAgentInfoJSON(agentDef catalog.AgentDef, installations []*agent.Insta
 		Version   string `json:"version"`
 		Method    string `json:"method"`
 		Path      string `json:"path"`
+		Source    string `json:"source,omitempty"`
 		HasUpdate bool   `json:"has_update"`
 		LatestVer string `json:"latest_version,omitempty"`
 	}
@@ -1066,6 +1338,7 @@ func outputAgentInfoJSON(agentDef catalog.AgentDef, installations []*agent.Insta
 			Version:   inst.InstalledVersion.String(),
 			Method:    string(inst.Method),
 			Path:      inst.ExecutablePath,
+			Source:    inst.Metadata["detected_by"],
 			HasUpdate: inst.HasUpdate(),
 			LatestVer: latestVer,
 		})
@@ -1081,6 +1354,7 @@ func newAgentRemoveCommand(cfg *config.Config) *cobra.Command {
 		force           bool
 		method          string
 		continueOnError bool
+		dryRun          bool
 		jsonOutput      bool
 	)
 
@@ -1099,7 +1373,12 @@ By default, the command stops at the first failure. Use
 
 Pass --json to emit a single structured result document on stdout. In
 --json mode, --force and --continue-on-error are implied (interactive
-prompts can't be answered from a script).`,
+prompts can't be answered from a script).
+
+Pass --dry-run to print the exact commands each removal would run, with
+their working directory, environment and whether they need administrator
+rights, without running them or prompting. With --json the plan is
+included in each entry.`,
 		Aliases: []string{"rm", "uninstall"},
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -1136,7 +1415,7 @@ prompts can't be answered from a script).`,
 
 			// Detect once and reuse the snapshot for every agent — running
 			// the full detector per arg would scan the system N times.
-			det := detector.New(plat)
+			det := detector.New(plat, detector.WithConfig(cfg))
 			installations, err := det.DetectAll(ctx, agentDefs)
 			if err != nil {
 				return fmt.Errorf("detection failed: %w", err)
@@ -1156,7 +1435,7 @@ prompts can't be answered from a script).`,
 			)
 
 			for _, agentID := range args {
-				result, err := removeOne(ctx, cat, inst, installations, agentID, method, force, msgOut)
+				result, err := removeOne(ctx, cat, inst, store, installations, agentID, method, force, dryRun, msgOut)
 				switch {
 				case err != nil:
 					failed = append(failed, agentID)
@@ -1177,6 +1456,15 @@ prompts can't be answered from a script).`,
 						Status: batchStatusSkipped,
 						Reason: "multiple installations; pass --method to disambiguate",
 					})
+				case result.Outcome == removeOutcomePlanned:
+					entries = append(entries, agentBatchEntry{
+						Agent:   agentID,
+						Status:  batchStatusNoop,
+						Method:  result.Method,
+						Version: result.Version,
+						Reason:  "dry run",
+						Plan:    result.Plan,
+					})
 				case result.Outcome == removeOutcomeCanceled:
 					skipped = append(skipped, agentID)
 					entries = append(entries, agentBatchEntry{
@@ -1201,8 +1489,9 @@ prompts can't be answered from a script).`,
 				return emitAgentBatchJSON("remove", entries)
 			}
 
-			// Summary only when we processed multiple agents.
-			if len(args) > 1 {
+			// Summary only when we processed multiple agents and removed
+			// something.
+			if len(args) > 1 && !dryRun {
 				fmt.Printf("\nRemoved %d of %d agent(s)", len(succeeded), len(args))
 				if len(failed) > 0 {
 					fmt.Printf("; %d failed: %s", len(failed), strings.Join(failed, ", "))
@@ -1223,6 +1512,7 @@ prompts can't be answered from a script).`,
 	cmd.Flags().BoolVarP(&force, "force", "F", false, "skip confirmation")
 	cmd.Flags().StringVarP(&method, "method", "m", "", "specific installation method to remove")
 	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep removing remaining agents after a failure (multi-agent only)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the commands the removal would run without running them")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit a single JSON result document on stdout instead of human-readable output")
 
 	return cmd
@@ -1240,6 +1530,8 @@ const (
 	removeOutcomeSkippedMulti
 	// removeOutcomeCanceled means the user answered no to the prompt.
 	removeOutcomeCanceled
+	// removeOutcomePlanned means --dry-run planned the removal instead.
+	removeOutcomePlanned
 )
 
 // removeOneResult captures both the human-facing outcome of removeOne and
@@ -1249,19 +1541,23 @@ type removeOneResult struct {
 	Outcome removeOutcome
 	Method  string
 	Version string
+	Plan    *providers.Plan
 }
 
 // removeOne removes a single installation. When msgOut is nil all
 // human-facing prose (the "Multiple installations..." listing, the
 // confirmation prompt, the success line) is suppressed — that's the path
 // --json takes. msgOut == os.Stdout reproduces the historical behavior.
+// With dryRun the removal is planned rather than carried out, without a
+// prompt.
 func removeOne(
 	ctx context.Context,
 	cat *catalog.Catalog,
 	inst *installer.Manager,
+	store storage.Store,
 	installations []*agent.Installation,
 	agentID, method string,
-	force bool,
+	force, dryRun bool,
 	msgOut io.Writer,
 ) (removeOneResult, error) {
 	emit := func(format string, a ...any) {
@@ -1312,7 +1608,7 @@ func removeOne(
 		Version: installation.InstalledVersion.String(),
 	}
 
-	if !force {
+	if !force && !dryRun {
 		emit("Are you sure you want to remove %s (%s via %s)? [y/N] ",
 			agentDef.Name, installation.InstalledVersion.String(), installation.Method)
 		var response string
@@ -1329,8 +1625,25 @@ func removeOne(
 		return result, fmt.Errorf("install method %s not found in catalog for %s", installation.Method, agentID)
 	}
 
+	if dryRun {
+		plan, err := inst.PlanUninstall(ctx, installation, methodDef)
+		if err != nil {
+			return result, fmt.Errorf("remove %s: %w", agentID, err)
+		}
+		emit("Would remove %s via %s:\n", agentDef.Name, installation.Method)
+		for _, line := range planLines(plan) {
+			emit("  %s\n", line)
+		}
+		result.Outcome = removeOutcomePlanned
+		result.Plan = plan
+		return result, nil
+	}
+
 	emit("Removing %s via %s...\n", agentDef.Name, installation.Method)
-	if err := inst.Uninstall(ctx, installation, methodDef); err != nil {
+	record := storage.RecordEvent(ctx, store, storage.NewInstallationEvent(storage.UpdateActionUninstall, installation, ""), storage.UpdateStatusRunning)
+	err := inst.Uninstall(ctx, installation, methodDef)
+	storage.FinishEvent(ctx, store, record, "", err)
+	if err != nil {
 		return result, fmt.Errorf("remove %s: %w", agentID, err)
 	}
 
@@ -1377,7 +1690,7 @@ results. The new detection results are then cached for future use.`,
 			}
 
 			catMgr := catalog.NewManager(cfg, store)
-			det := detector.New(plat)
+			det := detector.New(plat, detector.WithConfig(cfg))
 			instMgr := installer.NewManager(plat)
 			pipeline := orchestrator.NewFromManagers(cfg, plat, store, catMgr, det, instMgr)
 
@@ -1416,8 +1729,17 @@ type AgentListItem struct {
 	HasUpdate     bool   `json:"has_update"`
 	Path          string `json:"path"`
 	Status        string `json:"status"`
+	// PathStatus says whether Path is the copy on PATH a shell runs
+	// ("active"), one behind it ("shadowed"), or not on PATH ("off_path").
+	PathStatus string `json:"path_status,omitempty"`
+	// ShadowedBy is the active copy when PathStatus is "shadowed".
+	ShadowedBy    string `json:"shadowed_by,omitempty"`
+	PinnedVersion string `json:"pinned_version,omitempty"`
 	Healthy       *bool  `json:"healthy,omitempty"`
 	HealthError   string `json:"health_error,omitempty"`
+	// Project is the project root of an agent installed locally in a
+	// project, as listed by --project.
+	Project string `json:"project,omitempty"`
 }
 
 func outputAgentsTable(agents []AgentListItem, printer *output.Printer, showHealth bool) error {
@@ -1431,32 +1753,50 @@ func outputAgentsTable(agents []AgentListItem, printer *output.Printer, showHeal
 	styles := printer.Styles()
 	table := output.NewTable()
 
+	// Project-local agents get a column naming the project they are in.
+	showProject := false
+	for _, agent := range agents {
+		if agent.Project != "" {
+			showProject = true
+			break
+		}
+	}
+
+	// A PATH column appears once some copy is not the one a shell runs.
+	showPath := false
+	for _, agent := range agents {
+		if agent.PathStatus != "" && agent.PathStatus != detector.PathStatusActive {
+			showPath = true
+			break
+		}
+	}
+
 	// Set headers
+	headers := []string{
+		styles.FormatHeader("ID"),
+		styles.FormatHeader("AGENT"),
+		styles.FormatHeader("METHOD"),
+		styles.FormatHeader("VERSION"),
+		styles.FormatHeader("LATEST"),
+		styles.FormatHeader("STATUS"),
+	}
+	if showPath {
+		headers = append(headers, styles.FormatHeader("PATH"))
+	}
 	if showHealth {
-		table.SetHeaders(
-			styles.FormatHeader("ID"),
-			styles.FormatHeader("AGENT"),
-			styles.FormatHeader("METHOD"),
-			styles.FormatHeader("VERSION"),
-			styles.FormatHeader("LATEST"),
-			styles.FormatHeader("STATUS"),
-			styles.FormatHeader("HEALTH"),
-		)
-	} else {
-		table.SetHeaders(
-			styles.FormatHeader("ID"),
-			styles.FormatHeader("AGENT"),
-			styles.FormatHeader("METHOD"),
-			styles.FormatHeader("VERSION"),
-			styles.FormatHeader("LATEST"),
-			styles.FormatHeader("STATUS"),
-		)
+		headers = append(headers, styles.FormatHeader("HEALTH"))
+	}
+	if showProject {
+		headers = append(headers, styles.FormatHeader("PROJECT"))
 	}
+	table.SetHeaders(headers...)
 
 	// Add rows
 	for _, agent := range agents {
 		var statusIcon string
-		if agent.HasUpdate {
+		if agent.PinnedVersion != "" {
+			statusIcon = styles.FormatBadge("pinned "+agent.PinnedVersion, "warning")
+		} else if agent.HasUpdate {
 			statusIcon = styles.UpdateIcon()
 		} else {
 			statusIcon = styles.InstalledIcon()
@@ -1469,6 +1809,17 @@ func outputAgentsTable(agents []AgentListItem, printer *output.Printer, showHeal
 			latest = styles.FormatVersion(latest, false)
 		}
 
+		row := []string{
+			styles.Info.Render(agent.ID),
+			styles.FormatAgentName(agent.Name),
+			styles.FormatMethod(agent.Method),
+			styles.FormatVersion(agent.Version, agent.HasUpdate),
+			latest,
+			statusIcon,
+		}
+		if showPath {
+			row = append(row, formatPathStatus(styles, agent))
+		}
 		if showHealth {
 			healthIcon := "-"
 			if agent.Healthy != nil {
@@ -1478,31 +1829,32 @@ func outputAgentsTable(agents []AgentListItem, printer *output.Printer, showHeal
 					healthIcon = styles.ErrorIcon()
 				}
 			}
-			table.AddRow(
-				styles.Info.Render(agent.ID),
-				styles.FormatAgentName(agent.Name),
-				styles.FormatMethod(agent.Method),
-				styles.FormatVersion(agent.Version, agent.HasUpdate),
-				latest,
-				statusIcon,
-				healthIcon,
-			)
-		} else {
-			table.AddRow(
-				styles.Info.Render(agent.ID),
-				styles.FormatAgentName(agent.Name),
-				styles.FormatMethod(agent.Method),
-				styles.FormatVersion(agent.Version, agent.HasUpdate),
-				latest,
-				statusIcon,
-			)
+			row = append(row, healthIcon)
+		}
+		if showProject {
+			row = append(row, agent.Project)
 		}
+		table.AddRow(row...)
 	}
 
 	table.Render()
 	return nil
 }
 
+// formatPathStatus renders an agent's PATH status for the list table.
+func formatPathStatus(styles *output.Styles, agent AgentListItem) string {
+	switch agent.PathStatus {
+	case detector.PathStatusActive:
+		return styles.FormatBadge("active", "success")
+	case detector.PathStatusShadowed:
+		return styles.FormatBadge("shadowed by "+agent.ShadowedBy, "warning")
+	case detector.PathStatusOffPath:
+		return styles.FormatBadge("not on PATH", "info")
+	default:
+		return "-"
+	}
+}
+
 // verifyAgentHealth checks if an agent executable can run by trying --version or --help.
 func verifyAgentHealth(ctx context.Context, execPath string) (bool, string) {
 	// Try common version/help flags
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// ConflictCopy is one copy of an agent's executable on PATH.
+type ConflictCopy struct {
+	Rank int    `json:"rank"`
+	Path string `json:"path"`
+	// Target is set when Path is a symlink.
+	Target string `json:"target,omitempty"`
+	// Source is the package manager owning the copy, or "native".
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	Active  bool   `json:"active"`
+}
+
+// ConflictItem is an agent executable with its copies on PATH, in the order
+// a shell searches them.
+type ConflictItem struct {
+	Agent      string         `json:"agent"`
+	Name       string         `json:"name"`
+	Executable string         `json:"executable"`
+	Copies     []ConflictCopy `json:"copies"`
+}
+
+func newAgentConflictsCommand(cfg *config.Config) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "conflicts [agent-name]",
+		Short: "Show agents with more than one copy on PATH",
+		Long: `Show agents whose executable is found in more than one PATH directory.
+
+A shell runs the first match in PATH order; every later copy is shadowed
+and only runs when called by its full path. This happens when an agent is
+installed twice, say with npm and with its native installer, and usually
+means the shadowed copy is stale.
+
+Each copy is listed in resolution order with the package manager that owns
+it and its version. With an agent name, that agent's copies are shown even
+when there is only one.`,
+		Example: `  agentmgr agent conflicts
+  agentmgr agent conflicts claude-code
+  agentmgr agent conflicts --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+			defer cancel()
+
+			plat := platform.Current()
+			store, err := storage.NewSQLiteStore(plat.GetDataDir())
+			if err != nil {
+				return fmt.Errorf("failed to create storage: %w", err)
+			}
+			defer store.Close()
+
+			if err := store.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			catMgr := catalog.NewManager(cfg, store)
+			agentDefs, err := catMgr.GetAgentsForPlatform(ctx, string(plat.ID()))
+			if err != nil {
+				return fmt.Errorf("failed to load catalog: %w", err)
+			}
+
+			named := len(args) == 1
+			if named {
+				var found []catalog.AgentDef
+				for _, def := range agentDefs {
+					if def.ID == args[0] {
+						found = append(found, def)
+					}
+				}
+				if len(found) == 0 {
+					return fmt.Errorf("agent %q not found in catalog", args[0])
+				}
+				agentDefs = found
+			}
+
+			items := findConflicts(ctx, plat, agentDefs, named)
+
+			if format == "json" {
+				return outputConflictsJSON(os.Stdout, items)
+			}
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			return outputConflictsTable(items, printer)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "output format (table, json)")
+
+	return cmd
+}
+
+// findConflicts returns the agent executables with more than one copy on
+// PATH, or with at least one when showAll is set.
+func findConflicts(ctx context.Context, plat platform.Platform, agentDefs []catalog.AgentDef, showAll bool) []ConflictItem {
+	minCopies := 2
+	if showAll {
+		minCopies = 1
+	}
+
+	var items []ConflictItem
+	for _, def := range agentDefs {
+		for _, executable := range def.Detection.Executables {
+			matches := strategies.FindPathMatches(plat, executable)
+			if len(matches) < minCopies {
+				continue
+			}
+			items = append(items, ConflictItem{
+				Agent:      def.ID,
+				Name:       def.Name,
+				Executable: executable,
+				Copies: conflictCopies(matches, func(path string) string {
+					return strategies.ExecutableVersion(ctx, def, path).String()
+				}),
+			})
+		}
+	}
+	return items
+}
+
+// conflictCopies converts PATH matches to their display form, asking
+// version for each copy's version.
+func conflictCopies(matches []strategies.PathMatch, version func(path string) string) []ConflictCopy {
+	copies := make([]ConflictCopy, 0, len(matches))
+	for i, m := range matches {
+		c := ConflictCopy{
+			Rank:    i + 1,
+			Path:    m.Path,
+			Source:  m.Owner,
+			Version: version(m.Path),
+			Active:  m.Active,
+		}
+		if m.Target != m.Path {
+			c.Target = m.Target
+		}
+		if c.Source == "" {
+			c.Source = "native"
+		}
+		if c.Version == "0.0.0" {
+			c.Version = ""
+		}
+		copies = append(copies, c)
+	}
+	return copies
+}
+
+func outputConflictsJSON(w io.Writer, items []ConflictItem) error {
+	if items == nil {
+		items = []ConflictItem{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func outputConflictsTable(items []ConflictItem, printer *output.Printer) error {
+	if len(items) == 0 {
+		printer.Success("No agent has more than one copy on PATH")
+		return nil
+	}
+
+	styles := printer.Styles()
+	for i, item := range items {
+		if i > 0 {
+			printer.Println()
+		}
+		printer.Print("%s (%s): %q", styles.FormatAgentName(item.Name), item.Agent, item.Executable)
+
+		table := output.NewTable()
+		table.SetHeaders(
+			styles.FormatHeader("#"),
+			styles.FormatHeader("PATH"),
+			styles.FormatHeader("SOURCE"),
+			styles.FormatHeader("VERSION"),
+			styles.FormatHeader("STATUS"),
+		)
+
+		for _, c := range item.Copies {
+			path := c.Path
+			if c.Target != "" {
+				path += " -> " + c.Target
+			}
+			version := c.Version
+			if version == "" {
+				version = "-"
+			}
+			status := styles.FormatBadge("shadowed", "warning")
+			if c.Active {
+				status = styles.FormatBadge("active", "success")
+			}
+			table.AddRow(fmt.Sprint(c.Rank), path, styles.FormatMethod(c.Source), version, status)
+		}
+		table.Render()
+
+		active := item.Copies[0]
+		switch shadowed := len(item.Copies) - 1; shadowed {
+		case 0:
+		case 1:
+			printer.Info("Running %q starts #1 (%s); #2 only runs by full path.", item.Executable, active.Source)
+		default:
+			printer.Info("Running %q starts #1 (%s); #2-#%d only run by full path.", item.Executable, active.Source, shadowed+1)
+		}
+	}
+
+	printer.Println()
+	printer.Print("Shells search PATH directories in order and run the first match. To switch")
+	printer.Print("copies, uninstall the ones you do not use or move the wanted copy's directory")
+	printer.Print("earlier in PATH.")
+	return nil
+}
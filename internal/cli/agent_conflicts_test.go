@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
+)
+
+func TestConflictCopies(t *testing.T) {
+	matches := []strategies.PathMatch{
+		{Path: "/home/me/.local/bin/claude", Target: "/home/me/.local/share/claude/versions/2.1.0", Active: true},
+		{Path: "/usr/local/bin/claude", Target: "/usr/local/lib/node_modules/@anthropic-ai/claude-code/cli.js", Owner: "npm"},
+	}
+	versions := map[string]string{
+		"/home/me/.local/bin/claude": "2.1.0",
+		"/usr/local/bin/claude":      "0.0.0",
+	}
+
+	copies := conflictCopies(matches, func(path string) string { return versions[path] })
+	if len(copies) != 2 {
+		t.Fatalf("got %d copies, want 2", len(copies))
+	}
+
+	if c := copies[0]; c.Rank != 1 || !c.Active || c.Source != "native" || c.Version != "2.1.0" {
+		t.Errorf("copies[0] = %+v", c)
+	}
+	if c := copies[1]; c.Rank != 2 || c.Active || c.Source != "npm" || c.Version != "" {
+		t.Errorf("copies[1] = %+v, want a shadowed npm copy with no version", c)
+	}
+	if copies[1].Target == "" {
+		t.Error("symlink target should be reported")
+	}
+}
+
+func TestOutputConflictsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := outputConflictsJSON(&buf, nil); err != nil {
+		t.Fatalf("outputConflictsJSON() error = %v", err)
+	}
+
+	var got []ConflictItem
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("got %v, want an empty array", got)
+	}
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// HistoryItem is one recorded install, update or uninstall as shown by
+// `agent history`.
+type HistoryItem struct {
+	ID          int64      `json:"id"`
+	Agent       string     `json:"agent"`
+	Name        string     `json:"name"`
+	Action      string     `json:"action"`
+	Method      string     `json:"method"`
+	FromVersion string     `json:"from_version,omitempty"`
+	ToVersion   string     `json:"to_version,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// DetectedChangeItem is one change detection noticed that agentmgr did not
+// make, as shown by `agent history --detected`.
+type DetectedChangeItem struct {
+	ID          int64     `json:"id"`
+	Agent       string    `json:"agent"`
+	Name        string    `json:"name"`
+	Change      string    `json:"change"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path,omitempty"`
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+func newAgentHistoryCommand(cfg *config.Config) *cobra.Command {
+	var (
+		format   string
+		limit    int
+		detected bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history [agent-name]",
+		Short: "Show install, update and uninstall history",
+		Long: `Show the recorded history of installs, updates, rollbacks and uninstalls,
+newest first.
+
+With an agent name only that agent's history is shown. Every path that
+changes an agent records here: the CLI, the systray helper, and the REST and
+gRPC APIs.
+
+With --detected the changes made outside agentmgr are shown instead:
+installations that detection found added, upgraded or removed since its
+previous run, such as a manual npm install or a brew upgrade.`,
+		Example: `  agentmgr agent history
+  agentmgr agent history aider --limit 5
+  agentmgr agent history --detected
+  agentmgr agent history --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			agentID := ""
+			if len(args) == 1 {
+				agentID = args[0]
+			}
+
+			plat := platform.Current()
+			store, err := storage.NewSQLiteStore(plat.GetDataDir())
+			if err != nil {
+				return fmt.Errorf("failed to create storage: %w", err)
+			}
+			defer store.Close()
+
+			if err := store.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+
+			if detected {
+				changes, err := store.GetRegistryEvents(ctx, agentID, limit)
+				if err != nil {
+					return err
+				}
+
+				items := make([]DetectedChangeItem, 0, len(changes))
+				for _, change := range changes {
+					items = append(items, detectedChangeItemFromRecord(change))
+				}
+
+				if format == "json" {
+					return outputHistoryJSON(os.Stdout, items)
+				}
+				return outputDetectedChangesTable(items, printer)
+			}
+
+			events, err := store.GetUpdateHistory(ctx, agentID, limit)
+			if err != nil {
+				return err
+			}
+
+			items := make([]HistoryItem, 0, len(events))
+			for _, event := range events {
+				items = append(items, historyItemFromEvent(event))
+			}
+
+			if format == "json" {
+				return outputHistoryJSON(os.Stdout, items)
+			}
+
+			return outputHistoryTable(items, printer)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "output format (table, json)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of events to show")
+	cmd.Flags().BoolVar(&detected, "detected", false, "show changes made outside agentmgr that detection noticed")
+
+	return cmd
+}
+
+// historyItemFromEvent converts a stored event to its display form.
+func historyItemFromEvent(event *storage.UpdateEvent) HistoryItem {
+	return HistoryItem{
+		ID:          event.ID,
+		Agent:       event.AgentID,
+		Name:        event.AgentName,
+		Action:      string(event.Action),
+		Method:      event.InstallMethod,
+		FromVersion: event.FromVersion,
+		ToVersion:   event.ToVersion,
+		Status:      string(event.Status),
+		Error:       event.ErrorMessage,
+		StartedAt:   event.StartedAt,
+		CompletedAt: event.CompletedAt,
+	}
+}
+
+// detectedChangeItemFromRecord converts a stored registry event to its
+// display form.
+func detectedChangeItemFromRecord(record *storage.RegistryEventRecord) DetectedChangeItem {
+	return DetectedChangeItem{
+		ID:          record.ID,
+		Agent:       record.AgentID,
+		Name:        record.AgentName,
+		Change:      string(record.Type),
+		Method:      record.InstallMethod,
+		Path:        record.ExecutablePath,
+		FromVersion: record.FromVersion,
+		ToVersion:   record.ToVersion,
+		DetectedAt:  record.DetectedAt,
+	}
+}
+
+func outputHistoryJSON(w io.Writer, items any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func outputHistoryTable(items []HistoryItem, printer *output.Printer) error {
+	if len(items) == 0 {
+		printer.Info("No history recorded")
+		return nil
+	}
+
+	styles := printer.Styles()
+	table := output.NewTable()
+	table.SetHeaders(
+		styles.FormatHeader("WHEN"),
+		styles.FormatHeader("AGENT"),
+		styles.FormatHeader("ACTION"),
+		styles.FormatHeader("METHOD"),
+		styles.FormatHeader("VERSION"),
+		styles.FormatHeader("STATUS"),
+	)
+
+	for _, item := range items {
+		table.AddRow(
+			item.StartedAt.Local().Format("2006-01-02 15:04"),
+			styles.Info.Render(item.Agent),
+			item.Action,
+			styles.FormatMethod(item.Method),
+			historyVersion(item.FromVersion, item.ToVersion),
+			historyStatusBadge(styles, item.Status),
+		)
+	}
+
+	table.Render()
+	return nil
+}
+
+func outputDetectedChangesTable(items []DetectedChangeItem, printer *output.Printer) error {
+	if len(items) == 0 {
+		printer.Info("No changes outside agentmgr detected")
+		return nil
+	}
+
+	styles := printer.Styles()
+	table := output.NewTable()
+	table.SetHeaders(
+		styles.FormatHeader("DETECTED"),
+		styles.FormatHeader("AGENT"),
+		styles.FormatHeader("CHANGE"),
+		styles.FormatHeader("METHOD"),
+		styles.FormatHeader("VERSION"),
+		styles.FormatHeader("PATH"),
+	)
+
+	for _, item := range items {
+		table.AddRow(
+			item.DetectedAt.Local().Format("2006-01-02 15:04"),
+			styles.Info.Render(item.Agent),
+			item.Change,
+			styles.FormatMethod(item.Method),
+			historyVersion(item.FromVersion, item.ToVersion),
+			item.Path,
+		)
+	}
+
+	table.Render()
+	return nil
+}
+
+// historyVersion shows a version change as "from -> to", or whichever
+// side is known.
+func historyVersion(from, to string) string {
+	switch {
+	case from != "" && to != "":
+		return from + " -> " + to
+	case from != "":
+		return from
+	case to != "":
+		return to
+	default:
+		return "-"
+	}
+}
+
+// historyStatusBadge colors an event status by outcome.
+func historyStatusBadge(styles *output.Styles, status string) string {
+	switch storage.UpdateStatus(status) {
+	case storage.UpdateStatusCompleted:
+		return styles.FormatBadge(status, "success")
+	case storage.UpdateStatusFailed:
+		return styles.FormatBadge(status, "error")
+	case storage.UpdateStatusCancelled, storage.UpdateStatusRolledBack:
+		return styles.FormatBadge(status, "warning")
+	default:
+		return styles.FormatBadge(status, "info")
+	}
+}
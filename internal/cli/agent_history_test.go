@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+func TestOutputHistoryJSON(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []*storage.UpdateEvent{
+		{ID: 2, AgentID: "aider", AgentName: "Aider", Action: storage.UpdateActionUpdate, InstallMethod: "pip", FromVersion: "0.50.0", ToVersion: "0.51.0", Status: storage.UpdateStatusFailed, ErrorMessage: "boom", StartedAt: started},
+		{ID: 1, AgentID: "aider", AgentName: "Aider", Action: storage.UpdateActionInstall, InstallMethod: "pip", ToVersion: "0.50.0", Status: storage.UpdateStatusCompleted, StartedAt: started, CompletedAt: &started},
+	}
+
+	items := make([]HistoryItem, 0, len(events))
+	for _, event := range events {
+		items = append(items, historyItemFromEvent(event))
+	}
+
+	var buf bytes.Buffer
+	if err := outputHistoryJSON(&buf, items); err != nil {
+		t.Fatalf("outputHistoryJSON() error = %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d items, want 2", len(got))
+	}
+
+	if got[0]["action"] != "update" || got[0]["status"] != "failed" || got[0]["error"] != "boom" {
+		t.Errorf("first item = %v", got[0])
+	}
+	if _, ok := got[0]["completed_at"]; ok {
+		t.Error("completed_at should be omitted for an unfinished event")
+	}
+	if _, ok := got[1]["from_version"]; ok {
+		t.Error("from_version should be omitted for a fresh install")
+	}
+}
+
+func TestOutputDetectedChangesJSON(t *testing.T) {
+	detected := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []*storage.RegistryEventRecord{
+		{ID: 2, Type: agent.RegistryEventRemoved, AgentID: "aider", AgentName: "Aider", InstallMethod: "pip", FromVersion: "0.51.0", DetectedAt: detected},
+		{ID: 1, Type: agent.RegistryEventAdded, AgentID: "claude-code", AgentName: "Claude Code", InstallMethod: "npm", ExecutablePath: "/usr/local/bin/claude", ToVersion: "2.0.0", DetectedAt: detected},
+	}
+
+	items := make([]DetectedChangeItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, detectedChangeItemFromRecord(record))
+	}
+
+	var buf bytes.Buffer
+	if err := outputHistoryJSON(&buf, items); err != nil {
+		t.Fatalf("outputHistoryJSON() error = %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d items, want 2", len(got))
+	}
+	if got[0]["change"] != "removed" || got[0]["from_version"] != "0.51.0" {
+		t.Errorf("first item = %v", got[0])
+	}
+	if _, ok := got[0]["to_version"]; ok {
+		t.Error("to_version should be omitted for a removal")
+	}
+	if got[1]["change"] != "added" || got[1]["path"] != "/usr/local/bin/claude" {
+		t.Errorf("second item = %v", got[1])
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 )
 
 // ErrSilent is wrapped into errors returned by --json paths whose
@@ -32,6 +34,10 @@ const (
 	batchStatusError   = "error"
 	batchStatusSkipped = "skipped"
 	batchStatusNoop    = "noop"
+	batchStatusPinned  = "pinned"
+	// batchStatusRolledBack is an update that failed verification and
+	// was undone; it counts as a failure.
+	batchStatusRolledBack = "rolled_back"
 )
 
 type agentBatchEntry struct {
@@ -42,6 +48,8 @@ type agentBatchEntry struct {
 	PreviousVersion string `json:"previous_version,omitempty"`
 	Reason          string `json:"reason,omitempty"`
 	Error           string `json:"error,omitempty"`
+	// Plan is what --dry-run would have done.
+	Plan *providers.Plan `json:"plan,omitempty"`
 }
 
 type agentBatchSummary struct {
@@ -49,6 +57,8 @@ type agentBatchSummary struct {
 	Succeeded int `json:"succeeded"`
 	Failed    int `json:"failed"`
 	Skipped   int `json:"skipped,omitempty"`
+	// RolledBack counts the failures whose update was undone.
+	RolledBack int `json:"rolled_back,omitempty"`
 }
 
 // emitAgentBatchJSON writes the batch result to stdout as indented JSON
@@ -69,7 +79,10 @@ func emitAgentBatchJSON(command string, entries []agentBatchEntry) error {
 			res.Summary.Succeeded++
 		case batchStatusError:
 			res.Summary.Failed++
-		case batchStatusSkipped, batchStatusNoop:
+		case batchStatusRolledBack:
+			res.Summary.Failed++
+			res.Summary.RolledBack++
+		case batchStatusSkipped, batchStatusNoop, batchStatusPinned:
 			res.Summary.Skipped++
 		}
 	}
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
 )
 
 // captureStdout redirects os.Stdout for the duration of fn and returns
@@ -112,6 +116,51 @@ func TestEmitAgentBatchJSON_MixedOutcomes(t *testing.T) {
 	}
 }
 
+func TestEmitAgentBatchJSON_RolledBack(t *testing.T) {
+	entries := []agentBatchEntry{
+		{Agent: "ok", Status: batchStatusSuccess, Method: "brew", Version: "1.0.0"},
+		{Agent: "undone", Status: batchStatusRolledBack, Method: "npm", Version: "2.0.0", PreviousVersion: "2.0.0", Error: "failed verification"},
+	}
+
+	out, err := captureStdout(t, func() error {
+		return emitAgentBatchJSON("update", entries)
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 agent(s) failed") {
+		t.Errorf("error = %v, want a rolled back update counted as failed", err)
+	}
+
+	var got agentBatchResult
+	if jerr := json.Unmarshal([]byte(out), &got); jerr != nil {
+		t.Fatalf("output is not valid JSON: %v", jerr)
+	}
+	if got.Summary.Failed != 1 || got.Summary.RolledBack != 1 {
+		t.Errorf("summary = %+v, want failed=1 rolled_back=1", got.Summary)
+	}
+	if got.Results[1].Status != "rolled_back" {
+		t.Errorf("status = %q, want rolled_back", got.Results[1].Status)
+	}
+}
+
+func TestUpdateFailureEntry(t *testing.T) {
+	base := agentBatchEntry{Agent: "claude-code", Method: "npm", PreviousVersion: "2.0.0"}
+
+	entry := updateFailureEntry(base, errors.New("npm update failed"))
+	if entry.Status != batchStatusError || entry.Error != "npm update failed" || entry.Version != "" {
+		t.Errorf("plain failure = %+v", entry)
+	}
+
+	verr := &installer.VerificationError{AgentID: "claude-code", FromVersion: agent.MustParseVersion("2.0.0"), Err: errors.New("version is still 2.0.0")}
+	entry = updateFailureEntry(base, fmt.Errorf("update: %w", verr))
+	if entry.Status != batchStatusRolledBack || entry.Version != "2.0.0" {
+		t.Errorf("rolled back failure = %+v", entry)
+	}
+
+	verr.RollbackErr = errors.New("npm install failed")
+	if entry = updateFailureEntry(base, verr); entry.Status != batchStatusError {
+		t.Errorf("failed rollback status = %q, want %q", entry.Status, batchStatusError)
+	}
+}
+
 func TestEmitAgentBatchJSON_EmptyEntries(t *testing.T) {
 	out, err := captureStdout(t, func() error {
 		return emitAgentBatchJSON("remove", nil)
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/internal/orchestrator"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+func newAgentPinCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <agent-name> [version]",
+		Short: "Pin an agent to a version",
+		Long: `Pin an agent so that it is not moved forward by updates.
+
+When no version is given, the currently installed version is pinned.
+Pinned agents are skipped by 'agent update --all' and refused by
+'agent update <agent-name>' unless --force is passed.
+
+The version may instead be a constraint such as "^2.1" or ">=1.0, <2.0".
+Constrained agents keep updating, but only to the newest release that
+satisfies the constraint. The pin is written to the config file under
+agents.<agent-name>.pinned_version.`,
+		Example: `  agentmgr agent pin aider
+  agentmgr agent pin claude-code 1.0.30
+  agentmgr agent pin claude-code "^2.1"`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+			defer cancel()
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			agentID := args[0]
+
+			version := ""
+			if len(args) == 2 {
+				version = args[1]
+				if _, err := agent.ParseVersionConstraints(version); err != nil {
+					return fmt.Errorf("invalid version %q: %w", version, err)
+				}
+			} else {
+				installed, err := installedVersionForPin(ctx, cfg, agentID)
+				if err != nil {
+					return err
+				}
+				version = installed
+			}
+
+			if err := savePinnedVersion(cfg, agentID, version); err != nil {
+				return err
+			}
+
+			printer.Success("Pinned %s to %s", agentID, version)
+			return nil
+		},
+	}
+}
+
+func newAgentUnpinCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpin <agent-name>",
+		Short: "Remove a version pin from an agent",
+		Long: `Remove a version pin so the agent is updated normally again.
+
+The pin is cleared from the config file; other per-agent settings are
+left untouched.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			agentID := args[0]
+
+			if cfg.GetPinnedVersion(agentID) == "" {
+				printer.Info("%s is not pinned", agentID)
+				return nil
+			}
+
+			if err := savePinnedVersion(cfg, agentID, ""); err != nil {
+				return err
+			}
+
+			printer.Success("Unpinned %s", agentID)
+			return nil
+		},
+	}
+}
+
+// installedVersionForPin returns the installed version of agentID so that
+// `agent pin <id>` can pin to whatever is on disk right now. It refuses
+// to guess when several installations disagree on the version.
+func installedVersionForPin(ctx context.Context, cfg *config.Config, agentID string) (string, error) {
+	plat := platform.Current()
+
+	store, err := storage.NewSQLiteStore(plat.GetDataDir())
+	if err != nil {
+		return "", fmt.Errorf("failed to create storage: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.Initialize(ctx); err != nil {
+		return "", fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	catMgr := catalog.NewManager(cfg, store)
+	det := detector.New(plat, detector.WithConfig(cfg))
+	instMgr := installer.NewManager(plat)
+	pipeline := orchestrator.NewFromManagers(cfg, plat, store, catMgr, det, instMgr)
+
+	res, err := pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{SkipVersionCheck: true})
+	if err != nil {
+		return "", err
+	}
+
+	version := ""
+	for _, inst := range res.Installations {
+		if inst.AgentID != agentID {
+			continue
+		}
+		v := inst.InstalledVersion.String()
+		if version != "" && version != v {
+			return "", fmt.Errorf("agent %q has installations at %s and %s; pass the version to pin explicitly", agentID, version, v)
+		}
+		version = v
+	}
+
+	if version == "" {
+		return "", fmt.Errorf("agent %q not installed; pass the version to pin explicitly", agentID)
+	}
+	return version, nil
+}
+
+// savePinnedVersion writes agents.<agentID>.pinned_version to the config
+// file and mirrors the change into cfg so the rest of the process sees it.
+// An empty version clears the pin.
+func savePinnedVersion(cfg *config.Config, agentID, version string) error {
+	loader := config.NewLoader()
+	if _, err := loader.Load(""); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := loader.SetAndSave(fmt.Sprintf("agents.%s.pinned_version", agentID), version); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if cfg.Agents == nil {
+		cfg.Agents = make(map[string]config.AgentConfig)
+	}
+	agentCfg := cfg.Agents[agentID]
+	agentCfg.PinnedVersion = version
+	cfg.Agents[agentID] = agentCfg
+	return nil
+}
+
+// constraintTarget resolves the version a constraint-pinned installation
+// should be updated to: the newest release of its install method that
+// satisfies constraints. A non-empty reason means the installation should
+// be skipped instead, either because the registry could not be queried or
+// because nothing in range is newer than what is installed (force accepts
+// an in-range release that is not newer, so the update acts as a reinstall).
+func constraintTarget(ctx context.Context, inst *installer.Manager, cat *catalog.Catalog, constraints agent.VersionConstraints, installation *agent.Installation, force bool) (agent.Version, string) {
+	agentDef, ok := cat.GetAgent(installation.AgentID)
+	if !ok {
+		return agent.Version{}, "not found in catalog"
+	}
+	methodDef, ok := agentDef.GetInstallMethod(string(installation.Method))
+	if !ok {
+		return agent.Version{}, fmt.Sprintf("install method %s not found", installation.Method)
+	}
+
+	target, err := inst.ResolveVersion(ctx, methodDef, constraints)
+	if err != nil {
+		return agent.Version{}, err.Error()
+	}
+	if !force && !target.IsNewerThan(installation.InstalledVersion) {
+		return agent.Version{}, fmt.Sprintf("no version satisfying %s newer than %s", constraints, installation.InstalledVersion)
+	}
+	return target, ""
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+func newQuietPrinter(cfg *config.Config) *output.Printer {
+	printer := output.NewPrinter(cfg, true)
+	printer.SetOutput(io.Discard)
+	printer.SetErrorOutput(io.Discard)
+	return printer
+}
+
+func pinnedTestFixtures() (*config.Config, []*agent.Installation, *catalog.Catalog) {
+	cfg := config.Default()
+	cfg.Agents = map[string]config.AgentConfig{
+		"aider": {PinnedVersion: "0.80.0"},
+	}
+
+	latest := agent.MustParseVersion("0.86.0")
+	installations := []*agent.Installation{{
+		AgentID:          "aider",
+		AgentName:        "Aider",
+		Method:           agent.InstallMethodPip,
+		InstalledVersion: agent.MustParseVersion("0.80.0"),
+		LatestVersion:    &latest,
+	}}
+
+	cat := &catalog.Catalog{Agents: map[string]catalog.AgentDef{
+		"aider": {
+			ID:   "aider",
+			Name: "Aider",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"pip": {Method: "pip", Package: "aider-chat"},
+			},
+		},
+	}}
+	return cfg, installations, cat
+}
+
+func TestUpdateAllAgents_SkipsPinned(t *testing.T) {
+	cfg, installations, cat := pinnedTestFixtures()
+
+	// A nil installer proves the pinned agent never reaches Update, even
+	// with force set.
+	entries, err := updateAllAgents(context.Background(), cfg, installations, cat, nil, nil, true, false, newQuietPrinter(cfg))
+	if err != nil {
+		t.Fatalf("updateAllAgents() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Status != batchStatusPinned {
+		t.Errorf("Status = %q, want %q", entries[0].Status, batchStatusPinned)
+	}
+	if entries[0].Reason != "pinned to 0.80.0" {
+		t.Errorf("Reason = %q, want %q", entries[0].Reason, "pinned to 0.80.0")
+	}
+}
+
+func TestUpdateSingleAgent_RefusesPinnedWithoutForce(t *testing.T) {
+	cfg, installations, cat := pinnedTestFixtures()
+
+	entries, err := updateSingleAgent(context.Background(), cfg, "aider", installations, cat, nil, nil, false, false, newQuietPrinter(cfg))
+	if err == nil {
+		t.Fatal("expected an error for a pinned agent without --force")
+	}
+	if len(entries) != 1 || entries[0].Status != batchStatusError {
+		t.Fatalf("entries = %+v, want one error entry", entries)
+	}
+
+	// With --force and --dry-run the pin is bypassed and the update is
+	// planned, not carried out.
+	inst := installer.NewManager(platform.Current())
+	inst.RegisterProvider(planOnlyProvider{})
+	entries, err = updateSingleAgent(context.Background(), cfg, "aider", installations, cat, inst, nil, true, true, newQuietPrinter(cfg))
+	if err != nil {
+		t.Fatalf("forced dry run error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Status != batchStatusNoop {
+		t.Fatalf("entries = %+v, want one dry-run noop entry", entries)
+	}
+	if plan := entries[0].Plan; plan == nil || plan.Operation != providers.OperationUpdate || len(plan.Commands) != 1 {
+		t.Errorf("Plan = %+v, want the planned pip update", plan)
+	}
+}
+
+// planOnlyProvider handles pip and refuses to run anything, so tests
+// using it pass only if the CLI just plans.
+type planOnlyProvider struct{}
+
+func (planOnlyProvider) Name() string      { return "plan-only" }
+func (planOnlyProvider) Methods() []string { return []string{"pip"} }
+func (planOnlyProvider) IsAvailable() bool { return true }
+
+func (planOnlyProvider) Install(context.Context, catalog.AgentDef, catalog.InstallMethodDef, bool) (*providers.Result, error) {
+	return nil, errors.New("install must not run")
+}
+
+func (planOnlyProvider) Update(context.Context, *agent.Installation, catalog.AgentDef, catalog.InstallMethodDef) (*providers.Result, error) {
+	return nil, errors.New("update must not run")
+}
+
+func (planOnlyProvider) Uninstall(context.Context, *agent.Installation, catalog.InstallMethodDef) error {
+	return errors.New("uninstall must not run")
+}
+
+func (planOnlyProvider) GetLatestVersion(context.Context, catalog.InstallMethodDef) (agent.Version, error) {
+	return agent.Version{}, errors.New("no registry")
+}
+
+func (planOnlyProvider) PlanInstall(_ context.Context, _ catalog.AgentDef, method catalog.InstallMethodDef, _ bool) (*providers.Plan, error) {
+	return &providers.Plan{Commands: []providers.Command{{Argv: []string{"pip", "install", method.Package}}}}, nil
+}
+
+func (planOnlyProvider) PlanUpdate(_ context.Context, _ *agent.Installation, _ catalog.AgentDef, method catalog.InstallMethodDef) (*providers.Plan, error) {
+	return &providers.Plan{Commands: []providers.Command{{Argv: []string{"pip", "install", "--upgrade", method.Package}}}}, nil
+}
+
+func (planOnlyProvider) PlanUninstall(_ context.Context, _ *agent.Installation, method catalog.InstallMethodDef) (*providers.Plan, error) {
+	return &providers.Plan{Commands: []providers.Command{{Argv: []string{"pip", "uninstall", "-y", method.Package}}}}, nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// dryRunEntry returns the batch entry reporting a planned operation. A
+// planning failure turns it into an error entry: the real run would fail
+// the same way.
+func dryRunEntry(entry agentBatchEntry, plan *providers.Plan, err error) agentBatchEntry {
+	if err != nil {
+		entry.Status = batchStatusError
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Status = batchStatusNoop
+	entry.Reason = "dry run"
+	entry.Plan = plan
+	return entry
+}
+
+// planInstallEntry plans the install of agentID the way installOne would
+// carry it out.
+func planInstallEntry(ctx context.Context, cfg *config.Config, plat platform.Platform, cat *catalog.Catalog, inst *installer.Manager, agentID, method string, force bool) agentBatchEntry {
+	entry := agentBatchEntry{Agent: agentID, Version: providers.TargetVersion(ctx)}
+	agentDef, ok := cat.GetAgent(agentID)
+	if !ok {
+		return dryRunEntry(entry, nil, fmt.Errorf("agent %q not found in catalog", agentID))
+	}
+	methodDef, chosenMethod, err := installMethodFor(cfg, plat, agentDef, method)
+	entry.Method = chosenMethod
+	if err != nil {
+		return dryRunEntry(entry, nil, err)
+	}
+	plan, err := inst.PlanInstall(ctx, agentDef, methodDef, force)
+	return dryRunEntry(entry, plan, err)
+}
+
+// planUpdateEntry plans the update of installation to label, the version
+// shown for it, moving to its constraint target when it has one.
+func planUpdateEntry(ctx context.Context, cat *catalog.Catalog, inst *installer.Manager, installation *agent.Installation, targets map[*agent.Installation]agent.Version, label string) agentBatchEntry {
+	entry := agentBatchEntry{
+		Agent:           installation.AgentID,
+		Method:          string(installation.Method),
+		PreviousVersion: installation.InstalledVersion.String(),
+		Version:         label,
+	}
+	agentDef, ok := cat.GetAgent(installation.AgentID)
+	if !ok {
+		return dryRunEntry(entry, nil, fmt.Errorf("agent %q not found in catalog", installation.AgentID))
+	}
+	methodDef, ok := agentDef.GetInstallMethod(string(installation.Method))
+	if !ok {
+		return dryRunEntry(entry, nil, fmt.Errorf("install method %s not found in catalog for %s", installation.Method, installation.AgentID))
+	}
+	if target, ok := targets[installation]; ok {
+		ctx = providers.WithTargetVersion(ctx, target.String())
+	}
+	plan, err := inst.PlanUpdate(ctx, installation, agentDef, methodDef)
+	return dryRunEntry(entry, plan, err)
+}
+
+// planLines renders a plan for the terminal: each command as a shell
+// line, followed by its directory when that is not the current one, its
+// extra environment and whether it needs administrator rights, then the
+// steps taken without a command.
+func planLines(plan *providers.Plan) []string {
+	wd, _ := os.Getwd()
+	var lines []string
+	for _, c := range plan.Commands {
+		lines = append(lines, "$ "+c.String())
+		if c.Dir != "" && c.Dir != wd {
+			lines = append(lines, "    in "+c.Dir)
+		}
+		for _, env := range c.Env {
+			lines = append(lines, "    with "+env)
+		}
+		if c.Elevated {
+			lines = append(lines, "    runs with administrator rights")
+		}
+	}
+	for _, step := range plan.Steps {
+		lines = append(lines, "- "+step)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "(nothing to do)")
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+func TestPlanInstallEntry(t *testing.T) {
+	cfg, _, cat := pinnedTestFixtures()
+	plat := platform.Current()
+	inst := installer.NewManager(plat)
+	inst.RegisterProvider(planOnlyProvider{})
+	ctx := providers.WithTargetVersion(context.Background(), "0.85.0")
+
+	entry := planInstallEntry(ctx, cfg, plat, cat, inst, "aider", "pip", false)
+	if entry.Status != batchStatusNoop || entry.Method != "pip" || entry.Version != "0.85.0" || entry.Reason != "dry run" {
+		t.Errorf("entry = %+v, want a pip dry-run noop for 0.85.0", entry)
+	}
+	if entry.Plan == nil || entry.Plan.Operation != providers.OperationInstall || entry.Plan.TargetVersion != "0.85.0" {
+		t.Errorf("Plan = %+v, want the planned install", entry.Plan)
+	}
+
+	entry = planInstallEntry(ctx, cfg, plat, cat, inst, "aider", "brew", false)
+	if entry.Status != batchStatusError || entry.Plan != nil || !strings.Contains(entry.Error, `"brew" not available`) {
+		t.Errorf("entry = %+v, want an error for a method the catalog lacks", entry)
+	}
+
+	entry = planInstallEntry(ctx, cfg, plat, cat, inst, "nope", "", false)
+	if entry.Status != batchStatusError || entry.Error != `agent "nope" not found in catalog` {
+		t.Errorf("entry = %+v, want an error for an unknown agent", entry)
+	}
+}
+
+func TestPlanLines(t *testing.T) {
+	wd, _ := os.Getwd()
+	plan := &providers.Plan{
+		Commands: []providers.Command{
+			{Argv: []string{"npm", "install", "-g", "agent"}, Dir: wd},
+			{Argv: []string{"/bin/sh", "-c", "sudo make install"}, Dir: "/src/agent", Env: []string{"PREFIX=/opt"}, Elevated: true},
+		},
+		Steps: []string{"remove /opt/agent.old"},
+	}
+	want := []string{
+		"$ npm install -g agent",
+		"$ /bin/sh -c 'sudo make install'",
+		"    in /src/agent",
+		"    with PREFIX=/opt",
+		"    runs with administrator rights",
+		"- remove /opt/agent.old",
+	}
+	if got := planLines(plan); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("planLines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := planLines(&providers.Plan{}); len(got) != 1 || got[0] != "(nothing to do)" {
+		t.Errorf("planLines(empty) = %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/internal/orchestrator"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// rollbackHistoryLimit bounds how far back `agent rollback` looks for a
+// completed update to undo.
+const rollbackHistoryLimit = 50
+
+func newAgentRollbackCommand(cfg *config.Config) *cobra.Command {
+	var (
+		method string
+		force  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback <agent-name>",
+		Short: "Reinstall the version an agent had before its last update",
+		Long: `Roll an agent back to the version it had before its most recent
+successful update, as recorded in the update history.
+
+Only npm, pip/pipx/uv and Homebrew installations, and binary installations
+made from GitHub releases, can be rolled back, since those are the methods
+that can install a specific version. The rollback is
+recorded in 'agentmgr agent history' but is never itself rolled back, so
+running rollback twice in a row leaves the agent where the first one put it.
+
+Pass --method to pick the installation when an agent is installed by more
+than one method. Agents pinned with 'agentmgr agent pin' are refused
+unless --force is passed.`,
+		Example: `  agentmgr agent rollback aider
+  agentmgr agent rollback claude-code --method npm`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			agentID := args[0]
+			plat := platform.Current()
+
+			store, err := storage.NewSQLiteStore(plat.GetDataDir())
+			if err != nil {
+				return fmt.Errorf("failed to create storage: %w", err)
+			}
+			defer store.Close()
+
+			if err := store.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			history, err := store.GetUpdateHistory(ctx, agentID, rollbackHistoryLimit)
+			if err != nil {
+				return err
+			}
+			event := findRollbackEvent(history, method)
+			if event == nil {
+				return fmt.Errorf("no completed update of %q recorded to roll back", agentID)
+			}
+
+			if cfg.IsAgentPinned(agentID) && !force {
+				return fmt.Errorf("agent %q is pinned to %s (use --force to override)", agentID, cfg.GetPinnedVersion(agentID))
+			}
+
+			catMgr := catalog.NewManager(cfg, store)
+			det := detector.New(plat, detector.WithConfig(cfg))
+			inst := installer.NewManager(plat)
+			pipeline := orchestrator.NewFromManagers(cfg, plat, store, catMgr, det, inst)
+
+			res, err := pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{ForceRefresh: true, SkipVersionCheck: true})
+			if err != nil {
+				return err
+			}
+
+			var installation *agent.Installation
+			for _, candidate := range res.Installations {
+				if candidate.AgentID == agentID && string(candidate.Method) == event.InstallMethod {
+					installation = candidate
+					break
+				}
+			}
+			if installation == nil {
+				return fmt.Errorf("agent %q is no longer installed via %s", agentID, event.InstallMethod)
+			}
+
+			if installation.InstalledVersion.String() == event.FromVersion {
+				printer.Info("%s is already at %s", installation.AgentName, event.FromVersion)
+				return nil
+			}
+
+			cat, err := catMgr.Get(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to load catalog: %w", err)
+			}
+			agentDef, ok := cat.GetAgent(agentID)
+			if !ok {
+				return fmt.Errorf("agent %q not found in catalog", agentID)
+			}
+			methodDef, ok := agentDef.GetInstallMethod(event.InstallMethod)
+			if !ok {
+				return fmt.Errorf("install method %s not found for %q", event.InstallMethod, agentID)
+			}
+
+			msg := fmt.Sprintf("Rolling back %s via %s: %s -> %s", agentDef.Name, installation.Method, installation.InstalledVersion, event.FromVersion)
+			verbose := verboseInstallOutput(cfg)
+			var spinner *output.Spinner
+			if verbose {
+				fmt.Fprintln(os.Stderr, msg+"...")
+			} else {
+				spinner = output.NewSpinner(
+					output.WithMessage(msg+"..."),
+					output.WithNoColor(printer.NoColor()),
+				)
+				spinner.Start()
+			}
+
+			rollbackCtx := providers.WithTargetVersion(withInstallProgress(ctx, cfg), event.FromVersion)
+			record := storage.RecordEvent(ctx, store, storage.NewInstallationEvent(storage.UpdateActionRollback, installation, event.FromVersion), storage.UpdateStatusRunning)
+			result, err := inst.Update(rollbackCtx, installation, agentDef, methodDef)
+			storage.FinishEvent(ctx, store, record, result.VersionString(), err)
+			if err != nil {
+				failMsg := fmt.Sprintf("Failed to roll back %s: %v", agentDef.Name, err)
+				if verbose {
+					fmt.Fprintln(os.Stderr, failMsg)
+				} else {
+					spinner.Error(failMsg)
+				}
+				return fmt.Errorf("rollback %s: %w", agentID, err)
+			}
+
+			okMsg := fmt.Sprintf("Rolled back %s to %s", agentDef.Name, result.Version)
+			if verbose {
+				fmt.Fprintln(os.Stderr, okMsg)
+			} else {
+				spinner.Success(okMsg)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&method, "method", "m", "", "installation method to roll back (npm, pip, brew, etc.)")
+	cmd.Flags().BoolVarP(&force, "force", "F", false, "roll back even if the agent is pinned")
+
+	return cmd
+}
+
+// findRollbackEvent returns the most recent completed update in history
+// (newest first, as returned by GetUpdateHistory) that actually changed the
+// version, optionally restricted to one install method. Installs,
+// uninstalls and earlier rollbacks are skipped. It returns nil when there
+// is nothing to roll back to.
+func findRollbackEvent(history []*storage.UpdateEvent, method string) *storage.UpdateEvent {
+	for _, event := range history {
+		if event.Action != storage.UpdateActionUpdate || event.Status != storage.UpdateStatusCompleted {
+			continue
+		}
+		if method != "" && event.InstallMethod != method {
+			continue
+		}
+		if event.FromVersion == "" || event.FromVersion == event.ToVersion {
+			continue
+		}
+		return event
+	}
+	return nil
+}
+
+// parseAgentVersionArg splits an install argument of the form
+// "<agent>@<version>" into its parts. Agent IDs never contain '@', so the
+// first one separates the two; a bare agent name yields an empty version,
+// and an '@' with nothing after it is an error.
+func parseAgentVersionArg(arg string) (agentID, version string, err error) {
+	agentID, version, found := strings.Cut(arg, "@")
+	if found && version == "" {
+		return "", "", fmt.Errorf("missing version after '@' in %q", arg)
+	}
+	return agentID, version, nil
+}
+
+// agentVersionArgs rejects install arguments parseAgentVersionArg cannot
+// split, before anything is installed.
+func agentVersionArgs(cmd *cobra.Command, args []string) error {
+	for _, arg := range args {
+		if _, _, err := parseAgentVersionArg(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
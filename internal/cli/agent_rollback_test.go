@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+func TestFindRollbackEvent(t *testing.T) {
+	// Newest first, as returned by GetUpdateHistory.
+	history := []*storage.UpdateEvent{
+		{ID: 7, Action: storage.UpdateActionRollback, InstallMethod: "npm", FromVersion: "2.1.0", ToVersion: "2.0.0", Status: storage.UpdateStatusCompleted},
+		{ID: 6, Action: storage.UpdateActionInstall, InstallMethod: "pipx", ToVersion: "0.85.0", Status: storage.UpdateStatusCompleted},
+		{ID: 5, Action: storage.UpdateActionUpdate, InstallMethod: "npm", FromVersion: "2.1.0", ToVersion: "2.2.0", Status: storage.UpdateStatusFailed},
+		{ID: 4, Action: storage.UpdateActionUpdate, InstallMethod: "npm", FromVersion: "2.1.0", ToVersion: "2.1.0", Status: storage.UpdateStatusCompleted},
+		{ID: 3, Action: storage.UpdateActionUpdate, InstallMethod: "brew", FromVersion: "1.0.0", ToVersion: "1.1.0", Status: storage.UpdateStatusCompleted},
+		{ID: 2, Action: storage.UpdateActionUpdate, InstallMethod: "npm", FromVersion: "2.0.0", ToVersion: "2.1.0", Status: storage.UpdateStatusCompleted},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		wantID int64
+	}{
+		{name: "any method skips rollbacks, installs, failures and no-ops", method: "", wantID: 3},
+		{name: "restricted to npm", method: "npm", wantID: 2},
+		{name: "no match", method: "pipx", wantID: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findRollbackEvent(history, tt.method)
+			if tt.wantID == 0 {
+				if got != nil {
+					t.Errorf("findRollbackEvent() = event %d, want nil", got.ID)
+				}
+				return
+			}
+			if got == nil || got.ID != tt.wantID {
+				t.Errorf("findRollbackEvent() = %v, want event %d", got, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestParseAgentVersionArg(t *testing.T) {
+	tests := []struct {
+		arg         string
+		wantID      string
+		wantVersion string
+	}{
+		{"aider", "aider", ""},
+		{"aider@0.85.0", "aider", "0.85.0"},
+		{"claude-code@2.1.0-beta.1", "claude-code", "2.1.0-beta.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			id, version, err := parseAgentVersionArg(tt.arg)
+			if err != nil {
+				t.Fatalf("parseAgentVersionArg(%q) error = %v", tt.arg, err)
+			}
+			if id != tt.wantID || version != tt.wantVersion {
+				t.Errorf("parseAgentVersionArg(%q) = (%q, %q), want (%q, %q)", tt.arg, id, version, tt.wantID, tt.wantVersion)
+			}
+		})
+	}
+
+	if _, _, err := parseAgentVersionArg("aider@"); err == nil {
+		t.Error("parseAgentVersionArg(\"aider@\") error = nil, want a missing version error")
+	}
+	if err := agentVersionArgs(nil, []string{"aider", "claude-code@"}); err == nil {
+		t.Error("agentVersionArgs() error = nil, want the empty version rejected")
+	}
+}
@@ -26,6 +26,7 @@ including viewing the OpenAPI specification and endpoint information.`,
 		newAPIDocsCommand(cfg),
 		newAPISpecCommand(cfg),
 		newAPIEndpointsCommand(cfg),
+		newAPITokenCommand(cfg),
 	)
 
 	return cmd
@@ -76,6 +77,15 @@ You can use tools like Swagger UI, Redoc, or Postman to explore the API.`,
 			fmt.Println("  Updates:")
 			fmt.Println("    GET  /api/v1/updates           Check for updates")
 			fmt.Println("    GET  /api/v1/changelog/{id}    Get changelog")
+			fmt.Println("    GET  /api/v1/history           Install/update history")
+			fmt.Println()
+			fmt.Println("  Jobs:")
+			fmt.Println("    GET  /api/v1/jobs              List install/update jobs")
+			fmt.Println("    GET  /api/v1/jobs/{id}         Get job status and output")
+			fmt.Println("    DEL  /api/v1/jobs/{id}         Cancel a job")
+			fmt.Println()
+			fmt.Println("  Events:")
+			fmt.Println("    GET  /api/v1/events            Server-Sent Events stream")
 			fmt.Println()
 
 			if open {
@@ -156,6 +166,11 @@ func newAPIEndpointsCommand(cfg *config.Config) *cobra.Command {
 				{"GET", "/api/v1/catalog/search", "Search catalog (query: q)"},
 				{"GET", "/api/v1/updates", "Check for available updates"},
 				{"GET", "/api/v1/changelog/{agentID}", "Get changelog (query: from, to)"},
+				{"GET", "/api/v1/history", "Install/update history (query: agent, limit)"},
+				{"GET", "/api/v1/jobs", "List install, update and uninstall jobs"},
+				{"GET", "/api/v1/jobs/{id}", "Get job status and output"},
+				{"DELETE", "/api/v1/jobs/{id}", "Cancel a running job"},
+				{"GET", "/api/v1/events", "Server-Sent Events stream (query: types)"},
 				{"GET", "/openapi.yaml", "OpenAPI specification (YAML)"},
 				{"GET", "/openapi.json", "OpenAPI specification info"},
 			}
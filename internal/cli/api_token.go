@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/api/auth"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+func newAPITokenCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API authentication tokens",
+		Long: `Manage the bearer tokens accepted by the REST and gRPC APIs.
+
+When api.require_auth is set, every REST call under /api/v1 must send
+"Authorization: Bearer <token>" (or "X-API-Key: <token>"), and every gRPC
+call must carry the same value in its "authorization" metadata.
+
+The token in api.auth_token (see 'generate') has full access. Named tokens
+(see 'create') are limited to a scope:
+
+  read    list agents, the catalog, updates, changelogs and history
+  update  read, plus install and update agents and refresh the catalog
+  admin   everything, including uninstalling agents
+
+Only a hash of each named token is kept, in the local database.`,
+	}
+
+	cmd.AddCommand(
+		newAPITokenGenerateCommand(cfg),
+		newAPITokenCreateCommand(cfg),
+		newAPITokenListCommand(cfg),
+		newAPITokenRevokeCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newAPITokenGenerateCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a new admin API token and require it",
+		Long: `Generate a random admin API token, store it in the config file as
+api.auth_token and turn on api.require_auth.
+
+Any previous api.auth_token stops working. Running API servers read the
+config at startup, so restart the helper for the change to take effect.
+Named tokens are not affected.`,
+		Example: `  agentmgr api token generate
+  curl -H "Authorization: Bearer $(agentmgr api token generate)" http://localhost:8080/api/v1/agents`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := auth.GenerateToken()
+			if err != nil {
+				return err
+			}
+
+			if err := saveAPIToken(cfg, token); err != nil {
+				return err
+			}
+
+			// The token goes to stdout on its own so it can be captured;
+			// everything else goes to stderr.
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			printer.SetOutput(cmd.ErrOrStderr())
+			printer.Success("Generated API token; authentication is now required")
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+			return nil
+		},
+	}
+}
+
+func newAPITokenCreateCommand(cfg *config.Config) *cobra.Command {
+	var scope string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a named, scoped API token",
+		Long: `Create a named API token limited to a scope (read, update or admin)
+and print it. The token cannot be shown again; revoke it and create a new
+one if it is lost.
+
+Authentication is turned on (api.require_auth) if it was not already.
+Running API servers accept the new token on their next request, but they
+read api.require_auth at startup: when this command turns it on, restart
+the helper for authentication to be enforced.`,
+		Example: `  agentmgr api token create dashboard --scope read
+  agentmgr api token create provisioner --scope update`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsed, err := auth.ParseScope(scope)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			store, err := openTokenStore(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			secret, err := auth.CreateToken(ctx, store, args[0], parsed)
+			if err != nil {
+				return err
+			}
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			printer.SetOutput(cmd.ErrOrStderr())
+			if !cfg.API.RequireAuth {
+				if err := saveRequireAuth(cfg); err != nil {
+					return err
+				}
+				printer.Info("API authentication is now required; restart the helper for running API servers to enforce it")
+			}
+			printer.Success("Created %s token %q; it will not be shown again", parsed, args[0])
+			fmt.Fprintln(cmd.OutOrStdout(), secret)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&scope, "scope", "s", string(auth.ScopeRead), "token scope (read, update, admin)")
+
+	return cmd
+}
+
+func newAPITokenListCommand(cfg *config.Config) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List named API tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			store, err := openTokenStore(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			tokens, err := auth.ListTokens(ctx, store)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				return outputTokensJSON(cmd, tokens)
+			}
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			if len(tokens) == 0 {
+				printer.Info("No API tokens; create one with 'agentmgr api token create <name>'")
+				return nil
+			}
+
+			styles := printer.Styles()
+			table := output.NewTable()
+			table.SetHeaders(
+				styles.FormatHeader("NAME"),
+				styles.FormatHeader("SCOPE"),
+				styles.FormatHeader("CREATED"),
+			)
+			for _, t := range tokens {
+				table.AddRow(
+					styles.Info.Render(t.Name),
+					string(t.Scope),
+					t.CreatedAt.Local().Format("2006-01-02 15:04"),
+				)
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "output format (table, json)")
+
+	return cmd
+}
+
+// outputTokensJSON writes tokens without their hashes.
+func outputTokensJSON(cmd *cobra.Command, tokens []auth.Token) error {
+	type tokenItem struct {
+		Name      string    `json:"name"`
+		Scope     string    `json:"scope"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	items := make([]tokenItem, 0, len(tokens))
+	for _, t := range tokens {
+		items = append(items, tokenItem{Name: t.Name, Scope: string(t.Scope), CreatedAt: t.CreatedAt})
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func newAPITokenRevokeCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <name>",
+		Short: "Revoke a named API token",
+		Long: `Delete a named API token. Running API servers reject it from their
+next request on.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			store, err := openTokenStore(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if err := auth.RevokeToken(ctx, store, args[0]); err != nil {
+				return err
+			}
+
+			printer := output.NewPrinter(cfg, output.NoColor(cfg, false))
+			printer.Success("Revoked API token %q", args[0])
+			return nil
+		},
+	}
+}
+
+// openTokenStore opens the local database holding named API tokens.
+func openTokenStore(ctx context.Context) (*storage.SQLiteStore, error) {
+	store, err := storage.NewSQLiteStore(platform.Current().GetDataDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	if err := store.Initialize(ctx); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return store, nil
+}
+
+// saveAPIToken writes token to api.auth_token, enables api.require_auth in
+// the config file (making it owner-only) and mirrors both into cfg.
+func saveAPIToken(cfg *config.Config, token string) error {
+	loader := config.NewLoader()
+	if _, err := loader.Load(""); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	loader.Set("api.require_auth", true)
+	if err := loader.SetAndSave("api.auth_token", token); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	// The file now holds a credential; keep it private to the user.
+	if err := os.Chmod(loader.GetFilePath(), 0o600); err != nil {
+		return fmt.Errorf("failed to restrict config permissions: %w", err)
+	}
+
+	cfg.API.RequireAuth = true
+	cfg.API.AuthToken = token
+	return nil
+}
+
+// saveRequireAuth turns on api.require_auth in the config file and cfg.
+func saveRequireAuth(cfg *config.Config) error {
+	loader := config.NewLoader()
+	if _, err := loader.Load(""); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := loader.SetAndSave("api.require_auth", true); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	cfg.API.RequireAuth = true
+	return nil
+}
@@ -105,7 +105,7 @@ func TestNewAgentCommand(t *testing.T) {
 	}
 
 	// Check expected subcommands
-	expectedSubcommands := []string{"list", "install", "update", "info", "remove", "refresh"}
+	expectedSubcommands := []string{"list", "install", "update", "info", "remove", "refresh", "pin", "unpin", "rollback", "history", "conflicts"}
 	for _, name := range expectedSubcommands {
 		assertSubcommandExists(t, cmd, name)
 	}
@@ -138,7 +138,8 @@ func TestNewAgentCommand(t *testing.T) {
 		assertFlagExists(t, installCmd, "continue-on-error")
 		assertFlagExists(t, installCmd, "json")
 		// Removed: "version" — installer.Manager.Install never accepted
-		// a version pin, so the flag was a silent no-op.
+		// a version pin, so the flag was a silent no-op. Versions are now
+		// requested per agent as <agent-name>@<version> instead.
 		if installCmd.Flags().Lookup("version") != nil {
 			t.Error("flag \"version\" should have been removed from install command (was unwired)")
 		}
@@ -176,6 +177,45 @@ func TestNewAgentCommand(t *testing.T) {
 	}
 }
 
+func TestAgentListProjectArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"bare flag", []string{"--project"}, ".", false},
+		{"equals form", []string{"--project=/some/dir"}, "/some/dir", false},
+		{"space-separated form", []string{"--project", "/some/dir"}, "/some/dir", false},
+		{"no flag", []string{}, "", false},
+		{"argument without flag", []string{"/some/dir"}, "", true},
+		{"argument after equals form", []string{"--project=/a", "/b"}, "", true},
+		{"two arguments", []string{"--project", "/a", "/b"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newAgentListCommand(&config.Config{})
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			err := cmd.ValidateArgs(cmd.Flags().Args())
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "--project=<dir>") {
+					t.Errorf("ValidateArgs() error = %v, want one naming --project=<dir>", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateArgs() error = %v", err)
+			}
+			if got, _ := cmd.Flags().GetString("project"); got != tt.want {
+				t.Errorf("project = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNewCatalogCommand(t *testing.T) {
 	cfg := &config.Config{}
 	cmd := NewCatalogCommand(cfg)
@@ -534,7 +574,7 @@ func TestAgentCommandSubcommandCount(t *testing.T) {
 	cfg := &config.Config{}
 	cmd := NewAgentCommand(cfg)
 
-	expectedCount := 6 // list, install, update, info, remove, refresh
+	expectedCount := 11 // list, install, update, info, remove, refresh, pin, unpin, rollback, history, conflicts
 	actualCount := len(cmd.Commands())
 
 	if actualCount != expectedCount {
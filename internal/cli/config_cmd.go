@@ -139,7 +139,7 @@ func parseConfigValue(key, value string) interface{} {
 	boolKeys := []string{
 		"updates.auto_check", "updates.notify", "updates.auto_update",
 		"ui.show_hidden", "ui.use_colors", "ui.compact_mode",
-		"api.enable_grpc", "api.enable_rest", "api.require_auth",
+		"api.enable_grpc", "api.enable_rest", "api.require_auth", "api.rest_socket",
 	}
 	for _, k := range boolKeys {
 		if key == k {
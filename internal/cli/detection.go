@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// printDetectionReport reports a fresh detection run. Failed strategies
+// are always warned about, since a failure can hide every agent that
+// strategy would have found; verbose adds a per-strategy timing table on w.
+func printDetectionReport(w io.Writer, printer *output.Printer, res *detector.Result, verbose bool) {
+	if verbose {
+		fmt.Fprintf(w, "Detection: %d strategies, %d installations in %s\n",
+			len(res.Strategies), len(res.Installations), formatDetectionDuration(res.Duration))
+		if res.Reused > 0 {
+			fmt.Fprintf(w, "Detection: %d installations unchanged since the last run were not re-probed\n", res.Reused)
+		}
+
+		styles := printer.Styles()
+		table := output.NewTable().SetOutput(w)
+		table.SetHeaders(
+			styles.FormatHeader("STRATEGY"),
+			styles.FormatHeader("METHOD"),
+			styles.FormatHeader("FOUND"),
+			styles.FormatHeader("TIME"),
+			styles.FormatHeader("ERROR"),
+		)
+		for _, sr := range res.Strategies {
+			errText := ""
+			if sr.Err != nil {
+				errText = sr.Err.Error()
+			}
+			table.AddRow(sr.Name, string(sr.Method), fmt.Sprintf("%d", sr.Found), formatDetectionDuration(sr.Duration), errText)
+		}
+		table.Render()
+		fmt.Fprintln(w)
+		return
+	}
+
+	for _, sr := range res.Failed() {
+		if sr.TimedOut {
+			printer.Warning("%s detection timed out after %s; its agents may be missing", sr.Name, formatDetectionDuration(sr.Duration))
+			continue
+		}
+		printer.Warning("%s detection failed after %s: %v", sr.Name, formatDetectionDuration(sr.Duration), sr.Err)
+	}
+}
+
+// formatDetectionDuration rounds d for display, keeping sub-millisecond
+// strategies from showing as 0s.
+func formatDetectionDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// runDetectionChecks runs detection against the catalog and reports one
+// check per strategy, so doctor shows which strategies fail or are slow.
+func runDetectionChecks(ctx context.Context, cfg *config.Config, _ bool) []CheckResult {
+	plat := platform.Current()
+
+	store, err := storage.NewSQLiteStore(plat.GetDataDir())
+	if err != nil {
+		return []CheckResult{{Name: "Detection", Status: CheckSkipped, Message: fmt.Sprintf("could not open storage: %v", err)}}
+	}
+	defer store.Close()
+	if err := store.Initialize(ctx); err != nil {
+		return []CheckResult{{Name: "Detection", Status: CheckSkipped, Message: fmt.Sprintf("could not initialize storage: %v", err)}}
+	}
+
+	agentDefs, err := catalog.NewManager(cfg, store).GetAgentsForPlatform(ctx, string(plat.ID()))
+	if err != nil {
+		return []CheckResult{{Name: "Detection", Status: CheckSkipped, Message: fmt.Sprintf("could not load catalog: %v", err)}}
+	}
+
+	res, err := detector.New(plat, detector.WithConfig(cfg)).DetectAllWithResult(ctx, agentDefs)
+	results := detectionCheckResults(res)
+	if err != nil {
+		results = append(results, CheckResult{
+			Name:    "Detection",
+			Status:  CheckError,
+			Message: err.Error(),
+			Fix:     "Detection did not finish in time; check the strategies above for one that hangs",
+		})
+	}
+	return results
+}
+
+// detectionCheckResults turns each strategy of a detection run into a
+// doctor check.
+func detectionCheckResults(res *detector.Result) []CheckResult {
+	results := make([]CheckResult, 0, len(res.Strategies))
+	for _, sr := range res.Strategies {
+		if sr.TimedOut {
+			results = append(results, CheckResult{
+				Name:    sr.Name,
+				Status:  CheckWarning,
+				Message: fmt.Sprintf("timed out after %s (found %d before the deadline)", formatDetectionDuration(sr.Duration), sr.Found),
+				Fix:     fmt.Sprintf("Raise detection.strategy_timeouts.%s in the config if %s is just slow", sr.Name, sr.Name),
+			})
+			continue
+		}
+		if sr.Err != nil {
+			results = append(results, CheckResult{
+				Name:    sr.Name,
+				Status:  CheckWarning,
+				Message: fmt.Sprintf("failed after %s: %v", formatDetectionDuration(sr.Duration), sr.Err),
+				Fix:     fmt.Sprintf("Agents the %s strategy finds are not detected until this is fixed", sr.Name),
+			})
+			continue
+		}
+		results = append(results, CheckResult{
+			Name:    sr.Name,
+			Status:  CheckOK,
+			Message: fmt.Sprintf("found %d in %s", sr.Found, formatDetectionDuration(sr.Duration)),
+		})
+	}
+	return results
+}
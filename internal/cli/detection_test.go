@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/internal/cli/output"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+)
+
+func detectionTestResult() *detector.Result {
+	return &detector.Result{
+		Installations: []*agent.Installation{{AgentID: "claude-code"}},
+		Strategies: []detector.StrategyResult{
+			{Name: "npm", Method: agent.MethodNPM, Found: 1, Duration: 420 * time.Millisecond},
+			{Name: "brew", Method: agent.MethodBrew, Duration: 5 * time.Second, Err: errors.New("signal: killed")},
+			{Name: "pip", Method: agent.MethodPip, Duration: 30 * time.Second, TimedOut: true, Err: context.DeadlineExceeded},
+		},
+		Duration: 5 * time.Second,
+	}
+}
+
+func TestPrintDetectionReport(t *testing.T) {
+	var out, errOut bytes.Buffer
+	printer := output.NewPrinter(config.Default(), true)
+	printer.SetOutput(&out)
+	printer.SetErrorOutput(&errOut)
+
+	// Without verbose only the failure is reported, as a warning.
+	var report bytes.Buffer
+	printDetectionReport(&report, printer, detectionTestResult(), false)
+	if report.Len() != 0 {
+		t.Errorf("non-verbose report wrote %q, want nothing", report.String())
+	}
+	got := errOut.String()
+	if !strings.Contains(got, "brew detection failed after 5s: signal: killed") || strings.Contains(got, "npm") {
+		t.Errorf("warnings = %q, want the brew failure and not npm", got)
+	}
+	if !strings.Contains(got, "pip detection timed out after 30s") {
+		t.Errorf("warnings = %q, want the pip timeout", got)
+	}
+
+	errOut.Reset()
+	printDetectionReport(&report, printer, detectionTestResult(), true)
+	got = report.String()
+	for _, want := range []string{"3 strategies, 1 installations in 5s", "npm", "420ms", "brew", "signal: killed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("verbose report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "not re-probed") {
+		t.Errorf("verbose report of a full run mentions reuse:\n%s", got)
+	}
+
+	report.Reset()
+	res := detectionTestResult()
+	res.Reused = 4
+	printDetectionReport(&report, printer, res, true)
+	if got := report.String(); !strings.Contains(got, "4 installations unchanged since the last run were not re-probed") {
+		t.Errorf("verbose report missing the reused count:\n%s", got)
+	}
+}
+
+func TestDetectionCheckResults(t *testing.T) {
+	results := detectionCheckResults(detectionTestResult())
+	if len(results) != 3 {
+		t.Fatalf("got %d checks, want 3", len(results))
+	}
+	if results[0].Name != "npm" || results[0].Status != CheckOK || results[0].Message != "found 1 in 420ms" {
+		t.Errorf("npm check = %+v", results[0])
+	}
+	if results[1].Name != "brew" || results[1].Status != CheckWarning || !strings.Contains(results[1].Message, "signal: killed") {
+		t.Errorf("brew check = %+v", results[1])
+	}
+	if results[2].Status != CheckWarning || !strings.Contains(results[2].Fix, "detection.strategy_timeouts.pip") {
+		t.Errorf("pip check = %+v", results[2])
+	}
+
+	// Several strategies share the native method; the fix names the one
+	// that failed.
+	res := &detector.Result{Strategies: []detector.StrategyResult{
+		{Name: "signature", Method: agent.MethodNative, Err: errors.New("permission denied")},
+	}}
+	if fix := detectionCheckResults(res)[0].Fix; !strings.Contains(fix, "signature strategy") || strings.Contains(fix, "native") {
+		t.Errorf("signature fix = %q, want it to name the strategy", fix)
+	}
+}
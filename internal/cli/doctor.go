@@ -50,7 +50,8 @@ func NewDoctorCommand(cfg *config.Config) *cobra.Command {
 		Use:   "doctor",
 		Short: "Check system health and configuration",
 		Long: `Run health checks to verify system requirements, package managers,
-catalog accessibility, storage health, and agent configurations.
+detection strategies, catalog accessibility, storage health, and agent
+configurations.
 
 The doctor command helps identify and diagnose common issues with
 your AgentManager installation.
@@ -87,6 +88,14 @@ Examples:
 			printResults(printer, pmResults)
 			printer.Println()
 
+			// Detection strategy checks
+			printer.Print("Detection")
+			printer.Print("---------")
+			detResults := runDetectionChecks(ctx, cfg, verbose)
+			results = append(results, detResults...)
+			printResults(printer, detResults)
+			printer.Println()
+
 			// Storage checks
 			printer.Print("Storage")
 			printer.Print("-------")
@@ -6,9 +6,13 @@
 //
 //  1. Load the agent catalog for the current platform.
 //  2. Consult the detection cache (unless forced to refresh).
-//  3. Run detector.DetectAll when no usable cache entry exists.
+//  3. Run detector.DetectAllWithResult when no usable cache entry exists, or
+//     detector.Redetect when an expired entry has recorded fingerprints.
 //  4. Check each installed agent's registry for its latest version.
-//  5. Persist the refreshed detection cache and the last-update-check time.
+//  5. Diff a fresh detection against the previous cached set and record
+//     the changes agentmgr did not make itself.
+//  6. Persist the refreshed detection cache, its fingerprints, and the
+//     last-update-check time.
 //
 // Consolidating this flow behind a single Pipeline type removes the drift
 // between call sites that previously caused subtle bugs, while leaving the
@@ -39,9 +43,15 @@ type CatalogSource interface {
 
 // Detector is the subset of detector.Detector used by the pipeline.
 type Detector interface {
-	DetectAll(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error)
+	DetectAllWithResult(ctx context.Context, agents []catalog.AgentDef) (*detector.Result, error)
+	Redetect(ctx context.Context, agents []catalog.AgentDef, snap detector.Snapshot) (*detector.Result, error)
+	DetectProject(ctx context.Context, agents []catalog.AgentDef, dir string) (*detector.Result, error)
 }
 
+// managedHistoryLimit bounds how much update history is read to tell
+// agentmgr's own changes apart from those made outside it.
+const managedHistoryLimit = 200
+
 // VersionFetcher is the subset of installer.Manager used by the pipeline.
 // It mirrors versionfetch.LatestVersionFetcher so the pipeline can hand the
 // installer directly to the parallel version-check helper.
@@ -140,6 +150,18 @@ type Result struct {
 	// detection cache rather than a fresh detector run.
 	UsedDetectionCache bool
 
+	// Detection reports the strategies of a fresh detector run: their
+	// timing and any that failed. Nil when UsedDetectionCache is true.
+	// After an incremental re-detection, Detection.Reused counts the
+	// installations carried over from the expired cache.
+	Detection *detector.Result
+
+	// Changes holds the installations a fresh detection found added,
+	// updated or removed since the previous one, leaving out changes
+	// agentmgr made itself; they are also recorded in storage. Nil when
+	// the cache was used or there was no previous detection to compare.
+	Changes []agent.RegistryEvent
+
 	// RanVersionCheck reports whether the latest-version fetch executed.
 	// It is false when the cache was fresh and the update-check TTL had
 	// not yet elapsed, or when Options.SkipVersionCheck is true.
@@ -161,10 +183,19 @@ type Result struct {
 //     the update-check TTL has expired.
 //   - Otherwise the detector runs fresh. When ForceRefresh is true the cache
 //     is also cleared so a follow-up cold start does not see a stale entry.
+//   - An expired cache that has fingerprints recorded is re-detected
+//     incrementally: only installations whose files changed, and agents
+//     newly on PATH, are probed again (see detector.Redetect).
+//   - A fresh detection is diffed against the previous cached set, even
+//     under ForceRefresh. Installations that appeared, disappeared or
+//     changed version without an entry in the update history since then
+//     are returned in Result.Changes and saved as registry events.
 //   - After a version check the refreshed installations (with LatestVersion
 //     filled) are written back to the cache and the last-update-check
-//     timestamp is advanced. Cache-write errors are intentionally swallowed
-//     because they are non-fatal and match existing behavior.
+//     timestamp is advanced. Previous installations whose strategy failed
+//     are written back unchanged, without a fingerprint. Cache-write
+//     errors are intentionally swallowed because they are non-fatal and
+//     match existing behavior.
 //   - A catalog fetch error is fatal by default. Callers that need to
 //     continue with no definitions (e.g. the systray on a flaky network)
 //     can set Options.TolerateCatalogError. Version-check errors are
@@ -175,14 +206,25 @@ func (p *Pipeline) DetectAndCheckVersions(ctx context.Context, opts Options) (*R
 		installations      []*agent.Installation
 		usedDetectionCache bool
 		needUpdateCheck    bool
+		previous           []*agent.Installation
+		previousAt         time.Time
+		fingerprints       map[string]string
+		// unseen holds previous installations whose strategy failed in
+		// a fresh detection; see the cache write below.
+		unseen []*agent.Installation
 	)
 
 	// Try the detection cache before loading the catalog. On the fully warm
 	// path (fresh detection cache and fresh update-check TTL), agent list /
 	// systray refresh callers do not need catalog definitions at all.
-	if p.cfg.Detection.CacheEnabled && !opts.ForceRefresh {
+	// The cache is read under ForceRefresh too, as the previous detection
+	// to diff against.
+	if p.cfg.Detection.CacheEnabled {
 		cached, cachedAt, cacheErr := p.store.GetDetectionCache(ctx)
-		if cacheErr == nil && cached != nil && time.Since(cachedAt) < p.cfg.Detection.CacheDuration {
+		if cacheErr == nil && cached != nil {
+			previous, previousAt = cached, cachedAt
+		}
+		if !opts.ForceRefresh && previous != nil && time.Since(cachedAt) < p.cfg.Detection.CacheDuration {
 			installations = cached
 			usedDetectionCache = true
 
@@ -227,19 +269,99 @@ func (p *Pipeline) DetectAndCheckVersions(ctx context.Context, opts Options) (*R
 			_ = p.store.ClearDetectionCache(ctx)
 		}
 
-		installations, err = p.detector.DetectAll(ctx, agentDefs)
+		expired := previous
+		if opts.ForceRefresh {
+			expired = nil
+		}
+		detection, err := p.detect(ctx, agentDefs, expired)
 		if err != nil {
 			return nil, fmt.Errorf("detection failed: %w", err)
 		}
 		// A fresh detection always warrants a version check.
 		needUpdateCheck = true
+		installations = detection.Installations
 		result.Installations = installations
+		result.Detection = detection
+		fingerprints = detector.Fingerprints(installations)
+
+		// Without a catalog nothing can be detected, which is no reason to
+		// record every previous installation as removed.
+		if previous != nil && len(agentDefs) > 0 {
+			result.Changes = p.recordChanges(ctx, detection, previous, previousAt)
+			unseen = detection.Unseen(previous)
+		}
 	}
 
 	if opts.SkipVersionCheck || !needUpdateCheck {
 		return result, nil
 	}
 
+	p.checkVersions(ctx, result, opts)
+
+	// Persist the refreshed snapshot. Both writes are best-effort to preserve
+	// the silent-failure semantics the previous hand-rolled pipelines relied on.
+	//nolint:errcheck // best-effort timestamp; cached data is still usable without it
+	_ = p.store.SetLastUpdateCheckTime(ctx, time.Now())
+	if p.cfg.Detection.CacheEnabled {
+		// Installations a failed strategy may have missed stay cached, so
+		// the cache does not forget them and the next run does not take
+		// them for removed. Having no fingerprint, they are probed again
+		// then.
+		cache := installations
+		if len(unseen) > 0 {
+			cache = append(append([]*agent.Installation(nil), installations...), unseen...)
+		}
+		//nolint:errcheck // best-effort cache; agents will be redetected on the next run
+		_ = p.store.SaveDetectionCache(ctx, cache)
+		if !usedDetectionCache {
+			//nolint:errcheck // best-effort; without fingerprints the next expiry detects in full
+			_ = p.store.SaveDetectionFingerprints(ctx, fingerprints)
+		}
+	}
+
+	return result, nil
+}
+
+// DetectProject detects the agents installed locally in the project
+// containing dir (see detector.Detector.DetectProject) and checks their
+// latest versions. It neither reads nor writes the detection cache, whose
+// contents describe the global installations, and records no changes;
+// latest versions still come from the shared version cache.
+func (p *Pipeline) DetectProject(ctx context.Context, dir string, opts Options) (*Result, error) {
+	agentDefs, err := p.catalog.GetAgentsForPlatform(ctx, string(p.plat.ID()))
+	if err != nil {
+		if !opts.TolerateCatalogError {
+			return nil, fmt.Errorf("failed to load catalog: %w", err)
+		}
+		agentDefs = nil
+	}
+
+	agentDefMap := make(map[string]catalog.AgentDef, len(agentDefs))
+	for _, def := range agentDefs {
+		agentDefMap[def.ID] = def
+	}
+
+	detection, err := p.detector.DetectProject(ctx, agentDefs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+
+	result := &Result{
+		Installations: detection.Installations,
+		AgentDefs:     agentDefs,
+		AgentDefMap:   agentDefMap,
+		Detection:     detection,
+	}
+	if !opts.SkipVersionCheck {
+		p.checkVersions(ctx, result, opts)
+	}
+	return result, nil
+}
+
+// checkVersions fetches the latest version of each of result's
+// installations, going through the persistent version cache when one is
+// configured.
+func (p *Pipeline) checkVersions(ctx context.Context, result *Result, opts Options) {
 	concurrency := opts.VersionCheckConcurrency
 	if concurrency <= 0 {
 		concurrency = versionfetch.DefaultConcurrency
@@ -254,18 +376,75 @@ func (p *Pipeline) DetectAndCheckVersions(ctx context.Context, opts Options) (*R
 		}
 	}
 
-	errs := versionfetch.CheckLatestVersions(ctx, fetcher, installations, agentDefMap, concurrency)
+	result.VersionCheckErrors = versionfetch.CheckLatestVersions(ctx, fetcher, result.Installations, result.AgentDefMap, concurrency)
 	result.RanVersionCheck = true
-	result.VersionCheckErrors = errs
+}
 
-	// Persist the refreshed snapshot. Both writes are best-effort to preserve
-	// the silent-failure semantics the previous hand-rolled pipelines relied on.
-	//nolint:errcheck // best-effort timestamp; cached data is still usable without it
-	_ = p.store.SetLastUpdateCheckTime(ctx, time.Now())
-	if p.cfg.Detection.CacheEnabled {
-		//nolint:errcheck // best-effort cache; agents will be redetected on the next run
-		_ = p.store.SaveDetectionCache(ctx, installations)
+// detect runs the detector, incrementally against expired when the store
+// holds fingerprints for it and in full otherwise.
+func (p *Pipeline) detect(ctx context.Context, agentDefs []catalog.AgentDef, expired []*agent.Installation) (*detector.Result, error) {
+	if len(expired) > 0 {
+		fingerprints, err := p.store.GetDetectionFingerprints(ctx)
+		if err == nil && len(fingerprints) > 0 {
+			return p.detector.Redetect(ctx, agentDefs, detector.Snapshot{
+				Installations: expired,
+				Fingerprints:  fingerprints,
+			})
+		}
 	}
+	return p.detector.DetectAllWithResult(ctx, agentDefs)
+}
 
-	return result, nil
+// recordChanges diffs detection against previous, detected at since, and
+// saves the changes that agentmgr did not make. An installation is not
+// reported removed when the strategy that found it failed, since it may
+// only have gone unseen.
+func (p *Pipeline) recordChanges(ctx context.Context, detection *detector.Result, previous []*agent.Installation, since time.Time) []agent.RegistryEvent {
+	unseen := make(map[string]bool)
+	for _, inst := range detection.Unseen(previous) {
+		unseen[inst.Key()] = true
+	}
+	managed := p.managedSince(ctx, since)
+
+	var changes []agent.RegistryEvent
+	for _, event := range detection.Changes(previous) {
+		inst := event.Installation
+		if inst == nil {
+			inst = event.Previous
+		}
+		if event.Type == agent.RegistryEventRemoved && unseen[inst.Key()] {
+			continue
+		}
+		if managed[inst.AgentID+":"+string(inst.Method)] {
+			continue
+		}
+		changes = append(changes, event)
+	}
+
+	if len(changes) > 0 {
+		now := time.Now()
+		records := make([]*storage.RegistryEventRecord, 0, len(changes))
+		for _, event := range changes {
+			records = append(records, storage.NewRegistryEventRecord(event, now))
+		}
+		//nolint:errcheck // best-effort audit trail; the detection itself succeeded
+		_ = p.store.SaveRegistryEvents(ctx, records)
+	}
+	return changes
+}
+
+// managedSince returns the agent:method pairs agentmgr installed, updated,
+// rolled back or uninstalled at or after since, per the update history.
+func (p *Pipeline) managedSince(ctx context.Context, since time.Time) map[string]bool {
+	managed := make(map[string]bool)
+	history, err := p.store.GetUpdateHistory(ctx, "", managedHistoryLimit)
+	if err != nil {
+		return managed
+	}
+	for _, event := range history {
+		if !event.StartedAt.Before(since) || (event.CompletedAt != nil && !event.CompletedAt.Before(since)) {
+			managed[event.AgentID+":"+event.InstallMethod] = true
+		}
+	}
+	return managed
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
@@ -56,12 +57,16 @@ func (f *fakeCatalog) GetAgentsForPlatform(_ context.Context, _ string) ([]catal
 
 type fakeDetector struct {
 	installations []*agent.Installation
+	strategies    []detector.StrategyResult
 	err           error
 	calls         int
+	redetectCalls int
+	lastSnapshot  detector.Snapshot
+	projectDir    string
 	mu            sync.Mutex
 }
 
-func (f *fakeDetector) DetectAll(_ context.Context, _ []catalog.AgentDef) ([]*agent.Installation, error) {
+func (f *fakeDetector) DetectAllWithResult(_ context.Context, _ []catalog.AgentDef) (*detector.Result, error) {
 	f.mu.Lock()
 	f.calls++
 	f.mu.Unlock()
@@ -74,7 +79,29 @@ func (f *fakeDetector) DetectAll(_ context.Context, _ []catalog.AgentDef) ([]*ag
 		cp := *inst
 		out[i] = &cp
 	}
-	return out, nil
+	return &detector.Result{Installations: out, Strategies: f.strategies}, nil
+}
+
+// Redetect records the snapshot and answers like a full run, leaving the
+// incremental logic itself to the detector package's tests.
+func (f *fakeDetector) Redetect(ctx context.Context, agents []catalog.AgentDef, snap detector.Snapshot) (*detector.Result, error) {
+	f.mu.Lock()
+	f.redetectCalls++
+	f.lastSnapshot = snap
+	f.mu.Unlock()
+	res, err := f.DetectAllWithResult(ctx, agents)
+	if res != nil {
+		res.Reused = len(snap.Installations)
+	}
+	return res, err
+}
+
+// DetectProject records the directory and answers like a full run.
+func (f *fakeDetector) DetectProject(ctx context.Context, agents []catalog.AgentDef, dir string) (*detector.Result, error) {
+	f.mu.Lock()
+	f.projectDir = dir
+	f.mu.Unlock()
+	return f.DetectAllWithResult(ctx, agents)
 }
 
 type fakeFetcher struct {
@@ -119,6 +146,10 @@ type fakeStore struct {
 	saveCount        int
 	saveCache        []*agent.Installation
 	clearCount       int
+	fingerprints     map[string]string
+	fingerprintSaves int
+	history          []*storage.UpdateEvent
+	registryEvents   []*storage.RegistryEventRecord
 	lastUpdateCheck  time.Time
 	lastUpdateErr    error
 	setLastUpdateAt  time.Time
@@ -141,7 +172,22 @@ func (s *fakeStore) ListInstallations(context.Context, *agent.Filter) ([]*agent.
 func (s *fakeStore) DeleteInstallation(context.Context, string) error            { return nil }
 func (s *fakeStore) SaveUpdateEvent(context.Context, *storage.UpdateEvent) error { return nil }
 func (s *fakeStore) GetUpdateHistory(context.Context, string, int) ([]*storage.UpdateEvent, error) {
-	return nil, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history, nil
+}
+
+func (s *fakeStore) SaveRegistryEvents(_ context.Context, events []*storage.RegistryEventRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registryEvents = append(s.registryEvents, events...)
+	return nil
+}
+
+func (s *fakeStore) GetRegistryEvents(context.Context, string, int) ([]*storage.RegistryEventRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.registryEvents, nil
 }
 func (s *fakeStore) SaveCatalogCache(context.Context, []byte, string) error { return nil }
 func (s *fakeStore) GetCatalogCache(context.Context) ([]byte, string, time.Time, error) {
@@ -179,9 +225,24 @@ func (s *fakeStore) ClearDetectionCache(context.Context) error {
 	s.clearCount++
 	s.detection = nil
 	s.detectionAt = time.Time{}
+	s.fingerprints = nil
+	return nil
+}
+
+func (s *fakeStore) SaveDetectionFingerprints(_ context.Context, fingerprints map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprintSaves++
+	s.fingerprints = fingerprints
 	return nil
 }
 
+func (s *fakeStore) GetDetectionFingerprints(context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fingerprints, nil
+}
+
 func (s *fakeStore) GetDetectionCacheTime(context.Context) (time.Time, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -281,11 +342,14 @@ func TestPipeline_ColdDetectFillsVersionsAndSavesCache(t *testing.T) {
 	if res.UsedDetectionCache {
 		t.Errorf("UsedDetectionCache = true, want false (cold detect)")
 	}
+	if res.Detection == nil {
+		t.Errorf("Detection = nil, want the detector's result on cold detect")
+	}
 	if !res.RanVersionCheck {
 		t.Errorf("RanVersionCheck = false, want true on cold detect")
 	}
 	if det.calls != 1 {
-		t.Errorf("detector.DetectAll called %d times, want 1", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 1", det.calls)
 	}
 	if len(res.Installations) != 2 {
 		t.Fatalf("Installations len = %d, want 2", len(res.Installations))
@@ -328,11 +392,14 @@ func TestPipeline_CacheHitSkipsDetectionAndVersionCheckWhenFresh(t *testing.T) {
 	if !res.UsedDetectionCache {
 		t.Errorf("UsedDetectionCache = false, want true (fresh cache)")
 	}
+	if res.Detection != nil {
+		t.Errorf("Detection = %+v, want nil on a cache hit", res.Detection)
+	}
 	if res.RanVersionCheck {
 		t.Errorf("RanVersionCheck = true, want false (fresh update-check TTL)")
 	}
 	if det.calls != 0 {
-		t.Errorf("detector.DetectAll called %d times, want 0", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 0", det.calls)
 	}
 	if cat.calls != 0 {
 		t.Errorf("catalog.GetAgentsForPlatform called %d times, want 0 on warm fast path", cat.calls)
@@ -375,7 +442,7 @@ func TestPipeline_CacheHitWithStaleUpdateCheckRunsVersionCheckOnly(t *testing.T)
 		t.Errorf("RanVersionCheck = false, want true (stale update-check TTL)")
 	}
 	if det.calls != 0 {
-		t.Errorf("detector.DetectAll called %d times, want 0 (cache hit)", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 0 (cache hit)", det.calls)
 	}
 	if store.saveCount != 1 {
 		t.Errorf("store.SaveDetectionCache called %d times, want 1", store.saveCount)
@@ -450,7 +517,7 @@ func TestPipeline_ForceRefreshBypassesCacheAndClearsIt(t *testing.T) {
 		t.Errorf("store.ClearDetectionCache called %d times, want 1", store.clearCount)
 	}
 	if det.calls != 1 {
-		t.Errorf("detector.DetectAll called %d times, want 1", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 1", det.calls)
 	}
 }
 
@@ -553,7 +620,7 @@ func TestPipeline_TolerateCatalogErrorContinuesWithEmptyDefs(t *testing.T) {
 		t.Fatalf("DetectAndCheckVersions with TolerateCatalogError = %v, want nil", err)
 	}
 	if det.calls != 1 {
-		t.Errorf("detector.DetectAll called %d times, want 1 (should proceed even without catalog)", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 1 (should proceed even without catalog)", det.calls)
 	}
 	if len(res.AgentDefs) != 0 {
 		t.Errorf("AgentDefs len = %d, want 0", len(res.AgentDefs))
@@ -603,7 +670,7 @@ func TestPipeline_CacheDisabledAlwaysDetects(t *testing.T) {
 		t.Errorf("UsedDetectionCache = true, want false when cache disabled")
 	}
 	if det.calls != 1 {
-		t.Errorf("detector.DetectAll called %d times, want 1", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 1", det.calls)
 	}
 	if store.saveCount != 0 {
 		t.Errorf("store.SaveDetectionCache called %d times, want 0 (cache disabled)", store.saveCount)
@@ -654,6 +721,248 @@ func TestPipeline_StaleCacheIsTreatedAsMiss(t *testing.T) {
 		t.Errorf("UsedDetectionCache = true, want false when cache is stale")
 	}
 	if det.calls != 1 {
-		t.Errorf("detector.DetectAll called %d times, want 1", det.calls)
+		t.Errorf("detector.DetectAllWithResult called %d times, want 1", det.calls)
+	}
+}
+
+func TestPipeline_ExpiredCacheWithFingerprintsRedetects(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a")}}
+	det := &fakeDetector{installations: []*agent.Installation{makeInstallation("a")}}
+	fetcher := newFakeFetcher()
+	store := &fakeStore{
+		detection:    []*agent.Installation{makeInstallation("a")},
+		detectionAt:  time.Now().Add(-2 * time.Hour),
+		fingerprints: map[string]string{"a:npm:": "recorded"},
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, fetcher)
+
+	res, err := p.DetectAndCheckVersions(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+	if det.redetectCalls != 1 {
+		t.Fatalf("detector.Redetect called %d times, want 1", det.redetectCalls)
+	}
+	if len(det.lastSnapshot.Installations) != 1 || det.lastSnapshot.Fingerprints["a:npm:"] != "recorded" {
+		t.Errorf("snapshot = %+v, want the expired cache and its fingerprints", det.lastSnapshot)
+	}
+	if res.Detection == nil || res.Detection.Reused != 1 {
+		t.Errorf("Detection = %+v, want Reused = 1", res.Detection)
+	}
+	if store.fingerprintSaves != 1 {
+		t.Errorf("fingerprint saves = %d, want 1", store.fingerprintSaves)
+	}
+	if _, ok := store.fingerprints["a:npm:"]; !ok {
+		t.Errorf("saved fingerprints = %v, want an entry for a:npm:", store.fingerprints)
+	}
+}
+
+func TestPipeline_ForceRefreshIgnoresFingerprints(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a")}}
+	det := &fakeDetector{installations: []*agent.Installation{makeInstallation("a")}}
+	store := &fakeStore{
+		detection:    []*agent.Installation{makeInstallation("a")},
+		detectionAt:  time.Now().Add(-2 * time.Hour),
+		fingerprints: map[string]string{"a:npm:": "recorded"},
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, newFakeFetcher())
+
+	if _, err := p.DetectAndCheckVersions(context.Background(), Options{ForceRefresh: true}); err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+	if det.redetectCalls != 0 || det.calls != 1 {
+		t.Errorf("Redetect calls = %d, full calls = %d; want a full detection only", det.redetectCalls, det.calls)
+	}
+}
+
+func TestPipeline_RecordsChangesMadeOutsideAgentmgr(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a"), makeAgentDef("b"), makeAgentDef("c"), makeAgentDef("d")}}
+
+	upgraded := makeInstallation("a")
+	upgraded.InstalledVersion = agent.Version{Major: 1}
+	managed := makeInstallation("d")
+	managed.InstalledVersion = agent.Version{Major: 2}
+	// a was upgraded by hand, b removed, c installed, and d updated by agentmgr.
+	det := &fakeDetector{installations: []*agent.Installation{upgraded, makeInstallation("c"), managed}}
+
+	cachedAt := time.Now().Add(-2 * time.Hour)
+	store := &fakeStore{
+		detection:   []*agent.Installation{makeInstallation("a"), makeInstallation("b"), makeInstallation("d")},
+		detectionAt: cachedAt,
+		history: []*storage.UpdateEvent{
+			{AgentID: "d", InstallMethod: "npm", Action: storage.UpdateActionUpdate, StartedAt: cachedAt.Add(time.Minute)},
+			{AgentID: "c", InstallMethod: "npm", Action: storage.UpdateActionInstall, StartedAt: cachedAt.Add(-time.Hour)},
+		},
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, newFakeFetcher())
+
+	res, err := p.DetectAndCheckVersions(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+
+	got := make(map[string]agent.RegistryEventType)
+	for _, event := range res.Changes {
+		inst := event.Installation
+		if inst == nil {
+			inst = event.Previous
+		}
+		got[inst.AgentID] = event.Type
+	}
+	want := map[string]agent.RegistryEventType{
+		"a": agent.RegistryEventUpdated,
+		"b": agent.RegistryEventRemoved,
+		"c": agent.RegistryEventAdded,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Changes = %v, want %v", got, want)
+	}
+	for id, typ := range want {
+		if got[id] != typ {
+			t.Errorf("change for %s = %q, want %q", id, got[id], typ)
+		}
+	}
+
+	if len(store.registryEvents) != 3 {
+		t.Fatalf("saved %d registry events, want 3", len(store.registryEvents))
+	}
+	for _, record := range store.registryEvents {
+		if record.AgentID == "a" && (record.FromVersion != "0.9.0" || record.ToVersion != "1.0.0") {
+			t.Errorf("a's record = %+v, want 0.9.0 -> 1.0.0", record)
+		}
+	}
+}
+
+func TestPipeline_FailedStrategyDoesNotRecordRemovals(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a")}}
+	det := &fakeDetector{
+		strategies: []detector.StrategyResult{{Name: "npm", Method: "npm", Err: context.DeadlineExceeded, TimedOut: true}},
+	}
+	store := &fakeStore{
+		detection:   []*agent.Installation{makeInstallation("a")},
+		detectionAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, newFakeFetcher())
+
+	res, err := p.DetectAndCheckVersions(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+	if len(res.Changes) != 0 || len(store.registryEvents) != 0 {
+		t.Errorf("Changes = %+v, want none while the npm strategy failed", res.Changes)
+	}
+}
+
+func TestPipeline_FailedPipStrategyKeepsPipxInstall(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a"), makeAgentDef("b")}}
+
+	// a is a pipx install the pip strategy found; b a native install the
+	// binary strategy found. Only the pip strategy failed this time.
+	pipx := makeInstallation("a")
+	pipx.Method = agent.MethodPipx
+	pipx.Metadata = map[string]string{"detected_by": "pipx", detector.StrategyKey: "pip"}
+	native := makeInstallation("b")
+	native.Method = agent.MethodNative
+	native.Metadata = map[string]string{"detected_by": "binary", detector.StrategyKey: "binary"}
+
+	det := &fakeDetector{
+		strategies: []detector.StrategyResult{
+			{Name: "pip", Method: agent.MethodPip, Err: context.DeadlineExceeded, TimedOut: true},
+			{Name: "binary", Method: agent.MethodNative},
+		},
+	}
+	store := &fakeStore{
+		detection:   []*agent.Installation{pipx, native},
+		detectionAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, newFakeFetcher())
+
+	res, err := p.DetectAndCheckVersions(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+	if len(res.Changes) != 1 || res.Changes[0].Type != agent.RegistryEventRemoved || res.Changes[0].Previous.AgentID != "b" {
+		t.Errorf("Changes = %+v, want only b removed", res.Changes)
+	}
+	if len(store.registryEvents) != 1 {
+		t.Errorf("saved %d registry events, want 1", len(store.registryEvents))
+	}
+}
+
+func TestPipeline_FailedStrategyKeepsCachedInstallations(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a"), makeAgentDef("b")}}
+
+	pipx := makeInstallation("a")
+	pipx.Method = agent.MethodPipx
+	pipx.Metadata = map[string]string{detector.StrategyKey: "pip"}
+	npm := makeInstallation("b")
+	npm.Metadata = map[string]string{detector.StrategyKey: "npm"}
+
+	// The npm strategy still finds b; the pip strategy times out.
+	det := &fakeDetector{
+		installations: []*agent.Installation{npm},
+		strategies: []detector.StrategyResult{
+			{Name: "pip", Method: agent.MethodPip, Err: context.DeadlineExceeded, TimedOut: true},
+			{Name: "npm", Method: agent.MethodNPM, Found: 1},
+		},
+	}
+	store := &fakeStore{
+		detection:   []*agent.Installation{pipx, npm},
+		detectionAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, newFakeFetcher())
+
+	res, err := p.DetectAndCheckVersions(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("DetectAndCheckVersions: %v", err)
+	}
+	if len(res.Installations) != 1 {
+		t.Errorf("Installations = %d, want only what was detected", len(res.Installations))
+	}
+	if len(store.saveCache) != 2 || store.saveCache[1].Key() != pipx.Key() {
+		t.Errorf("saved cache = %+v, want b and the unseen pipx install", store.saveCache)
+	}
+	if _, ok := store.fingerprints[pipx.Key()]; ok {
+		t.Errorf("fingerprints = %v, want none for the unseen pipx install", store.fingerprints)
+	}
+	if _, ok := store.fingerprints[npm.Key()]; !ok {
+		t.Errorf("fingerprints = %v, want b's", store.fingerprints)
+	}
+}
+
+func TestPipeline_DetectProjectLeavesCacheAlone(t *testing.T) {
+	cfg := defaultConfig()
+	cat := &fakeCatalog{agents: []catalog.AgentDef{makeAgentDef("a")}}
+	det := &fakeDetector{installations: []*agent.Installation{makeInstallation("a")}}
+	fetcher := newFakeFetcher()
+	fetcher.versions["a"] = agent.Version{Major: 2}
+	store := &fakeStore{}
+
+	p := New(cfg, fakePlatform{}, store, cat, det, fetcher)
+
+	res, err := p.DetectProject(context.Background(), "/work/app", Options{})
+	if err != nil {
+		t.Fatalf("DetectProject: %v", err)
+	}
+	if det.projectDir != "/work/app" {
+		t.Errorf("detector got dir %q, want /work/app", det.projectDir)
+	}
+	if !res.RanVersionCheck || res.Installations[0].LatestVersion == nil {
+		t.Errorf("latest versions not checked: %+v", res.Installations[0])
+	}
+	if store.saveCount != 0 || store.setLastUpdateCnt != 0 {
+		t.Errorf("project detection wrote the cache (%d saves, %d update-check times)", store.saveCount, store.setLastUpdateCnt)
 	}
 }
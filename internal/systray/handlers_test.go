@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/ipc"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
 // mkTestApp builds a minimal *App sufficient for testing pure IPC/menu
@@ -312,3 +314,36 @@ func TestDialogTracking(t *testing.T) {
 	}
 	a.dialogProcsMu.Unlock()
 }
+
+// notifyPlatform records notifications; every other platform method is
+// left unimplemented.
+type notifyPlatform struct {
+	platform.Platform
+	titles   []string
+	messages []string
+}
+
+func (p *notifyPlatform) ShowNotification(title, message string) error {
+	p.titles = append(p.titles, title)
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func TestUpdateSingleAgent_Pinned(t *testing.T) {
+	a := mkTestApp()
+	plat := &notifyPlatform{}
+	a.platform = plat
+	a.config = &config.Config{Agents: map[string]config.AgentConfig{
+		"aider": {PinnedVersion: "1.0.0"},
+	}}
+
+	// With no catalog or installer, anything past the pin check panics.
+	a.updateSingleAgent(mkInstallation("aider", "pip", "1.0.0", "1.1.0"))
+
+	if len(plat.titles) != 1 || plat.titles[0] != "Update Skipped" {
+		t.Fatalf("notifications = %v, want a single Update Skipped", plat.titles)
+	}
+	if !strings.Contains(plat.messages[0], "pinned to 1.0.0") {
+		t.Errorf("message = %q, want it to name the pin", plat.messages[0])
+	}
+}
@@ -3,13 +3,17 @@ package systray
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/getlantern/systray"
@@ -17,12 +21,14 @@ import (
 	"github.com/kevinelliott/agentmanager/internal/orchestrator"
 	"github.com/kevinelliott/agentmanager/internal/versionfetch"
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
 	grpcapi "github.com/kevinelliott/agentmanager/pkg/api/grpc"
 	"github.com/kevinelliott/agentmanager/pkg/api/rest"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/detector"
 	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/ipc"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
@@ -60,6 +66,10 @@ type App struct {
 	// gRPC API server (optional)
 	grpcServer *grpcapi.Server
 
+	// events is shared with the API servers, so watchers see changes made
+	// from the menu as well as through the APIs
+	events *events.Bus
+
 	// State
 	agents      []agent.Installation
 	agentsMu    sync.RWMutex
@@ -119,6 +129,7 @@ func New(cfg *config.Config, cfgLoader *config.Loader, plat platform.Platform, s
 		installer:    inst,
 		version:      version,
 		startTime:    time.Now(),
+		events:       events.NewBus(),
 		ctx:          ctx,
 		cancel:       cancel,
 		done:         make(chan struct{}),
@@ -165,10 +176,23 @@ func (a *App) startRESTServer() error {
 		a.config, a.platform, a.store, a.detector, a.catalog, a.installer,
 		rest.WithVersion(a.version),
 		rest.WithStartTime(a.startTime),
+		rest.WithEventBus(a.events),
 	)
-	return a.restServer.Start(a.ctx, rest.ServerConfig{
-		Address: fmt.Sprintf(":%d", a.config.API.RESTPort),
-	})
+
+	srvCfg := rest.ServerConfig{
+		Address: net.JoinHostPort(a.config.API.BindAddress, strconv.Itoa(a.config.API.RESTPort)),
+	}
+	if a.config.API.RESTSocket {
+		srvCfg = rest.ServerConfig{Network: "unix", Address: rest.SocketPath(a.platform)}
+	}
+
+	if err := a.restServer.Start(a.ctx, srvCfg); err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return fmt.Errorf("port %d is already in use (change api.rest_port or stop the other process): %w", a.config.API.RESTPort, err)
+		}
+		return err
+	}
+	return nil
 }
 
 // startGRPCServer starts the gRPC API server.
@@ -177,9 +201,10 @@ func (a *App) startGRPCServer() error {
 		a.config, a.platform, a.store, a.detector, a.catalog, a.installer,
 		grpcapi.WithVersion(a.version),
 		grpcapi.WithStartTime(a.startTime),
+		grpcapi.WithEventBus(a.events),
 	)
 	return a.grpcServer.Start(a.ctx, grpcapi.ServerConfig{
-		Address: fmt.Sprintf(":%d", a.config.API.GRPCPort),
+		Address: net.JoinHostPort(a.config.API.BindAddress, strconv.Itoa(a.config.API.GRPCPort)),
 	})
 }
 
@@ -615,10 +640,14 @@ func (a *App) checkUpdates(ctx context.Context) error {
 			// Write the refreshed LatestVersion fields back into the shared
 			// state under the write lock, matching snapshot ordering.
 			a.agentsMu.Lock()
+			var newlyAvailable []agent.Installation
 			for i := range snapshot {
 				if i >= len(a.agents) {
 					break
 				}
+				if snapshot[i].HasUpdate() && !sameVersion(a.agents[i].LatestVersion, snapshot[i].LatestVersion) {
+					newlyAvailable = append(newlyAvailable, *snapshot[i])
+				}
 				a.agents[i].LatestVersion = snapshot[i].LatestVersion
 			}
 			// Persist the refreshed snapshot so subsequent reads see the new
@@ -635,6 +664,10 @@ func (a *App) checkUpdates(ctx context.Context) error {
 			} else {
 				a.agentsMu.Unlock()
 			}
+
+			for i := range newlyAvailable {
+				a.events.PublishInstallation(events.TypeUpdateAvailable, &newlyAvailable[i])
+			}
 		}
 	}
 
@@ -982,6 +1015,15 @@ Write-Output 'close'
 
 // updateSingleAgent updates a single agent installation.
 func (a *App) updateSingleAgent(inst agent.Installation) {
+	// Pinned agents stay put; unpinning is left to the CLI.
+	if a.config.IsAgentPinned(inst.AgentID) {
+		a.platform.ShowNotification(
+			"Update Skipped",
+			fmt.Sprintf("%s is pinned to %s (run 'agentmgr agent unpin %s' to update it)", inst.AgentName, a.config.GetPinnedVersion(inst.AgentID), inst.AgentID),
+		)
+		return
+	}
+
 	a.platform.ShowNotification(
 		"Update Started",
 		fmt.Sprintf("Updating %s...", inst.AgentName),
@@ -1011,10 +1053,24 @@ func (a *App) updateSingleAgent(inst agent.Installation) {
 	}
 
 	// Perform the update
-	result, err := a.installer.Update(ctx, &inst, *agentDef, methodDef)
+	updateCtx, err := a.withConstraintTarget(ctx, &inst, methodDef)
 	if err != nil {
 		a.platform.ShowNotification(
-			"Update Failed",
+			"Update Skipped",
+			fmt.Sprintf("%s: %v", inst.AgentName, err),
+		)
+		return
+	}
+	record := storage.RecordEvent(ctx, a.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, &inst, providers.TargetVersion(updateCtx)), storage.UpdateStatusRunning)
+	result, err := a.runUpdate(updateCtx, &inst, *agentDef, methodDef)
+	storage.FinishEvent(ctx, a.store, record, result.VersionString(), err)
+	if err != nil {
+		title := "Update Failed"
+		if updateRolledBack(err) {
+			title = "Update Rolled Back"
+		}
+		a.platform.ShowNotification(
+			title,
 			fmt.Sprintf("Failed to update %s: %v", inst.AgentName, err),
 		)
 		return
@@ -1034,7 +1090,8 @@ func (a *App) updateAllAgents(ctx context.Context) {
 	a.agentsMu.RLock()
 	var toUpdate []agent.Installation
 	for _, ag := range a.agents {
-		if ag.HasUpdate() {
+		// Pinned agents are left alone by "Update All".
+		if ag.HasUpdate() && !a.config.IsAgentPinned(ag.AgentID) {
 			toUpdate = append(toUpdate, ag)
 		}
 	}
@@ -1049,14 +1106,21 @@ func (a *App) updateAllAgents(ctx context.Context) {
 		fmt.Sprintf("Updating %d agents...", len(toUpdate)),
 	)
 
-	// Update each agent sequentially
-	var succeeded, failed int
-	for _, inst := range toUpdate {
+	// Queue the batch in the update history before starting, then update
+	// each agent sequentially
+	records := make([]*storage.UpdateEvent, len(toUpdate))
+	for i := range toUpdate {
+		records[i] = storage.RecordEvent(ctx, a.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, &toUpdate[i], ""), storage.UpdateStatusPending)
+	}
+
+	var succeeded, failed, rolledBack int
+	for i, inst := range toUpdate {
 		updateCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 
 		// Get agent definition from catalog
 		agentDef, err := a.catalog.GetAgent(updateCtx, inst.AgentID)
 		if err != nil {
+			storage.FinishEvent(ctx, a.store, records[i], "", err)
 			failed++
 			cancel()
 			continue
@@ -1065,15 +1129,29 @@ func (a *App) updateAllAgents(ctx context.Context) {
 		// Find the install method
 		methodDef, ok := agentDef.GetInstallMethod(string(inst.Method))
 		if !ok {
+			storage.SetEventStatus(ctx, a.store, records[i], storage.UpdateStatusCancelled)
 			failed++
 			cancel()
 			continue
 		}
 
+		// Constraint-pinned agents only move within their range
+		updateCtx, err = a.withConstraintTarget(updateCtx, &inst, methodDef)
+		if err != nil {
+			storage.SetEventStatus(ctx, a.store, records[i], storage.UpdateStatusCancelled)
+			cancel()
+			continue
+		}
+
 		// Perform the update
-		_, err = a.installer.Update(updateCtx, &inst, *agentDef, methodDef)
+		storage.SetEventStatus(ctx, a.store, records[i], storage.UpdateStatusRunning)
+		result, err := a.runUpdate(updateCtx, &inst, *agentDef, methodDef)
+		storage.FinishEvent(ctx, a.store, records[i], result.VersionString(), err)
 		if err != nil {
 			failed++
+			if updateRolledBack(err) {
+				rolledBack++
+			}
 		} else {
 			succeeded++
 		}
@@ -1086,6 +1164,11 @@ func (a *App) updateAllAgents(ctx context.Context) {
 			"Updates Complete",
 			fmt.Sprintf("Successfully updated %d agents", succeeded),
 		)
+	} else if rolledBack > 0 {
+		a.platform.ShowNotification(
+			"Updates Complete",
+			fmt.Sprintf("Updated %d agents, %d failed (%d rolled back)", succeeded, failed, rolledBack),
+		)
 	} else {
 		a.platform.ShowNotification(
 			"Updates Complete",
@@ -1097,6 +1180,76 @@ func (a *App) updateAllAgents(ctx context.Context) {
 	a.refreshAgents(ctx)
 }
 
+// withConstraintTarget returns ctx asking the installer for the newest
+// release within inst's configured version constraint, or ctx unchanged
+// when the agent has none. It errors when nothing in range is newer than
+// the installed version.
+func (a *App) withConstraintTarget(ctx context.Context, inst *agent.Installation, methodDef catalog.InstallMethodDef) (context.Context, error) {
+	constraints := a.config.GetVersionConstraints(inst.AgentID)
+	if constraints == nil {
+		return ctx, nil
+	}
+	target, err := a.installer.ResolveVersion(ctx, methodDef, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsNewerThan(inst.InstalledVersion) {
+		return nil, fmt.Errorf("no version satisfying %s newer than %s", constraints, inst.InstalledVersion)
+	}
+	return providers.WithTargetVersion(ctx, target.String()), nil
+}
+
+// runInstall installs def with its output streamed to the event bus, and
+// publishes the new installation when it succeeds.
+func (a *App) runInstall(ctx context.Context, def catalog.AgentDef, methodDef catalog.InstallMethodDef, global bool) (*providers.Result, error) {
+	progress := a.events.ProgressWriter(string(storage.UpdateActionInstall), def.ID)
+	result, err := a.installer.Install(providers.WithProgressWriter(ctx, progress), def, methodDef, global)
+	progress.Close()
+	if err == nil {
+		a.events.PublishInstallation(events.TypeAdded, result.Installation(global))
+	}
+	return result, err
+}
+
+// runUpdate is runInstall for updates.
+func (a *App) runUpdate(ctx context.Context, inst *agent.Installation, def catalog.AgentDef, methodDef catalog.InstallMethodDef) (*providers.Result, error) {
+	progress := a.events.ProgressWriter(string(storage.UpdateActionUpdate), inst.AgentID)
+	result, err := a.installer.Update(providers.WithProgressWriter(ctx, progress), inst, def, methodDef)
+	progress.Close()
+	if err == nil {
+		updated := *inst
+		updated.InstalledVersion = result.Version
+		a.events.PublishInstallation(events.TypeUpdated, &updated)
+	}
+	return result, err
+}
+
+// updateRolledBack reports whether an update failed verification and
+// was undone by the installer.
+func updateRolledBack(err error) bool {
+	var verr *installer.VerificationError
+	return errors.As(err, &verr) && verr.RolledBack()
+}
+
+// runUninstall is runInstall for uninstalls.
+func (a *App) runUninstall(ctx context.Context, inst *agent.Installation, methodDef catalog.InstallMethodDef) error {
+	progress := a.events.ProgressWriter(string(storage.UpdateActionUninstall), inst.AgentID)
+	err := a.installer.Uninstall(providers.WithProgressWriter(ctx, progress), inst, methodDef)
+	progress.Close()
+	if err == nil {
+		a.events.PublishInstallation(events.TypeRemoved, inst)
+	}
+	return err
+}
+
+// sameVersion reports whether two optional versions are equal.
+func sameVersion(a, b *agent.Version) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(*b)
+}
+
 // openTUI launches the TUI application in a new terminal window.
 func (a *App) openTUI() {
 	// Find the agentmgr binary
@@ -16,6 +16,7 @@ import (
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
 
 // Apple HIG-compliant design constants
@@ -1686,7 +1687,7 @@ func (a *App) installAgent(def catalog.AgentDef) (bool, error) {
 	// Find the first available install method
 	for methodName, methodDef := range def.InstallMethods {
 		methodDef.Method = methodName // Ensure method name is set
-		_, err := a.installer.Install(a.ctx, def, methodDef, false)
+		err := a.installWithHistory(def, methodDef)
 		if err == nil {
 			return true, nil
 		}
@@ -1725,7 +1726,9 @@ func (a *App) updateAgentByID(agentID string) (bool, error) {
 	}
 	methodDef.Method = string(target.Method)
 
-	_, err = a.installer.Update(a.ctx, target, *agentDef, methodDef)
+	record := storage.RecordEvent(a.ctx, a.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, target, ""), storage.UpdateStatusRunning)
+	result, err := a.runUpdate(a.ctx, target, *agentDef, methodDef)
+	storage.FinishEvent(a.ctx, a.store, record, result.VersionString(), err)
 	return err == nil, err
 }
 
@@ -1756,7 +1759,9 @@ func (a *App) uninstallAgent(def catalog.AgentDef) (bool, error) {
 	}
 	methodDef.Method = methodName
 
-	err := a.installer.Uninstall(a.ctx, target, methodDef)
+	record := storage.RecordEvent(a.ctx, a.store, storage.NewInstallationEvent(storage.UpdateActionUninstall, target, ""), storage.UpdateStatusRunning)
+	err := a.runUninstall(a.ctx, target, methodDef)
+	storage.FinishEvent(a.ctx, a.store, record, "", err)
 	return err == nil, err
 }
 
@@ -1768,10 +1773,24 @@ func (a *App) installAgentWithMethod(def catalog.AgentDef, method string) (bool,
 	}
 	methodDef.Method = method
 
-	_, err := a.installer.Install(a.ctx, def, methodDef, false)
+	err := a.installWithHistory(def, methodDef)
 	return err == nil, err
 }
 
+// installWithHistory installs def via methodDef and records the attempt in
+// the update history.
+func (a *App) installWithHistory(def catalog.AgentDef, methodDef catalog.InstallMethodDef) error {
+	record := storage.RecordEvent(a.ctx, a.store, &storage.UpdateEvent{
+		AgentID:       def.ID,
+		AgentName:     def.Name,
+		Action:        storage.UpdateActionInstall,
+		InstallMethod: methodDef.Method,
+	}, storage.UpdateStatusRunning)
+	result, err := a.runInstall(a.ctx, def, methodDef, false)
+	storage.FinishEvent(a.ctx, a.store, record, result.VersionString(), err)
+	return err
+}
+
 // uninstallAgentWithMethod uninstalls an agent using a specific method.
 func (a *App) uninstallAgentWithMethod(def catalog.AgentDef, method string) (bool, error) {
 	// Find the installed agent with the specific method
@@ -1797,6 +1816,8 @@ func (a *App) uninstallAgentWithMethod(def catalog.AgentDef, method string) (boo
 	}
 	methodDef.Method = method
 
-	err := a.installer.Uninstall(a.ctx, target, methodDef)
+	record := storage.RecordEvent(a.ctx, a.store, storage.NewInstallationEvent(storage.UpdateActionUninstall, target, ""), storage.UpdateStatusRunning)
+	err := a.runUninstall(a.ctx, target, methodDef)
+	storage.FinishEvent(a.ctx, a.store, record, "", err)
 	return err == nil, err
 }
@@ -52,6 +52,7 @@ type Model struct {
 	// Data
 	agents      []*agent.Installation
 	catalog     *catalog.Catalog
+	activity    []*storage.UpdateEvent
 	selectedIdx int
 
 	// UI state
@@ -207,7 +208,7 @@ func (m Model) loadDataWithRefresh(forceRefresh bool) tea.Msg {
 	// intentionally dropped here; the TUI surfaces them indirectly via
 	// HasUpdate() being false for unchecked installations, matching the
 	// previous behavior.
-	det := detector.New(m.platform)
+	det := detector.New(m.platform, detector.WithConfig(m.config))
 	instMgr := installer.NewManager(m.platform)
 	pipeline := orchestrator.NewFromManagers(m.config, m.platform, m.store, m.catMgr, det, instMgr)
 	res, err := pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{ForceRefresh: forceRefresh})
@@ -215,16 +216,27 @@ func (m Model) loadDataWithRefresh(forceRefresh bool) tea.Msg {
 		return dataLoadedMsg{err: err}
 	}
 
+	// History is best-effort: without it the dashboard shows no activity.
+	var activity []*storage.UpdateEvent
+	if m.store != nil {
+		activity, _ = m.store.GetUpdateHistory(ctx, "", recentActivityLimit)
+	}
+
 	return dataLoadedMsg{
-		agents: res.Installations,
+		agents:   res.Installations,
+		activity: activity,
 	}
 }
 
+// recentActivityLimit is how many history events the dashboard shows.
+const recentActivityLimit = 5
+
 // dataLoadedMsg is sent when data has been loaded.
 type dataLoadedMsg struct {
-	agents  []*agent.Installation
-	catalog *catalog.Catalog
-	err     error
+	agents   []*agent.Installation
+	catalog  *catalog.Catalog
+	activity []*storage.UpdateEvent
+	err      error
 }
 
 func (m Model) loadCatalog(forceRefresh bool) tea.Msg {
@@ -305,6 +317,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.err = msg.err
 		} else {
 			m.agents = msg.agents
+			m.activity = msg.activity
 			if msg.catalog != nil {
 				m.catalog = msg.catalog
 			}
@@ -467,10 +480,19 @@ func (m Model) dashboardView() string {
 		styles.Version.Render(m.platform.Architecture()),
 	))
 
-	// Recent activity placeholder
+	// Recent activity from the install/update history
+	activity := "  No recent activity"
+	if len(m.activity) > 0 {
+		lines := make([]string, len(m.activity))
+		for i, e := range m.activity {
+			lines[i] = "  " + activityLine(e)
+		}
+		activity = strings.Join(lines, "\n")
+	}
 	activityBox := styles.Box.Render(fmt.Sprintf(
-		"%s\n\n  No recent activity",
+		"%s\n\n%s",
 		styles.Subtitle.Render("Recent Activity"),
+		activity,
 	))
 
 	// Layout boxes horizontally
@@ -484,6 +506,34 @@ func (m Model) dashboardView() string {
 	return b.String()
 }
 
+// activityLine renders a history event for the dashboard, e.g.
+// "Jan 2 15:04  update claude-code 1.0.0 -> 1.1.0  rolled back". Updates
+// undone after failing verification stand out from plain failures.
+func activityLine(e *storage.UpdateEvent) string {
+	version := e.ToVersion
+	if e.FromVersion != "" && e.ToVersion != "" {
+		version = e.FromVersion + " -> " + e.ToVersion
+	} else if version == "" {
+		version = e.FromVersion
+	}
+
+	var status string
+	switch e.Status {
+	case storage.UpdateStatusCompleted:
+		status = styles.StatusInstalled.Render("completed")
+	case storage.UpdateStatusFailed:
+		status = styles.StatusError.Render("failed")
+	case storage.UpdateStatusRolledBack:
+		status = styles.StatusUpdateAvailable.Render("rolled back")
+	default:
+		status = styles.Help.Render(string(e.Status))
+	}
+
+	return fmt.Sprintf("%s  %s %s %s  %s",
+		styles.Help.Render(e.StartedAt.Local().Format("Jan 2 15:04")),
+		e.Action, e.AgentID, styles.Version.Render(version), status)
+}
+
 // agentListView renders the agent list.
 func (m Model) agentListView() string {
 	if m.loading {
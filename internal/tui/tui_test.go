@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
 
 func TestViewConstants(t *testing.T) {
@@ -199,3 +202,40 @@ func TestAgentItemWithDifferentAgents(t *testing.T) {
 		})
 	}
 }
+
+func TestActivityLine(t *testing.T) {
+	started := time.Date(2026, 3, 4, 5, 6, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		event storage.UpdateEvent
+		want  []string
+	}{
+		{
+			"completed update",
+			storage.UpdateEvent{AgentID: "aider", Action: storage.UpdateActionUpdate, FromVersion: "0.50.0", ToVersion: "0.51.0", Status: storage.UpdateStatusCompleted},
+			[]string{"Mar 4 05:06", "update aider", "0.50.0 -> 0.51.0", "completed"},
+		},
+		{
+			"rolled back update",
+			storage.UpdateEvent{AgentID: "claude-code", Action: storage.UpdateActionUpdate, FromVersion: "2.0.0", ToVersion: "2.1.0", Status: storage.UpdateStatusRolledBack},
+			[]string{"update claude-code", "2.0.0 -> 2.1.0", "rolled back"},
+		},
+		{
+			"install",
+			storage.UpdateEvent{AgentID: "aider", Action: storage.UpdateActionInstall, ToVersion: "0.50.0", Status: storage.UpdateStatusFailed},
+			[]string{"install aider", "0.50.0", "failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.StartedAt = started
+			line := activityLine(&tt.event)
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("activityLine() = %q, want it to contain %q", line, want)
+				}
+			}
+		})
+	}
+}
@@ -22,6 +22,10 @@ const (
 	InstallMethodNative     InstallMethod = "native"
 	InstallMethodCurl       InstallMethod = "curl"
 	InstallMethodBinary     InstallMethod = "binary"
+	InstallMethodCargo      InstallMethod = "cargo"
+	InstallMethodGo         InstallMethod = "go"
+	InstallMethodBun        InstallMethod = "bun"
+	InstallMethodNix        InstallMethod = "nix"
 
 	// Short form aliases
 	MethodNPM        = InstallMethodNPM
@@ -35,6 +39,10 @@ const (
 	MethodNative     = InstallMethodNative
 	MethodCurl       = InstallMethodCurl
 	MethodBinary     = InstallMethodBinary
+	MethodCargo      = InstallMethodCargo
+	MethodGo         = InstallMethodGo
+	MethodBun        = InstallMethodBun
+	MethodNix        = InstallMethodNix
 )
 
 // String returns the string representation of the install method.
@@ -56,6 +64,10 @@ func (m InstallMethod) DisplayName() string {
 		InstallMethodNative:     "Native Installer",
 		InstallMethodCurl:       "curl",
 		InstallMethodBinary:     "Binary",
+		InstallMethodCargo:      "Cargo",
+		InstallMethodGo:         "go install",
+		InstallMethodBun:        "Bun",
+		InstallMethodNix:        "Nix",
 	}
 	if name, ok := names[m]; ok {
 		return name
@@ -111,6 +123,10 @@ const (
 	StatusError      Status = "error"
 	StatusInstalling Status = "installing"
 	StatusUpdating   Status = "updating"
+	// StatusPinned marks an installation whose agent has a pinned version in
+	// the user config. GetStatus never returns it; callers that have the
+	// config at hand substitute it so pinned agents stand out in listings.
+	StatusPinned Status = "pinned"
 )
 
 // GetStatus returns the current status of the installation.
@@ -21,6 +21,10 @@ func TestInstallMethodString(t *testing.T) {
 		{InstallMethodNative, "native"},
 		{InstallMethodCurl, "curl"},
 		{InstallMethodBinary, "binary"},
+		{InstallMethodCargo, "cargo"},
+		{InstallMethodGo, "go"},
+		{InstallMethodBun, "bun"},
+		{InstallMethodNix, "nix"},
 	}
 
 	for _, tt := range tests {
@@ -49,6 +53,8 @@ func TestInstallMethodDisplayName(t *testing.T) {
 		{InstallMethodNative, "Native Installer"},
 		{InstallMethodCurl, "curl"},
 		{InstallMethodBinary, "Binary"},
+		{InstallMethodCargo, "Cargo"},
+		{InstallMethodNix, "Nix"},
 		{InstallMethod("unknown"), "unknown"}, // Unknown method returns string form
 	}
 
@@ -64,7 +64,10 @@ func DefaultRegistryOptions() RegistryOptions {
 type RegistryEvent struct {
 	Type         RegistryEventType `json:"type"`
 	Installation *Installation     `json:"installation,omitempty"`
-	Error        error             `json:"error,omitempty"`
+	// Previous is the installation as it was before an updated or removed
+	// event.
+	Previous *Installation `json:"previous,omitempty"`
+	Error    error         `json:"error,omitempty"`
 }
 
 // RegistryEventType defines types of registry events.
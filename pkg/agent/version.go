@@ -269,3 +269,120 @@ func (c VersionConstraint) Matches(v Version) bool {
 		return cmp == 0
 	}
 }
+
+// VersionConstraints is a set of constraints that must all match, such as
+// the ">=1.2 <2" in a user's pinned_version setting.
+type VersionConstraints []VersionConstraint
+
+// constraintOperators lists recognised operators, longest first so that
+// ">=" is not mistaken for ">".
+var constraintOperators = []string{">=", "<=", "==", ">", "<", "=", "~", "^"}
+
+// ParseVersionConstraints parses a constraint string into its constraints.
+// Constraints are separated by commas or whitespace; a bare version means
+// an exact match. Examples: "1.2.3", "^2.1", "~1.4.0", ">=1.0, <2.0".
+func ParseVersionConstraints(s string) (VersionConstraints, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	// Allow a space between operator and version (">= 1.2").
+	var parts []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		for _, op := range constraintOperators {
+			if f == op && i+1 < len(fields) {
+				f += fields[i+1]
+				i++
+				break
+			}
+		}
+		parts = append(parts, f)
+	}
+
+	constraints := make(VersionConstraints, 0, len(parts))
+	for _, part := range parts {
+		op := "="
+		for _, candidate := range constraintOperators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = part[len(candidate):]
+				break
+			}
+		}
+		if op == "==" {
+			op = "="
+		}
+		if !semverRegex.MatchString(part) {
+			return nil, fmt.Errorf("invalid version %q in constraint %q", part, s)
+		}
+		v, err := ParseVersion(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q in constraint %q: %w", part, s, err)
+		}
+		constraints = append(constraints, VersionConstraint{Operator: op, Version: v})
+	}
+	return constraints, nil
+}
+
+// Matches returns true if v satisfies every constraint in the set.
+func (cs VersionConstraints) Matches(v Version) bool {
+	for _, c := range cs {
+		if !c.Matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsExact returns true if the set pins a single exact version.
+func (cs VersionConstraints) IsExact() bool {
+	return len(cs) == 1 && (cs[0].Operator == "=" || cs[0].Operator == "==")
+}
+
+// String returns the constraints in their canonical comma-separated form.
+func (cs VersionConstraints) String() string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		op := c.Operator
+		if op == "=" {
+			op = ""
+		}
+		parts[i] = op + c.Version.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Latest returns the newest version in versions that satisfies the
+// constraints. Prereleases are only considered when one of the constraints
+// names a prerelease itself, matching how npm and pip resolve ranges.
+func (cs VersionConstraints) Latest(versions []Version) (Version, bool) {
+	allowPrerelease := false
+	for _, c := range cs {
+		if c.Version.Prerelease != "" {
+			allowPrerelease = true
+			break
+		}
+	}
+
+	var (
+		best  Version
+		found bool
+	)
+	for _, v := range versions {
+		if v.Prerelease != "" && !allowPrerelease {
+			continue
+		}
+		if !cs.Matches(v) {
+			continue
+		}
+		if !found || v.IsNewerThan(best) {
+			best = v
+			found = true
+		}
+	}
+	return best, found
+}
@@ -397,3 +397,80 @@ func TestVersionConstraintMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVersionConstraints(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		exact   bool
+		wantErr bool
+	}{
+		{"1.2.3", "1.2.3", true, false},
+		{"==1.2.3", "1.2.3", true, false},
+		{"^2.1", "^2.1", false, false},
+		{"~1.4.0", "~1.4.0", false, false},
+		{">=1.0, <2.0", ">=1.0, <2.0", false, false},
+		{">= 1.0 < 2.0", ">=1.0, <2.0", false, false},
+		{"v1.2.3", "v1.2.3", true, false},
+		{"", "", false, true},
+		{"^", "", false, true},
+		{"latest", "", false, true},
+		{">=1.0, banana", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseVersionConstraints(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseVersionConstraints(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersionConstraints(%q) error = %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("String() = %q, want %q", got.String(), tt.want)
+			}
+			if got.IsExact() != tt.exact {
+				t.Errorf("IsExact() = %v, want %v", got.IsExact(), tt.exact)
+			}
+		})
+	}
+}
+
+func TestVersionConstraintsLatest(t *testing.T) {
+	var versions []Version
+	for _, s := range []string{"1.9.0", "2.0.0", "2.1.0", "2.1.5", "2.2.0-beta.1", "2.4.1", "3.0.0"} {
+		versions = append(versions, MustParseVersion(s))
+	}
+
+	tests := []struct {
+		constraint string
+		want       string
+		found      bool
+	}{
+		{"^2.1", "2.4.1", true},
+		{"~2.1", "2.1.5", true},
+		{">=2.0, <2.2", "2.1.5", true},
+		{">=2.2.0-beta.0, <2.3", "2.2.0-beta.1", true},
+		{"^4.0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			cs, err := ParseVersionConstraints(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseVersionConstraints() error = %v", err)
+			}
+			got, found := cs.Latest(versions)
+			if found != tt.found {
+				t.Fatalf("Latest() found = %v, want %v", found, tt.found)
+			}
+			if found && got.String() != tt.want {
+				t.Errorf("Latest() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
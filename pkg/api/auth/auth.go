@@ -0,0 +1,93 @@
+// Package auth implements the bearer-token checks shared by the REST and
+// gRPC API servers.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// tokenBytes is the amount of randomness in a generated token.
+const tokenBytes = 32
+
+// Errors returned by Authenticate.
+var (
+	ErrNoToken      = errors.New("missing API token")
+	ErrInvalidToken = errors.New("invalid API token")
+)
+
+// GenerateToken returns a new random API token, hex encoded.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// BearerToken extracts the token from an Authorization header value of the
+// form "Bearer <token>". The scheme is matched case-insensitively.
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Authenticator checks request tokens against the API config and the
+// scoped tokens kept in the settings store.
+type Authenticator struct {
+	cfg   *config.Config
+	store storage.Store
+}
+
+// New returns an Authenticator for cfg.API and the scoped tokens in store.
+// The store is read on every check, so tokens created or revoked while a
+// server runs take effect on its next request. A nil cfg disables
+// authentication; a nil store leaves only api.auth_token.
+func New(cfg *config.Config, store storage.Store) *Authenticator {
+	return &Authenticator{cfg: cfg, store: store}
+}
+
+// Required reports whether requests must carry a token.
+func (a *Authenticator) Required() bool {
+	return a != nil && a.cfg != nil && a.cfg.API.RequireAuth
+}
+
+// Authenticate returns the scope token grants. When authentication is not
+// required every caller is an admin. api.auth_token is an admin token;
+// anything else must match a token created with CreateToken.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Scope, error) {
+	if !a.Required() {
+		return ScopeAdmin, nil
+	}
+	if token == "" {
+		return "", ErrNoToken
+	}
+
+	if want := a.cfg.API.AuthToken; want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+		return ScopeAdmin, nil
+	}
+
+	if a.store != nil {
+		tokens, err := ListTokens(ctx, a.store)
+		if err != nil {
+			return "", err
+		}
+		if t := matchToken(tokens, token); t != nil {
+			return t.Scope, nil
+		}
+	}
+
+	return "", ErrInvalidToken
+}
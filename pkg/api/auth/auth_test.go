@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+func TestGenerateToken(t *testing.T) {
+	a, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	b, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if len(a) != 2*tokenBytes {
+		t.Errorf("len(token) = %d, want %d", len(a), 2*tokenBytes)
+	}
+	if a == b {
+		t.Error("GenerateToken() returned the same token twice")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		wantOK bool
+	}{
+		{"Bearer abc123", "abc123", true},
+		{"bearer abc123", "abc123", true},
+		{"  Bearer   abc123  ", "abc123", true},
+		{"Basic abc123", "", false},
+		{"Bearer", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := BearerToken(tt.header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("BearerToken(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// newTestStore returns an initialized SQLite store in a temp dir.
+func newTestStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return store
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	readSecret, err := CreateToken(ctx, store, "dashboard", ScopeRead)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	required := &config.Config{API: config.APIConfig{RequireAuth: true, AuthToken: "secret"}}
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		token     string
+		wantScope Scope
+		wantErr   error
+	}{
+		{"nil config", nil, "", ScopeAdmin, nil},
+		{"auth off", &config.Config{API: config.APIConfig{AuthToken: "secret"}}, "", ScopeAdmin, nil},
+		{"missing token", required, "", "", ErrNoToken},
+		{"wrong token", required, "guess", "", ErrInvalidToken},
+		{"config token is admin", required, "secret", ScopeAdmin, nil},
+		{"scoped token", required, readSecret, ScopeRead, nil},
+		{"no config token", &config.Config{API: config.APIConfig{RequireAuth: true}}, "", "", ErrNoToken},
+		{"no config token, scoped token", &config.Config{API: config.APIConfig{RequireAuth: true}}, readSecret, ScopeRead, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := New(tt.cfg, store).Authenticate(ctx, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate(%q) error = %v, want %v", tt.token, err, tt.wantErr)
+			}
+			if scope != tt.wantScope {
+				t.Errorf("Authenticate(%q) scope = %q, want %q", tt.token, scope, tt.wantScope)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// tokensSettingKey is the settings-store key holding every scoped token.
+const tokensSettingKey = "api_tokens"
+
+// Scope is the set of API operations a token may call. Each scope includes
+// everything the ones below it allow.
+type Scope string
+
+const (
+	// ScopeRead allows listing agents, the catalog, updates and history.
+	ScopeRead Scope = "read"
+	// ScopeUpdate additionally allows installing and updating agents and
+	// refreshing the catalog.
+	ScopeUpdate Scope = "update"
+	// ScopeAdmin allows everything, including uninstalling agents.
+	ScopeAdmin Scope = "admin"
+)
+
+// scopeRank orders scopes from least to most privileged.
+var scopeRank = map[Scope]int{
+	ScopeRead:   1,
+	ScopeUpdate: 2,
+	ScopeAdmin:  3,
+}
+
+// ErrTokenNotFound is returned by RevokeToken for an unknown name.
+var ErrTokenNotFound = errors.New("API token not found")
+
+// ParseScope validates a scope name.
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(s)
+	if _, ok := scopeRank[scope]; !ok {
+		return "", fmt.Errorf("unknown scope %q (want read, update or admin)", s)
+	}
+	return scope, nil
+}
+
+// Allows reports whether a token with scope s may call an operation that
+// needs scope need.
+func (s Scope) Allows(need Scope) bool {
+	have, ok := scopeRank[s]
+	return ok && have >= scopeRank[need]
+}
+
+// Token is a named, scoped API token as stored. Only a SHA-256 hash of the
+// secret is kept; the secret itself is shown once, by CreateToken.
+type Token struct {
+	Name      string    `json:"name"`
+	Scope     Scope     `json:"scope"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListTokens returns the stored tokens in creation order.
+func ListTokens(ctx context.Context, store storage.Store) ([]Token, error) {
+	value, err := store.GetSetting(ctx, tokensSettingKey)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	var tokens []Token
+	if err := json.Unmarshal([]byte(value), &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// CreateToken stores a new token called name with the given scope and
+// returns its secret. Names must be unique.
+func CreateToken(ctx context.Context, store storage.Store, name string, scope Scope) (string, error) {
+	if name == "" {
+		return "", errors.New("token name is required")
+	}
+	if _, err := ParseScope(string(scope)); err != nil {
+		return "", err
+	}
+
+	tokens, err := ListTokens(ctx, store)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tokens {
+		if t.Name == name {
+			return "", fmt.Errorf("API token %q already exists", name)
+		}
+	}
+
+	secret, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	tokens = append(tokens, Token{
+		Name:      name,
+		Scope:     scope,
+		Hash:      hashToken(secret),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err := saveTokens(ctx, store, tokens); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// RevokeToken deletes the token called name.
+func RevokeToken(ctx context.Context, store storage.Store, name string) error {
+	tokens, err := ListTokens(ctx, store)
+	if err != nil {
+		return err
+	}
+	for i, t := range tokens {
+		if t.Name == name {
+			return saveTokens(ctx, store, append(tokens[:i], tokens[i+1:]...))
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrTokenNotFound, name)
+}
+
+func saveTokens(ctx context.Context, store storage.Store, tokens []Token) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return store.SetSetting(ctx, tokensSettingKey, string(data))
+}
+
+// matchToken returns the stored token whose hash matches secret, or nil.
+// Every hash is compared so the time taken does not depend on which token
+// (if any) matched.
+func matchToken(tokens []Token, secret string) *Token {
+	hash := []byte(hashToken(secret))
+	var found *Token
+	for i := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(tokens[i].Hash)) == 1 {
+			found = &tokens[i]
+		}
+	}
+	return found
+}
+
+// hashToken returns the hex SHA-256 of secret. Tokens are 256 random bits,
+// so a fast hash is enough to make a leaked database useless.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		have, need Scope
+		want       bool
+	}{
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeUpdate, false},
+		{ScopeRead, ScopeAdmin, false},
+		{ScopeUpdate, ScopeRead, true},
+		{ScopeUpdate, ScopeUpdate, true},
+		{ScopeUpdate, ScopeAdmin, false},
+		{ScopeAdmin, ScopeAdmin, true},
+		{"", ScopeRead, false},
+		{"bogus", ScopeRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.have.Allows(tt.need); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.have, tt.need, got, tt.want)
+		}
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for _, s := range []string{"read", "update", "admin"} {
+		if _, err := ParseScope(s); err != nil {
+			t.Errorf("ParseScope(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseScope("write"); err == nil {
+		t.Error("ParseScope(write) should fail")
+	}
+}
+
+func TestTokenLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	secret, err := CreateToken(ctx, store, "provisioner", ScopeUpdate)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if _, err := CreateToken(ctx, store, "provisioner", ScopeRead); err == nil {
+		t.Error("CreateToken() with a duplicate name should fail")
+	}
+	if _, err := CreateToken(ctx, store, "dashboard", "write"); err == nil {
+		t.Error("CreateToken() with an unknown scope should fail")
+	}
+
+	tokens, err := ListTokens(ctx, store)
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Name != "provisioner" || tokens[0].Scope != ScopeUpdate {
+		t.Fatalf("ListTokens() = %+v", tokens)
+	}
+
+	// Only the hash is stored.
+	raw, err := store.GetSetting(ctx, tokensSettingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(raw, secret) {
+		t.Error("settings store should not contain the token secret")
+	}
+	if got := matchToken(tokens, secret); got == nil || got.Name != "provisioner" {
+		t.Errorf("matchToken() = %v, want provisioner", got)
+	}
+
+	if err := RevokeToken(ctx, store, "provisioner"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if err := RevokeToken(ctx, store, "provisioner"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("second RevokeToken() error = %v, want ErrTokenNotFound", err)
+	}
+	tokens, _ = ListTokens(ctx, store)
+	if matchToken(tokens, secret) != nil {
+		t.Error("revoked token should no longer match")
+	}
+}
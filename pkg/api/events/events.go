@@ -0,0 +1,186 @@
+// Package events provides the in-process event bus shared by the REST
+// server, the gRPC server and the systray helper, so that an install made
+// through any of them reaches every watcher.
+package events
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 100
+
+// Type identifies the kind of an Event.
+type Type string
+
+const (
+	// TypeAdded is published after an agent is installed.
+	TypeAdded Type = "added"
+	// TypeUpdated is published after an agent is updated.
+	TypeUpdated Type = "updated"
+	// TypeRemoved is published after an agent is uninstalled.
+	TypeRemoved Type = "removed"
+	// TypeUpdateAvailable is published when an update check finds a newer
+	// release for an installed agent.
+	TypeUpdateAvailable Type = "update_available"
+	// TypeProgress carries one line of output from a running operation.
+	TypeProgress Type = "progress"
+)
+
+// Event is a single notification on the Bus.
+type Event struct {
+	Type Type
+	// AgentID is set on every event.
+	AgentID string
+	// Installation is the affected installation, when there is one. It is
+	// nil for progress lines of an install that has not finished.
+	Installation *agent.Installation
+	// Operation ("install", "update" or "uninstall") and Message are set on
+	// progress events.
+	Operation string
+	Message   string
+	Timestamp time.Time
+}
+
+// Bus fans events out to subscribers. A nil *Bus discards everything, so
+// publishers need not check whether one was configured.
+type Bus struct {
+	mu   sync.RWMutex
+	subs []chan Event
+}
+
+// NewBus returns an empty Bus.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe returns a channel receiving every event published from now on.
+// Release it with Unsubscribe.
+func (b *Bus) Subscribe() <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe stops delivery to ch and closes it. Unknown channels are
+// ignored.
+func (b *Bus) Unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subs {
+		if sub == ch {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers.
+func (b *Bus) Subscribers() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Publish delivers event to every subscriber, stamping it with the current
+// time if it has none. Subscribers whose buffer is full miss the event
+// rather than blocking the publisher.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			// Channel full, skip
+		}
+	}
+}
+
+// PublishInstallation publishes an added, updated or removed event for inst.
+func (b *Bus) PublishInstallation(typ Type, inst *agent.Installation) {
+	if inst == nil {
+		return
+	}
+	b.Publish(Event{Type: typ, AgentID: inst.AgentID, Installation: inst})
+}
+
+// ProgressWriter returns a writer that publishes each line written to it as
+// a progress event for operation on agentID. Attach it to an installer
+// call with providers.WithProgressWriter, and Close it afterwards to flush
+// a final line that has no trailing newline.
+func (b *Bus) ProgressWriter(operation, agentID string) *ProgressWriter {
+	return &ProgressWriter{bus: b, operation: operation, agentID: agentID}
+}
+
+// ProgressWriter splits subprocess output into lines and publishes them.
+// Carriage returns end a line too, so progress bars that redraw in place
+// produce one event per redraw.
+type ProgressWriter struct {
+	bus       *Bus
+	operation string
+	agentID   string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (w *ProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.publish(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Close publishes any buffered partial line.
+func (w *ProgressWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.publish(w.buf)
+	w.buf = nil
+	return nil
+}
+
+func (w *ProgressWriter) publish(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	w.bus.Publish(Event{
+		Type:      TypeProgress,
+		AgentID:   w.agentID,
+		Operation: w.operation,
+		Message:   string(line),
+	})
+}
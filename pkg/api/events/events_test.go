@@ -0,0 +1,97 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+)
+
+func TestBusPublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	ch1 := bus.Subscribe()
+	ch2 := bus.Subscribe()
+	if got := bus.Subscribers(); got != 2 {
+		t.Fatalf("Subscribers() = %d, want 2", got)
+	}
+
+	bus.PublishInstallation(TypeAdded, &agent.Installation{AgentID: "aider"})
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		select {
+		case e := <-ch:
+			if e.Type != TypeAdded || e.AgentID != "aider" || e.Installation == nil {
+				t.Errorf("event = %+v", e)
+			}
+			if e.Timestamp.IsZero() {
+				t.Error("Publish should stamp events")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("subscriber did not receive the event")
+		}
+	}
+
+	bus.Unsubscribe(ch1)
+	if _, ok := <-ch1; ok {
+		t.Error("Unsubscribe should close the channel")
+	}
+	bus.Unsubscribe(make(chan Event)) // unknown channels are ignored
+	if got := bus.Subscribers(); got != 1 {
+		t.Errorf("Subscribers() = %d, want 1", got)
+	}
+	bus.Unsubscribe(ch2)
+}
+
+func TestBusDropsForSlowSubscribers(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe()
+	defer bus.Unsubscribe(ch)
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(Event{Type: TypeProgress, Message: fmt.Sprint(i)})
+	}
+	if got := len(ch); got != subscriberBuffer {
+		t.Errorf("buffered = %d, want %d", got, subscriberBuffer)
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	var bus *Bus
+	bus.Publish(Event{Type: TypeAdded})
+	bus.PublishInstallation(TypeRemoved, &agent.Installation{})
+	if got := bus.Subscribers(); got != 0 {
+		t.Errorf("Subscribers() = %d, want 0", got)
+	}
+	w := bus.ProgressWriter("install", "aider")
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Errorf("Write() error = %v", err)
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe()
+	defer bus.Unsubscribe(ch)
+
+	w := bus.ProgressWriter("update", "claude-code")
+	fmt.Fprint(w, "fetching pack")
+	fmt.Fprint(w, "ages\n\n10%\r50%\r")
+	fmt.Fprint(w, "done")
+	w.Close()
+
+	want := []string{"fetching packages", "10%", "50%", "done"}
+	for _, msg := range want {
+		select {
+		case e := <-ch:
+			if e.Type != TypeProgress || e.Operation != "update" || e.AgentID != "claude-code" || e.Message != msg {
+				t.Errorf("event = %+v, want message %q", e, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("missing progress line %q", msg)
+		}
+	}
+	if len(ch) != 0 {
+		t.Errorf("%d unexpected extra events", len(ch))
+	}
+}
@@ -0,0 +1,2389 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: agentmgr.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Installation represents an installed agent.
+type Installation struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Key              string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	AgentId          string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentName        string                 `protobuf:"bytes,3,opt,name=agent_name,json=agentName,proto3" json:"agent_name,omitempty"`
+	InstallMethod    string                 `protobuf:"bytes,4,opt,name=install_method,json=installMethod,proto3" json:"install_method,omitempty"`
+	InstalledVersion string                 `protobuf:"bytes,5,opt,name=installed_version,json=installedVersion,proto3" json:"installed_version,omitempty"`
+	LatestVersion    string                 `protobuf:"bytes,6,opt,name=latest_version,json=latestVersion,proto3" json:"latest_version,omitempty"`
+	ExecutablePath   string                 `protobuf:"bytes,7,opt,name=executable_path,json=executablePath,proto3" json:"executable_path,omitempty"`
+	InstallPath      string                 `protobuf:"bytes,8,opt,name=install_path,json=installPath,proto3" json:"install_path,omitempty"`
+	IsGlobal         bool                   `protobuf:"varint,9,opt,name=is_global,json=isGlobal,proto3" json:"is_global,omitempty"`
+	DetectedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=detected_at,json=detectedAt,proto3" json:"detected_at,omitempty"`
+	LastChecked      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=last_checked,json=lastChecked,proto3" json:"last_checked,omitempty"`
+	Metadata         map[string]string      `protobuf:"bytes,12,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	HasUpdate        bool                   `protobuf:"varint,13,opt,name=has_update,json=hasUpdate,proto3" json:"has_update,omitempty"`
+	Status           string                 `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"`
+	PinnedVersion    string                 `protobuf:"bytes,15,opt,name=pinned_version,json=pinnedVersion,proto3" json:"pinned_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Installation) Reset() {
+	*x = Installation{}
+	mi := &file_agentmgr_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Installation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Installation) ProtoMessage() {}
+
+func (x *Installation) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Installation.ProtoReflect.Descriptor instead.
+func (*Installation) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Installation) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Installation) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Installation) GetAgentName() string {
+	if x != nil {
+		return x.AgentName
+	}
+	return ""
+}
+
+func (x *Installation) GetInstallMethod() string {
+	if x != nil {
+		return x.InstallMethod
+	}
+	return ""
+}
+
+func (x *Installation) GetInstalledVersion() string {
+	if x != nil {
+		return x.InstalledVersion
+	}
+	return ""
+}
+
+func (x *Installation) GetLatestVersion() string {
+	if x != nil {
+		return x.LatestVersion
+	}
+	return ""
+}
+
+func (x *Installation) GetExecutablePath() string {
+	if x != nil {
+		return x.ExecutablePath
+	}
+	return ""
+}
+
+func (x *Installation) GetInstallPath() string {
+	if x != nil {
+		return x.InstallPath
+	}
+	return ""
+}
+
+func (x *Installation) GetIsGlobal() bool {
+	if x != nil {
+		return x.IsGlobal
+	}
+	return false
+}
+
+func (x *Installation) GetDetectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DetectedAt
+	}
+	return nil
+}
+
+func (x *Installation) GetLastChecked() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastChecked
+	}
+	return nil
+}
+
+func (x *Installation) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Installation) GetHasUpdate() bool {
+	if x != nil {
+		return x.HasUpdate
+	}
+	return false
+}
+
+func (x *Installation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Installation) GetPinnedVersion() string {
+	if x != nil {
+		return x.PinnedVersion
+	}
+	return ""
+}
+
+// CatalogAgent represents an agent in the catalog.
+type CatalogAgent struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Homepage       string                 `protobuf:"bytes,4,opt,name=homepage,proto3" json:"homepage,omitempty"`
+	Repository     string                 `protobuf:"bytes,5,opt,name=repository,proto3" json:"repository,omitempty"`
+	InstallMethods []*InstallMethodDef    `protobuf:"bytes,6,rep,name=install_methods,json=installMethods,proto3" json:"install_methods,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CatalogAgent) Reset() {
+	*x = CatalogAgent{}
+	mi := &file_agentmgr_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogAgent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogAgent) ProtoMessage() {}
+
+func (x *CatalogAgent) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogAgent.ProtoReflect.Descriptor instead.
+func (*CatalogAgent) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CatalogAgent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CatalogAgent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CatalogAgent) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CatalogAgent) GetHomepage() string {
+	if x != nil {
+		return x.Homepage
+	}
+	return ""
+}
+
+func (x *CatalogAgent) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *CatalogAgent) GetInstallMethods() []*InstallMethodDef {
+	if x != nil {
+		return x.InstallMethods
+	}
+	return nil
+}
+
+// InstallMethodDef defines an installation method.
+type InstallMethodDef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Package       string                 `protobuf:"bytes,2,opt,name=package,proto3" json:"package,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	Platforms     []string               `protobuf:"bytes,4,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallMethodDef) Reset() {
+	*x = InstallMethodDef{}
+	mi := &file_agentmgr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallMethodDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallMethodDef) ProtoMessage() {}
+
+func (x *InstallMethodDef) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallMethodDef.ProtoReflect.Descriptor instead.
+func (*InstallMethodDef) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InstallMethodDef) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *InstallMethodDef) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *InstallMethodDef) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *InstallMethodDef) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+// Filter for listing agents.
+type AgentFilter struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AgentIds []string               `protobuf:"bytes,1,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	Methods  []string               `protobuf:"bytes,2,rep,name=methods,proto3" json:"methods,omitempty"`
+	// Unset matches both values.
+	HasUpdate     *bool  `protobuf:"varint,3,opt,name=has_update,json=hasUpdate,proto3,oneof" json:"has_update,omitempty"`
+	IsGlobal      *bool  `protobuf:"varint,4,opt,name=is_global,json=isGlobal,proto3,oneof" json:"is_global,omitempty"`
+	Query         string `protobuf:"bytes,5,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentFilter) Reset() {
+	*x = AgentFilter{}
+	mi := &file_agentmgr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentFilter) ProtoMessage() {}
+
+func (x *AgentFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentFilter.ProtoReflect.Descriptor instead.
+func (*AgentFilter) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AgentFilter) GetAgentIds() []string {
+	if x != nil {
+		return x.AgentIds
+	}
+	return nil
+}
+
+func (x *AgentFilter) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *AgentFilter) GetHasUpdate() bool {
+	if x != nil && x.HasUpdate != nil {
+		return *x.HasUpdate
+	}
+	return false
+}
+
+func (x *AgentFilter) GetIsGlobal() bool {
+	if x != nil && x.IsGlobal != nil {
+		return *x.IsGlobal
+	}
+	return false
+}
+
+func (x *AgentFilter) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+// ListAgentsRequest requests a list of agents.
+type ListAgentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *AgentFilter           `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	SortBy        string                 `protobuf:"bytes,2,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder     string                 `protobuf:"bytes,3,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsRequest) Reset() {
+	*x = ListAgentsRequest{}
+	mi := &file_agentmgr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsRequest) ProtoMessage() {}
+
+func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListAgentsRequest) GetFilter() *AgentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListAgentsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListAgentsRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+func (x *ListAgentsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAgentsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// ListAgentsResponse contains the list of agents.
+type ListAgentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*Installation        `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsResponse) Reset() {
+	*x = ListAgentsResponse{}
+	mi := &file_agentmgr_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsResponse) ProtoMessage() {}
+
+func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListAgentsResponse) GetAgents() []*Installation {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *ListAgentsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// GetAgentRequest requests a specific agent.
+type GetAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentRequest) Reset() {
+	*x = GetAgentRequest{}
+	mi := &file_agentmgr_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentRequest) ProtoMessage() {}
+
+func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetAgentRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// GetAgentResponse contains the agent details.
+type GetAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agent         *Installation          `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentResponse) Reset() {
+	*x = GetAgentResponse{}
+	mi := &file_agentmgr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentResponse) ProtoMessage() {}
+
+func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetAgentResponse) GetAgent() *Installation {
+	if x != nil {
+		return x.Agent
+	}
+	return nil
+}
+
+// InstallAgentRequest requests agent installation.
+type InstallAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Global        bool                   `protobuf:"varint,3,opt,name=global,proto3" json:"global,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallAgentRequest) Reset() {
+	*x = InstallAgentRequest{}
+	mi := &file_agentmgr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallAgentRequest) ProtoMessage() {}
+
+func (x *InstallAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallAgentRequest.ProtoReflect.Descriptor instead.
+func (*InstallAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *InstallAgentRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InstallAgentRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *InstallAgentRequest) GetGlobal() bool {
+	if x != nil {
+		return x.Global
+	}
+	return false
+}
+
+// InstallAgentResponse contains the installation result.
+type InstallAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Installation  *Installation          `protobuf:"bytes,1,opt,name=installation,proto3" json:"installation,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallAgentResponse) Reset() {
+	*x = InstallAgentResponse{}
+	mi := &file_agentmgr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallAgentResponse) ProtoMessage() {}
+
+func (x *InstallAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallAgentResponse.ProtoReflect.Descriptor instead.
+func (*InstallAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *InstallAgentResponse) GetInstallation() *Installation {
+	if x != nil {
+		return x.Installation
+	}
+	return nil
+}
+
+func (x *InstallAgentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *InstallAgentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// UpdateAgentRequest requests an agent update.
+type UpdateAgentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Update even if the agent is pinned to a version.
+	Force         bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAgentRequest) Reset() {
+	*x = UpdateAgentRequest{}
+	mi := &file_agentmgr_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAgentRequest) ProtoMessage() {}
+
+func (x *UpdateAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAgentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateAgentRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UpdateAgentRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+// UpdateAgentResponse contains the update result.
+type UpdateAgentResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Installation *Installation          `protobuf:"bytes,1,opt,name=installation,proto3" json:"installation,omitempty"`
+	FromVersion  string                 `protobuf:"bytes,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion    string                 `protobuf:"bytes,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	Success      bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	Message      string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	// The update failed verification and the previous version was
+	// reinstalled.
+	RolledBack    bool `protobuf:"varint,6,opt,name=rolled_back,json=rolledBack,proto3" json:"rolled_back,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAgentResponse) Reset() {
+	*x = UpdateAgentResponse{}
+	mi := &file_agentmgr_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAgentResponse) ProtoMessage() {}
+
+func (x *UpdateAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAgentResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateAgentResponse) GetInstallation() *Installation {
+	if x != nil {
+		return x.Installation
+	}
+	return nil
+}
+
+func (x *UpdateAgentResponse) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *UpdateAgentResponse) GetToVersion() string {
+	if x != nil {
+		return x.ToVersion
+	}
+	return ""
+}
+
+func (x *UpdateAgentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateAgentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateAgentResponse) GetRolledBack() bool {
+	if x != nil {
+		return x.RolledBack
+	}
+	return false
+}
+
+// UninstallAgentRequest requests agent uninstallation.
+type UninstallAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UninstallAgentRequest) Reset() {
+	*x = UninstallAgentRequest{}
+	mi := &file_agentmgr_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UninstallAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UninstallAgentRequest) ProtoMessage() {}
+
+func (x *UninstallAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UninstallAgentRequest.ProtoReflect.Descriptor instead.
+func (*UninstallAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UninstallAgentRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// UninstallAgentResponse contains the uninstallation result.
+type UninstallAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UninstallAgentResponse) Reset() {
+	*x = UninstallAgentResponse{}
+	mi := &file_agentmgr_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UninstallAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UninstallAgentResponse) ProtoMessage() {}
+
+func (x *UninstallAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UninstallAgentResponse.ProtoReflect.Descriptor instead.
+func (*UninstallAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UninstallAgentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UninstallAgentResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// ListCatalogRequest requests the catalog list.
+type ListCatalogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Platform      string                 `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogRequest) Reset() {
+	*x = ListCatalogRequest{}
+	mi := &file_agentmgr_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogRequest) ProtoMessage() {}
+
+func (x *ListCatalogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogRequest.ProtoReflect.Descriptor instead.
+func (*ListCatalogRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListCatalogRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+// ListCatalogResponse contains the catalog list.
+type ListCatalogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*CatalogAgent        `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogResponse) Reset() {
+	*x = ListCatalogResponse{}
+	mi := &file_agentmgr_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogResponse) ProtoMessage() {}
+
+func (x *ListCatalogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogResponse.ProtoReflect.Descriptor instead.
+func (*ListCatalogResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListCatalogResponse) GetAgents() []*CatalogAgent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *ListCatalogResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// GetCatalogAgentRequest requests a catalog agent.
+type GetCatalogAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCatalogAgentRequest) Reset() {
+	*x = GetCatalogAgentRequest{}
+	mi := &file_agentmgr_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCatalogAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCatalogAgentRequest) ProtoMessage() {}
+
+func (x *GetCatalogAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCatalogAgentRequest.ProtoReflect.Descriptor instead.
+func (*GetCatalogAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetCatalogAgentRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// GetCatalogAgentResponse contains the catalog agent.
+type GetCatalogAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agent         *CatalogAgent          `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCatalogAgentResponse) Reset() {
+	*x = GetCatalogAgentResponse{}
+	mi := &file_agentmgr_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCatalogAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCatalogAgentResponse) ProtoMessage() {}
+
+func (x *GetCatalogAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCatalogAgentResponse.ProtoReflect.Descriptor instead.
+func (*GetCatalogAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetCatalogAgentResponse) GetAgent() *CatalogAgent {
+	if x != nil {
+		return x.Agent
+	}
+	return nil
+}
+
+// RefreshCatalogResponse contains the refresh result.
+type RefreshCatalogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	AgentCount    int32                  `protobuf:"varint,3,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
+	Updated       bool                   `protobuf:"varint,4,opt,name=updated,proto3" json:"updated,omitempty"`
+	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshCatalogResponse) Reset() {
+	*x = RefreshCatalogResponse{}
+	mi := &file_agentmgr_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshCatalogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshCatalogResponse) ProtoMessage() {}
+
+func (x *RefreshCatalogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshCatalogResponse.ProtoReflect.Descriptor instead.
+func (*RefreshCatalogResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RefreshCatalogResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RefreshCatalogResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RefreshCatalogResponse) GetAgentCount() int32 {
+	if x != nil {
+		return x.AgentCount
+	}
+	return 0
+}
+
+func (x *RefreshCatalogResponse) GetUpdated() bool {
+	if x != nil {
+		return x.Updated
+	}
+	return false
+}
+
+func (x *RefreshCatalogResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// SearchCatalogRequest requests a catalog search.
+type SearchCatalogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Platform      string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchCatalogRequest) Reset() {
+	*x = SearchCatalogRequest{}
+	mi := &file_agentmgr_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchCatalogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchCatalogRequest) ProtoMessage() {}
+
+func (x *SearchCatalogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchCatalogRequest.ProtoReflect.Descriptor instead.
+func (*SearchCatalogRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchCatalogRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchCatalogRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+// SearchCatalogResponse contains the search results.
+type SearchCatalogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*CatalogAgent        `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchCatalogResponse) Reset() {
+	*x = SearchCatalogResponse{}
+	mi := &file_agentmgr_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchCatalogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchCatalogResponse) ProtoMessage() {}
+
+func (x *SearchCatalogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchCatalogResponse.ProtoReflect.Descriptor instead.
+func (*SearchCatalogResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchCatalogResponse) GetAgents() []*CatalogAgent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *SearchCatalogResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// CheckUpdatesResponse contains update check results.
+type CheckUpdatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updates       []*UpdateInfo          `protobuf:"bytes,1,rep,name=updates,proto3" json:"updates,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckUpdatesResponse) Reset() {
+	*x = CheckUpdatesResponse{}
+	mi := &file_agentmgr_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUpdatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUpdatesResponse) ProtoMessage() {}
+
+func (x *CheckUpdatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckUpdatesResponse.ProtoReflect.Descriptor instead.
+func (*CheckUpdatesResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CheckUpdatesResponse) GetUpdates() []*UpdateInfo {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+func (x *CheckUpdatesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// UpdateInfo contains information about an available update.
+type UpdateInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Installation  *Installation          `protobuf:"bytes,1,opt,name=installation,proto3" json:"installation,omitempty"`
+	FromVersion   string                 `protobuf:"bytes,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     string                 `protobuf:"bytes,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateInfo) Reset() {
+	*x = UpdateInfo{}
+	mi := &file_agentmgr_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateInfo) ProtoMessage() {}
+
+func (x *UpdateInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateInfo.ProtoReflect.Descriptor instead.
+func (*UpdateInfo) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UpdateInfo) GetInstallation() *Installation {
+	if x != nil {
+		return x.Installation
+	}
+	return nil
+}
+
+func (x *UpdateInfo) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *UpdateInfo) GetToVersion() string {
+	if x != nil {
+		return x.ToVersion
+	}
+	return ""
+}
+
+// GetChangelogRequest requests a changelog.
+type GetChangelogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	FromVersion   string                 `protobuf:"bytes,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     string                 `protobuf:"bytes,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChangelogRequest) Reset() {
+	*x = GetChangelogRequest{}
+	mi := &file_agentmgr_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangelogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangelogRequest) ProtoMessage() {}
+
+func (x *GetChangelogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChangelogRequest.ProtoReflect.Descriptor instead.
+func (*GetChangelogRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetChangelogRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetChangelogRequest) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *GetChangelogRequest) GetToVersion() string {
+	if x != nil {
+		return x.ToVersion
+	}
+	return ""
+}
+
+// GetChangelogResponse contains the changelog.
+type GetChangelogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changelog     string                 `protobuf:"bytes,1,opt,name=changelog,proto3" json:"changelog,omitempty"`
+	Releases      []*Release             `protobuf:"bytes,2,rep,name=releases,proto3" json:"releases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChangelogResponse) Reset() {
+	*x = GetChangelogResponse{}
+	mi := &file_agentmgr_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangelogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangelogResponse) ProtoMessage() {}
+
+func (x *GetChangelogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChangelogResponse.ProtoReflect.Descriptor instead.
+func (*GetChangelogResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetChangelogResponse) GetChangelog() string {
+	if x != nil {
+		return x.Changelog
+	}
+	return ""
+}
+
+func (x *GetChangelogResponse) GetReleases() []*Release {
+	if x != nil {
+		return x.Releases
+	}
+	return nil
+}
+
+// Release represents a single release.
+type Release struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Highlights    []string               `protobuf:"bytes,4,rep,name=highlights,proto3" json:"highlights,omitempty"`
+	PublishedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	Url           string                 `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Release) Reset() {
+	*x = Release{}
+	mi := &file_agentmgr_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Release) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Release) ProtoMessage() {}
+
+func (x *Release) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Release.ProtoReflect.Descriptor instead.
+func (*Release) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Release) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Release) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Release) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Release) GetHighlights() []string {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+func (x *Release) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Release) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// GetHistoryRequest requests recorded install/update/uninstall events.
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // empty for every agent
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_agentmgr_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetHistoryRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// GetHistoryResponse contains history events, newest first.
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*HistoryEvent        `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_agentmgr_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetHistoryResponse) GetEvents() []*HistoryEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// HistoryEvent is one recorded install, update, rollback or uninstall.
+type HistoryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentName     string                 `protobuf:"bytes,3,opt,name=agent_name,json=agentName,proto3" json:"agent_name,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"` // "install", "update", "rollback", "uninstall"
+	Method        string                 `protobuf:"bytes,5,opt,name=method,proto3" json:"method,omitempty"`
+	FromVersion   string                 `protobuf:"bytes,6,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     string                 `protobuf:"bytes,7,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // "pending", "running", "completed", "failed", "cancelled"
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryEvent) Reset() {
+	*x = HistoryEvent{}
+	mi := &file_agentmgr_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryEvent) ProtoMessage() {}
+
+func (x *HistoryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryEvent.ProtoReflect.Descriptor instead.
+func (*HistoryEvent) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HistoryEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HistoryEvent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetAgentName() string {
+	if x != nil {
+		return x.AgentName
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetToVersion() string {
+	if x != nil {
+		return x.ToVersion
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *HistoryEvent) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+// StatusResponse contains the service status.
+type StatusResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Running            bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	UptimeSeconds      int64                  `protobuf:"varint,2,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	AgentCount         int32                  `protobuf:"varint,3,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
+	UpdatesAvailable   int32                  `protobuf:"varint,4,opt,name=updates_available,json=updatesAvailable,proto3" json:"updates_available,omitempty"`
+	LastCatalogRefresh *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_catalog_refresh,json=lastCatalogRefresh,proto3" json:"last_catalog_refresh,omitempty"`
+	LastUpdateCheck    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_update_check,json=lastUpdateCheck,proto3" json:"last_update_check,omitempty"`
+	Version            string                 `protobuf:"bytes,7,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_agentmgr_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StatusResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *StatusResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetAgentCount() int32 {
+	if x != nil {
+		return x.AgentCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetUpdatesAvailable() int32 {
+	if x != nil {
+		return x.UpdatesAvailable
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetLastCatalogRefresh() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCatalogRefresh
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetLastUpdateCheck() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdateCheck
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// AgentEvent represents an agent-related event.
+type AgentEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "added", "updated", "removed", "update_available"
+	Installation  *Installation          `protobuf:"bytes,2,opt,name=installation,proto3" json:"installation,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentEvent) Reset() {
+	*x = AgentEvent{}
+	mi := &file_agentmgr_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentEvent) ProtoMessage() {}
+
+func (x *AgentEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agentmgr_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentEvent.ProtoReflect.Descriptor instead.
+func (*AgentEvent) Descriptor() ([]byte, []int) {
+	return file_agentmgr_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AgentEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetInstallation() *Installation {
+	if x != nil {
+		return x.Installation
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_agentmgr_proto protoreflect.FileDescriptor
+
+const file_agentmgr_proto_rawDesc = "" +
+	"\n" +
+	"\x0eagentmgr.proto\x12\vagentmgr.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x9a\x05\n" +
+	"\fInstallation\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"agent_name\x18\x03 \x01(\tR\tagentName\x12%\n" +
+	"\x0einstall_method\x18\x04 \x01(\tR\rinstallMethod\x12+\n" +
+	"\x11installed_version\x18\x05 \x01(\tR\x10installedVersion\x12%\n" +
+	"\x0elatest_version\x18\x06 \x01(\tR\rlatestVersion\x12'\n" +
+	"\x0fexecutable_path\x18\a \x01(\tR\x0eexecutablePath\x12!\n" +
+	"\finstall_path\x18\b \x01(\tR\vinstallPath\x12\x1b\n" +
+	"\tis_global\x18\t \x01(\bR\bisGlobal\x12;\n" +
+	"\vdetected_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"detectedAt\x12=\n" +
+	"\flast_checked\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vlastChecked\x12C\n" +
+	"\bmetadata\x18\f \x03(\v2'.agentmgr.v1.Installation.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"has_update\x18\r \x01(\bR\thasUpdate\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12%\n" +
+	"\x0epinned_version\x18\x0f \x01(\tR\rpinnedVersion\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd8\x01\n" +
+	"\fCatalogAgent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bhomepage\x18\x04 \x01(\tR\bhomepage\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x05 \x01(\tR\n" +
+	"repository\x12F\n" +
+	"\x0finstall_methods\x18\x06 \x03(\v2\x1d.agentmgr.v1.InstallMethodDefR\x0einstallMethods\"|\n" +
+	"\x10InstallMethodDef\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x18\n" +
+	"\apackage\x18\x02 \x01(\tR\apackage\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\x12\x1c\n" +
+	"\tplatforms\x18\x04 \x03(\tR\tplatforms\"\xbd\x01\n" +
+	"\vAgentFilter\x12\x1b\n" +
+	"\tagent_ids\x18\x01 \x03(\tR\bagentIds\x12\x18\n" +
+	"\amethods\x18\x02 \x03(\tR\amethods\x12\"\n" +
+	"\n" +
+	"has_update\x18\x03 \x01(\bH\x00R\thasUpdate\x88\x01\x01\x12 \n" +
+	"\tis_global\x18\x04 \x01(\bH\x01R\bisGlobal\x88\x01\x01\x12\x14\n" +
+	"\x05query\x18\x05 \x01(\tR\x05queryB\r\n" +
+	"\v_has_updateB\f\n" +
+	"\n" +
+	"_is_global\"\xab\x01\n" +
+	"\x11ListAgentsRequest\x120\n" +
+	"\x06filter\x18\x01 \x01(\v2\x18.agentmgr.v1.AgentFilterR\x06filter\x12\x17\n" +
+	"\asort_by\x18\x02 \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\x03 \x01(\tR\tsortOrder\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x05R\x06offset\"]\n" +
+	"\x12ListAgentsResponse\x121\n" +
+	"\x06agents\x18\x01 \x03(\v2\x19.agentmgr.v1.InstallationR\x06agents\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"#\n" +
+	"\x0fGetAgentRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"C\n" +
+	"\x10GetAgentResponse\x12/\n" +
+	"\x05agent\x18\x01 \x01(\v2\x19.agentmgr.v1.InstallationR\x05agent\"`\n" +
+	"\x13InstallAgentRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x16\n" +
+	"\x06global\x18\x03 \x01(\bR\x06global\"\x89\x01\n" +
+	"\x14InstallAgentResponse\x12=\n" +
+	"\finstallation\x18\x01 \x01(\v2\x19.agentmgr.v1.InstallationR\finstallation\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"<\n" +
+	"\x12UpdateAgentRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"\xeb\x01\n" +
+	"\x13UpdateAgentResponse\x12=\n" +
+	"\finstallation\x18\x01 \x01(\v2\x19.agentmgr.v1.InstallationR\finstallation\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\tR\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\tR\ttoVersion\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x1f\n" +
+	"\vrolled_back\x18\x06 \x01(\bR\n" +
+	"rolledBack\")\n" +
+	"\x15UninstallAgentRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"L\n" +
+	"\x16UninstallAgentResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"0\n" +
+	"\x12ListCatalogRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\"^\n" +
+	"\x13ListCatalogResponse\x121\n" +
+	"\x06agents\x18\x01 \x03(\v2\x19.agentmgr.v1.CatalogAgentR\x06agents\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"3\n" +
+	"\x16GetCatalogAgentRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"J\n" +
+	"\x17GetCatalogAgentResponse\x12/\n" +
+	"\x05agent\x18\x01 \x01(\v2\x19.agentmgr.v1.CatalogAgentR\x05agent\"\xa1\x01\n" +
+	"\x16RefreshCatalogResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vagent_count\x18\x03 \x01(\x05R\n" +
+	"agentCount\x12\x18\n" +
+	"\aupdated\x18\x04 \x01(\bR\aupdated\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\"H\n" +
+	"\x14SearchCatalogRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\"`\n" +
+	"\x15SearchCatalogResponse\x121\n" +
+	"\x06agents\x18\x01 \x03(\v2\x19.agentmgr.v1.CatalogAgentR\x06agents\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"_\n" +
+	"\x14CheckUpdatesResponse\x121\n" +
+	"\aupdates\x18\x01 \x03(\v2\x17.agentmgr.v1.UpdateInfoR\aupdates\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\x8d\x01\n" +
+	"\n" +
+	"UpdateInfo\x12=\n" +
+	"\finstallation\x18\x01 \x01(\v2\x19.agentmgr.v1.InstallationR\finstallation\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\tR\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\tR\ttoVersion\"r\n" +
+	"\x13GetChangelogRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\tR\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\tR\ttoVersion\"f\n" +
+	"\x14GetChangelogResponse\x12\x1c\n" +
+	"\tchangelog\x18\x01 \x01(\tR\tchangelog\x120\n" +
+	"\breleases\x18\x02 \x03(\v2\x14.agentmgr.v1.ReleaseR\breleases\"\xbe\x01\n" +
+	"\aRelease\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x12\x1e\n" +
+	"\n" +
+	"highlights\x18\x04 \x03(\tR\n" +
+	"highlights\x12=\n" +
+	"\fpublished_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\"D\n" +
+	"\x11GetHistoryRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"]\n" +
+	"\x12GetHistoryResponse\x121\n" +
+	"\x06events\x18\x01 \x03(\v2\x19.agentmgr.v1.HistoryEventR\x06events\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xf2\x02\n" +
+	"\fHistoryEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"agent_name\x18\x03 \x01(\tR\tagentName\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06method\x18\x05 \x01(\tR\x06method\x12!\n" +
+	"\ffrom_version\x18\x06 \x01(\tR\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\a \x01(\tR\ttoVersion\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"started_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\xcf\x02\n" +
+	"\x0eStatusResponse\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12%\n" +
+	"\x0euptime_seconds\x18\x02 \x01(\x03R\ruptimeSeconds\x12\x1f\n" +
+	"\vagent_count\x18\x03 \x01(\x05R\n" +
+	"agentCount\x12+\n" +
+	"\x11updates_available\x18\x04 \x01(\x05R\x10updatesAvailable\x12L\n" +
+	"\x14last_catalog_refresh\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x12lastCatalogRefresh\x12F\n" +
+	"\x11last_update_check\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0flastUpdateCheck\x12\x18\n" +
+	"\aversion\x18\a \x01(\tR\aversion\"\x99\x01\n" +
+	"\n" +
+	"AgentEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12=\n" +
+	"\finstallation\x18\x02 \x01(\v2\x19.agentmgr.v1.InstallationR\finstallation\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp2\xf9\b\n" +
+	"\x13AgentManagerService\x12M\n" +
+	"\n" +
+	"ListAgents\x12\x1e.agentmgr.v1.ListAgentsRequest\x1a\x1f.agentmgr.v1.ListAgentsResponse\x12G\n" +
+	"\bGetAgent\x12\x1c.agentmgr.v1.GetAgentRequest\x1a\x1d.agentmgr.v1.GetAgentResponse\x12S\n" +
+	"\fInstallAgent\x12 .agentmgr.v1.InstallAgentRequest\x1a!.agentmgr.v1.InstallAgentResponse\x12P\n" +
+	"\vUpdateAgent\x12\x1f.agentmgr.v1.UpdateAgentRequest\x1a .agentmgr.v1.UpdateAgentResponse\x12Y\n" +
+	"\x0eUninstallAgent\x12\".agentmgr.v1.UninstallAgentRequest\x1a#.agentmgr.v1.UninstallAgentResponse\x12P\n" +
+	"\vListCatalog\x12\x1f.agentmgr.v1.ListCatalogRequest\x1a .agentmgr.v1.ListCatalogResponse\x12\\\n" +
+	"\x0fGetCatalogAgent\x12#.agentmgr.v1.GetCatalogAgentRequest\x1a$.agentmgr.v1.GetCatalogAgentResponse\x12M\n" +
+	"\x0eRefreshCatalog\x12\x16.google.protobuf.Empty\x1a#.agentmgr.v1.RefreshCatalogResponse\x12V\n" +
+	"\rSearchCatalog\x12!.agentmgr.v1.SearchCatalogRequest\x1a\".agentmgr.v1.SearchCatalogResponse\x12I\n" +
+	"\fCheckUpdates\x12\x16.google.protobuf.Empty\x1a!.agentmgr.v1.CheckUpdatesResponse\x12S\n" +
+	"\fGetChangelog\x12 .agentmgr.v1.GetChangelogRequest\x1a!.agentmgr.v1.GetChangelogResponse\x12M\n" +
+	"\n" +
+	"GetHistory\x12\x1e.agentmgr.v1.GetHistoryRequest\x1a\x1f.agentmgr.v1.GetHistoryResponse\x12@\n" +
+	"\tGetStatus\x12\x16.google.protobuf.Empty\x1a\x1b.agentmgr.v1.StatusResponse\x12@\n" +
+	"\vWatchAgents\x12\x16.google.protobuf.Empty\x1a\x17.agentmgr.v1.AgentEvent0\x01B6Z4github.com/kevinelliott/agentmanager/pkg/api/grpc/pbb\x06proto3"
+
+var (
+	file_agentmgr_proto_rawDescOnce sync.Once
+	file_agentmgr_proto_rawDescData []byte
+)
+
+func file_agentmgr_proto_rawDescGZIP() []byte {
+	file_agentmgr_proto_rawDescOnce.Do(func() {
+		file_agentmgr_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agentmgr_proto_rawDesc), len(file_agentmgr_proto_rawDesc)))
+	})
+	return file_agentmgr_proto_rawDescData
+}
+
+var file_agentmgr_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
+var file_agentmgr_proto_goTypes = []any{
+	(*Installation)(nil),            // 0: agentmgr.v1.Installation
+	(*CatalogAgent)(nil),            // 1: agentmgr.v1.CatalogAgent
+	(*InstallMethodDef)(nil),        // 2: agentmgr.v1.InstallMethodDef
+	(*AgentFilter)(nil),             // 3: agentmgr.v1.AgentFilter
+	(*ListAgentsRequest)(nil),       // 4: agentmgr.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),      // 5: agentmgr.v1.ListAgentsResponse
+	(*GetAgentRequest)(nil),         // 6: agentmgr.v1.GetAgentRequest
+	(*GetAgentResponse)(nil),        // 7: agentmgr.v1.GetAgentResponse
+	(*InstallAgentRequest)(nil),     // 8: agentmgr.v1.InstallAgentRequest
+	(*InstallAgentResponse)(nil),    // 9: agentmgr.v1.InstallAgentResponse
+	(*UpdateAgentRequest)(nil),      // 10: agentmgr.v1.UpdateAgentRequest
+	(*UpdateAgentResponse)(nil),     // 11: agentmgr.v1.UpdateAgentResponse
+	(*UninstallAgentRequest)(nil),   // 12: agentmgr.v1.UninstallAgentRequest
+	(*UninstallAgentResponse)(nil),  // 13: agentmgr.v1.UninstallAgentResponse
+	(*ListCatalogRequest)(nil),      // 14: agentmgr.v1.ListCatalogRequest
+	(*ListCatalogResponse)(nil),     // 15: agentmgr.v1.ListCatalogResponse
+	(*GetCatalogAgentRequest)(nil),  // 16: agentmgr.v1.GetCatalogAgentRequest
+	(*GetCatalogAgentResponse)(nil), // 17: agentmgr.v1.GetCatalogAgentResponse
+	(*RefreshCatalogResponse)(nil),  // 18: agentmgr.v1.RefreshCatalogResponse
+	(*SearchCatalogRequest)(nil),    // 19: agentmgr.v1.SearchCatalogRequest
+	(*SearchCatalogResponse)(nil),   // 20: agentmgr.v1.SearchCatalogResponse
+	(*CheckUpdatesResponse)(nil),    // 21: agentmgr.v1.CheckUpdatesResponse
+	(*UpdateInfo)(nil),              // 22: agentmgr.v1.UpdateInfo
+	(*GetChangelogRequest)(nil),     // 23: agentmgr.v1.GetChangelogRequest
+	(*GetChangelogResponse)(nil),    // 24: agentmgr.v1.GetChangelogResponse
+	(*Release)(nil),                 // 25: agentmgr.v1.Release
+	(*GetHistoryRequest)(nil),       // 26: agentmgr.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),      // 27: agentmgr.v1.GetHistoryResponse
+	(*HistoryEvent)(nil),            // 28: agentmgr.v1.HistoryEvent
+	(*StatusResponse)(nil),          // 29: agentmgr.v1.StatusResponse
+	(*AgentEvent)(nil),              // 30: agentmgr.v1.AgentEvent
+	nil,                             // 31: agentmgr.v1.Installation.MetadataEntry
+	(*timestamppb.Timestamp)(nil),   // 32: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),           // 33: google.protobuf.Empty
+}
+var file_agentmgr_proto_depIdxs = []int32{
+	32, // 0: agentmgr.v1.Installation.detected_at:type_name -> google.protobuf.Timestamp
+	32, // 1: agentmgr.v1.Installation.last_checked:type_name -> google.protobuf.Timestamp
+	31, // 2: agentmgr.v1.Installation.metadata:type_name -> agentmgr.v1.Installation.MetadataEntry
+	2,  // 3: agentmgr.v1.CatalogAgent.install_methods:type_name -> agentmgr.v1.InstallMethodDef
+	3,  // 4: agentmgr.v1.ListAgentsRequest.filter:type_name -> agentmgr.v1.AgentFilter
+	0,  // 5: agentmgr.v1.ListAgentsResponse.agents:type_name -> agentmgr.v1.Installation
+	0,  // 6: agentmgr.v1.GetAgentResponse.agent:type_name -> agentmgr.v1.Installation
+	0,  // 7: agentmgr.v1.InstallAgentResponse.installation:type_name -> agentmgr.v1.Installation
+	0,  // 8: agentmgr.v1.UpdateAgentResponse.installation:type_name -> agentmgr.v1.Installation
+	1,  // 9: agentmgr.v1.ListCatalogResponse.agents:type_name -> agentmgr.v1.CatalogAgent
+	1,  // 10: agentmgr.v1.GetCatalogAgentResponse.agent:type_name -> agentmgr.v1.CatalogAgent
+	1,  // 11: agentmgr.v1.SearchCatalogResponse.agents:type_name -> agentmgr.v1.CatalogAgent
+	22, // 12: agentmgr.v1.CheckUpdatesResponse.updates:type_name -> agentmgr.v1.UpdateInfo
+	0,  // 13: agentmgr.v1.UpdateInfo.installation:type_name -> agentmgr.v1.Installation
+	25, // 14: agentmgr.v1.GetChangelogResponse.releases:type_name -> agentmgr.v1.Release
+	32, // 15: agentmgr.v1.Release.published_at:type_name -> google.protobuf.Timestamp
+	28, // 16: agentmgr.v1.GetHistoryResponse.events:type_name -> agentmgr.v1.HistoryEvent
+	32, // 17: agentmgr.v1.HistoryEvent.started_at:type_name -> google.protobuf.Timestamp
+	32, // 18: agentmgr.v1.HistoryEvent.completed_at:type_name -> google.protobuf.Timestamp
+	32, // 19: agentmgr.v1.StatusResponse.last_catalog_refresh:type_name -> google.protobuf.Timestamp
+	32, // 20: agentmgr.v1.StatusResponse.last_update_check:type_name -> google.protobuf.Timestamp
+	0,  // 21: agentmgr.v1.AgentEvent.installation:type_name -> agentmgr.v1.Installation
+	32, // 22: agentmgr.v1.AgentEvent.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 23: agentmgr.v1.AgentManagerService.ListAgents:input_type -> agentmgr.v1.ListAgentsRequest
+	6,  // 24: agentmgr.v1.AgentManagerService.GetAgent:input_type -> agentmgr.v1.GetAgentRequest
+	8,  // 25: agentmgr.v1.AgentManagerService.InstallAgent:input_type -> agentmgr.v1.InstallAgentRequest
+	10, // 26: agentmgr.v1.AgentManagerService.UpdateAgent:input_type -> agentmgr.v1.UpdateAgentRequest
+	12, // 27: agentmgr.v1.AgentManagerService.UninstallAgent:input_type -> agentmgr.v1.UninstallAgentRequest
+	14, // 28: agentmgr.v1.AgentManagerService.ListCatalog:input_type -> agentmgr.v1.ListCatalogRequest
+	16, // 29: agentmgr.v1.AgentManagerService.GetCatalogAgent:input_type -> agentmgr.v1.GetCatalogAgentRequest
+	33, // 30: agentmgr.v1.AgentManagerService.RefreshCatalog:input_type -> google.protobuf.Empty
+	19, // 31: agentmgr.v1.AgentManagerService.SearchCatalog:input_type -> agentmgr.v1.SearchCatalogRequest
+	33, // 32: agentmgr.v1.AgentManagerService.CheckUpdates:input_type -> google.protobuf.Empty
+	23, // 33: agentmgr.v1.AgentManagerService.GetChangelog:input_type -> agentmgr.v1.GetChangelogRequest
+	26, // 34: agentmgr.v1.AgentManagerService.GetHistory:input_type -> agentmgr.v1.GetHistoryRequest
+	33, // 35: agentmgr.v1.AgentManagerService.GetStatus:input_type -> google.protobuf.Empty
+	33, // 36: agentmgr.v1.AgentManagerService.WatchAgents:input_type -> google.protobuf.Empty
+	5,  // 37: agentmgr.v1.AgentManagerService.ListAgents:output_type -> agentmgr.v1.ListAgentsResponse
+	7,  // 38: agentmgr.v1.AgentManagerService.GetAgent:output_type -> agentmgr.v1.GetAgentResponse
+	9,  // 39: agentmgr.v1.AgentManagerService.InstallAgent:output_type -> agentmgr.v1.InstallAgentResponse
+	11, // 40: agentmgr.v1.AgentManagerService.UpdateAgent:output_type -> agentmgr.v1.UpdateAgentResponse
+	13, // 41: agentmgr.v1.AgentManagerService.UninstallAgent:output_type -> agentmgr.v1.UninstallAgentResponse
+	15, // 42: agentmgr.v1.AgentManagerService.ListCatalog:output_type -> agentmgr.v1.ListCatalogResponse
+	17, // 43: agentmgr.v1.AgentManagerService.GetCatalogAgent:output_type -> agentmgr.v1.GetCatalogAgentResponse
+	18, // 44: agentmgr.v1.AgentManagerService.RefreshCatalog:output_type -> agentmgr.v1.RefreshCatalogResponse
+	20, // 45: agentmgr.v1.AgentManagerService.SearchCatalog:output_type -> agentmgr.v1.SearchCatalogResponse
+	21, // 46: agentmgr.v1.AgentManagerService.CheckUpdates:output_type -> agentmgr.v1.CheckUpdatesResponse
+	24, // 47: agentmgr.v1.AgentManagerService.GetChangelog:output_type -> agentmgr.v1.GetChangelogResponse
+	27, // 48: agentmgr.v1.AgentManagerService.GetHistory:output_type -> agentmgr.v1.GetHistoryResponse
+	29, // 49: agentmgr.v1.AgentManagerService.GetStatus:output_type -> agentmgr.v1.StatusResponse
+	30, // 50: agentmgr.v1.AgentManagerService.WatchAgents:output_type -> agentmgr.v1.AgentEvent
+	37, // [37:51] is the sub-list for method output_type
+	23, // [23:37] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_agentmgr_proto_init() }
+func file_agentmgr_proto_init() {
+	if File_agentmgr_proto != nil {
+		return
+	}
+	file_agentmgr_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agentmgr_proto_rawDesc), len(file_agentmgr_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   32,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agentmgr_proto_goTypes,
+		DependencyIndexes: file_agentmgr_proto_depIdxs,
+		MessageInfos:      file_agentmgr_proto_msgTypes,
+	}.Build()
+	File_agentmgr_proto = out.File
+	file_agentmgr_proto_goTypes = nil
+	file_agentmgr_proto_depIdxs = nil
+}
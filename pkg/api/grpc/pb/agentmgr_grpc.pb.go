@@ -0,0 +1,636 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: agentmgr.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AgentManagerService_ListAgents_FullMethodName      = "/agentmgr.v1.AgentManagerService/ListAgents"
+	AgentManagerService_GetAgent_FullMethodName        = "/agentmgr.v1.AgentManagerService/GetAgent"
+	AgentManagerService_InstallAgent_FullMethodName    = "/agentmgr.v1.AgentManagerService/InstallAgent"
+	AgentManagerService_UpdateAgent_FullMethodName     = "/agentmgr.v1.AgentManagerService/UpdateAgent"
+	AgentManagerService_UninstallAgent_FullMethodName  = "/agentmgr.v1.AgentManagerService/UninstallAgent"
+	AgentManagerService_ListCatalog_FullMethodName     = "/agentmgr.v1.AgentManagerService/ListCatalog"
+	AgentManagerService_GetCatalogAgent_FullMethodName = "/agentmgr.v1.AgentManagerService/GetCatalogAgent"
+	AgentManagerService_RefreshCatalog_FullMethodName  = "/agentmgr.v1.AgentManagerService/RefreshCatalog"
+	AgentManagerService_SearchCatalog_FullMethodName   = "/agentmgr.v1.AgentManagerService/SearchCatalog"
+	AgentManagerService_CheckUpdates_FullMethodName    = "/agentmgr.v1.AgentManagerService/CheckUpdates"
+	AgentManagerService_GetChangelog_FullMethodName    = "/agentmgr.v1.AgentManagerService/GetChangelog"
+	AgentManagerService_GetHistory_FullMethodName      = "/agentmgr.v1.AgentManagerService/GetHistory"
+	AgentManagerService_GetStatus_FullMethodName       = "/agentmgr.v1.AgentManagerService/GetStatus"
+	AgentManagerService_WatchAgents_FullMethodName     = "/agentmgr.v1.AgentManagerService/WatchAgents"
+)
+
+// AgentManagerServiceClient is the client API for AgentManagerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AgentManagerService provides agent management capabilities.
+type AgentManagerServiceClient interface {
+	// Agent operations
+	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
+	GetAgent(ctx context.Context, in *GetAgentRequest, opts ...grpc.CallOption) (*GetAgentResponse, error)
+	InstallAgent(ctx context.Context, in *InstallAgentRequest, opts ...grpc.CallOption) (*InstallAgentResponse, error)
+	UpdateAgent(ctx context.Context, in *UpdateAgentRequest, opts ...grpc.CallOption) (*UpdateAgentResponse, error)
+	UninstallAgent(ctx context.Context, in *UninstallAgentRequest, opts ...grpc.CallOption) (*UninstallAgentResponse, error)
+	// Catalog operations
+	ListCatalog(ctx context.Context, in *ListCatalogRequest, opts ...grpc.CallOption) (*ListCatalogResponse, error)
+	GetCatalogAgent(ctx context.Context, in *GetCatalogAgentRequest, opts ...grpc.CallOption) (*GetCatalogAgentResponse, error)
+	RefreshCatalog(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RefreshCatalogResponse, error)
+	SearchCatalog(ctx context.Context, in *SearchCatalogRequest, opts ...grpc.CallOption) (*SearchCatalogResponse, error)
+	// Update operations
+	CheckUpdates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CheckUpdatesResponse, error)
+	GetChangelog(ctx context.Context, in *GetChangelogRequest, opts ...grpc.CallOption) (*GetChangelogResponse, error)
+	// History operations
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// Status operations
+	GetStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Streaming operations
+	WatchAgents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentEvent], error)
+}
+
+type agentManagerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentManagerServiceClient(cc grpc.ClientConnInterface) AgentManagerServiceClient {
+	return &agentManagerServiceClient{cc}
+}
+
+func (c *agentManagerServiceClient) ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentsResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_ListAgents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) GetAgent(ctx context.Context, in *GetAgentRequest, opts ...grpc.CallOption) (*GetAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAgentResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_GetAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) InstallAgent(ctx context.Context, in *InstallAgentRequest, opts ...grpc.CallOption) (*InstallAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstallAgentResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_InstallAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) UpdateAgent(ctx context.Context, in *UpdateAgentRequest, opts ...grpc.CallOption) (*UpdateAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAgentResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_UpdateAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) UninstallAgent(ctx context.Context, in *UninstallAgentRequest, opts ...grpc.CallOption) (*UninstallAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UninstallAgentResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_UninstallAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) ListCatalog(ctx context.Context, in *ListCatalogRequest, opts ...grpc.CallOption) (*ListCatalogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCatalogResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_ListCatalog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) GetCatalogAgent(ctx context.Context, in *GetCatalogAgentRequest, opts ...grpc.CallOption) (*GetCatalogAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCatalogAgentResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_GetCatalogAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) RefreshCatalog(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RefreshCatalogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshCatalogResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_RefreshCatalog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) SearchCatalog(ctx context.Context, in *SearchCatalogRequest, opts ...grpc.CallOption) (*SearchCatalogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchCatalogResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_SearchCatalog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) CheckUpdates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CheckUpdatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckUpdatesResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_CheckUpdates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) GetChangelog(ctx context.Context, in *GetChangelogRequest, opts ...grpc.CallOption) (*GetChangelogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetChangelogResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_GetChangelog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) GetStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, AgentManagerService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentManagerServiceClient) WatchAgents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentManagerService_ServiceDesc.Streams[0], AgentManagerService_WatchAgents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, AgentEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentManagerService_WatchAgentsClient = grpc.ServerStreamingClient[AgentEvent]
+
+// AgentManagerServiceServer is the server API for AgentManagerService service.
+// All implementations must embed UnimplementedAgentManagerServiceServer
+// for forward compatibility.
+//
+// AgentManagerService provides agent management capabilities.
+type AgentManagerServiceServer interface {
+	// Agent operations
+	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
+	GetAgent(context.Context, *GetAgentRequest) (*GetAgentResponse, error)
+	InstallAgent(context.Context, *InstallAgentRequest) (*InstallAgentResponse, error)
+	UpdateAgent(context.Context, *UpdateAgentRequest) (*UpdateAgentResponse, error)
+	UninstallAgent(context.Context, *UninstallAgentRequest) (*UninstallAgentResponse, error)
+	// Catalog operations
+	ListCatalog(context.Context, *ListCatalogRequest) (*ListCatalogResponse, error)
+	GetCatalogAgent(context.Context, *GetCatalogAgentRequest) (*GetCatalogAgentResponse, error)
+	RefreshCatalog(context.Context, *emptypb.Empty) (*RefreshCatalogResponse, error)
+	SearchCatalog(context.Context, *SearchCatalogRequest) (*SearchCatalogResponse, error)
+	// Update operations
+	CheckUpdates(context.Context, *emptypb.Empty) (*CheckUpdatesResponse, error)
+	GetChangelog(context.Context, *GetChangelogRequest) (*GetChangelogResponse, error)
+	// History operations
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// Status operations
+	GetStatus(context.Context, *emptypb.Empty) (*StatusResponse, error)
+	// Streaming operations
+	WatchAgents(*emptypb.Empty, grpc.ServerStreamingServer[AgentEvent]) error
+	mustEmbedUnimplementedAgentManagerServiceServer()
+}
+
+// UnimplementedAgentManagerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentManagerServiceServer struct{}
+
+func (UnimplementedAgentManagerServiceServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) GetAgent(context.Context, *GetAgentRequest) (*GetAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAgent not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) InstallAgent(context.Context, *InstallAgentRequest) (*InstallAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstallAgent not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) UpdateAgent(context.Context, *UpdateAgentRequest) (*UpdateAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAgent not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) UninstallAgent(context.Context, *UninstallAgentRequest) (*UninstallAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UninstallAgent not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) ListCatalog(context.Context, *ListCatalogRequest) (*ListCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCatalog not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) GetCatalogAgent(context.Context, *GetCatalogAgentRequest) (*GetCatalogAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCatalogAgent not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) RefreshCatalog(context.Context, *emptypb.Empty) (*RefreshCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshCatalog not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) SearchCatalog(context.Context, *SearchCatalogRequest) (*SearchCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchCatalog not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) CheckUpdates(context.Context, *emptypb.Empty) (*CheckUpdatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckUpdates not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) GetChangelog(context.Context, *GetChangelogRequest) (*GetChangelogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChangelog not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) GetStatus(context.Context, *emptypb.Empty) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) WatchAgents(*emptypb.Empty, grpc.ServerStreamingServer[AgentEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAgents not implemented")
+}
+func (UnimplementedAgentManagerServiceServer) mustEmbedUnimplementedAgentManagerServiceServer() {}
+func (UnimplementedAgentManagerServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeAgentManagerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentManagerServiceServer will
+// result in compilation errors.
+type UnsafeAgentManagerServiceServer interface {
+	mustEmbedUnimplementedAgentManagerServiceServer()
+}
+
+func RegisterAgentManagerServiceServer(s grpc.ServiceRegistrar, srv AgentManagerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAgentManagerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AgentManagerService_ServiceDesc, srv)
+}
+
+func _AgentManagerService_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_ListAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).ListAgents(ctx, req.(*ListAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_GetAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).GetAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_GetAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).GetAgent(ctx, req.(*GetAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_InstallAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).InstallAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_InstallAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).InstallAgent(ctx, req.(*InstallAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_UpdateAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).UpdateAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_UpdateAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).UpdateAgent(ctx, req.(*UpdateAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_UninstallAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UninstallAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).UninstallAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_UninstallAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).UninstallAgent(ctx, req.(*UninstallAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_ListCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).ListCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_ListCatalog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).ListCatalog(ctx, req.(*ListCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_GetCatalogAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCatalogAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).GetCatalogAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_GetCatalogAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).GetCatalogAgent(ctx, req.(*GetCatalogAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_RefreshCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).RefreshCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_RefreshCatalog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).RefreshCatalog(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_SearchCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).SearchCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_SearchCatalog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).SearchCatalog(ctx, req.(*SearchCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_CheckUpdates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).CheckUpdates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_CheckUpdates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).CheckUpdates(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_GetChangelog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChangelogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).GetChangelog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_GetChangelog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).GetChangelog(ctx, req.(*GetChangelogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentManagerServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentManagerService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentManagerServiceServer).GetStatus(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentManagerService_WatchAgents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentManagerServiceServer).WatchAgents(m, &grpc.GenericServerStream[emptypb.Empty, AgentEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentManagerService_WatchAgentsServer = grpc.ServerStreamingServer[AgentEvent]
+
+// AgentManagerService_ServiceDesc is the grpc.ServiceDesc for AgentManagerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentManagerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentmgr.v1.AgentManagerService",
+	HandlerType: (*AgentManagerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAgents",
+			Handler:    _AgentManagerService_ListAgents_Handler,
+		},
+		{
+			MethodName: "GetAgent",
+			Handler:    _AgentManagerService_GetAgent_Handler,
+		},
+		{
+			MethodName: "InstallAgent",
+			Handler:    _AgentManagerService_InstallAgent_Handler,
+		},
+		{
+			MethodName: "UpdateAgent",
+			Handler:    _AgentManagerService_UpdateAgent_Handler,
+		},
+		{
+			MethodName: "UninstallAgent",
+			Handler:    _AgentManagerService_UninstallAgent_Handler,
+		},
+		{
+			MethodName: "ListCatalog",
+			Handler:    _AgentManagerService_ListCatalog_Handler,
+		},
+		{
+			MethodName: "GetCatalogAgent",
+			Handler:    _AgentManagerService_GetCatalogAgent_Handler,
+		},
+		{
+			MethodName: "RefreshCatalog",
+			Handler:    _AgentManagerService_RefreshCatalog_Handler,
+		},
+		{
+			MethodName: "SearchCatalog",
+			Handler:    _AgentManagerService_SearchCatalog_Handler,
+		},
+		{
+			MethodName: "CheckUpdates",
+			Handler:    _AgentManagerService_CheckUpdates_Handler,
+		},
+		{
+			MethodName: "GetChangelog",
+			Handler:    _AgentManagerService_GetChangelog_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _AgentManagerService_GetHistory_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _AgentManagerService_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAgents",
+			Handler:       _AgentManagerService_WatchAgents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "agentmgr.proto",
+}
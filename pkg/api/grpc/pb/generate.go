@@ -0,0 +1,7 @@
+// Package pb contains the protobuf messages and gRPC service stubs generated
+// from api/proto/agentmgr.proto. Regenerate with `make generate` after
+// editing the proto; protoc, protoc-gen-go and protoc-gen-go-grpc must be
+// on PATH.
+package pb
+
+//go:generate protoc --proto_path=../../../../api/proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative agentmgr.proto
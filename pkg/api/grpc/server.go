@@ -3,10 +3,12 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,14 +16,19 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/auth"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
+	"github.com/kevinelliott/agentmanager/pkg/api/grpc/pb"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/detector"
 	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
@@ -41,6 +48,7 @@ type Server struct {
 
 	grpcServer *grpc.Server
 	listener   net.Listener
+	auth       *auth.Authenticator
 
 	// State
 	agents      []*agent.Installation
@@ -50,9 +58,9 @@ type Server struct {
 	lastCheck   time.Time
 	version     string
 
-	// Event subscribers
-	subscribers []chan *AgentEvent
-	subMu       sync.RWMutex
+	// events is shared with the REST server and systray when they are
+	// given the same bus via WithEventBus.
+	events *events.Bus
 }
 
 // Option configures a gRPC Server during construction.
@@ -76,6 +84,16 @@ func WithStartTime(t time.Time) Option {
 	}
 }
 
+// WithEventBus publishes the server's events on bus instead of a private
+// one, and streams bus events from other publishers to WatchAgents.
+func WithEventBus(bus *events.Bus) Option {
+	return func(s *Server) {
+		if bus != nil {
+			s.events = bus
+		}
+	}
+}
+
 // ServerConfig configures the gRPC server.
 type ServerConfig struct {
 	Address  string
@@ -95,15 +113,16 @@ func NewServer(
 	opts ...Option,
 ) *Server {
 	s := &Server{
-		config:      cfg,
-		platform:    plat,
-		store:       store,
-		detector:    det,
-		catalog:     cat,
-		installer:   inst,
-		startTime:   time.Now(),
-		version:     "dev",
-		subscribers: make([]chan *AgentEvent, 0),
+		config:    cfg,
+		platform:  plat,
+		store:     store,
+		detector:  det,
+		catalog:   cat,
+		installer: inst,
+		startTime: time.Now(),
+		version:   "dev",
+		events:    events.NewBus(),
+		auth:      auth.New(cfg, store),
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -143,6 +162,87 @@ func recoveryStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc
 	return handler(srv, ss)
 }
 
+// methodScopes is the token scope each AgentManagerService RPC needs.
+// Methods missing here (other than reflection) need admin.
+var methodScopes = map[string]auth.Scope{
+	pb.AgentManagerService_ListAgents_FullMethodName:      auth.ScopeRead,
+	pb.AgentManagerService_GetAgent_FullMethodName:        auth.ScopeRead,
+	pb.AgentManagerService_InstallAgent_FullMethodName:    auth.ScopeUpdate,
+	pb.AgentManagerService_UpdateAgent_FullMethodName:     auth.ScopeUpdate,
+	pb.AgentManagerService_UninstallAgent_FullMethodName:  auth.ScopeAdmin,
+	pb.AgentManagerService_ListCatalog_FullMethodName:     auth.ScopeRead,
+	pb.AgentManagerService_GetCatalogAgent_FullMethodName: auth.ScopeRead,
+	pb.AgentManagerService_RefreshCatalog_FullMethodName:  auth.ScopeUpdate,
+	pb.AgentManagerService_SearchCatalog_FullMethodName:   auth.ScopeRead,
+	pb.AgentManagerService_CheckUpdates_FullMethodName:    auth.ScopeRead,
+	pb.AgentManagerService_GetChangelog_FullMethodName:    auth.ScopeRead,
+	pb.AgentManagerService_GetHistory_FullMethodName:      auth.ScopeRead,
+	pb.AgentManagerService_GetStatus_FullMethodName:       auth.ScopeRead,
+	pb.AgentManagerService_WatchAgents_FullMethodName:     auth.ScopeRead,
+}
+
+// methodScope returns the scope needed to call fullMethod.
+func methodScope(fullMethod string) auth.Scope {
+	if scope, ok := methodScopes[fullMethod]; ok {
+		return scope
+	}
+	if strings.HasPrefix(fullMethod, "/grpc.reflection.") {
+		return auth.ScopeRead
+	}
+	return auth.ScopeAdmin
+}
+
+// authorize checks that the API token carried in ctx's incoming metadata,
+// either as "authorization: Bearer <token>" or as "x-api-key", is valid and
+// scoped for fullMethod.
+func (s *Server) authorize(ctx context.Context, fullMethod string) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if t, ok := auth.BearerToken(v); ok {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			if keys := md.Get("x-api-key"); len(keys) > 0 {
+				token = keys[0]
+			}
+		}
+	}
+
+	scope, err := s.auth.Authenticate(ctx, token)
+	if errors.Is(err, auth.ErrNoToken) || errors.Is(err, auth.ErrInvalidToken) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check API token: %v", err)
+	}
+
+	if need := methodScope(fullMethod); !scope.Allows(need) {
+		return status.Errorf(codes.PermissionDenied, "token scope %q does not allow %s access", scope, need)
+	}
+	return nil
+}
+
+// authUnaryInterceptor rejects unary calls without a valid, sufficiently
+// scoped API token when api.require_auth is set.
+func (s *Server) authUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStreamInterceptor is the streaming counterpart to
+// authUnaryInterceptor.
+func (s *Server) authStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
 // Start starts the gRPC server.
 func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 	listener, err := net.Listen("tcp", cfg.Address)
@@ -151,10 +251,30 @@ func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 	}
 	s.listener = listener
 
+	grpcServer, err := s.newGRPCServer(cfg)
+	if err != nil {
+		return err
+	}
+	s.grpcServer = grpcServer
+
+	// Start serving
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			// Log error
+		}
+	}()
+
+	return nil
+}
+
+// newGRPCServer builds the grpc.Server with the AgentManagerService and
+// reflection registered, ready to Serve on any listener.
+func (s *Server) newGRPCServer(cfg ServerConfig) (*grpc.Server, error) {
 	// Create gRPC server with hardening options.
 	//   - Keepalive pings keep long-lived IPC connections healthy.
 	//   - Message-size caps bound memory for a single RPC.
 	//   - Recovery interceptors convert handler panics into codes.Internal.
+	//   - Auth interceptors enforce api.require_auth before any handler runs.
 	opts := []grpc.ServerOption{
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			Time:    30 * time.Second,
@@ -162,15 +282,15 @@ func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 		}),
 		grpc.MaxRecvMsgSize(maxGRPCMsgBytes),
 		grpc.MaxSendMsgSize(maxGRPCMsgBytes),
-		grpc.ChainUnaryInterceptor(recoveryUnaryInterceptor),
-		grpc.ChainStreamInterceptor(recoveryStreamInterceptor),
+		grpc.ChainUnaryInterceptor(recoveryUnaryInterceptor, s.authUnaryInterceptor),
+		grpc.ChainStreamInterceptor(recoveryStreamInterceptor, s.authStreamInterceptor),
 	}
 
 	// Add TLS support if configured
 	if cfg.TLS && cfg.CertFile != "" && cfg.KeyFile != "" {
 		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
 		if err != nil {
-			return fmt.Errorf("failed to load TLS credentials: %w", err)
+			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
 		}
 		tlsConfig := &tls.Config{
 			Certificates: []tls.Certificate{cert},
@@ -179,19 +299,13 @@ func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
-	s.grpcServer = grpc.NewServer(opts...)
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterAgentManagerServiceServer(grpcServer, &service{s: s})
 
 	// Register reflection for debugging
-	reflection.Register(s.grpcServer)
-
-	// Start serving
-	go func() {
-		if err := s.grpcServer.Serve(listener); err != nil {
-			// Log error
-		}
-	}()
+	reflection.Register(grpcServer)
 
-	return nil
+	return grpcServer, nil
 }
 
 // Stop gracefully stops the gRPC server.
@@ -255,7 +369,7 @@ func (s *Server) ListAgents(ctx context.Context, req *ListAgentsRequest) (*ListA
 	// Convert to API format
 	agents := make([]*Installation, len(filtered))
 	for i, inst := range filtered {
-		agents[i] = FromAgentInstallation(inst)
+		agents[i] = s.toAPIInstallation(inst)
 	}
 
 	return &ListAgentsResponse{
@@ -272,7 +386,7 @@ func (s *Server) GetAgent(ctx context.Context, req *GetAgentRequest) (*GetAgentR
 	for _, inst := range s.agents {
 		if inst.Key() == req.Key {
 			return &GetAgentResponse{
-				Agent: FromAgentInstallation(inst),
+				Agent: s.toAPIInstallation(inst),
 			}, nil
 		}
 	}
@@ -308,7 +422,16 @@ func (s *Server) InstallAgent(ctx context.Context, req *InstallAgentRequest) (*I
 	}
 
 	// Install the agent
-	result, err := s.installer.Install(ctx, *agentDef, methodDef, req.Global)
+	record := storage.RecordEvent(ctx, s.store, &storage.UpdateEvent{
+		AgentID:       agentDef.ID,
+		AgentName:     agentDef.Name,
+		Action:        storage.UpdateActionInstall,
+		InstallMethod: req.Method,
+	}, storage.UpdateStatusRunning)
+	progress := s.events.ProgressWriter(string(storage.UpdateActionInstall), agentDef.ID)
+	result, err := s.installer.Install(providers.WithProgressWriter(ctx, progress), *agentDef, methodDef, req.Global)
+	progress.Close()
+	storage.FinishEvent(ctx, s.store, record, result.VersionString(), err)
 	if err != nil {
 		return &InstallAgentResponse{
 			Success: false,
@@ -320,18 +443,20 @@ func (s *Server) InstallAgent(ctx context.Context, req *InstallAgentRequest) (*I
 	s.refreshAgents(ctx)
 
 	// Find the new installation
-	var inst *Installation
+	var inst *agent.Installation
 	s.agentsMu.RLock()
 	for _, a := range s.agents {
 		if a.AgentID == req.AgentID && string(a.Method) == req.Method {
-			inst = FromAgentInstallation(a)
+			inst = a
 			break
 		}
 	}
 	s.agentsMu.RUnlock()
 
+	s.events.PublishInstallation(events.TypeAdded, inst)
+
 	return &InstallAgentResponse{
-		Installation: inst,
+		Installation: s.toAPIInstallation(inst),
 		Success:      true,
 		Message:      fmt.Sprintf("Installed version %s", result.Version.String()),
 	}, nil
@@ -364,6 +489,13 @@ func (s *Server) UpdateAgent(ctx context.Context, req *UpdateAgentRequest) (*Upd
 		}, nil
 	}
 
+	if pin := s.pinnedVersion(inst.AgentID); pin != "" && !req.Force {
+		return &UpdateAgentResponse{
+			Success: false,
+			Message: fmt.Sprintf("agent is pinned to %s (set force to override)", pin),
+		}, nil
+	}
+
 	fromVersion := inst.InstalledVersion.String()
 
 	// Get agent definition from catalog
@@ -384,12 +516,39 @@ func (s *Server) UpdateAgent(ctx context.Context, req *UpdateAgentRequest) (*Upd
 		}, nil
 	}
 
+	// A constraint pin limits the update to the newest release in range.
+	if constraints := s.versionConstraints(inst.AgentID); constraints != nil {
+		target, err := s.installer.ResolveVersion(ctx, methodDef, constraints)
+		if err != nil {
+			return &UpdateAgentResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		// Never downgrade out of an installed version above the range
+		// unless asked to.
+		if !target.IsNewerThan(inst.InstalledVersion) && !req.Force {
+			return &UpdateAgentResponse{
+				Success: false,
+				Message: fmt.Sprintf("no version satisfying %s is newer than %s (set force to override)", constraints, inst.InstalledVersion),
+			}, nil
+		}
+		ctx = providers.WithTargetVersion(ctx, target.String())
+	}
+
 	// Update the agent
-	result, err := s.installer.Update(ctx, inst, *agentDef, methodDef)
+	record := storage.RecordEvent(ctx, s.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, inst, providers.TargetVersion(ctx)), storage.UpdateStatusRunning)
+	progress := s.events.ProgressWriter(string(storage.UpdateActionUpdate), inst.AgentID)
+	result, err := s.installer.Update(providers.WithProgressWriter(ctx, progress), inst, *agentDef, methodDef)
+	progress.Close()
+	storage.FinishEvent(ctx, s.store, record, result.VersionString(), err)
 	if err != nil {
+		var verr *installer.VerificationError
 		return &UpdateAgentResponse{
-			Success: false,
-			Message: err.Error(),
+			FromVersion: fromVersion,
+			Success:     false,
+			Message:     err.Error(),
+			RolledBack:  errors.As(err, &verr) && verr.RolledBack(),
 		}, nil
 	}
 
@@ -397,18 +556,20 @@ func (s *Server) UpdateAgent(ctx context.Context, req *UpdateAgentRequest) (*Upd
 	s.refreshAgents(ctx)
 
 	// Find the updated installation
-	var updated *Installation
+	var updated *agent.Installation
 	s.agentsMu.RLock()
 	for _, a := range s.agents {
 		if a.Key() == req.Key {
-			updated = FromAgentInstallation(a)
+			updated = a
 			break
 		}
 	}
 	s.agentsMu.RUnlock()
 
+	s.events.PublishInstallation(events.TypeUpdated, updated)
+
 	return &UpdateAgentResponse{
-		Installation: updated,
+		Installation: s.toAPIInstallation(updated),
 		FromVersion:  fromVersion,
 		ToVersion:    result.Version.String(),
 		Success:      true,
@@ -462,7 +623,12 @@ func (s *Server) UninstallAgent(ctx context.Context, req *UninstallAgentRequest)
 	}
 
 	// Uninstall the agent
-	if err := s.installer.Uninstall(ctx, inst, methodDef); err != nil {
+	record := storage.RecordEvent(ctx, s.store, storage.NewInstallationEvent(storage.UpdateActionUninstall, inst, ""), storage.UpdateStatusRunning)
+	progress := s.events.ProgressWriter(string(storage.UpdateActionUninstall), inst.AgentID)
+	err = s.installer.Uninstall(providers.WithProgressWriter(ctx, progress), inst, methodDef)
+	progress.Close()
+	storage.FinishEvent(ctx, s.store, record, "", err)
+	if err != nil {
 		return &UninstallAgentResponse{
 			Success: false,
 			Message: err.Error(),
@@ -472,6 +638,8 @@ func (s *Server) UninstallAgent(ctx context.Context, req *UninstallAgentRequest)
 	// Refresh agents
 	s.refreshAgents(ctx)
 
+	s.events.PublishInstallation(events.TypeRemoved, inst)
+
 	return &UninstallAgentResponse{
 		Success: true,
 		Message: "Agent uninstalled successfully",
@@ -597,7 +765,7 @@ func (s *Server) CheckUpdates(ctx context.Context) (*CheckUpdatesResponse, error
 	for _, inst := range s.agents {
 		if inst.HasUpdate() {
 			updates = append(updates, &UpdateInfo{
-				Installation: FromAgentInstallation(inst),
+				Installation: s.toAPIInstallation(inst),
 				FromVersion:  inst.InstalledVersion.String(),
 				ToVersion:    inst.LatestVersion.String(),
 			})
@@ -614,12 +782,12 @@ func (s *Server) CheckUpdates(ctx context.Context) (*CheckUpdatesResponse, error
 func (s *Server) GetChangelog(ctx context.Context, req *GetChangelogRequest) (*GetChangelogResponse, error) {
 	fromVer, err := agent.ParseVersion(req.FromVersion)
 	if err != nil {
-		return nil, fmt.Errorf("invalid from_version: %w", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from_version: %v", err)
 	}
 
 	toVer, err := agent.ParseVersion(req.ToVersion)
 	if err != nil {
-		return nil, fmt.Errorf("invalid to_version: %w", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid to_version: %v", err)
 	}
 
 	changelog, err := s.catalog.GetChangelog(ctx, req.AgentID, fromVer, toVer)
@@ -634,6 +802,46 @@ func (s *Server) GetChangelog(ctx context.Context, req *GetChangelogRequest) (*G
 	}, nil
 }
 
+// defaultHistoryLimit is the number of events GetHistory returns when the
+// request does not set a limit.
+const defaultHistoryLimit = 50
+
+// GetHistory returns recorded install, update and uninstall events,
+// newest first.
+func (s *Server) GetHistory(ctx context.Context, req *GetHistoryRequest) (*GetHistoryResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	events, err := s.store.GetUpdateHistory(ctx, req.AgentID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*HistoryEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, &HistoryEvent{
+			ID:          event.ID,
+			AgentID:     event.AgentID,
+			AgentName:   event.AgentName,
+			Action:      string(event.Action),
+			Method:      event.InstallMethod,
+			FromVersion: event.FromVersion,
+			ToVersion:   event.ToVersion,
+			Status:      string(event.Status),
+			Error:       event.ErrorMessage,
+			StartedAt:   event.StartedAt,
+			CompletedAt: event.CompletedAt,
+		})
+	}
+
+	return &GetHistoryResponse{
+		Events: result,
+		Total:  len(result),
+	}, nil
+}
+
 // GetStatus returns the service status.
 func (s *Server) GetStatus(ctx context.Context) (*StatusResponse, error) {
 	s.agentsMu.RLock()
@@ -657,43 +865,47 @@ func (s *Server) GetStatus(ctx context.Context) (*StatusResponse, error) {
 	}, nil
 }
 
-// Subscribe subscribes to agent events.
-func (s *Server) Subscribe() <-chan *AgentEvent {
-	ch := make(chan *AgentEvent, 100)
-	s.subMu.Lock()
-	s.subscribers = append(s.subscribers, ch)
-	s.subMu.Unlock()
-	return ch
+// Subscribe subscribes to the server's event bus.
+func (s *Server) Subscribe() <-chan events.Event {
+	return s.events.Subscribe()
 }
 
-// Unsubscribe unsubscribes from agent events.
-func (s *Server) Unsubscribe(ch <-chan *AgentEvent) {
-	s.subMu.Lock()
-	defer s.subMu.Unlock()
+// Unsubscribe unsubscribes ch from the server's event bus.
+func (s *Server) Unsubscribe(ch <-chan events.Event) {
+	s.events.Unsubscribe(ch)
+}
 
-	for i, sub := range s.subscribers {
-		if sub == ch {
-			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
-			close(sub)
-			break
-		}
+// toAPIInstallation converts inst to API format, reporting the pinned
+// status from the server config when the agent is pinned.
+func (s *Server) toAPIInstallation(inst *agent.Installation) *Installation {
+	out := FromAgentInstallation(inst)
+	if out == nil {
+		return nil
+	}
+	if pin := s.pinnedVersion(inst.AgentID); pin != "" {
+		out.Status = string(agent.StatusPinned)
+		out.PinnedVersion = pin
 	}
+	return out
 }
 
-// publishEvent publishes an event to all subscribers.
-//
-//nolint:unused // Reserved for streaming events implementation
-func (s *Server) publishEvent(event *AgentEvent) {
-	s.subMu.RLock()
-	defer s.subMu.RUnlock()
+// pinnedVersion returns the exact version agentID is pinned to, or ""
+// when the agent is unpinned, only constrained to a range, or the server
+// was built without a config.
+func (s *Server) pinnedVersion(agentID string) string {
+	if s.config == nil || !s.config.IsAgentPinned(agentID) {
+		return ""
+	}
+	return s.config.GetPinnedVersion(agentID)
+}
 
-	for _, ch := range s.subscribers {
-		select {
-		case ch <- event:
-		default:
-			// Channel full, skip
-		}
+// versionConstraints returns the range constraint configured for agentID,
+// or nil when there is none.
+func (s *Server) versionConstraints(agentID string) agent.VersionConstraints {
+	if s.config == nil {
+		return nil
 	}
+	return s.config.GetVersionConstraints(agentID)
 }
 
 // refreshAgents refreshes the agent list.
@@ -3,6 +3,8 @@ package grpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,8 +14,11 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
@@ -44,6 +49,12 @@ func (m *mockPlatform) ShowChangelogDialog(a, b, c, d string) platform.DialogRes
 // mockStore implements storage.Store for testing
 type mockStore struct {
 	catalogData []byte
+
+	// events records every SaveUpdateEvent insert, oldest first
+	events []*storage.UpdateEvent
+
+	// settings backs GetSetting/SetSetting (used by scoped API tokens)
+	settings map[string]string
 }
 
 func (m *mockStore) Initialize(ctx context.Context) error { return nil }
@@ -59,10 +70,20 @@ func (m *mockStore) ListInstallations(ctx context.Context, filter *agent.Filter)
 }
 func (m *mockStore) DeleteInstallation(ctx context.Context, key string) error { return nil }
 func (m *mockStore) SaveUpdateEvent(ctx context.Context, event *storage.UpdateEvent) error {
+	if event.ID == 0 {
+		event.ID = int64(len(m.events) + 1)
+		m.events = append(m.events, event)
+	}
 	return nil
 }
 func (m *mockStore) GetUpdateHistory(ctx context.Context, agentID string, limit int) ([]*storage.UpdateEvent, error) {
-	return nil, nil
+	var out []*storage.UpdateEvent
+	for i := len(m.events) - 1; i >= 0 && len(out) < limit; i-- {
+		if agentID == "" || m.events[i].AgentID == agentID {
+			out = append(out, m.events[i])
+		}
+	}
+	return out, nil
 }
 func (m *mockStore) GetCatalogCache(ctx context.Context) ([]byte, string, time.Time, error) {
 	return m.catalogData, "", time.Now(), nil
@@ -71,9 +92,20 @@ func (m *mockStore) SaveCatalogCache(ctx context.Context, data []byte, etag stri
 	m.catalogData = data
 	return nil
 }
-func (m *mockStore) GetSetting(ctx context.Context, key string) (string, error) { return "", nil }
-func (m *mockStore) SetSetting(ctx context.Context, key, value string) error    { return nil }
-func (m *mockStore) DeleteSetting(ctx context.Context, key string) error        { return nil }
+func (m *mockStore) GetSetting(ctx context.Context, key string) (string, error) {
+	return m.settings[key], nil
+}
+func (m *mockStore) SetSetting(ctx context.Context, key, value string) error {
+	if m.settings == nil {
+		m.settings = make(map[string]string)
+	}
+	m.settings[key] = value
+	return nil
+}
+func (m *mockStore) DeleteSetting(ctx context.Context, key string) error {
+	delete(m.settings, key)
+	return nil
+}
 func (m *mockStore) SaveDetectionCache(ctx context.Context, installations []*agent.Installation) error {
 	return nil
 }
@@ -84,6 +116,18 @@ func (m *mockStore) ClearDetectionCache(ctx context.Context) error { return nil
 func (m *mockStore) GetDetectionCacheTime(ctx context.Context) (time.Time, error) {
 	return time.Time{}, nil
 }
+func (m *mockStore) SaveRegistryEvents(ctx context.Context, events []*storage.RegistryEventRecord) error {
+	return nil
+}
+func (m *mockStore) GetRegistryEvents(ctx context.Context, agentID string, limit int) ([]*storage.RegistryEventRecord, error) {
+	return nil, nil
+}
+func (m *mockStore) SaveDetectionFingerprints(ctx context.Context, fingerprints map[string]string) error {
+	return nil
+}
+func (m *mockStore) GetDetectionFingerprints(ctx context.Context) (map[string]string, error) {
+	return nil, nil
+}
 func (m *mockStore) SetLastUpdateCheckTime(ctx context.Context, t time.Time) error { return nil }
 func (m *mockStore) GetLastUpdateCheckTime(ctx context.Context) (time.Time, error) {
 	return time.Time{}, nil
@@ -445,10 +489,7 @@ func TestSubscribeUnsubscribe(t *testing.T) {
 	}
 
 	// Check subscriber count
-	server.subMu.RLock()
-	subCount := len(server.subscribers)
-	server.subMu.RUnlock()
-
+	subCount := server.events.Subscribers()
 	if subCount != 1 {
 		t.Errorf("Subscriber count = %d, want 1", subCount)
 	}
@@ -457,10 +498,7 @@ func TestSubscribeUnsubscribe(t *testing.T) {
 	server.Unsubscribe(ch)
 
 	// Check subscriber count after unsubscribe
-	server.subMu.RLock()
-	subCount = len(server.subscribers)
-	server.subMu.RUnlock()
-
+	subCount = server.events.Subscribers()
 	if subCount != 0 {
 		t.Errorf("Subscriber count after unsubscribe = %d, want 0", subCount)
 	}
@@ -1217,9 +1255,7 @@ func TestMultipleSubscribers(t *testing.T) {
 	ch2 := server.Subscribe()
 	ch3 := server.Subscribe()
 
-	server.subMu.RLock()
-	count := len(server.subscribers)
-	server.subMu.RUnlock()
+	count := server.events.Subscribers()
 	if count != 3 {
 		t.Errorf("Subscriber count = %d, want 3", count)
 	}
@@ -1227,20 +1263,16 @@ func TestMultipleSubscribers(t *testing.T) {
 	// Unsubscribe middle one
 	server.Unsubscribe(ch2)
 
-	server.subMu.RLock()
-	count = len(server.subscribers)
-	server.subMu.RUnlock()
+	count = server.events.Subscribers()
 	if count != 2 {
 		t.Errorf("Subscriber count after unsubscribe = %d, want 2", count)
 	}
 
 	// Unsubscribe non-existent (should be no-op)
-	fakeCh := make(chan *AgentEvent)
+	fakeCh := make(chan events.Event)
 	server.Unsubscribe(fakeCh)
 
-	server.subMu.RLock()
-	count = len(server.subscribers)
-	server.subMu.RUnlock()
+	count = server.events.Subscribers()
 	if count != 2 {
 		t.Errorf("Subscriber count after fake unsubscribe = %d, want 2", count)
 	}
@@ -1421,3 +1453,149 @@ func TestServerWithVersionOption(t *testing.T) {
 		t.Errorf("Version = %q, want %q", status.Version, "v1.2.3")
 	}
 }
+
+func TestUpdateAgentRefusesPinnedAgent(t *testing.T) {
+	server := setupTestServer()
+	server.installer = installer.NewManager(&mockPlatform{})
+	server.config.Agents = map[string]config.AgentConfig{
+		"claude-code": {PinnedVersion: "1.0.0"},
+	}
+
+	inst := &agent.Installation{
+		AgentID:          "claude-code",
+		AgentName:        "Claude Code",
+		Method:           agent.InstallMethodNPM,
+		InstalledVersion: agent.MustParseVersion("1.0.0"),
+	}
+	server.agents = []*agent.Installation{inst}
+
+	resp, err := server.UpdateAgent(context.Background(), &UpdateAgentRequest{Key: inst.Key()})
+	if err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("Success should be false for a pinned agent")
+	}
+	if !strings.Contains(resp.Message, "pinned to 1.0.0") {
+		t.Errorf("Message = %q, want it to mention the pin", resp.Message)
+	}
+
+	got, err := server.GetAgent(context.Background(), &GetAgentRequest{Key: inst.Key()})
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.Agent.Status != string(agent.StatusPinned) || got.Agent.PinnedVersion != "1.0.0" {
+		t.Errorf("GetAgent status = %q pinned = %q, want pinned/1.0.0", got.Agent.Status, got.Agent.PinnedVersion)
+	}
+}
+
+func TestUpdateAgentRefusesDowngradeIntoRange(t *testing.T) {
+	server := setupTestServer()
+	m, p := newRangeInstaller()
+	server.installer = m
+	server.config.Agents = map[string]config.AgentConfig{
+		"claude-code": {PinnedVersion: "^2.1"},
+	}
+
+	// 3.0.0 is installed, above everything the constraint allows.
+	inst := &agent.Installation{
+		AgentID:          "claude-code",
+		AgentName:        "Claude Code",
+		Method:           agent.InstallMethodNPM,
+		InstalledVersion: agent.MustParseVersion("3.0.0"),
+	}
+	server.agents = []*agent.Installation{inst}
+
+	resp, err := server.UpdateAgent(context.Background(), &UpdateAgentRequest{Key: inst.Key()})
+	if err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+	if resp.Success || !strings.Contains(resp.Message, "newer than 3.0.0") {
+		t.Errorf("UpdateAgent() = %+v, want a refusal to downgrade", resp)
+	}
+	if len(p.targets) != 0 {
+		t.Fatalf("Update called with %v, want no downgrade", p.targets)
+	}
+
+	if _, err := server.UpdateAgent(context.Background(), &UpdateAgentRequest{Key: inst.Key(), Force: true}); err != nil {
+		t.Fatalf("forced UpdateAgent() error = %v", err)
+	}
+	if len(p.targets) != 1 || p.targets[0] != "2.2.0" {
+		t.Errorf("forced Update targets = %v, want [2.2.0]", p.targets)
+	}
+}
+
+func TestUpdateAgentRecordsHistory(t *testing.T) {
+	server := setupTestServer()
+	// npm is not on the mock platform's PATH, so the update itself fails
+	// and the recorded event must say so.
+	server.installer = installer.NewManager(&mockPlatform{})
+
+	inst := &agent.Installation{
+		AgentID:          "claude-code",
+		AgentName:        "Claude Code",
+		Method:           agent.InstallMethodNPM,
+		InstalledVersion: agent.MustParseVersion("1.0.0"),
+	}
+	server.agents = []*agent.Installation{inst}
+
+	resp, err := server.UpdateAgent(context.Background(), &UpdateAgentRequest{Key: inst.Key()})
+	if err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+	if resp.Success {
+		t.Fatal("UpdateAgent() should fail without npm")
+	}
+
+	history, err := server.GetHistory(context.Background(), &GetHistoryRequest{AgentID: "claude-code"})
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if history.Total != 1 {
+		t.Fatalf("GetHistory() total = %d, want 1", history.Total)
+	}
+	event := history.Events[0]
+	if event.Action != "update" || event.Status != "failed" || event.FromVersion != "1.0.0" {
+		t.Errorf("event = %+v, want failed update from 1.0.0", event)
+	}
+	if event.Error == "" || event.CompletedAt == nil {
+		t.Errorf("failed event should carry an error and completion time: %+v", event)
+	}
+}
+
+// rangeProvider handles npm and lists a fixed set of versions, so version
+// constraints resolve without a registry. Update records its target and
+// fails, leaving nothing to re-detect.
+type rangeProvider struct {
+	versions []agent.Version
+	targets  []string
+}
+
+func (p *rangeProvider) Name() string      { return "range" }
+func (p *rangeProvider) Methods() []string { return []string{"npm"} }
+func (p *rangeProvider) IsAvailable() bool { return true }
+func (p *rangeProvider) Install(context.Context, catalog.AgentDef, catalog.InstallMethodDef, bool) (*providers.Result, error) {
+	return &providers.Result{}, nil
+}
+func (p *rangeProvider) Update(ctx context.Context, _ *agent.Installation, _ catalog.AgentDef, _ catalog.InstallMethodDef) (*providers.Result, error) {
+	p.targets = append(p.targets, providers.TargetVersion(ctx))
+	return nil, errors.New("range provider does not update")
+}
+func (p *rangeProvider) Uninstall(context.Context, *agent.Installation, catalog.InstallMethodDef) error {
+	return nil
+}
+func (p *rangeProvider) GetLatestVersion(context.Context, catalog.InstallMethodDef) (agent.Version, error) {
+	return p.versions[len(p.versions)-1], nil
+}
+func (p *rangeProvider) ListVersions(context.Context, catalog.InstallMethodDef) ([]agent.Version, error) {
+	return p.versions, nil
+}
+
+func newRangeInstaller() (*installer.Manager, *rangeProvider) {
+	p := &rangeProvider{versions: []agent.Version{
+		agent.MustParseVersion("2.0.0"), agent.MustParseVersion("2.1.0"), agent.MustParseVersion("2.2.0"),
+	}}
+	m := installer.NewManager(&mockPlatform{})
+	m.RegisterProvider(p)
+	return m, p
+}
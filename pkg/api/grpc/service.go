@@ -0,0 +1,379 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
+	"github.com/kevinelliott/agentmanager/pkg/api/grpc/pb"
+)
+
+// service adapts Server to the generated pb.AgentManagerServiceServer
+// interface. Each RPC converts the protobuf request to the API types in
+// types.go, calls the matching Server method and converts the result back,
+// so Server stays usable in-process without protobuf.
+type service struct {
+	pb.UnimplementedAgentManagerServiceServer
+	s *Server
+}
+
+// ListAgents implements pb.AgentManagerServiceServer.
+func (svc *service) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	resp, err := svc.s.ListAgents(ctx, &ListAgentsRequest{
+		Filter:    agentFilterFromPB(req.GetFilter()),
+		SortBy:    req.GetSortBy(),
+		SortOrder: req.GetSortOrder(),
+		Limit:     int(req.GetLimit()),
+		Offset:    int(req.GetOffset()),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.ListAgentsResponse{
+		Agents: installationsToPB(resp.Agents),
+		Total:  int32(resp.Total),
+	}, nil
+}
+
+// GetAgent implements pb.AgentManagerServiceServer.
+func (svc *service) GetAgent(ctx context.Context, req *pb.GetAgentRequest) (*pb.GetAgentResponse, error) {
+	resp, err := svc.s.GetAgent(ctx, &GetAgentRequest{Key: req.GetKey()})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.GetAgentResponse{Agent: installationToPB(resp.Agent)}, nil
+}
+
+// InstallAgent implements pb.AgentManagerServiceServer.
+func (svc *service) InstallAgent(ctx context.Context, req *pb.InstallAgentRequest) (*pb.InstallAgentResponse, error) {
+	resp, err := svc.s.InstallAgent(ctx, &InstallAgentRequest{
+		AgentID: req.GetAgentId(),
+		Method:  req.GetMethod(),
+		Global:  req.GetGlobal(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.InstallAgentResponse{
+		Installation: installationToPB(resp.Installation),
+		Success:      resp.Success,
+		Message:      resp.Message,
+	}, nil
+}
+
+// UpdateAgent implements pb.AgentManagerServiceServer.
+func (svc *service) UpdateAgent(ctx context.Context, req *pb.UpdateAgentRequest) (*pb.UpdateAgentResponse, error) {
+	resp, err := svc.s.UpdateAgent(ctx, &UpdateAgentRequest{
+		Key:   req.GetKey(),
+		Force: req.GetForce(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.UpdateAgentResponse{
+		Installation: installationToPB(resp.Installation),
+		FromVersion:  resp.FromVersion,
+		ToVersion:    resp.ToVersion,
+		Success:      resp.Success,
+		Message:      resp.Message,
+		RolledBack:   resp.RolledBack,
+	}, nil
+}
+
+// UninstallAgent implements pb.AgentManagerServiceServer.
+func (svc *service) UninstallAgent(ctx context.Context, req *pb.UninstallAgentRequest) (*pb.UninstallAgentResponse, error) {
+	resp, err := svc.s.UninstallAgent(ctx, &UninstallAgentRequest{Key: req.GetKey()})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.UninstallAgentResponse{
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+// ListCatalog implements pb.AgentManagerServiceServer.
+func (svc *service) ListCatalog(ctx context.Context, req *pb.ListCatalogRequest) (*pb.ListCatalogResponse, error) {
+	resp, err := svc.s.ListCatalog(ctx, &ListCatalogRequest{Platform: req.GetPlatform()})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.ListCatalogResponse{
+		Agents: catalogAgentsToPB(resp.Agents),
+		Total:  int32(resp.Total),
+	}, nil
+}
+
+// GetCatalogAgent implements pb.AgentManagerServiceServer.
+func (svc *service) GetCatalogAgent(ctx context.Context, req *pb.GetCatalogAgentRequest) (*pb.GetCatalogAgentResponse, error) {
+	resp, err := svc.s.GetCatalogAgent(ctx, &GetCatalogAgentRequest{AgentID: req.GetAgentId()})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.GetCatalogAgentResponse{Agent: catalogAgentToPB(resp.Agent)}, nil
+}
+
+// RefreshCatalog implements pb.AgentManagerServiceServer.
+func (svc *service) RefreshCatalog(ctx context.Context, _ *emptypb.Empty) (*pb.RefreshCatalogResponse, error) {
+	resp, err := svc.s.RefreshCatalog(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.RefreshCatalogResponse{
+		Success:    resp.Success,
+		Message:    resp.Message,
+		AgentCount: int32(resp.AgentCount),
+		Updated:    resp.Updated,
+		Version:    resp.Version,
+	}, nil
+}
+
+// SearchCatalog implements pb.AgentManagerServiceServer.
+func (svc *service) SearchCatalog(ctx context.Context, req *pb.SearchCatalogRequest) (*pb.SearchCatalogResponse, error) {
+	resp, err := svc.s.SearchCatalog(ctx, &SearchCatalogRequest{
+		Query:    req.GetQuery(),
+		Platform: req.GetPlatform(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.SearchCatalogResponse{
+		Agents: catalogAgentsToPB(resp.Agents),
+		Total:  int32(resp.Total),
+	}, nil
+}
+
+// CheckUpdates implements pb.AgentManagerServiceServer.
+func (svc *service) CheckUpdates(ctx context.Context, _ *emptypb.Empty) (*pb.CheckUpdatesResponse, error) {
+	resp, err := svc.s.CheckUpdates(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	updates := make([]*pb.UpdateInfo, 0, len(resp.Updates))
+	for _, u := range resp.Updates {
+		updates = append(updates, &pb.UpdateInfo{
+			Installation: installationToPB(u.Installation),
+			FromVersion:  u.FromVersion,
+			ToVersion:    u.ToVersion,
+		})
+	}
+	return &pb.CheckUpdatesResponse{
+		Updates: updates,
+		Total:   int32(resp.Total),
+	}, nil
+}
+
+// GetChangelog implements pb.AgentManagerServiceServer.
+func (svc *service) GetChangelog(ctx context.Context, req *pb.GetChangelogRequest) (*pb.GetChangelogResponse, error) {
+	resp, err := svc.s.GetChangelog(ctx, &GetChangelogRequest{
+		AgentID:     req.GetAgentId(),
+		FromVersion: req.GetFromVersion(),
+		ToVersion:   req.GetToVersion(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	releases := make([]*pb.Release, 0, len(resp.Releases))
+	for _, r := range resp.Releases {
+		releases = append(releases, &pb.Release{
+			Version:     r.Version,
+			Title:       r.Title,
+			Body:        r.Body,
+			Highlights:  r.Highlights,
+			PublishedAt: timestampToPB(r.PublishedAt),
+			Url:         r.URL,
+		})
+	}
+	return &pb.GetChangelogResponse{
+		Changelog: resp.Changelog,
+		Releases:  releases,
+	}, nil
+}
+
+// GetHistory implements pb.AgentManagerServiceServer.
+func (svc *service) GetHistory(ctx context.Context, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	resp, err := svc.s.GetHistory(ctx, &GetHistoryRequest{
+		AgentID: req.GetAgentId(),
+		Limit:   int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	events := make([]*pb.HistoryEvent, 0, len(resp.Events))
+	for _, e := range resp.Events {
+		event := &pb.HistoryEvent{
+			Id:          e.ID,
+			AgentId:     e.AgentID,
+			AgentName:   e.AgentName,
+			Action:      e.Action,
+			Method:      e.Method,
+			FromVersion: e.FromVersion,
+			ToVersion:   e.ToVersion,
+			Status:      e.Status,
+			Error:       e.Error,
+			StartedAt:   timestampToPB(e.StartedAt),
+		}
+		if e.CompletedAt != nil {
+			event.CompletedAt = timestampToPB(*e.CompletedAt)
+		}
+		events = append(events, event)
+	}
+	return &pb.GetHistoryResponse{
+		Events: events,
+		Total:  int32(resp.Total),
+	}, nil
+}
+
+// GetStatus implements pb.AgentManagerServiceServer.
+func (svc *service) GetStatus(ctx context.Context, _ *emptypb.Empty) (*pb.StatusResponse, error) {
+	resp, err := svc.s.GetStatus(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.StatusResponse{
+		Running:            resp.Running,
+		UptimeSeconds:      resp.UptimeSeconds,
+		AgentCount:         int32(resp.AgentCount),
+		UpdatesAvailable:   int32(resp.UpdatesAvailable),
+		LastCatalogRefresh: timestampToPB(resp.LastCatalogRefresh),
+		LastUpdateCheck:    timestampToPB(resp.LastUpdateCheck),
+		Version:            resp.Version,
+	}, nil
+}
+
+// WatchAgents implements pb.AgentManagerServiceServer. It streams every
+// agent event on the server's bus until the client goes away or the server
+// stops. Progress lines are left to the REST event stream, since
+// AgentEvent has no field for them.
+func (svc *service) WatchAgents(_ *emptypb.Empty, stream pb.AgentManagerService_WatchAgentsServer) error {
+	ch := svc.s.Subscribe()
+	defer svc.s.Unsubscribe(ch)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if event.Type == events.TypeProgress {
+				continue
+			}
+			if err := stream.Send(&pb.AgentEvent{
+				Type:         string(event.Type),
+				Installation: installationToPB(svc.s.toAPIInstallation(event.Installation)),
+				Timestamp:    timestampToPB(event.Timestamp),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatusError maps an error from a Server method to a gRPC status.
+// Errors that already carry a status pass through unchanged.
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func agentFilterFromPB(f *pb.AgentFilter) *AgentFilter {
+	if f == nil {
+		return nil
+	}
+	return &AgentFilter{
+		AgentIDs:  f.GetAgentIds(),
+		Methods:   f.GetMethods(),
+		HasUpdate: f.HasUpdate,
+		IsGlobal:  f.IsGlobal,
+		Query:     f.GetQuery(),
+	}
+}
+
+func installationToPB(inst *Installation) *pb.Installation {
+	if inst == nil {
+		return nil
+	}
+	return &pb.Installation{
+		Key:              inst.Key,
+		AgentId:          inst.AgentID,
+		AgentName:        inst.AgentName,
+		InstallMethod:    inst.InstallMethod,
+		InstalledVersion: inst.InstalledVersion,
+		LatestVersion:    inst.LatestVersion,
+		ExecutablePath:   inst.ExecutablePath,
+		InstallPath:      inst.InstallPath,
+		IsGlobal:         inst.IsGlobal,
+		DetectedAt:       timestampToPB(inst.DetectedAt),
+		LastChecked:      timestampToPB(inst.LastChecked),
+		Metadata:         inst.Metadata,
+		HasUpdate:        inst.HasUpdate,
+		Status:           inst.Status,
+		PinnedVersion:    inst.PinnedVersion,
+	}
+}
+
+func installationsToPB(insts []*Installation) []*pb.Installation {
+	out := make([]*pb.Installation, 0, len(insts))
+	for _, inst := range insts {
+		out = append(out, installationToPB(inst))
+	}
+	return out
+}
+
+func catalogAgentToPB(a *CatalogAgent) *pb.CatalogAgent {
+	if a == nil {
+		return nil
+	}
+	methods := make([]*pb.InstallMethodDef, 0, len(a.InstallMethods))
+	for _, m := range a.InstallMethods {
+		methods = append(methods, &pb.InstallMethodDef{
+			Method:    m.Method,
+			Package:   m.Package,
+			Command:   m.Command,
+			Platforms: m.Platforms,
+		})
+	}
+	return &pb.CatalogAgent{
+		Id:             a.ID,
+		Name:           a.Name,
+		Description:    a.Description,
+		Homepage:       a.Homepage,
+		Repository:     a.Repository,
+		InstallMethods: methods,
+	}
+}
+
+func catalogAgentsToPB(agents []*CatalogAgent) []*pb.CatalogAgent {
+	out := make([]*pb.CatalogAgent, 0, len(agents))
+	for _, a := range agents {
+		out = append(out, catalogAgentToPB(a))
+	}
+	return out
+}
+
+// timestampToPB converts t to a protobuf timestamp, leaving the zero time
+// unset rather than encoding year 1.
+func timestampToPB(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
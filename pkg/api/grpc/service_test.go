@@ -0,0 +1,309 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/auth"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
+	"github.com/kevinelliott/agentmanager/pkg/api/grpc/pb"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// dialBufconn serves server's gRPC service on an in-memory listener and
+// returns a client connected to it. Everything is torn down when the test
+// ends.
+func dialBufconn(t *testing.T, server *Server) pb.AgentManagerServiceClient {
+	t.Helper()
+
+	grpcServer, err := server.newGRPCServer(ServerConfig{})
+	if err != nil {
+		t.Fatalf("newGRPCServer() error = %v", err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = grpcServer.Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return pb.NewAgentManagerServiceClient(conn)
+}
+
+func TestServiceOverBufconn(t *testing.T) {
+	server := setupTestServer()
+	server.version = "1.2.3"
+	latest := agent.MustParseVersion("1.1.0")
+	inst := &agent.Installation{
+		AgentID:          "claude-code",
+		AgentName:        "Claude Code",
+		Method:           agent.InstallMethodNPM,
+		InstalledVersion: agent.MustParseVersion("1.0.0"),
+		LatestVersion:    &latest,
+		ExecutablePath:   "/usr/local/bin/claude",
+		DetectedAt:       time.Now(),
+	}
+	server.agents = []*agent.Installation{inst}
+	client := dialBufconn(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("GetStatus", func(t *testing.T) {
+		resp, err := client.GetStatus(ctx, &emptypb.Empty{})
+		if err != nil {
+			t.Fatalf("GetStatus() error = %v", err)
+		}
+		if !resp.GetRunning() || resp.GetVersion() != "1.2.3" {
+			t.Errorf("GetStatus() = %v", resp)
+		}
+		if resp.GetAgentCount() != 1 || resp.GetUpdatesAvailable() != 1 {
+			t.Errorf("counts = %d/%d, want 1/1", resp.GetAgentCount(), resp.GetUpdatesAvailable())
+		}
+	})
+
+	t.Run("GetAgent", func(t *testing.T) {
+		resp, err := client.GetAgent(ctx, &pb.GetAgentRequest{Key: inst.Key()})
+		if err != nil {
+			t.Fatalf("GetAgent() error = %v", err)
+		}
+		got := resp.GetAgent()
+		if got.GetAgentId() != "claude-code" || got.GetInstalledVersion() != "1.0.0" || !got.GetHasUpdate() {
+			t.Errorf("GetAgent() = %v", got)
+		}
+		if got.GetDetectedAt() == nil {
+			t.Error("DetectedAt should be set")
+		}
+		if got.GetLastChecked() != nil {
+			t.Error("zero LastChecked should be left unset")
+		}
+	})
+
+	t.Run("ListCatalog", func(t *testing.T) {
+		resp, err := client.ListCatalog(ctx, &pb.ListCatalogRequest{})
+		if err != nil {
+			t.Fatalf("ListCatalog() error = %v", err)
+		}
+		if int(resp.GetTotal()) != len(resp.GetAgents()) || resp.GetTotal() == 0 {
+			t.Errorf("ListCatalog() total = %d, agents = %d", resp.GetTotal(), len(resp.GetAgents()))
+		}
+	})
+
+	t.Run("GetCatalogAgent", func(t *testing.T) {
+		resp, err := client.GetCatalogAgent(ctx, &pb.GetCatalogAgentRequest{AgentId: "claude-code"})
+		if err != nil {
+			t.Fatalf("GetCatalogAgent() error = %v", err)
+		}
+		if resp.GetAgent().GetId() != "claude-code" || len(resp.GetAgent().GetInstallMethods()) == 0 {
+			t.Errorf("GetCatalogAgent() = %v", resp.GetAgent())
+		}
+	})
+
+	t.Run("GetChangelog invalid version", func(t *testing.T) {
+		_, err := client.GetChangelog(ctx, &pb.GetChangelogRequest{AgentId: "claude-code", ToVersion: "1.0.0"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetChangelog() code = %v, want InvalidArgument", status.Code(err))
+		}
+	})
+
+	t.Run("GetHistory", func(t *testing.T) {
+		storage.RecordEvent(ctx, server.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, inst, "1.1.0"), storage.UpdateStatusPending)
+
+		resp, err := client.GetHistory(ctx, &pb.GetHistoryRequest{AgentId: "claude-code"})
+		if err != nil {
+			t.Fatalf("GetHistory() error = %v", err)
+		}
+		if resp.GetTotal() != 1 {
+			t.Fatalf("GetHistory() total = %d, want 1", resp.GetTotal())
+		}
+		event := resp.GetEvents()[0]
+		if event.GetAction() != "update" || event.GetStatus() != "pending" || event.GetToVersion() != "1.1.0" {
+			t.Errorf("event = %v", event)
+		}
+		if event.GetCompletedAt() != nil {
+			t.Error("pending event should have no completion time")
+		}
+	})
+}
+
+func TestWatchAgentsOverBufconn(t *testing.T) {
+	server := setupTestServer()
+	client := dialBufconn(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchAgents(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("WatchAgents() error = %v", err)
+	}
+
+	// The handler subscribes asynchronously; wait for it before publishing.
+	for {
+		if server.events.Subscribers() > 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("WatchAgents never subscribed")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	// Progress lines are not forwarded; the next event received must be
+	// the update.
+	server.events.Publish(events.Event{Type: events.TypeProgress, AgentID: "aider", Message: "npm install"})
+	server.events.PublishInstallation(events.TypeUpdated, &agent.Installation{
+		AgentID:          "aider",
+		InstalledVersion: agent.MustParseVersion("0.51.0"),
+	})
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	if event.GetType() != "updated" || event.GetInstallation().GetAgentId() != "aider" {
+		t.Errorf("event = %v", event)
+	}
+
+	// Cancelling the stream must release the subscription.
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if server.events.Subscribers() == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("WatchAgents did not unsubscribe after the client cancelled")
+}
+
+func TestAuthOverBufconn(t *testing.T) {
+	server := setupTestServer()
+	server.config.API.RequireAuth = true
+	server.config.API.AuthToken = "s3cret"
+	client := dialBufconn(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	t.Run("mutating RPCs without a token", func(t *testing.T) {
+		_, err := client.InstallAgent(ctx, &pb.InstallAgentRequest{AgentId: "claude-code", Method: "npm"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("InstallAgent() code = %v, want Unauthenticated", status.Code(err))
+		}
+		_, err = client.UpdateAgent(ctx, &pb.UpdateAgentRequest{Key: "claude-code:npm:/usr/local/bin/claude"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("UpdateAgent() code = %v, want Unauthenticated", status.Code(err))
+		}
+		_, err = client.UninstallAgent(ctx, &pb.UninstallAgentRequest{Key: "claude-code:npm:/usr/local/bin/claude"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("UninstallAgent() code = %v, want Unauthenticated", status.Code(err))
+		}
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		_, err := client.UninstallAgent(withToken("guess"), &pb.UninstallAgentRequest{Key: "x"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("UninstallAgent() code = %v, want Unauthenticated", status.Code(err))
+		}
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		if _, err := client.GetStatus(withToken("s3cret"), &emptypb.Empty{}); err != nil {
+			t.Errorf("GetStatus() error = %v", err)
+		}
+		keyCtx := metadata.AppendToOutgoingContext(ctx, "x-api-key", "s3cret")
+		if _, err := client.GetStatus(keyCtx, &emptypb.Empty{}); err != nil {
+			t.Errorf("GetStatus() with x-api-key error = %v", err)
+		}
+	})
+
+	t.Run("stream without a token", func(t *testing.T) {
+		stream, err := client.WatchAgents(ctx, &emptypb.Empty{})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("WatchAgents() code = %v, want Unauthenticated", status.Code(err))
+		}
+	})
+}
+
+func TestScopedTokensOverBufconn(t *testing.T) {
+	server := setupTestServer()
+	server.config.API.RequireAuth = true
+	client := dialBufconn(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	readSecret, err := auth.CreateToken(ctx, server.store, "dashboard", auth.ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateSecret, err := auth.CreateToken(ctx, server.store, "provisioner", auth.ScopeUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as := func(secret string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+secret)
+	}
+
+	if _, err := client.GetStatus(as(readSecret), &emptypb.Empty{}); err != nil {
+		t.Errorf("GetStatus() as read error = %v", err)
+	}
+
+	_, err = client.UpdateAgent(as(readSecret), &pb.UpdateAgentRequest{Key: "x"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("UpdateAgent() as read code = %v, want PermissionDenied", status.Code(err))
+	}
+	// Passes the scope check; the unknown key is reported in the response.
+	if _, err := client.UpdateAgent(as(updateSecret), &pb.UpdateAgentRequest{Key: "x"}); err != nil {
+		t.Errorf("UpdateAgent() as update error = %v", err)
+	}
+
+	_, err = client.UninstallAgent(as(updateSecret), &pb.UninstallAgentRequest{Key: "x"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("UninstallAgent() as update code = %v, want PermissionDenied", status.Code(err))
+	}
+}
+
+func TestMethodScopesCoverService(t *testing.T) {
+	for _, m := range pb.AgentManagerService_ServiceDesc.Methods {
+		full := "/" + pb.AgentManagerService_ServiceDesc.ServiceName + "/" + m.MethodName
+		if _, ok := methodScopes[full]; !ok {
+			t.Errorf("methodScopes has no entry for %s", full)
+		}
+	}
+	for _, st := range pb.AgentManagerService_ServiceDesc.Streams {
+		full := "/" + pb.AgentManagerService_ServiceDesc.ServiceName + "/" + st.StreamName
+		if _, ok := methodScopes[full]; !ok {
+			t.Errorf("methodScopes has no entry for %s", full)
+		}
+	}
+}
@@ -24,6 +24,7 @@ type Installation struct {
 	Metadata         map[string]string `json:"metadata,omitempty"`
 	HasUpdate        bool              `json:"has_update"`
 	Status           string            `json:"status"`
+	PinnedVersion    string            `json:"pinned_version,omitempty"`
 }
 
 // FromAgentInstallation converts from pkg/agent.Installation to API format.
@@ -147,9 +148,11 @@ type InstallAgentResponse struct {
 	Message      string        `json:"message,omitempty"`
 }
 
-// UpdateAgentRequest requests an agent update.
+// UpdateAgentRequest requests an agent update. Force overrides a version
+// pin configured for the agent.
 type UpdateAgentRequest struct {
-	Key string `json:"key"`
+	Key   string `json:"key"`
+	Force bool   `json:"force,omitempty"`
 }
 
 // UpdateAgentResponse contains the update result.
@@ -159,6 +162,9 @@ type UpdateAgentResponse struct {
 	ToVersion    string        `json:"to_version"`
 	Success      bool          `json:"success"`
 	Message      string        `json:"message,omitempty"`
+	// RolledBack reports that the update failed verification and the
+	// previous version was reinstalled.
+	RolledBack bool `json:"rolled_back,omitempty"`
 }
 
 // UninstallAgentRequest requests agent uninstallation.
@@ -250,6 +256,34 @@ type GetChangelogResponse struct {
 	Releases  []*Release `json:"releases,omitempty"`
 }
 
+// GetHistoryRequest requests recorded install/update/uninstall events.
+// An empty AgentID returns events for every agent.
+type GetHistoryRequest struct {
+	AgentID string `json:"agent_id,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+// HistoryEvent is one recorded install, update, rollback or uninstall.
+type HistoryEvent struct {
+	ID          int64      `json:"id"`
+	AgentID     string     `json:"agent_id"`
+	AgentName   string     `json:"agent_name"`
+	Action      string     `json:"action"`
+	Method      string     `json:"method"`
+	FromVersion string     `json:"from_version,omitempty"`
+	ToVersion   string     `json:"to_version,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// GetHistoryResponse contains history events, newest first.
+type GetHistoryResponse struct {
+	Events []*HistoryEvent `json:"events"`
+	Total  int             `json:"total"`
+}
+
 // StatusResponse contains the service status.
 type StatusResponse struct {
 	Running            bool      `json:"running"`
@@ -260,10 +294,3 @@ type StatusResponse struct {
 	LastUpdateCheck    time.Time `json:"last_update_check"`
 	Version            string    `json:"version"`
 }
-
-// AgentEvent represents an agent-related event.
-type AgentEvent struct {
-	Type         string        `json:"type"` // "added", "updated", "removed", "update_available"
-	Installation *Installation `json:"installation,omitempty"`
-	Timestamp    time.Time     `json:"timestamp"`
-}
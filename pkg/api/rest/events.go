@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
+)
+
+// eventsKeepAlive is how often an idle event stream sends a comment, so
+// proxies and clients do not time the connection out.
+const eventsKeepAlive = 15 * time.Second
+
+// handleEvents streams bus events as Server-Sent Events until the client
+// disconnects or the server shuts down. The optional "types" query
+// parameter is a comma-separated list of event types to send.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var want map[events.Type]bool
+	if types := r.URL.Query().Get("types"); types != "" {
+		want = make(map[events.Type]bool)
+		for _, t := range strings.Split(types, ",") {
+			want[events.Type(strings.TrimSpace(t))] = true
+		}
+	}
+
+	// The stream outlives the server's WriteTimeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	ch := s.events.Subscribe()
+	defer s.events.Unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.streamsDone:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if want != nil && !want[event.Type] {
+				continue
+			}
+			data, err := json.Marshal(s.eventToMap(event))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// eventToMap converts a bus event to its JSON form, using the same
+// installation shape as the agents endpoints.
+func (s *Server) eventToMap(event events.Event) map[string]interface{} {
+	m := map[string]interface{}{
+		"type":      event.Type,
+		"agent_id":  event.AgentID,
+		"timestamp": event.Timestamp,
+	}
+	if event.Installation != nil {
+		m["installation"] = s.installationToMap(event.Installation)
+	}
+	if event.Operation != "" {
+		m["operation"] = event.Operation
+	}
+	if event.Message != "" {
+		m["message"] = event.Message
+	}
+	return m
+}
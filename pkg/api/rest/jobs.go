@@ -0,0 +1,312 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+)
+
+// jobStatus is the lifecycle state of a job.
+type jobStatus string
+
+const (
+	jobRunning   jobStatus = "running"
+	jobSucceeded jobStatus = "succeeded"
+	jobFailed    jobStatus = "failed"
+	jobCancelled jobStatus = "cancelled"
+	// jobRolledBack is an update that failed verification and was undone
+	// by reinstalling the previous version.
+	jobRolledBack jobStatus = "rolled_back"
+)
+
+const (
+	// maxJobOutput bounds the output kept per job; older output is
+	// dropped first.
+	maxJobOutput = 256 << 10 // 256 KiB
+
+	// jobRetention is how long finished jobs stay available for polling.
+	jobRetention = time.Hour
+)
+
+// jobFunc performs a job's work, writing subprocess output to output. The
+// returned map is reported as the job's result.
+type jobFunc func(ctx context.Context, output io.Writer) (map[string]interface{}, error)
+
+// job is an install, update or uninstall running in the background.
+type job struct {
+	id        string
+	operation string
+	agentID   string
+	createdAt time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu         sync.Mutex
+	status     jobStatus
+	output     []byte
+	truncated  bool
+	result     map[string]interface{}
+	err        string
+	finishedAt time.Time
+}
+
+// Write appends subprocess output, keeping the last maxJobOutput bytes.
+func (j *job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.output = append(j.output, p...)
+	if over := len(j.output) - maxJobOutput; over > 0 {
+		j.output = append(j.output[:0], j.output[over:]...)
+		j.truncated = true
+	}
+	return len(p), nil
+}
+
+func (j *job) finish(result map[string]interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finishedAt = time.Now()
+	switch {
+	case err == nil:
+		j.status = jobSucceeded
+		j.result = result
+	case errors.Is(err, context.Canceled):
+		j.status = jobCancelled
+		j.err = err.Error()
+	case rolledBack(err):
+		j.status = jobRolledBack
+		j.err = err.Error()
+	default:
+		j.status = jobFailed
+		j.err = err.Error()
+	}
+}
+
+// rolledBack reports whether err is an update the installer undid.
+func rolledBack(err error) bool {
+	var verr *installer.VerificationError
+	return errors.As(err, &verr) && verr.RolledBack()
+}
+
+func (j *job) finished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status != jobRunning
+}
+
+func (j *job) toMap() map[string]interface{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	m := map[string]interface{}{
+		"id":         j.id,
+		"operation":  j.operation,
+		"agent_id":   j.agentID,
+		"status":     j.status,
+		"output":     string(j.output),
+		"created_at": j.createdAt,
+	}
+	if j.truncated {
+		m["output_truncated"] = true
+	}
+	if j.result != nil {
+		m["result"] = j.result
+	}
+	if j.err != "" {
+		m["error"] = j.err
+	}
+	if !j.finishedAt.IsZero() {
+		m["finished_at"] = j.finishedAt
+	}
+	return m
+}
+
+// jobManager tracks jobs for the lifetime of a Server.
+type jobManager struct {
+	// ctx is the parent of every job's context; shutdown cancels it.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func newJobManager() *jobManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobManager{ctx: ctx, cancel: cancel, jobs: make(map[string]*job)}
+}
+
+// start runs fn in the background as a new job.
+func (m *jobManager) start(operation, agentID string, fn jobFunc) *job {
+	ctx, cancel := context.WithCancel(m.ctx)
+	j := &job{
+		id:        newJobID(),
+		operation: operation,
+		agentID:   agentID,
+		createdAt: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		status:    jobRunning,
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[j.id] = j
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(j.done)
+		defer cancel()
+
+		result, err := fn(ctx, j)
+		j.finish(result, err)
+	}()
+
+	return j
+}
+
+// get returns the job with id, or nil.
+func (m *jobManager) get(id string) *job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id]
+}
+
+// list returns all retained jobs, newest first.
+func (m *jobManager) list() []*job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].createdAt.After(jobs[b].createdAt)
+	})
+	return jobs
+}
+
+// prune drops jobs that finished more than jobRetention ago. The caller
+// holds m.mu.
+func (m *jobManager) prune() {
+	cutoff := time.Now().Add(-jobRetention)
+	for id, j := range m.jobs {
+		j.mu.Lock()
+		expired := !j.finishedAt.IsZero() && j.finishedAt.Before(cutoff)
+		j.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// shutdown cancels every running job and waits for them to return, or for
+// ctx to end.
+func (m *jobManager) shutdown(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newJobID returns a random job identifier.
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// jobError reports a job that failed after its context was cancelled as
+// cancelled: providers surface a killed subprocess, not ctx.Err().
+func jobError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
+	return err
+}
+
+// respondJobAccepted answers a request that started j with 202 and where to
+// poll for it.
+func (s *Server) respondJobAccepted(w http.ResponseWriter, j *job) {
+	location := "/api/v1/jobs/" + j.id
+	w.Header().Set("Location", location)
+	resp := j.toMap()
+	resp["location"] = location
+	s.respondJSON(w, http.StatusAccepted, resp)
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := s.jobs.list()
+	result := make([]map[string]interface{}, 0, len(jobs))
+	for _, j := range jobs {
+		result = append(result, j.toMap())
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"jobs":  result,
+		"total": len(result),
+	})
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	j := s.jobs.get(chi.URLParam(r, "id"))
+	if j == nil {
+		s.respondError(w, http.StatusNotFound, "Job not found", nil)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, j.toMap())
+}
+
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	j := s.jobs.get(chi.URLParam(r, "id"))
+	if j == nil {
+		s.respondError(w, http.StatusNotFound, "Job not found", nil)
+		return
+	}
+	if j.finished() {
+		s.respondError(w, http.StatusConflict, "Job already finished", nil)
+		return
+	}
+
+	j.cancel()
+
+	// Give the subprocess a moment to exit so the response usually shows
+	// the final state; clients poll for it otherwise.
+	status := http.StatusOK
+	select {
+	case <-j.done:
+	case <-time.After(2 * time.Second):
+		status = http.StatusAccepted
+	case <-r.Context().Done():
+		return
+	}
+	s.respondJSON(w, status, j.toMap())
+}
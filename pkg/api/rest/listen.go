@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// socketFileName is the REST socket's name, alongside the IPC socket.
+const socketFileName = "agentmgr-api.sock"
+
+// defaultSocketMode limits the REST socket to the user running the server.
+// Anyone who can connect has full API access when auth is off, so the file
+// permissions are the access control.
+const defaultSocketMode os.FileMode = 0o600
+
+// ErrUnsafeBind is returned by Start for a TCP address that is reachable
+// from other hosts while api.require_auth is off.
+var ErrUnsafeBind = errors.New("refusing to listen on a non-loopback address without api.require_auth")
+
+// SocketPath returns where the REST API's Unix socket lives on plat: next
+// to the IPC socket, or in the data directory on Windows, where the IPC
+// endpoint is a named pipe.
+func SocketPath(plat platform.Platform) string {
+	if plat.ID() == platform.Windows {
+		return filepath.Join(plat.GetDataDir(), socketFileName)
+	}
+	return filepath.Join(filepath.Dir(plat.GetIPCSocketPath()), socketFileName)
+}
+
+// listen opens the listener described by cfg. Errors (a port already in
+// use, a socket owned by another server) are returned to Start's caller.
+func (s *Server) listen(cfg ServerConfig) (net.Listener, error) {
+	if cfg.Network == "unix" {
+		return listenUnix(cfg.Address, cfg.SocketMode)
+	}
+
+	if !s.auth.Required() {
+		loopback, err := isLoopbackAddress(cfg.Address)
+		if err != nil {
+			return nil, err
+		}
+		if !loopback {
+			return nil, fmt.Errorf("%w: %q", ErrUnsafeBind, cfg.Address)
+		}
+	}
+
+	listener, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	return listener, nil
+}
+
+// listenUnix listens on a Unix socket at path with the given permissions
+// (0600 when zero), replacing a stale socket left by a crashed server.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if mode == 0 {
+		mode = defaultSocketMode
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		conn, err := net.DialTimeout("unix", path, 500*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another server is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// Windows ignores file modes; access is governed by the directory's ACL.
+	if runtime.GOOS == "windows" {
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen: %w", err)
+		}
+		return listener, nil
+	}
+
+	// The socket is bound inside a private directory and moved into place
+	// once it has its final permissions, so it is never reachable with the
+	// looser ones the umask gives it.
+	private, err := os.MkdirTemp(filepath.Dir(path), ".agentmgr-api-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(private)
+
+	bound := filepath.Join(private, "sock")
+	listener, err := net.Listen("unix", bound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(bound, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	if err := os.Rename(bound, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	return &socketListener{Listener: listener, path: path}, nil
+}
+
+// socketListener is a listener on a socket moved to path: it reports path
+// as its address and removes the socket file when closed, as a listener
+// bound directly at path would.
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.path, Net: "unix"}
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// isLoopbackAddress reports whether a host:port address only accepts
+// connections from this machine. An empty host means every interface.
+func isLoopbackAddress(addr string) (bool, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return true, nil
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback(), nil
+}
@@ -4,19 +4,27 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/auth"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/detector"
 	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
@@ -32,6 +40,20 @@ type Server struct {
 
 	router     chi.Router
 	httpServer *http.Server
+	listener   net.Listener
+	auth       *auth.Authenticator
+	events     *events.Bus
+	jobs       *jobManager
+
+	// streamsDone is closed when the server shuts down so that open event
+	// streams end and Shutdown does not wait on them.
+	streamsDone  chan struct{}
+	closeStreams func()
+
+	// lastDetection is the most recent detector run, reported by /status.
+	detectionMu     sync.Mutex
+	lastDetection   *detector.Result
+	lastDetectionAt time.Time
 
 	// State
 	startTime time.Time
@@ -59,9 +81,27 @@ func WithStartTime(t time.Time) Option {
 	}
 }
 
+// WithEventBus streams events from bus on /api/v1/events and publishes the
+// server's own install, update and uninstall events to it, so they are
+// shared with the gRPC server and the systray.
+func WithEventBus(bus *events.Bus) Option {
+	return func(s *Server) {
+		if bus != nil {
+			s.events = bus
+		}
+	}
+}
+
 // ServerConfig configures the REST server.
 type ServerConfig struct {
-	Address  string
+	// Network is "tcp" (the default) or "unix". For "unix", Address is the
+	// socket path; see SocketPath.
+	Network string
+	// Address is host:port for TCP. Non-loopback hosts need auth enabled.
+	Address string
+	// SocketMode is the Unix socket's file mode; 0600 when zero.
+	SocketMode os.FileMode
+
 	TLS      bool
 	CertFile string
 	KeyFile  string
@@ -79,15 +119,20 @@ func NewServer(
 	opts ...Option,
 ) *Server {
 	s := &Server{
-		config:    cfg,
-		platform:  plat,
-		store:     store,
-		detector:  det,
-		catalog:   cat,
-		installer: inst,
-		startTime: time.Now(),
-		version:   "dev",
-	}
+		config:      cfg,
+		platform:    plat,
+		store:       store,
+		detector:    det,
+		catalog:     cat,
+		installer:   inst,
+		startTime:   time.Now(),
+		version:     "dev",
+		auth:        auth.New(cfg, store),
+		events:      events.NewBus(),
+		jobs:        newJobManager(),
+		streamsDone: make(chan struct{}),
+	}
+	s.closeStreams = sync.OnceFunc(func() { close(s.streamsDone) })
 	for _, opt := range opts {
 		opt(s)
 	}
@@ -105,53 +150,90 @@ func (s *Server) setupRoutes() {
 	// middleware.RealIP is intentionally omitted: chi deprecated it for
 	// GHSA-3fxj-6jh8-hvhx (and friends) because it rewrites r.RemoteAddr
 	// from spoofable X-Forwarded-For / X-Real-IP / True-Client-IP headers.
-	// Auth here is APIKey, not IP, and the server binds loopback by default,
+	// Auth here is a bearer token, not IP, and the server binds loopback by default,
 	// so the unmodified TCP peer in r.RemoteAddr is what we want logged.
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 	r.Use(s.corsMiddleware)
 	r.Use(s.contentTypeMiddleware)
 
-	// API routes
+	// Every route but the event stream gets a request timeout.
+	timeout := middleware.Timeout(60 * time.Second)
+
+	// API routes. Every route names the token scope it needs.
+	read := s.requireScope(auth.ScopeRead)
+	update := s.requireScope(auth.ScopeUpdate)
+	admin := s.requireScope(auth.ScopeAdmin)
+
 	r.Route("/api/v1", func(r chi.Router) {
-		// Status
-		r.Get("/status", s.handleGetStatus)
-
-		// Agents
-		r.Route("/agents", func(r chi.Router) {
-			r.Get("/", s.handleListAgents)
-			r.Get("/{key}", s.handleGetAgent)
-			r.Post("/", s.handleInstallAgent)
-			r.Put("/{key}", s.handleUpdateAgent)
-			r.Delete("/{key}", s.handleUninstallAgent)
-		})
+		r.Use(s.authMiddleware)
 
-		// Catalog
-		r.Route("/catalog", func(r chi.Router) {
-			r.Get("/", s.handleListCatalog)
-			r.Get("/{agentID}", s.handleGetCatalogAgent)
-			r.Post("/refresh", s.handleRefreshCatalog)
-			r.Get("/search", s.handleSearchCatalog)
-		})
+		// Events
+		r.With(read).Get("/events", s.handleEvents)
+
+		r.Group(func(r chi.Router) {
+			r.Use(timeout)
+
+			// Status
+			r.With(read).Get("/status", s.handleGetStatus)
+
+			// Agents
+			r.Route("/agents", func(r chi.Router) {
+				r.With(read).Get("/", s.handleListAgents)
+				r.With(read).Get("/{key}", s.handleGetAgent)
+				r.With(update).Post("/", s.handleInstallAgent)
+				r.With(update).Put("/{key}", s.handleUpdateAgent)
+				r.With(admin).Delete("/{key}", s.handleUninstallAgent)
+			})
+
+			// Catalog
+			r.Route("/catalog", func(r chi.Router) {
+				r.With(read).Get("/", s.handleListCatalog)
+				r.With(read).Get("/{agentID}", s.handleGetCatalogAgent)
+				r.With(update).Post("/refresh", s.handleRefreshCatalog)
+				r.With(read).Get("/search", s.handleSearchCatalog)
+			})
+
+			// Updates
+			r.With(read).Get("/updates", s.handleCheckUpdates)
+			r.With(read).Get("/changelog/{agentID}", s.handleGetChangelog)
 
-		// Updates
-		r.Get("/updates", s.handleCheckUpdates)
-		r.Get("/changelog/{agentID}", s.handleGetChangelog)
+			// History
+			r.With(read).Get("/history", s.handleGetHistory)
+
+			// Jobs
+			r.Route("/jobs", func(r chi.Router) {
+				r.With(read).Get("/", s.handleListJobs)
+				r.With(read).Get("/{id}", s.handleGetJob)
+				r.With(update).Delete("/{id}", s.handleCancelJob)
+			})
+		})
 	})
 
-	// Health check
-	r.Get("/health", s.handleHealth)
+	r.Group(func(r chi.Router) {
+		r.Use(timeout)
+
+		// Health check
+		r.Get("/health", s.handleHealth)
 
-	// OpenAPI specification
-	r.Get("/openapi.yaml", s.handleOpenAPISpec)
-	r.Get("/openapi.json", s.handleOpenAPISpecJSON)
+		// OpenAPI specification
+		r.Get("/openapi.yaml", s.handleOpenAPISpec)
+		r.Get("/openapi.json", s.handleOpenAPISpecJSON)
+	})
 
 	s.router = r
 }
 
-// Start starts the REST server.
+// Start binds the listener described by cfg and serves on it in the
+// background. Bind failures, such as a port already in use, are returned
+// before Start does.
 func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
+	listener, err := s.listen(cfg)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
 	s.httpServer = &http.Server{
 		Addr:              cfg.Address,
 		Handler:           s.router,
@@ -161,13 +243,14 @@ func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 		IdleTimeout:       60 * time.Second,
 		MaxHeaderBytes:    1 << 20, // 1 MiB
 	}
+	s.httpServer.RegisterOnShutdown(s.closeStreams)
 
 	go func() {
 		var err error
 		if cfg.TLS && cfg.CertFile != "" && cfg.KeyFile != "" {
-			err = s.httpServer.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+			err = s.httpServer.ServeTLS(listener, cfg.CertFile, cfg.KeyFile)
 		} else {
-			err = s.httpServer.ListenAndServe()
+			err = s.httpServer.Serve(listener)
 		}
 		if err != nil && err != http.ErrServerClosed {
 			// Log error
@@ -179,14 +262,23 @@ func (s *Server) Start(ctx context.Context, cfg ServerConfig) error {
 
 // Stop gracefully stops the REST server.
 func (s *Server) Stop(ctx context.Context) error {
+	var err error
 	if s.httpServer != nil {
-		return s.httpServer.Shutdown(ctx)
+		err = s.httpServer.Shutdown(ctx)
 	}
-	return nil
+	// Running jobs are cancelled, which stops their subprocesses.
+	if jobErr := s.jobs.shutdown(ctx); err == nil {
+		err = jobErr
+	}
+	return err
 }
 
-// Address returns the server's listening address.
+// Address returns the server's listening address: the bound host:port
+// (with the real port when started on port 0) or the socket path.
 func (s *Server) Address() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Addr
 	}
@@ -210,6 +302,51 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// scopeContextKey carries the authenticated token's auth.Scope from
+// authMiddleware to requireScope.
+type scopeContextKey struct{}
+
+// authMiddleware rejects requests without a valid API token when
+// api.require_auth is set, and records the token's scope for requireScope.
+// The token is read from an "Authorization: Bearer" header, falling back
+// to X-API-Key.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := auth.BearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			token = r.Header.Get("X-API-Key")
+		}
+
+		scope, err := s.auth.Authenticate(r.Context(), token)
+		if errors.Is(err, auth.ErrNoToken) || errors.Is(err, auth.ErrInvalidToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="agentmgr"`)
+			s.respondError(w, http.StatusUnauthorized, "Unauthorized", err)
+			return
+		}
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, "Failed to check API token", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), scopeContextKey{}, scope)))
+	})
+}
+
+// requireScope returns middleware refusing requests whose token scope
+// (recorded by authMiddleware) does not include need.
+func (s *Server) requireScope(need auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, _ := r.Context().Value(scopeContextKey{}).(auth.Scope)
+			if !scope.Allows(need) {
+				s.respondError(w, http.StatusForbidden, "Forbidden", fmt.Errorf("token scope %q does not allow %s access", scope, need))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func (s *Server) contentTypeMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -262,9 +399,58 @@ func (s *Server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 		"last_catalog_refresh": lastCatalogRefresh,
 		"last_update_check":    lastUpdateCheck,
 		"version":              s.version,
+		"detection":            s.detectionStatus(),
 	})
 }
 
+// recordDetection keeps res as the last detection run for /status.
+func (s *Server) recordDetection(res *detector.Result) {
+	s.detectionMu.Lock()
+	defer s.detectionMu.Unlock()
+	s.lastDetection = res
+	s.lastDetectionAt = time.Now()
+}
+
+// detectionStatus describes the last detection run, with each strategy's
+// timing and error, or returns nil when the server has only served cached
+// results so far.
+func (s *Server) detectionStatus() map[string]interface{} {
+	s.detectionMu.Lock()
+	res, ranAt := s.lastDetection, s.lastDetectionAt
+	s.detectionMu.Unlock()
+
+	if res == nil {
+		return nil
+	}
+
+	strategies := make([]map[string]interface{}, len(res.Strategies))
+	failed := 0
+	for i, sr := range res.Strategies {
+		entry := map[string]interface{}{
+			"name":        sr.Name,
+			"method":      string(sr.Method),
+			"found":       sr.Found,
+			"duration_ms": sr.Duration.Milliseconds(),
+		}
+		if sr.Err != nil {
+			entry["error"] = sr.Err.Error()
+			failed++
+		}
+		if sr.TimedOut {
+			entry["timed_out"] = true
+		}
+		strategies[i] = entry
+	}
+
+	return map[string]interface{}{
+		"ran_at":        ranAt,
+		"duration_ms":   res.Duration.Milliseconds(),
+		"installations": len(res.Installations),
+		"failed":        failed,
+		"strategies":    strategies,
+	}
+}
+
 // getAgentsWithCache returns detected agents, preferring the on-disk
 // detection cache when it is fresher than cfg.Detection.CacheDuration.
 // Pass ?refresh=true on the inbound request to force a re-detection.
@@ -289,14 +475,17 @@ func (s *Server) getAgentsWithCache(r *http.Request, defs []catalog.AgentDef) ([
 		return nil, fmt.Errorf("detector not available")
 	}
 
-	agents, err := s.detector.DetectAll(ctx, defs)
+	res, err := s.detector.DetectAllWithResult(ctx, defs)
 	if err != nil {
 		return nil, err
 	}
+	s.recordDetection(res)
+	agents := res.Installations
 
 	// Best-effort save-back; failures here shouldn't block the response.
 	if cacheEnabled && s.store != nil {
 		_ = s.store.SaveDetectionCache(ctx, agents)
+		_ = s.store.SaveDetectionFingerprints(ctx, detector.Fingerprints(agents))
 	}
 
 	return agents, nil
@@ -400,18 +589,31 @@ func (s *Server) handleInstallAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Install the agent
-	result, err := s.installer.Install(ctx, *agentDef, methodDef, req.Global)
-	if err != nil {
-		s.respondError(w, http.StatusInternalServerError, "Installation failed", err)
-		return
-	}
+	// Install the agent in the background; the client polls the job
+	job := s.jobs.start(string(storage.UpdateActionInstall), agentDef.ID, func(ctx context.Context, output io.Writer) (map[string]interface{}, error) {
+		record := storage.RecordEvent(ctx, s.store, &storage.UpdateEvent{
+			AgentID:       agentDef.ID,
+			AgentName:     agentDef.Name,
+			Action:        storage.UpdateActionInstall,
+			InstallMethod: req.Method,
+		}, storage.UpdateStatusRunning)
+		progress := s.events.ProgressWriter(string(storage.UpdateActionInstall), agentDef.ID)
+		result, err := s.installer.Install(providers.WithProgressWriter(ctx, io.MultiWriter(output, progress)), *agentDef, methodDef, req.Global)
+		progress.Close()
+		err = jobError(ctx, err)
+		storage.FinishEvent(ctx, s.store, record, result.VersionString(), err)
+		if err != nil {
+			return nil, err
+		}
+		s.events.PublishInstallation(events.TypeAdded, result.Installation(req.Global))
 
-	s.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": fmt.Sprintf("Installed version %s", result.Version),
-		"version": result.Version,
+		return map[string]interface{}{
+			"message": fmt.Sprintf("Installed version %s", result.Version),
+			"version": result.Version.String(),
+		}, nil
 	})
+
+	s.respondJobAccepted(w, job)
 }
 
 func (s *Server) handleUpdateAgent(w http.ResponseWriter, r *http.Request) {
@@ -446,6 +648,13 @@ func (s *Server) handleUpdateAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Pinned agents are only updated when the caller explicitly asks with
+	// ?force=true, mirroring `agentmgr agent update --force`.
+	if pin := s.pinnedVersion(inst.AgentID); pin != "" && r.URL.Query().Get("force") != "true" {
+		s.respondError(w, http.StatusConflict, fmt.Sprintf("Agent is pinned to %s", pin), nil)
+		return
+	}
+
 	// Get agent definition from catalog
 	agentDef, err := s.catalog.GetAgent(ctx, inst.AgentID)
 	if err != nil {
@@ -460,21 +669,51 @@ func (s *Server) handleUpdateAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fromVersion := inst.InstalledVersion.String()
-
-	// Update the agent
-	result, err := s.installer.Update(ctx, inst, *agentDef, methodDef)
-	if err != nil {
-		s.respondError(w, http.StatusInternalServerError, "Update failed", err)
-		return
+	// A constraint pin limits the update to the newest release in range.
+	if constraints := s.versionConstraints(inst.AgentID); constraints != nil {
+		target, err := s.installer.ResolveVersion(ctx, methodDef, constraints)
+		if err != nil {
+			s.respondError(w, http.StatusConflict, "No version satisfies the agent's constraint", err)
+			return
+		}
+		// Never downgrade out of an installed version above the range
+		// unless asked to.
+		if !target.IsNewerThan(inst.InstalledVersion) && r.URL.Query().Get("force") != "true" {
+			s.respondError(w, http.StatusConflict, fmt.Sprintf("No version satisfying %s is newer than %s", constraints, inst.InstalledVersion), nil)
+			return
+		}
+		ctx = providers.WithTargetVersion(ctx, target.String())
 	}
 
-	s.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success":      true,
-		"message":      fmt.Sprintf("Updated from %s to %s", fromVersion, result.Version),
-		"from_version": fromVersion,
-		"to_version":   result.Version,
+	fromVersion := inst.InstalledVersion.String()
+	targetVersion := providers.TargetVersion(ctx)
+
+	// Update the agent in the background; the client polls the job
+	job := s.jobs.start(string(storage.UpdateActionUpdate), inst.AgentID, func(ctx context.Context, output io.Writer) (map[string]interface{}, error) {
+		if targetVersion != "" {
+			ctx = providers.WithTargetVersion(ctx, targetVersion)
+		}
+		record := storage.RecordEvent(ctx, s.store, storage.NewInstallationEvent(storage.UpdateActionUpdate, inst, targetVersion), storage.UpdateStatusRunning)
+		progress := s.events.ProgressWriter(string(storage.UpdateActionUpdate), inst.AgentID)
+		result, err := s.installer.Update(providers.WithProgressWriter(ctx, io.MultiWriter(output, progress)), inst, *agentDef, methodDef)
+		progress.Close()
+		err = jobError(ctx, err)
+		storage.FinishEvent(ctx, s.store, record, result.VersionString(), err)
+		if err != nil {
+			return nil, err
+		}
+		updated := *inst
+		updated.InstalledVersion = result.Version
+		s.events.PublishInstallation(events.TypeUpdated, &updated)
+
+		return map[string]interface{}{
+			"message":      fmt.Sprintf("Updated from %s to %s", fromVersion, result.Version),
+			"from_version": fromVersion,
+			"to_version":   result.Version.String(),
+		}, nil
 	})
+
+	s.respondJobAccepted(w, job)
 }
 
 func (s *Server) handleUninstallAgent(w http.ResponseWriter, r *http.Request) {
@@ -523,16 +762,25 @@ func (s *Server) handleUninstallAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Uninstall the agent
-	if err := s.installer.Uninstall(ctx, inst, methodDef); err != nil {
-		s.respondError(w, http.StatusInternalServerError, "Uninstallation failed", err)
-		return
-	}
+	// Uninstall the agent in the background; the client polls the job
+	job := s.jobs.start(string(storage.UpdateActionUninstall), inst.AgentID, func(ctx context.Context, output io.Writer) (map[string]interface{}, error) {
+		record := storage.RecordEvent(ctx, s.store, storage.NewInstallationEvent(storage.UpdateActionUninstall, inst, ""), storage.UpdateStatusRunning)
+		progress := s.events.ProgressWriter(string(storage.UpdateActionUninstall), inst.AgentID)
+		err := s.installer.Uninstall(providers.WithProgressWriter(ctx, io.MultiWriter(output, progress)), inst, methodDef)
+		progress.Close()
+		err = jobError(ctx, err)
+		storage.FinishEvent(ctx, s.store, record, "", err)
+		if err != nil {
+			return nil, err
+		}
+		s.events.PublishInstallation(events.TypeRemoved, inst)
 
-	s.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": "Agent uninstalled successfully",
+		return map[string]interface{}{
+			"message": "Agent uninstalled successfully",
+		}, nil
 	})
+
+	s.respondJobAccepted(w, job)
 }
 
 func (s *Server) handleListCatalog(w http.ResponseWriter, r *http.Request) {
@@ -650,6 +898,57 @@ func (s *Server) handleSearchCatalog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// defaultHistoryLimit is the number of history events returned when the
+// caller does not pass ?limit.
+const defaultHistoryLimit = 50
+
+func (s *Server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	events, err := s.store.GetUpdateHistory(ctx, query.Get("agent"), limit)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, "Failed to load history", err)
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		result = append(result, updateEventToMap(event))
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"events": result,
+		"total":  len(result),
+	})
+}
+
+func updateEventToMap(event *storage.UpdateEvent) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":           event.ID,
+		"agent_id":     event.AgentID,
+		"agent_name":   event.AgentName,
+		"action":       string(event.Action),
+		"method":       event.InstallMethod,
+		"from_version": event.FromVersion,
+		"to_version":   event.ToVersion,
+		"status":       string(event.Status),
+		"started_at":   event.StartedAt,
+	}
+	if event.ErrorMessage != "" {
+		m["error"] = event.ErrorMessage
+	}
+	if event.CompletedAt != nil {
+		m["completed_at"] = *event.CompletedAt
+	}
+	return m
+}
+
 func (s *Server) handleCheckUpdates(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -741,7 +1040,7 @@ func (s *Server) installationToMap(inst *agent.Installation) map[string]interfac
 		latestVer = inst.LatestVersion.String()
 	}
 
-	return map[string]interface{}{
+	m := map[string]interface{}{
 		"key":               inst.Key(),
 		"agent_id":          inst.AgentID,
 		"agent_name":        inst.AgentName,
@@ -757,6 +1056,30 @@ func (s *Server) installationToMap(inst *agent.Installation) map[string]interfac
 		"has_update":        inst.HasUpdate(),
 		"status":            string(inst.GetStatus()),
 	}
+	if pin := s.pinnedVersion(inst.AgentID); pin != "" {
+		m["status"] = string(agent.StatusPinned)
+		m["pinned_version"] = pin
+	}
+	return m
+}
+
+// pinnedVersion returns the exact version agentID is pinned to, or ""
+// when the agent is unpinned, only constrained to a range, or the server
+// was built without a config.
+func (s *Server) pinnedVersion(agentID string) string {
+	if s.config == nil || !s.config.IsAgentPinned(agentID) {
+		return ""
+	}
+	return s.config.GetPinnedVersion(agentID)
+}
+
+// versionConstraints returns the range constraint configured for agentID,
+// or nil when there is none.
+func (s *Server) versionConstraints(agentID string) agent.VersionConstraints {
+	if s.config == nil {
+		return nil
+	}
+	return s.config.GetVersionConstraints(agentID)
 }
 
 func (s *Server) catalogAgentToMap(def *catalog.AgentDef) map[string]interface{} {
@@ -846,8 +1169,8 @@ paths:
     post:
       summary: Install an agent
       responses:
-        "200":
-          description: Agent installed
+        "202":
+          description: Installation job started
   /agents/{key}:
     get:
       summary: Get agent details
@@ -863,13 +1186,13 @@ paths:
     put:
       summary: Update an agent
       responses:
-        "200":
-          description: Agent updated
+        "202":
+          description: Update job started
     delete:
       summary: Uninstall an agent
       responses:
-        "200":
-          description: Agent uninstalled
+        "202":
+          description: Uninstall job started
   /catalog:
     get:
       summary: List catalog agents
@@ -923,4 +1246,21 @@ paths:
       responses:
         "200":
           description: Changelog
+  /jobs:
+    get:
+      summary: List jobs
+      responses:
+        "200":
+          description: Jobs
+  /jobs/{id}:
+    get:
+      summary: Get a job
+      responses:
+        "200":
+          description: Job status and output
+    delete:
+      summary: Cancel a job
+      responses:
+        "200":
+          description: Job cancelled
 `
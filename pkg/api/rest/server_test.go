@@ -1,17 +1,29 @@
 package rest
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/api/auth"
+	"github.com/kevinelliott/agentmanager/pkg/api/events"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 	"github.com/kevinelliott/agentmanager/pkg/storage"
 )
@@ -49,6 +61,12 @@ type mockStore struct {
 	detSaveCount   int
 	detCacheErr    error
 	detSaveErrHook func([]*agent.Installation) error
+
+	// update history returned by GetUpdateHistory, newest first
+	events []*storage.UpdateEvent
+
+	// settings backs GetSetting/SetSetting (used by scoped API tokens)
+	settings map[string]string
 }
 
 func (m *mockStore) Initialize(ctx context.Context) error { return nil }
@@ -67,7 +85,13 @@ func (m *mockStore) SaveUpdateEvent(ctx context.Context, event *storage.UpdateEv
 	return nil
 }
 func (m *mockStore) GetUpdateHistory(ctx context.Context, agentID string, limit int) ([]*storage.UpdateEvent, error) {
-	return nil, nil
+	var out []*storage.UpdateEvent
+	for _, e := range m.events {
+		if (agentID == "" || e.AgentID == agentID) && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
 }
 func (m *mockStore) GetCatalogCache(ctx context.Context) ([]byte, string, time.Time, error) {
 	return m.catalogData, "", time.Now(), nil
@@ -76,9 +100,20 @@ func (m *mockStore) SaveCatalogCache(ctx context.Context, data []byte, etag stri
 	m.catalogData = data
 	return nil
 }
-func (m *mockStore) GetSetting(ctx context.Context, key string) (string, error) { return "", nil }
-func (m *mockStore) SetSetting(ctx context.Context, key, value string) error    { return nil }
-func (m *mockStore) DeleteSetting(ctx context.Context, key string) error        { return nil }
+func (m *mockStore) GetSetting(ctx context.Context, key string) (string, error) {
+	return m.settings[key], nil
+}
+func (m *mockStore) SetSetting(ctx context.Context, key, value string) error {
+	if m.settings == nil {
+		m.settings = make(map[string]string)
+	}
+	m.settings[key] = value
+	return nil
+}
+func (m *mockStore) DeleteSetting(ctx context.Context, key string) error {
+	delete(m.settings, key)
+	return nil
+}
 func (m *mockStore) SaveDetectionCache(ctx context.Context, installations []*agent.Installation) error {
 	m.detSaveCount++
 	if m.detSaveErrHook != nil {
@@ -98,6 +133,18 @@ func (m *mockStore) ClearDetectionCache(ctx context.Context) error { return nil
 func (m *mockStore) GetDetectionCacheTime(ctx context.Context) (time.Time, error) {
 	return time.Time{}, nil
 }
+func (m *mockStore) SaveRegistryEvents(ctx context.Context, events []*storage.RegistryEventRecord) error {
+	return nil
+}
+func (m *mockStore) GetRegistryEvents(ctx context.Context, agentID string, limit int) ([]*storage.RegistryEventRecord, error) {
+	return nil, nil
+}
+func (m *mockStore) SaveDetectionFingerprints(ctx context.Context, fingerprints map[string]string) error {
+	return nil
+}
+func (m *mockStore) GetDetectionFingerprints(ctx context.Context) (map[string]string, error) {
+	return nil, nil
+}
 func (m *mockStore) SetLastUpdateCheckTime(ctx context.Context, t time.Time) error { return nil }
 func (m *mockStore) GetLastUpdateCheckTime(ctx context.Context) (time.Time, error) {
 	return time.Time{}, nil
@@ -221,6 +268,69 @@ func TestGetStatusEndpoint(t *testing.T) {
 	if version, _ := resp["version"].(string); version != "dev" {
 		t.Errorf("version = %q, want %q", version, "dev")
 	}
+	if resp["detection"] != nil {
+		t.Errorf("detection = %v, want null before any detection ran", resp["detection"])
+	}
+}
+
+// stubStrategy is a detection strategy with a fixed outcome.
+type stubStrategy struct {
+	name          string
+	installations []*agent.Installation
+	err           error
+}
+
+func (s *stubStrategy) Name() string                          { return s.name }
+func (s *stubStrategy) Method() agent.InstallMethod           { return agent.InstallMethod(s.name) }
+func (s *stubStrategy) IsApplicable(p platform.Platform) bool { return true }
+func (s *stubStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	return s.installations, s.err
+}
+
+func TestGetStatusReportsDetection(t *testing.T) {
+	det := &detector.Detector{}
+	det.RegisterStrategy(&stubStrategy{name: "npm", installations: []*agent.Installation{
+		{AgentID: "claude-code", AgentName: "Claude Code", Method: agent.InstallMethodNPM, ExecutablePath: "/usr/local/bin/claude"},
+	}})
+	det.RegisterStrategy(&stubStrategy{name: "brew", err: errors.New("brew crashed")})
+
+	cfg := newTestConfig()
+	store := &mockStore{}
+	server := NewServer(cfg, &mockPlatform{}, store, det, catalog.NewManager(cfg, store), nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/status?refresh=true", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if agentCount, _ := resp["agent_count"].(float64); agentCount != 1 {
+		t.Errorf("agent_count = %v, want 1", agentCount)
+	}
+	detection, ok := resp["detection"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("detection = %v, want an object", resp["detection"])
+	}
+	if detection["installations"] != float64(1) || detection["failed"] != float64(1) {
+		t.Errorf("detection = %v, want 1 installation and 1 failure", detection)
+	}
+	strategies, _ := detection["strategies"].([]interface{})
+	if len(strategies) != 2 {
+		t.Fatalf("strategies = %v, want 2", detection["strategies"])
+	}
+	npm := strategies[0].(map[string]interface{})
+	if npm["name"] != "npm" || npm["found"] != float64(1) || npm["error"] != nil {
+		t.Errorf("strategies[0] = %v, want npm found 1", npm)
+	}
+	brew := strategies[1].(map[string]interface{})
+	if brew["name"] != "brew" || brew["error"] != "brew crashed" {
+		t.Errorf("strategies[1] = %v, want brew failing with its error", brew)
+	}
 }
 
 func TestListCatalogEndpoint(t *testing.T) {
@@ -551,7 +661,7 @@ func TestServerStartStop(t *testing.T) {
 	server := setupTestServer()
 
 	ctx := context.Background()
-	cfg := ServerConfig{Address: ":0"} // Use random port
+	cfg := ServerConfig{Address: "127.0.0.1:0"} // Use random port
 
 	if err := server.Start(ctx, cfg); err != nil {
 		t.Fatalf("Start() error = %v", err)
@@ -574,7 +684,7 @@ func TestServerAddress(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	cfg := ServerConfig{Address: ":8888"}
+	cfg := ServerConfig{Address: "127.0.0.1:8888"}
 
 	if err := server.Start(ctx, cfg); err != nil {
 		t.Fatalf("Start() error = %v", err)
@@ -583,8 +693,114 @@ func TestServerAddress(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 
-	if server.Address() != ":8888" {
-		t.Errorf("Address() = %q, want %q", server.Address(), ":8888")
+	if server.Address() != "127.0.0.1:8888" {
+		t.Errorf("Address() = %q, want %q", server.Address(), "127.0.0.1:8888")
+	}
+}
+
+func TestServerStartReturnsListenError(t *testing.T) {
+	ctx := context.Background()
+
+	first := setupTestServer()
+	if err := first.Start(ctx, ServerConfig{Address: "127.0.0.1:0"}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer first.Stop(ctx)
+
+	second := setupTestServer()
+	if err := second.Start(ctx, ServerConfig{Address: first.Address()}); err == nil {
+		second.Stop(ctx)
+		t.Fatal("Start() on a port in use should return an error")
+	}
+}
+
+func TestServerRefusesNonLoopbackWithoutAuth(t *testing.T) {
+	ctx := context.Background()
+
+	for _, addr := range []string{":0", "0.0.0.0:0", "[::]:0"} {
+		server := setupTestServer()
+		err := server.Start(ctx, ServerConfig{Address: addr})
+		if !errors.Is(err, ErrUnsafeBind) {
+			server.Stop(ctx)
+			t.Errorf("Start(%q) error = %v, want ErrUnsafeBind", addr, err)
+		}
+	}
+
+	server := setupTestServer()
+	server.config.API.RequireAuth = true
+	server.config.API.AuthToken = "secret"
+	if err := server.Start(ctx, ServerConfig{Address: ":0"}); err != nil {
+		t.Fatalf("Start() with auth enabled error = %v", err)
+	}
+	server.Stop(ctx)
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are not enforced on Windows")
+	}
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	// A leftover socket file from a crashed server is replaced.
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := setupTestServer()
+	if err := server.Start(ctx, ServerConfig{Network: "unix", Address: path}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer server.Stop(ctx)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+	if server.Address() != path {
+		t.Errorf("Address() = %q, want %q", server.Address(), path)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://agentmgr/health")
+	if err != nil {
+		t.Fatalf("GET /health over socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	// A second server must not steal a live socket.
+	other := setupTestServer()
+	if err := other.Start(ctx, ServerConfig{Network: "unix", Address: path}); err == nil {
+		other.Stop(ctx)
+		t.Error("Start() on a live socket should return an error")
+	}
+
+	// Stopping removes the socket, and binding it left nothing behind.
+	server.Stop(ctx)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket still present after Stop(): %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("socket directory holds %d entries after Stop(), want 0", len(entries))
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	got := SocketPath(&mockPlatform{})
+	want := filepath.Join(filepath.Dir((&mockPlatform{}).GetIPCSocketPath()), "agentmgr-api.sock")
+	if got != want {
+		t.Errorf("SocketPath() = %q, want %q", got, want)
 	}
 }
 
@@ -849,3 +1065,555 @@ func TestGetAgentsWithCache_CacheDisabled(t *testing.T) {
 		t.Fatal("expected error: disabled cache should bypass and hit nil detector")
 	}
 }
+
+func TestUpdateAgentEndpoint_RefusesPinnedAgent(t *testing.T) {
+	server := setupTestServer()
+	server.installer = installer.NewManager(&mockPlatform{})
+	server.config.Detection.CacheEnabled = true
+	server.config.Detection.CacheDuration = time.Hour
+	server.config.Agents = map[string]config.AgentConfig{
+		"claude-code": {PinnedVersion: "1.0.0"},
+	}
+
+	inst := makeCachedInstallation("claude-code")
+	store := server.store.(*mockStore)
+	store.detCache = []*agent.Installation{inst}
+	store.detCachedAt = time.Now()
+
+	req := httptest.NewRequest("PUT", "/api/v1/agents/"+inst.Key(), nil)
+	w := httptest.NewRecorder()
+
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Status = %d, want %d; body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "pinned to 1.0.0") {
+		t.Errorf("body should mention the pin, got %s", w.Body.String())
+	}
+}
+
+func TestUpdateAgentEndpoint_RefusesDowngradeIntoRange(t *testing.T) {
+	server := setupTestServer()
+	m, p := newRangeInstaller()
+	server.installer = m
+	server.config.Detection.CacheEnabled = true
+	server.config.Detection.CacheDuration = time.Hour
+	server.config.Agents = map[string]config.AgentConfig{
+		"claude-code": {PinnedVersion: "^2.1"},
+	}
+
+	// 3.0.0 is installed, above everything the constraint allows.
+	inst := makeCachedInstallation("claude-code")
+	inst.InstalledVersion = agent.MustParseVersion("3.0.0")
+	store := server.store.(*mockStore)
+	store.detCache = []*agent.Installation{inst}
+	store.detCachedAt = time.Now()
+
+	req := httptest.NewRequest("PUT", "/api/v1/agents/"+inst.Key(), nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Status = %d, want %d; body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "newer than 3.0.0") {
+		t.Errorf("body should say nothing in range is newer, got %s", w.Body.String())
+	}
+	if len(p.targets) != 0 {
+		t.Errorf("Update called with %v, want no downgrade", p.targets)
+	}
+
+	// With force the newest version in range is installed.
+	req = httptest.NewRequest("PUT", "/api/v1/agents/"+inst.Key()+"?force=true", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("forced Status = %d, want %d; body=%s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+}
+
+func TestInstallationToMap_Pinned(t *testing.T) {
+	server := setupTestServer()
+	server.config.Agents = map[string]config.AgentConfig{
+		"pinned-agent": {PinnedVersion: "1.0.0"},
+	}
+
+	m := server.installationToMap(makeCachedInstallation("pinned-agent"))
+	if m["status"] != string(agent.StatusPinned) {
+		t.Errorf("status = %v, want %q", m["status"], agent.StatusPinned)
+	}
+	if m["pinned_version"] != "1.0.0" {
+		t.Errorf("pinned_version = %v, want 1.0.0", m["pinned_version"])
+	}
+
+	m = server.installationToMap(makeCachedInstallation("other-agent"))
+	if _, ok := m["pinned_version"]; ok {
+		t.Error("unpinned agent should not report pinned_version")
+	}
+}
+
+func TestGetHistoryEndpoint(t *testing.T) {
+	server := setupTestServer()
+	store := server.store.(*mockStore)
+	completed := time.Now()
+	store.events = []*storage.UpdateEvent{
+		{ID: 3, AgentID: "aider", AgentName: "Aider", Action: storage.UpdateActionUpdate, InstallMethod: "pipx", FromVersion: "0.84.0", ToVersion: "0.85.0", Status: storage.UpdateStatusCompleted, CompletedAt: &completed},
+		{ID: 2, AgentID: "claude-code", AgentName: "Claude Code", Action: storage.UpdateActionInstall, InstallMethod: "npm", ToVersion: "2.1.0", Status: storage.UpdateStatusFailed, ErrorMessage: "EACCES"},
+		{ID: 1, AgentID: "aider", AgentName: "Aider", Action: storage.UpdateActionInstall, InstallMethod: "pipx", ToVersion: "0.84.0", Status: storage.UpdateStatusCompleted},
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/history?agent=aider", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Events []map[string]interface{} `json:"events"`
+		Total  int                      `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || len(resp.Events) != 2 {
+		t.Fatalf("total = %d, events = %d, want 2", resp.Total, len(resp.Events))
+	}
+	first := resp.Events[0]
+	if first["action"] != "update" || first["from_version"] != "0.84.0" || first["status"] != "completed" {
+		t.Errorf("first event = %v, want the completed aider update", first)
+	}
+	if _, ok := first["completed_at"]; !ok {
+		t.Error("completed event should carry completed_at")
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/history?limit=1", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 1 {
+		t.Errorf("total with limit=1 = %d, want 1", resp.Total)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	server := setupTestServer()
+	server.config.API.RequireAuth = true
+	server.config.API.AuthToken = "s3cret"
+
+	mutating := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"POST", "/api/v1/agents", `{"agent_id":"claude-code","method":"npm"}`},
+		{"PUT", "/api/v1/agents/claude-code:npm:/usr/local/bin/claude", `{}`},
+		{"DELETE", "/api/v1/agents/claude-code:npm:/usr/local/bin/claude", ""},
+		{"POST", "/api/v1/catalog/refresh", ""},
+	}
+
+	for _, ep := range mutating {
+		t.Run(ep.method+" "+ep.path, func(t *testing.T) {
+			for name, header := range map[string]string{
+				"no token":    "",
+				"wrong token": "Bearer guess",
+				"wrong shape": "s3cret",
+			} {
+				req := httptest.NewRequest(ep.method, ep.path, strings.NewReader(ep.body))
+				if header != "" {
+					req.Header.Set("Authorization", header)
+				}
+				w := httptest.NewRecorder()
+				server.router.ServeHTTP(w, req)
+
+				if w.Code != http.StatusUnauthorized {
+					t.Errorf("%s: Status = %d, want %d", name, w.Code, http.StatusUnauthorized)
+				}
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("%s: missing WWW-Authenticate header", name)
+				}
+			}
+		})
+	}
+
+	t.Run("bearer token accepted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("X-API-Key accepted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("health stays open", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/health", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("auth disabled", func(t *testing.T) {
+		server.config.API.RequireAuth = false
+		defer func() { server.config.API.RequireAuth = true }()
+
+		req := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestScopedTokens(t *testing.T) {
+	server := setupTestServer()
+	server.config.API.RequireAuth = true
+	ctx := context.Background()
+
+	secrets := map[auth.Scope]string{}
+	for _, scope := range []auth.Scope{auth.ScopeRead, auth.ScopeUpdate, auth.ScopeAdmin} {
+		secret, err := auth.CreateToken(ctx, server.store, string(scope)+"-token", scope)
+		if err != nil {
+			t.Fatalf("CreateToken(%s) error = %v", scope, err)
+		}
+		secrets[scope] = secret
+	}
+
+	// Each request is checked only for whether it gets past the scope
+	// check; what the handler then does doesn't matter here.
+	requests := []struct {
+		method string
+		path   string
+		need   auth.Scope
+	}{
+		{"GET", "/api/v1/catalog", auth.ScopeRead},
+		{"GET", "/api/v1/history", auth.ScopeRead},
+		{"POST", "/api/v1/agents", auth.ScopeUpdate},
+		{"PUT", "/api/v1/agents/claude-code:npm:claude", auth.ScopeUpdate},
+		{"DELETE", "/api/v1/agents/claude-code:npm:claude", auth.ScopeAdmin},
+	}
+
+	for _, req := range requests {
+		for scope, secret := range secrets {
+			t.Run(fmt.Sprintf("%s %s as %s", req.method, req.path, scope), func(t *testing.T) {
+				r := httptest.NewRequest(req.method, req.path, strings.NewReader("{}"))
+				r.Header.Set("Authorization", "Bearer "+secret)
+				w := httptest.NewRecorder()
+				server.router.ServeHTTP(w, r)
+
+				forbidden := w.Code == http.StatusForbidden
+				if want := !scope.Allows(req.need); forbidden != want {
+					t.Errorf("Status = %d, forbidden = %v, want %v", w.Code, forbidden, want)
+				}
+				if w.Code == http.StatusUnauthorized {
+					t.Errorf("scoped token was not recognized")
+				}
+			})
+		}
+	}
+
+	t.Run("revoked token", func(t *testing.T) {
+		if err := auth.RevokeToken(ctx, server.store, "admin-token"); err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+		r.Header.Set("Authorization", "Bearer "+secrets[auth.ScopeAdmin])
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestEventsStream(t *testing.T) {
+	bus := events.NewBus()
+	cfg := newTestConfig()
+	server := NewServer(cfg, &mockPlatform{}, &mockStore{}, nil, nil, nil, WithEventBus(bus))
+
+	ctx := context.Background()
+	if err := server.Start(ctx, ServerConfig{Address: "127.0.0.1:0"}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	resp, err := http.Get("http://" + server.Address() + "/api/v1/events?types=added,progress")
+	if err != nil {
+		t.Fatalf("GET /api/v1/events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for bus.Subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("event stream never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Filtered out by ?types=.
+	bus.PublishInstallation(events.TypeRemoved, &agent.Installation{AgentID: "aider"})
+	bus.PublishInstallation(events.TypeAdded, &agent.Installation{
+		AgentID:          "claude-code",
+		Method:           agent.MethodNPM,
+		InstalledVersion: agent.MustParseVersion("1.0.0"),
+	})
+	bus.Publish(events.Event{Type: events.TypeProgress, AgentID: "claude-code", Operation: "install", Message: "added 1 package"})
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, map[string]interface{}) {
+		t.Helper()
+		var name string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				var data map[string]interface{}
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data); err != nil {
+					t.Fatalf("bad event data %q: %v", line, err)
+				}
+				return name, data
+			}
+		}
+	}
+
+	name, data := readEvent()
+	if name != "added" || data["agent_id"] != "claude-code" {
+		t.Errorf("first event = %s %v, want added claude-code", name, data)
+	}
+	if inst, ok := data["installation"].(map[string]interface{}); !ok || inst["installed_version"] != "1.0.0" {
+		t.Errorf("installation = %v", data["installation"])
+	}
+
+	name, data = readEvent()
+	if name != "progress" || data["message"] != "added 1 package" || data["operation"] != "install" {
+		t.Errorf("second event = %s %v", name, data)
+	}
+
+	// Stopping the server must end open streams rather than wait on them.
+	stopCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := server.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+// setupJobTestServer returns a server whose catalog has a "fake" agent
+// installed natively by running command.
+func setupJobTestServer(t *testing.T, command string) *Server {
+	t.Helper()
+
+	cat := createTestCatalog()
+	cat.Agents["fake"] = catalog.AgentDef{
+		ID:   "fake",
+		Name: "Fake Agent",
+		InstallMethods: map[string]catalog.InstallMethodDef{
+			"native": {
+				Method:    "native",
+				Command:   command,
+				Platforms: []string{"darwin", "linux", "windows"},
+			},
+		},
+	}
+	catalogJSON, _ := json.Marshal(cat)
+
+	cfg := newTestConfig()
+	store := &mockStore{catalogData: catalogJSON}
+	server := NewServer(cfg, &mockPlatform{}, store, nil, catalog.NewManager(cfg, store), installer.NewManager(&mockPlatform{}))
+	t.Cleanup(func() { server.Stop(context.Background()) })
+	return server
+}
+
+// startInstallJob posts an install of the fake agent and returns the job.
+func startInstallJob(t *testing.T, server *Server) map[string]interface{} {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/api/v1/agents", strings.NewReader(`{"agent_id":"fake","method":"native"}`))
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Status = %d, want %d; body=%s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/v1/jobs/"+job["id"].(string) {
+		t.Errorf("Location = %q, want the job URL", loc)
+	}
+	if job["status"] != "running" || job["operation"] != "install" || job["agent_id"] != "fake" {
+		t.Errorf("job = %v", job)
+	}
+	return job
+}
+
+func getJob(t *testing.T, server *Server, id string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/api/v1/jobs/"+id, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	var job map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &job)
+	return w.Code, job
+}
+
+func TestInstallJob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	server := setupJobTestServer(t, "echo step one; echo step two")
+	id := startInstallJob(t, server)["id"].(string)
+
+	var job map[string]interface{}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, got := getJob(t, server, id)
+		if code != http.StatusOK {
+			t.Fatalf("GET job status = %d", code)
+		}
+		if got["status"] != "running" {
+			job = got
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if job["status"] != "succeeded" {
+		t.Fatalf("job = %v, want succeeded", job)
+	}
+	if !strings.Contains(job["output"].(string), "step two") {
+		t.Errorf("output = %q, want the command's output", job["output"])
+	}
+	if _, ok := job["result"].(map[string]interface{}); !ok {
+		t.Errorf("result = %v, want an object", job["result"])
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/jobs", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	var list map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if list["total"] != float64(1) {
+		t.Errorf("GET /jobs = %s, want one job", w.Body.String())
+	}
+
+	if code, _ := getJob(t, server, "missing"); code != http.StatusNotFound {
+		t.Errorf("GET unknown job status = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestJobFinishRolledBack(t *testing.T) {
+	verr := &installer.VerificationError{AgentID: "fake", FromVersion: agent.MustParseVersion("1.0.0"), Err: errors.New("version is still 1.0.0")}
+
+	j := &job{status: jobRunning}
+	j.finish(nil, verr)
+	if j.status != jobRolledBack || !strings.Contains(j.err, "rolled back to 1.0.0") {
+		t.Errorf("status = %q, err = %q, want rolled_back", j.status, j.err)
+	}
+
+	verr.RollbackErr = errors.New("reinstall failed")
+	j = &job{status: jobRunning}
+	j.finish(nil, verr)
+	if j.status != jobFailed {
+		t.Errorf("status = %q after a failed rollback, want failed", j.status)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+
+	server := setupJobTestServer(t, "echo started; sleep 30")
+	id := startInstallJob(t, server)["id"].(string)
+
+	cancel := func() (int, map[string]interface{}) {
+		req := httptest.NewRequest("DELETE", "/api/v1/jobs/"+id, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		var job map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &job)
+		return w.Code, job
+	}
+
+	code, job := cancel()
+	if code != http.StatusOK {
+		t.Fatalf("DELETE status = %d, want %d", code, http.StatusOK)
+	}
+	if job["status"] != "cancelled" {
+		t.Errorf("job = %v, want cancelled", job)
+	}
+
+	if code, _ := cancel(); code != http.StatusConflict {
+		t.Errorf("second DELETE status = %d, want %d", code, http.StatusConflict)
+	}
+}
+
+// rangeProvider handles npm and lists a fixed set of versions, so version
+// constraints resolve without a registry. Update records its target and
+// fails, leaving nothing to re-detect.
+type rangeProvider struct {
+	versions []agent.Version
+	targets  []string
+}
+
+func (p *rangeProvider) Name() string      { return "range" }
+func (p *rangeProvider) Methods() []string { return []string{"npm"} }
+func (p *rangeProvider) IsAvailable() bool { return true }
+func (p *rangeProvider) Install(context.Context, catalog.AgentDef, catalog.InstallMethodDef, bool) (*providers.Result, error) {
+	return &providers.Result{}, nil
+}
+func (p *rangeProvider) Update(ctx context.Context, _ *agent.Installation, _ catalog.AgentDef, _ catalog.InstallMethodDef) (*providers.Result, error) {
+	p.targets = append(p.targets, providers.TargetVersion(ctx))
+	return nil, errors.New("range provider does not update")
+}
+func (p *rangeProvider) Uninstall(context.Context, *agent.Installation, catalog.InstallMethodDef) error {
+	return nil
+}
+func (p *rangeProvider) GetLatestVersion(context.Context, catalog.InstallMethodDef) (agent.Version, error) {
+	return p.versions[len(p.versions)-1], nil
+}
+func (p *rangeProvider) ListVersions(context.Context, catalog.InstallMethodDef) ([]agent.Version, error) {
+	return p.versions, nil
+}
+
+func newRangeInstaller() (*installer.Manager, *rangeProvider) {
+	p := &rangeProvider{versions: []agent.Version{
+		agent.MustParseVersion("2.0.0"), agent.MustParseVersion("2.1.0"), agent.MustParseVersion("2.2.0"),
+	}}
+	m := installer.NewManager(&mockPlatform{})
+	m.RegisterProvider(p)
+	return m, p
+}
@@ -86,6 +86,18 @@ func (m *mockStore) ClearDetectionCache(ctx context.Context) error {
 func (m *mockStore) GetDetectionCacheTime(ctx context.Context) (time.Time, error) {
 	return time.Time{}, nil
 }
+func (m *mockStore) SaveRegistryEvents(ctx context.Context, events []*storage.RegistryEventRecord) error {
+	return nil
+}
+func (m *mockStore) GetRegistryEvents(ctx context.Context, agentID string, limit int) ([]*storage.RegistryEventRecord, error) {
+	return nil, nil
+}
+func (m *mockStore) SaveDetectionFingerprints(ctx context.Context, fingerprints map[string]string) error {
+	return nil
+}
+func (m *mockStore) GetDetectionFingerprints(ctx context.Context) (map[string]string, error) {
+	return nil, nil
+}
 func (m *mockStore) SetLastUpdateCheckTime(ctx context.Context, t time.Time) error {
 	return nil
 }
@@ -56,6 +56,24 @@ type InstallMethodDef struct {
 	GlobalFlag   string            `json:"global_flag,omitempty"`
 	PreReqs      []string          `json:"prereqs,omitempty"`
 	Metadata     map[string]string `json:"metadata,omitempty"`
+	Release      *ReleaseDef       `json:"release,omitempty"`
+}
+
+// ReleaseDef describes how the binary method installs an agent from the
+// assets of its GitHub releases.
+type ReleaseDef struct {
+	// Repo is the GitHub repository, as "owner/repo".
+	Repo string `json:"repo"`
+	// Assets maps a platform to the name of its release asset. Keys are
+	// "<os>_<arch>" (such as "darwin_arm64"), "<os>" or "default", and
+	// names may use the {version}, {tag}, {os} and {arch} placeholders.
+	Assets map[string]string `json:"assets"`
+	// Checksums names the release asset listing SHA-256 sums of the other
+	// assets. It defaults to "checksums.txt" and may use the placeholders.
+	Checksums string `json:"checksums,omitempty"`
+	// Binaries are the executables installed from the asset. They default
+	// to the agent's detection executables.
+	Binaries []string `json:"binaries,omitempty"`
 }
 
 // DetectionDef defines how to detect an agent.
@@ -64,6 +82,10 @@ type DetectionDef struct {
 	VersionCmd   string                  `json:"version_cmd"`
 	VersionRegex string                  `json:"version_regex,omitempty"`
 	Signatures   map[string]SignatureDef `json:"signatures,omitempty"`
+	// SmokeTestCmd is a shell command run after an update, alongside
+	// VersionCmd, to check the agent still works. A non-zero exit rolls
+	// the update back.
+	SmokeTestCmd string `json:"smoke_test_cmd,omitempty"`
 }
 
 // SignatureDef defines detection signatures for a specific install method.
@@ -3,6 +3,8 @@ package config
 
 import (
 	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
 )
 
 // DefaultCatalogRefreshInterval is the minimum cadence for checking the
@@ -47,6 +49,23 @@ type DetectionConfig struct {
 
 	// CacheEnabled enables caching of detected agents
 	CacheEnabled bool `yaml:"cache_enabled" json:"cache_enabled" mapstructure:"cache_enabled"`
+
+	// StrategyTimeout bounds each detection strategy (npm, brew, pip, ...).
+	// A strategy still running when it passes is reported as timed out and
+	// detection returns without it. Zero means no limit.
+	StrategyTimeout time.Duration `yaml:"strategy_timeout" json:"strategy_timeout" mapstructure:"strategy_timeout"`
+
+	// StrategyTimeouts overrides StrategyTimeout for individual strategies,
+	// keyed by strategy name.
+	StrategyTimeouts map[string]time.Duration `yaml:"strategy_timeouts" json:"strategy_timeouts" mapstructure:"strategy_timeouts"`
+}
+
+// TimeoutFor returns the deadline for the named detection strategy.
+func (d DetectionConfig) TimeoutFor(strategy string) time.Duration {
+	if timeout, ok := d.StrategyTimeouts[strategy]; ok {
+		return timeout
+	}
+	return d.StrategyTimeout
 }
 
 // CatalogConfig contains catalog-related settings.
@@ -111,6 +130,14 @@ type APIConfig struct {
 	// RESTPort is the port for the REST server
 	RESTPort int `yaml:"rest_port" json:"rest_port" mapstructure:"rest_port"`
 
+	// BindAddress is the host the REST and gRPC servers listen on. Anything
+	// other than a loopback address requires RequireAuth.
+	BindAddress string `yaml:"bind_address" json:"bind_address" mapstructure:"bind_address"`
+
+	// RESTSocket serves the REST API on a Unix domain socket, readable only
+	// by the current user, instead of on RESTPort
+	RESTSocket bool `yaml:"rest_socket" json:"rest_socket" mapstructure:"rest_socket"`
+
 	// RequireAuth requires authentication for API calls
 	RequireAuth bool `yaml:"require_auth" json:"require_auth" mapstructure:"require_auth"`
 
@@ -162,7 +189,9 @@ type AgentConfig struct {
 	// Hidden hides this agent from listings
 	Hidden bool `yaml:"hidden" json:"hidden" mapstructure:"hidden"`
 
-	// PinnedVersion prevents updates past this version
+	// PinnedVersion holds either an exact version, which freezes the agent,
+	// or a constraint such as "^2.1" or ">=1.0, <2.0", which lets updates
+	// move to the newest registry version that satisfies it.
 	PinnedVersion string `yaml:"pinned_version" json:"pinned_version" mapstructure:"pinned_version"`
 
 	// Disabled prevents detection and management
@@ -181,6 +210,7 @@ func Default() *Config {
 			CacheDuration:            time.Hour,
 			UpdateCheckCacheDuration: 15 * time.Minute,
 			CacheEnabled:             true,
+			StrategyTimeout:          30 * time.Second,
 		},
 		Updates: UpdateConfig{
 			AutoCheck:     true,
@@ -201,6 +231,8 @@ func Default() *Config {
 			GRPCPort:    50051,
 			EnableREST:  false,
 			RESTPort:    8080,
+			BindAddress: "127.0.0.1",
+			RESTSocket:  false,
 			RequireAuth: false,
 			AuthToken:   "",
 		},
@@ -232,6 +264,9 @@ func (c *Config) Validate() error {
 	if c.Detection.UpdateCheckCacheDuration < time.Minute {
 		c.Detection.UpdateCheckCacheDuration = time.Minute
 	}
+	if c.Detection.StrategyTimeout < 0 {
+		c.Detection.StrategyTimeout = 0
+	}
 	if c.Updates.CheckInterval < time.Minute {
 		c.Updates.CheckInterval = time.Minute
 	}
@@ -244,6 +279,9 @@ func (c *Config) Validate() error {
 	if c.API.RESTPort < 1 || c.API.RESTPort > 65535 {
 		c.API.RESTPort = 8080
 	}
+	if c.API.BindAddress == "" {
+		c.API.BindAddress = "127.0.0.1"
+	}
 	return nil
 }
 
@@ -278,3 +316,27 @@ func (c *Config) GetPinnedVersion(agentID string) string {
 	}
 	return ""
 }
+
+// IsAgentPinned returns true if the agent is frozen at a pinned version.
+// Pinned agents are skipped by bulk updates and refused by single-agent
+// updates unless the caller explicitly forces the update. Agents with a
+// range constraint are not considered pinned; see GetVersionConstraints.
+func (c *Config) IsAgentPinned(agentID string) bool {
+	return c.GetPinnedVersion(agentID) != "" && c.GetVersionConstraints(agentID) == nil
+}
+
+// GetVersionConstraints returns the parsed range constraint for an agent,
+// or nil when the agent has no pin, is pinned to an exact version, or the
+// pin does not parse (which is treated as an exact pin so a typo never
+// lets an agent float forward).
+func (c *Config) GetVersionConstraints(agentID string) agent.VersionConstraints {
+	pin := c.GetPinnedVersion(agentID)
+	if pin == "" {
+		return nil
+	}
+	constraints, err := agent.ParseVersionConstraints(pin)
+	if err != nil || constraints.IsExact() {
+		return nil
+	}
+	return constraints
+}
@@ -57,6 +57,12 @@ func TestDefault(t *testing.T) {
 	if cfg.API.RESTPort != 8080 {
 		t.Errorf("API.RESTPort = %d, want 8080", cfg.API.RESTPort)
 	}
+	if cfg.API.BindAddress != "127.0.0.1" {
+		t.Errorf("API.BindAddress = %q, want %q", cfg.API.BindAddress, "127.0.0.1")
+	}
+	if cfg.API.RESTSocket {
+		t.Error("API.RESTSocket should be false")
+	}
 
 	// Test logging defaults
 	if cfg.Logging.Level != "info" {
@@ -171,6 +177,18 @@ func TestValidate(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "fix empty bind address",
+			modify: func(c *Config) {
+				c.API.BindAddress = ""
+			},
+			check: func(c *Config) error {
+				if c.API.BindAddress != "127.0.0.1" {
+					t.Errorf("BindAddress should be 127.0.0.1, got %q", c.API.BindAddress)
+				}
+				return nil
+			},
+		},
 		{
 			name: "fix invalid rest port high",
 			modify: func(c *Config) {
@@ -352,6 +370,39 @@ func TestGetPinnedVersion(t *testing.T) {
 	}
 }
 
+func TestIsAgentPinned(t *testing.T) {
+	cfg := Default()
+	cfg.Agents = map[string]AgentConfig{
+		"pinned-agent":      {PinnedVersion: "2.0.0"},
+		"constrained-agent": {PinnedVersion: "^2.1"},
+		"garbled-agent":     {PinnedVersion: "two-point-oh"},
+		"unpinned-agent":    {Hidden: true},
+	}
+
+	tests := []struct {
+		agentID     string
+		pinned      bool
+		constrained bool
+	}{
+		{"pinned-agent", true, false},
+		{"constrained-agent", false, true},
+		{"garbled-agent", true, false},
+		{"unpinned-agent", false, false},
+		{"unknown-agent", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agentID, func(t *testing.T) {
+			if got := cfg.IsAgentPinned(tt.agentID); got != tt.pinned {
+				t.Errorf("IsAgentPinned(%q) = %v, want %v", tt.agentID, got, tt.pinned)
+			}
+			if got := cfg.GetVersionConstraints(tt.agentID) != nil; got != tt.constrained {
+				t.Errorf("GetVersionConstraints(%q) != nil = %v, want %v", tt.agentID, got, tt.constrained)
+			}
+		})
+	}
+}
+
 func TestAgentConfigCustomPaths(t *testing.T) {
 	cfg := Default()
 	cfg.Agents = map[string]AgentConfig{
@@ -372,6 +423,21 @@ func TestAgentConfigCustomPaths(t *testing.T) {
 	}
 }
 
+func TestDetectionConfigTimeoutFor(t *testing.T) {
+	cfg := Default()
+	if got := cfg.Detection.TimeoutFor("npm"); got != 30*time.Second {
+		t.Errorf("default TimeoutFor(npm) = %v, want 30s", got)
+	}
+
+	cfg.Detection.StrategyTimeouts = map[string]time.Duration{"brew": time.Minute}
+	if got := cfg.Detection.TimeoutFor("brew"); got != time.Minute {
+		t.Errorf("TimeoutFor(brew) = %v, want 1m", got)
+	}
+	if got := cfg.Detection.TimeoutFor("npm"); got != 30*time.Second {
+		t.Errorf("TimeoutFor(npm) = %v, want the 30s default", got)
+	}
+}
+
 func TestCatalogConfig(t *testing.T) {
 	cfg := CatalogConfig{
 		SourceURL:       "https://example.com/catalog.json",
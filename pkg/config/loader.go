@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 
 	"github.com/kevinelliott/agentmanager/pkg/platform"
@@ -69,7 +71,7 @@ func (l *Loader) Load(customPath string) (*Config, error) {
 
 	// Unmarshal into struct
 	cfg := Default()
-	if err := l.v.Unmarshal(cfg); err != nil {
+	if err := l.v.Unmarshal(cfg, withAgentShorthand); err != nil {
 		return nil, fmt.Errorf("error parsing config: %w", err)
 	}
 
@@ -81,6 +83,21 @@ func (l *Loader) Load(customPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// withAgentShorthand lets an agent entry be written as a bare version
+// constraint, e.g. `claude-code: "^2.1"`, as shorthand for
+// `claude-code: {pinned_version: "^2.1"}`. It wraps viper's default hooks
+// rather than replacing them so durations and slices still decode.
+func withAgentShorthand(c *mapstructure.DecoderConfig) {
+	c.DecodeHook = mapstructure.ComposeDecodeHookFunc(agentConfigShorthandHook, c.DecodeHook)
+}
+
+func agentConfigShorthandHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(AgentConfig{}) || from.Kind() != reflect.String {
+		return data, nil
+	}
+	return map[string]interface{}{"pinned_version": data}, nil
+}
+
 // Save saves the configuration to file.
 func (l *Loader) Save(cfg *Config) error {
 	// Ensure directory exists
@@ -182,6 +199,8 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("api.grpc_port", defaults.API.GRPCPort)
 	l.v.SetDefault("api.enable_rest", defaults.API.EnableREST)
 	l.v.SetDefault("api.rest_port", defaults.API.RESTPort)
+	l.v.SetDefault("api.bind_address", defaults.API.BindAddress)
+	l.v.SetDefault("api.rest_socket", defaults.API.RESTSocket)
 	l.v.SetDefault("api.require_auth", defaults.API.RequireAuth)
 	l.v.SetDefault("api.auth_token", defaults.API.AuthToken)
 
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewLoader(t *testing.T) {
@@ -107,6 +108,75 @@ logging:
 	}
 }
 
+func TestLoaderLoadAgentShorthand(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+catalog:
+  refresh_interval: 48h
+agents:
+  claude-code: "^2.1"
+  aider:
+    pinned_version: "0.85.0"
+    hidden: true
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := NewLoader().Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if got := cfg.GetPinnedVersion("claude-code"); got != "^2.1" {
+		t.Errorf("claude-code pinned_version = %q, want %q", got, "^2.1")
+	}
+	if cfg.GetVersionConstraints("claude-code") == nil {
+		t.Error("claude-code shorthand should parse as a version constraint")
+	}
+	if got := cfg.GetPinnedVersion("aider"); got != "0.85.0" || !cfg.IsAgentHidden("aider") {
+		t.Errorf("aider config = %+v, want pinned 0.85.0 and hidden", cfg.Agents["aider"])
+	}
+	// The shorthand hook must not displace viper's default duration hook.
+	if cfg.Catalog.RefreshInterval != 48*time.Hour {
+		t.Errorf("RefreshInterval = %v, want 48h", cfg.Catalog.RefreshInterval)
+	}
+}
+
+func TestLoaderLoadStrategyTimeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+detection:
+  strategy_timeout: 10s
+  strategy_timeouts:
+    npm: 2m
+    signature: 0s
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := NewLoader().Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	tests := map[string]time.Duration{
+		"npm":       2 * time.Minute,
+		"signature": 0,
+		"brew":      10 * time.Second,
+	}
+	for strategy, want := range tests {
+		if got := cfg.Detection.TimeoutFor(strategy); got != want {
+			t.Errorf("TimeoutFor(%q) = %v, want %v", strategy, got, want)
+		}
+	}
+}
+
 func TestLoaderSave(t *testing.T) {
 	// Create a temp directory
 	tmpDir, err := os.MkdirTemp("", "agentmgr-test-*")
@@ -9,6 +9,7 @@ import (
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
@@ -27,16 +28,43 @@ type Strategy interface {
 	Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error)
 }
 
+// StrategyKey is the metadata key under which detection records the name
+// of the strategy that found an installation. It tells which strategy to
+// blame when an installation goes missing: the method alone does not, as
+// the pip strategy also finds pipx and uv installs and several strategies
+// report native ones.
+const StrategyKey = "strategy"
+
+// timeoutGrace is how long a strategy that overran its deadline gets to
+// return what it found before detection moves on without it.
+const timeoutGrace = 250 * time.Millisecond
+
 // Detector orchestrates agent detection across multiple strategies.
 type Detector struct {
 	strategies     []Strategy
 	platform       platform.Platform
 	pluginRegistry *PluginRegistry
+	config         *config.Config
 	mu             sync.RWMutex
 }
 
+// Option configures a Detector.
+type Option func(*Detector)
+
+// WithConfig makes detection honor the settings in cfg: the directories
+// and files in agents.<id>.custom_paths are searched for the agent's
+// executables, and each strategy is bounded by its detection timeout.
+func WithConfig(cfg *config.Config) Option {
+	return func(d *Detector) {
+		if cfg != nil {
+			d.config = cfg
+			d.strategies = append(d.strategies, NewCustomPathStrategy(d.platform, cfg))
+		}
+	}
+}
+
 // New creates a new Detector with all available strategies.
-func New(p platform.Platform) *Detector {
+func New(p platform.Platform, opts ...Option) *Detector {
 	d := &Detector{
 		platform:       p,
 		strategies:     make([]Strategy, 0),
@@ -48,6 +76,15 @@ func New(p platform.Platform) *Detector {
 	d.RegisterStrategy(NewNPMStrategy(p))
 	d.RegisterStrategy(NewPipStrategy(p))
 	d.RegisterStrategy(NewBrewStrategy(p))
+	d.RegisterStrategy(NewCargoStrategy(p))
+	d.RegisterStrategy(NewGoStrategy(p))
+	d.RegisterStrategy(NewBunStrategy(p))
+	d.RegisterStrategy(NewNixStrategy(p))
+	d.RegisterStrategy(NewSignatureStrategy(p))
+
+	for _, opt := range opts {
+		opt(d)
+	}
 
 	return d
 }
@@ -86,15 +123,24 @@ func (d *Detector) GetStrategies() []Strategy {
 }
 
 // DetectAll runs all applicable strategies and returns found installations.
+// Strategies that fail are skipped; use DetectAllWithResult to see why.
 func (d *Detector) DetectAll(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	result, err := d.DetectAllWithResult(ctx, agents)
+	return result.Installations, err
+}
+
+// DetectAllWithResult runs all applicable strategies and reports, besides
+// the installations found, how long each strategy took and the error of
+// every strategy that failed. A failing strategy does not fail the run.
+// The error is non-nil only when ctx ended before detection finished; the
+// result then holds whatever the strategies found in time.
+func (d *Detector) DetectAllWithResult(ctx context.Context, agents []catalog.AgentDef) (*Result, error) {
+	start := time.Now()
+
 	d.mu.RLock()
 	strategies := d.strategies
 	d.mu.RUnlock()
 
-	// Pre-filter applicable strategies so the result/error channel buffers
-	// match the number of goroutines that will actually send (previously
-	// sized to len(strategies), which over-allocated and, on the edge, risked
-	// a mismatch if senders outnumbered the buffer).
 	applicable := make([]Strategy, 0, len(strategies))
 	for _, s := range strategies {
 		if s.IsApplicable(d.platform) {
@@ -102,56 +148,111 @@ func (d *Detector) DetectAll(ctx context.Context, agents []catalog.AgentDef) ([]
 		}
 	}
 
-	var wg sync.WaitGroup
-	resultsChan := make(chan []*agent.Installation, len(applicable))
-	errorsChan := make(chan error, len(applicable))
+	// Each goroutine writes only its own slot, so results come back in
+	// registration order without locking.
+	found := make([][]*agent.Installation, len(applicable))
+	reports := make([]StrategyResult, len(applicable))
 
-	for _, s := range applicable {
+	var wg sync.WaitGroup
+	for i, s := range applicable {
 		wg.Add(1)
-		go func(strategy Strategy) {
+		go func(i int, strategy Strategy) {
 			defer wg.Done()
-
-			installations, err := strategy.Detect(ctx, agents)
-			if err != nil {
-				errorsChan <- fmt.Errorf("%s detection failed: %w", strategy.Name(), err)
-				return
-			}
-
-			if len(installations) > 0 {
-				resultsChan <- installations
-			}
-		}(s)
+			found[i], reports[i] = d.runStrategy(ctx, strategy, agents)
+		}(i, s)
 	}
-
-	// Wait for all strategies to complete
 	wg.Wait()
-	close(resultsChan)
-	close(errorsChan)
-
-	// Collect all results
-	var allInstallations []*agent.Installation
-	for installations := range resultsChan {
-		allInstallations = append(allInstallations, installations...)
-	}
 
-	// Drain error channel (errors are logged by strategies, not surfaced)
-	for range errorsChan {
-		// Errors from individual strategies are logged but don't fail detection
+	result := &Result{Strategies: reports}
+	for i, installations := range found {
+		recordStrategy(installations, reports[i].Name)
+		result.Installations = append(result.Installations, installations...)
+		if err := reports[i].Err; err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s detection failed: %w", reports[i].Name, err))
+		}
 	}
 
 	// Deduplicate installations by key
-	allInstallations = deduplicateInstallations(allInstallations)
+	result.Installations = deduplicateInstallations(result.Installations)
+	d.annotatePathStatus(agents, result.Installations)
 
 	// Set detection timestamp
 	now := time.Now()
-	for _, inst := range allInstallations {
+	for _, inst := range result.Installations {
 		if inst.DetectedAt.IsZero() {
 			inst.DetectedAt = now
 		}
 		inst.LastChecked = now
 	}
+	result.Duration = now.Sub(start)
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("detection interrupted: %w", err)
+	}
+	return result, nil
+}
+
+// runStrategy runs one strategy under its configured deadline. A strategy
+// stuck past the deadline, or past ctx, is abandoned after timeoutGrace
+// rather than holding up the run; its goroutine finishes on its own once
+// the canceled context kills its commands.
+func (d *Detector) runStrategy(ctx context.Context, strategy Strategy, agents []catalog.AgentDef) ([]*agent.Installation, StrategyResult) {
+	report := StrategyResult{Name: strategy.Name(), Method: strategy.Method()}
+
+	timeout := d.strategyTimeout(report.Name)
+	sctx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		sctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	type outcome struct {
+		installations []*agent.Installation
+		err           error
+	}
+	done := make(chan outcome, 1)
+
+	began := time.Now()
+	go func() {
+		installations, err := strategy.Detect(sctx, agents)
+		done <- outcome{installations, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-sctx.Done():
+		report.TimedOut = ctx.Err() == nil
+		select {
+		case out = <-done:
+		case <-time.After(timeoutGrace):
+			out.err = sctx.Err()
+		}
+	}
+	report.Duration = time.Since(began)
+
+	switch {
+	case report.TimedOut:
+		// Keep whatever the strategy handed back in the grace period.
+		report.Err = fmt.Errorf("timed out after %s: %w", timeout, context.DeadlineExceeded)
+		report.Found = len(out.installations)
+		return out.installations, report
+	case out.err != nil:
+		report.Err = out.err
+		return nil, report
+	}
 
-	return allInstallations, nil
+	report.Found = len(out.installations)
+	return out.installations, report
+}
+
+// strategyTimeout returns the configured deadline for the named strategy,
+// or zero when detection runs without a config.
+func (d *Detector) strategyTimeout(name string) time.Duration {
+	if d.config == nil {
+		return 0
+	}
+	return d.config.Detection.TimeoutFor(name)
 }
 
 // DetectByMethod runs only the strategy for the given method.
@@ -187,6 +288,19 @@ func (d *Detector) DetectAgent(ctx context.Context, agentDef catalog.AgentDef) (
 	return d.DetectAll(ctx, []catalog.AgentDef{agentDef})
 }
 
+// recordStrategy notes under StrategyKey that the named strategy found
+// installations, unless an earlier strategy already claimed one.
+func recordStrategy(installations []*agent.Installation, name string) {
+	for _, inst := range installations {
+		if inst.Metadata == nil {
+			inst.Metadata = make(map[string]string)
+		}
+		if inst.Metadata[StrategyKey] == "" {
+			inst.Metadata[StrategyKey] = name
+		}
+	}
+}
+
 // deduplicateInstallations removes duplicate installations by key.
 func deduplicateInstallations(installations []*agent.Installation) []*agent.Installation {
 	seen := make(map[string]bool)
@@ -206,8 +320,39 @@ func deduplicateInstallations(installations []*agent.Installation) []*agent.Inst
 // Result represents the result of a detection run.
 type Result struct {
 	Installations []*agent.Installation
-	Errors        []error
-	Duration      time.Duration
+	// Strategies reports each strategy that ran, in registration order.
+	Strategies []StrategyResult
+	// Errors holds one error per failed strategy.
+	Errors   []error
+	Duration time.Duration
+	// Reused counts the installations Redetect carried over from its
+	// snapshot without probing them again.
+	Reused int
+}
+
+// StrategyResult reports how one strategy fared in a detection run.
+type StrategyResult struct {
+	Name     string
+	Method   agent.InstallMethod
+	Found    int
+	Duration time.Duration
+	// TimedOut reports that the strategy overran its deadline. Found then
+	// counts only what it returned before detection moved on.
+	TimedOut bool
+	// Err is the error the strategy returned, or nil. It wraps
+	// context.DeadlineExceeded when TimedOut is set.
+	Err error
+}
+
+// Failed returns the strategies that returned an error.
+func (r *Result) Failed() []StrategyResult {
+	var failed []StrategyResult
+	for _, s := range r.Strategies {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+	return failed
 }
 
 // NewInstallations returns installations that are not in the existing list.
@@ -227,6 +372,59 @@ func (r *Result) NewInstallations(existing []*agent.Installation) []*agent.Insta
 	return newInsts
 }
 
+// Changes compares the result with the installations of a previous run and
+// returns an added event for each new installation, an updated event for
+// each whose installed version changed, and a removed event for each that
+// is gone. Updated and removed events carry the previous installation.
+func (r *Result) Changes(previous []*agent.Installation) []agent.RegistryEvent {
+	var events []agent.RegistryEvent
+	for _, inst := range r.NewInstallations(previous) {
+		events = append(events, agent.RegistryEvent{Type: agent.RegistryEventAdded, Installation: inst})
+	}
+
+	byKey := make(map[string]*agent.Installation, len(previous))
+	for _, inst := range previous {
+		byKey[inst.Key()] = inst
+	}
+	for _, inst := range r.Installations {
+		prev, ok := byKey[inst.Key()]
+		if ok && !prev.InstalledVersion.Equals(inst.InstalledVersion) {
+			events = append(events, agent.RegistryEvent{Type: agent.RegistryEventUpdated, Installation: inst, Previous: prev})
+		}
+	}
+
+	for _, inst := range r.RemovedInstallations(previous) {
+		events = append(events, agent.RegistryEvent{Type: agent.RegistryEventRemoved, Previous: inst})
+	}
+	return events
+}
+
+// Unseen returns the installations of previous that the run did not find
+// and whose strategy failed: they may only have gone unseen, and are not
+// to be taken as removed. An installation recorded without its strategy
+// is blamed on the failed strategies for its install method.
+func (r *Result) Unseen(previous []*agent.Installation) []*agent.Installation {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, len(failed))
+	methods := make(map[agent.InstallMethod]bool, len(failed))
+	for _, sr := range failed {
+		names[sr.Name] = true
+		methods[sr.Method] = true
+	}
+
+	var unseen []*agent.Installation
+	for _, inst := range r.RemovedInstallations(previous) {
+		name := inst.Metadata[StrategyKey]
+		if (name != "" && names[name]) || (name == "" && methods[inst.Method]) {
+			unseen = append(unseen, inst)
+		}
+	}
+	return unseen
+}
+
 // RemovedInstallations returns installations that exist but were not detected.
 func (r *Result) RemovedInstallations(existing []*agent.Installation) []*agent.Installation {
 	detectedKeys := make(map[string]bool)
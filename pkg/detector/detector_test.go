@@ -2,11 +2,14 @@ package detector
 
 import (
 	"context"
+	"errors"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
@@ -53,6 +56,24 @@ func TestNewDetector(t *testing.T) {
 	}
 }
 
+func TestNewDetectorWithConfig(t *testing.T) {
+	p := platform.Current()
+	base := len(New(p).GetStrategies())
+
+	d := New(p, WithConfig(&config.Config{}))
+	strategies := d.GetStrategies()
+	if len(strategies) != base+1 {
+		t.Fatalf("New(WithConfig) registered %d strategies, want %d", len(strategies), base+1)
+	}
+	if name := strategies[len(strategies)-1].Name(); name != "custom_path" {
+		t.Errorf("added strategy = %q, want custom_path", name)
+	}
+
+	if got := len(New(p, WithConfig(nil)).GetStrategies()); got != base {
+		t.Errorf("WithConfig(nil) registered %d strategies, want %d", got, base)
+	}
+}
+
 func TestDetectorRegisterStrategy(t *testing.T) {
 	p := platform.Current()
 	d := &Detector{
@@ -492,3 +513,279 @@ func TestResultStruct(t *testing.T) {
 		t.Errorf("Duration = %v, want %v", result.Duration, 5*time.Second)
 	}
 }
+
+func TestDetectAllWithResult(t *testing.T) {
+	d := &Detector{platform: platform.Current()}
+	d.RegisterStrategy(&mockStrategy{
+		name:       "npm",
+		method:     agent.InstallMethodNPM,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "claude-code", Method: agent.InstallMethodNPM, ExecutablePath: "/usr/local/bin/claude"},
+		},
+	})
+	d.RegisterStrategy(&mockStrategy{
+		name:       "brew",
+		method:     agent.InstallMethodBrew,
+		applicable: true,
+		err:        errors.New("brew crashed"),
+	})
+	d.RegisterStrategy(&mockStrategy{
+		name:       "pip",
+		method:     agent.InstallMethodPip,
+		applicable: false,
+	})
+
+	result, err := d.DetectAllWithResult(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("DetectAllWithResult() error = %v", err)
+	}
+	if len(result.Installations) != 1 {
+		t.Fatalf("Installations = %d, want 1", len(result.Installations))
+	}
+	if got := result.Installations[0].Metadata[StrategyKey]; got != "npm" {
+		t.Errorf("Metadata[%q] = %q, want npm", StrategyKey, got)
+	}
+
+	// Inapplicable strategies are not reported; the rest keep
+	// registration order.
+	if len(result.Strategies) != 2 {
+		t.Fatalf("Strategies = %d, want 2", len(result.Strategies))
+	}
+	if s := result.Strategies[0]; s.Name != "npm" || s.Found != 1 || s.Err != nil {
+		t.Errorf("Strategies[0] = %+v, want npm found 1", s)
+	}
+	if s := result.Strategies[1]; s.Name != "brew" || s.Err == nil {
+		t.Errorf("Strategies[1] = %+v, want failed brew", s)
+	}
+
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Name != "brew" {
+		t.Errorf("Failed() = %+v, want brew", failed)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Error() != "brew detection failed: brew crashed" {
+		t.Errorf("Errors = %v", result.Errors)
+	}
+}
+
+func TestDetectAllWithResultCanceled(t *testing.T) {
+	d := &Detector{platform: platform.Current()}
+	d.RegisterStrategy(&mockStrategy{name: "npm", method: agent.InstallMethodNPM, applicable: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := d.DetectAllWithResult(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DetectAllWithResult() error = %v, want context.Canceled", err)
+	}
+	if result == nil || len(result.Strategies) != 1 {
+		t.Errorf("partial result = %+v, want the strategy report", result)
+	}
+}
+
+// slowStrategy blocks until release is closed, ignoring its context like a
+// command whose children keep its output open. With partial set it instead
+// returns partial as soon as its context ends.
+type slowStrategy struct {
+	name    string
+	release chan struct{}
+	partial []*agent.Installation
+}
+
+func (s *slowStrategy) Name() string                          { return s.name }
+func (s *slowStrategy) Method() agent.InstallMethod           { return agent.InstallMethod(s.name) }
+func (s *slowStrategy) IsApplicable(p platform.Platform) bool { return true }
+func (s *slowStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	if s.partial != nil {
+		<-ctx.Done()
+		return s.partial, ctx.Err()
+	}
+	<-s.release
+	return nil, nil
+}
+
+func TestDetectAllWithResultStrategyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	cfg := config.Default()
+	cfg.Detection.StrategyTimeout = 20 * time.Millisecond
+	cfg.Detection.StrategyTimeouts = map[string]time.Duration{"partial": 40 * time.Millisecond}
+
+	d := &Detector{platform: platform.Current(), config: cfg}
+	d.RegisterStrategy(&mockStrategy{
+		name:       "npm",
+		method:     agent.InstallMethodNPM,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "claude-code", Method: agent.InstallMethodNPM, ExecutablePath: "/usr/local/bin/claude"},
+		},
+	})
+	d.RegisterStrategy(&slowStrategy{name: "hung", release: release})
+	d.RegisterStrategy(&slowStrategy{name: "partial", partial: []*agent.Installation{
+		{AgentID: "aider", Method: agent.InstallMethodPip, ExecutablePath: "/usr/local/bin/aider"},
+	}})
+
+	start := time.Now()
+	result, err := d.DetectAllWithResult(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("DetectAllWithResult() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("detection took %v; a hung strategy held up the run", elapsed)
+	}
+
+	// The npm result and the partial strategy's result are both kept.
+	if len(result.Installations) != 2 {
+		t.Errorf("Installations = %d, want 2", len(result.Installations))
+	}
+
+	npm, hung, partial := result.Strategies[0], result.Strategies[1], result.Strategies[2]
+	if npm.TimedOut || npm.Err != nil || npm.Found != 1 {
+		t.Errorf("npm = %+v, want found 1", npm)
+	}
+	if !hung.TimedOut || !errors.Is(hung.Err, context.DeadlineExceeded) || hung.Found != 0 {
+		t.Errorf("hung = %+v, want timed out with nothing found", hung)
+	}
+	if !partial.TimedOut || partial.Found != 1 {
+		t.Errorf("partial = %+v, want timed out with its partial result", partial)
+	}
+	if partial.Duration < 40*time.Millisecond {
+		t.Errorf("partial ran %v, want its own 40ms deadline", partial.Duration)
+	}
+}
+
+func TestStrategyTimeoutDisabled(t *testing.T) {
+	d := &Detector{platform: platform.Current()}
+	if got := d.strategyTimeout("npm"); got != 0 {
+		t.Errorf("strategyTimeout() without config = %v, want 0", got)
+	}
+
+	cfg := config.Default()
+	cfg.Detection.StrategyTimeout = 0
+	d = New(platform.Current(), WithConfig(cfg))
+	if got := d.strategyTimeout("npm"); got != 0 {
+		t.Errorf("strategyTimeout() with strategy_timeout 0 = %v, want 0", got)
+	}
+}
+
+func TestDetectAllWithResultPathStatus(t *testing.T) {
+	nativeDir := t.TempDir()
+	npmDir := t.TempDir()
+	t.Setenv("PATH", nativeDir+string(os.PathListSeparator)+npmDir)
+
+	native := writeFingerprintExecutable(t, nativeDir, "agentx")
+	npm := writeFingerprintExecutable(t, npmDir, "agentx")
+	offPath := writeFingerprintExecutable(t, t.TempDir(), "agentx")
+
+	d := &Detector{platform: platform.Current()}
+	d.RegisterStrategy(&mockStrategy{
+		name:       "npm",
+		method:     agent.InstallMethodNPM,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "x", Method: agent.InstallMethodNPM, ExecutablePath: npm, Metadata: map[string]string{"detected_by": "npm"}},
+		},
+	})
+	d.RegisterStrategy(&mockStrategy{
+		name:       "binary",
+		method:     agent.InstallMethodNative,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "x", Method: agent.InstallMethodNative, ExecutablePath: native},
+		},
+	})
+	d.RegisterStrategy(&mockStrategy{
+		name:       "cargo",
+		method:     agent.InstallMethodCargo,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "x", Method: agent.InstallMethodCargo, ExecutablePath: offPath},
+		},
+	})
+
+	agents := []catalog.AgentDef{{ID: "x", Detection: catalog.DetectionDef{Executables: []string{"agentx"}}}}
+	result, err := d.DetectAllWithResult(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("DetectAllWithResult() error = %v", err)
+	}
+	if len(result.Installations) != 3 {
+		t.Fatalf("Installations = %d, want 3", len(result.Installations))
+	}
+
+	// The npm copy is owned by a package manager, yet still reports that
+	// the native copy shadows it.
+	shadowed, active, off := result.Installations[0].Metadata, result.Installations[1].Metadata, result.Installations[2].Metadata
+	if shadowed["path_status"] != PathStatusShadowed || shadowed["path_rank"] != "2" || shadowed["shadowed_by"] != native {
+		t.Errorf("npm metadata = %v, want shadowed at rank 2 by %s", shadowed, native)
+	}
+	if shadowed["detected_by"] != "npm" {
+		t.Errorf("npm metadata = %v, want the strategy's own keys kept", shadowed)
+	}
+	if active["path_status"] != PathStatusActive || active["path_rank"] != "1" || active["shadowed_by"] != "" {
+		t.Errorf("native metadata = %v, want active at rank 1", active)
+	}
+	if off["path_status"] != PathStatusOffPath || off["path_rank"] != "" {
+		t.Errorf("cargo metadata = %v, want off_path without a rank", off)
+	}
+}
+
+func TestResultChanges(t *testing.T) {
+	kept := &agent.Installation{AgentID: "kept", Method: agent.InstallMethodNPM, InstalledVersion: agent.Version{Major: 1}}
+	bumped := &agent.Installation{AgentID: "bumped", Method: agent.InstallMethodBrew, InstalledVersion: agent.Version{Major: 1}}
+	gone := &agent.Installation{AgentID: "gone", Method: agent.InstallMethodPip}
+	previous := []*agent.Installation{kept, bumped, gone}
+
+	bumpedNow := *bumped
+	bumpedNow.InstalledVersion = agent.Version{Major: 2}
+	fresh := &agent.Installation{AgentID: "fresh", Method: agent.InstallMethodNPM}
+	keptNow := *kept
+
+	result := &Result{Installations: []*agent.Installation{&keptNow, &bumpedNow, fresh}}
+	events := result.Changes(previous)
+
+	if len(events) != 3 {
+		t.Fatalf("Changes() = %+v, want 3 events", events)
+	}
+	if e := events[0]; e.Type != agent.RegistryEventAdded || e.Installation != fresh || e.Previous != nil {
+		t.Errorf("events[0] = %+v, want fresh added", e)
+	}
+	if e := events[1]; e.Type != agent.RegistryEventUpdated || e.Installation != &bumpedNow || e.Previous != bumped {
+		t.Errorf("events[1] = %+v, want bumped updated from its previous installation", e)
+	}
+	if e := events[2]; e.Type != agent.RegistryEventRemoved || e.Installation != nil || e.Previous != gone {
+		t.Errorf("events[2] = %+v, want gone removed", e)
+	}
+
+	if events := result.Changes(result.Installations); len(events) != 0 {
+		t.Errorf("Changes() against itself = %+v, want none", events)
+	}
+}
+
+func TestResultUnseen(t *testing.T) {
+	pipx := &agent.Installation{AgentID: "aider", Method: agent.InstallMethodPipx, Metadata: map[string]string{StrategyKey: "pip"}}
+	native := &agent.Installation{AgentID: "claude-code", Method: agent.InstallMethodNative, Metadata: map[string]string{StrategyKey: "binary"}}
+	signature := &agent.Installation{AgentID: "codex", Method: agent.InstallMethodNative, Metadata: map[string]string{StrategyKey: "signature"}}
+	legacy := &agent.Installation{AgentID: "gemini", Method: agent.InstallMethodPip}
+	previous := []*agent.Installation{pipx, native, signature, legacy}
+
+	result := &Result{Strategies: []StrategyResult{
+		{Name: "pip", Method: agent.InstallMethodPip, TimedOut: true, Err: context.DeadlineExceeded},
+		{Name: "binary", Method: agent.InstallMethodNative},
+		{Name: "signature", Method: agent.InstallMethodNative, Err: errors.New("permission denied")},
+	}}
+
+	// The pipx and signature installs went unseen with their strategies;
+	// the binary one is really gone; the one recorded without a strategy
+	// is matched by method.
+	unseen := result.Unseen(previous)
+	if len(unseen) != 3 || unseen[0] != pipx || unseen[1] != signature || unseen[2] != legacy {
+		t.Errorf("Unseen() = %+v, want the pipx, signature and legacy installs", unseen)
+	}
+
+	result.Strategies = result.Strategies[1:2]
+	if unseen := result.Unseen(previous); len(unseen) != 0 {
+		t.Errorf("Unseen() without failures = %+v, want none", unseen)
+	}
+}
@@ -0,0 +1,213 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
+)
+
+// Fingerprint describes the on-disk state of an installation in two
+// lines. The first covers the executable: its resolved path, size,
+// modification time and inode. The second holds the modification times of
+// the directories a neighbouring install touches: the directory the
+// executable was found in, and the package root it resolves into, such as
+// npm's node_modules or a Homebrew formula's Cellar directory.
+//
+// Two equal fingerprints mean nothing was installed, upgraded or removed
+// there, so the installation's detected version still holds. It returns ""
+// when the installation has no path to fingerprint.
+func Fingerprint(inst *agent.Installation) string {
+	path := inst.ExecutablePath
+	if path == "" {
+		path = inst.InstallPath
+	}
+	if path == "" {
+		return ""
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "missing"
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "missing"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%d|%d|%d\n", target, info.Size(), info.ModTime().UnixNano(), fileInode(info))
+	for i, dir := range fingerprintDirs(path, target) {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(dirFingerprint(dir))
+	}
+	return b.String()
+}
+
+// Fingerprints returns the fingerprint of each installation, keyed by
+// installation key.
+func Fingerprints(installations []*agent.Installation) map[string]string {
+	fingerprints := make(map[string]string, len(installations))
+	for _, inst := range installations {
+		fingerprints[inst.Key()] = Fingerprint(inst)
+	}
+	return fingerprints
+}
+
+// fingerprintDirs returns the directories whose modification times feed
+// an installation's fingerprint.
+func fingerprintDirs(path, target string) []string {
+	dirs := []string{filepath.Dir(path)}
+	if root := packageRoot(target); root != dirs[0] {
+		dirs = append(dirs, root)
+	}
+	return dirs
+}
+
+// packageRoot returns the directory a package manager rewrites when it
+// installs or removes a package next to target: the enclosing
+// node_modules, the formula's directory under a Homebrew Cellar, or else
+// target's own directory.
+func packageRoot(target string) string {
+	dir := filepath.Dir(target)
+	for d := dir; ; d = filepath.Dir(d) {
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		if strings.EqualFold(filepath.Base(d), "node_modules") || strings.EqualFold(filepath.Base(parent), "Cellar") {
+			return d
+		}
+	}
+	return dir
+}
+
+// dirFingerprint returns dir's modification time, or "-" if it is gone.
+func dirFingerprint(dir string) string {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "-"
+	}
+	return fmt.Sprint(info.ModTime().UnixNano())
+}
+
+// Snapshot is a previous detection run to re-detect against: its
+// installations and the fingerprints recorded for them.
+type Snapshot struct {
+	Installations []*agent.Installation
+	Fingerprints  map[string]string
+}
+
+// Redetect re-probes only what changed since snap and carries everything
+// else over without running a command. An agent is re-probed when one of
+// its installations has a fingerprint that differs from the recorded one
+// (or none was recorded), or when one of its executables is on PATH at a
+// location snap does not know. A changed installation also re-probes every
+// agent that can be installed with the same method when the directories
+// it shares with them changed, since that is how a new install by the
+// same package manager shows up.
+//
+// The result's Installations hold the carried-over and re-probed
+// installations together; Reused counts the carried-over ones. Its
+// Strategies are those of the partial run, and empty when nothing changed.
+func (d *Detector) Redetect(ctx context.Context, agents []catalog.AgentDef, snap Snapshot) (*Result, error) {
+	start := time.Now()
+
+	changed := make(map[string]bool)
+	dirtyMethods := make(map[agent.InstallMethod]bool)
+	known := make(map[string]bool)
+	for _, inst := range snap.Installations {
+		if inst.ExecutablePath != "" {
+			known[inst.ExecutablePath] = true
+			if target, err := filepath.EvalSymlinks(inst.ExecutablePath); err == nil {
+				known[target] = true
+			}
+		}
+
+		recorded, ok := snap.Fingerprints[inst.Key()]
+		now := Fingerprint(inst)
+		if ok && now != "" && now == recorded {
+			continue
+		}
+		changed[inst.AgentID] = true
+
+		// Only a change to the shared directories implicates the
+		// agent's neighbours; an executable rewritten in place does not.
+		_, recordedDirs, _ := strings.Cut(recorded, "\n")
+		_, nowDirs, _ := strings.Cut(now, "\n")
+		if !ok || nowDirs != recordedDirs {
+			dirtyMethods[inst.Method] = true
+		}
+	}
+
+	for _, def := range agents {
+		if changed[def.ID] {
+			continue
+		}
+		for method := range def.InstallMethods {
+			if dirtyMethods[agent.InstallMethod(method)] {
+				changed[def.ID] = true
+				break
+			}
+		}
+		if !changed[def.ID] && d.hasNewPathExecutable(def, known) {
+			changed[def.ID] = true
+		}
+	}
+
+	var reprobe []catalog.AgentDef
+	for _, def := range agents {
+		if changed[def.ID] {
+			reprobe = append(reprobe, def)
+		}
+	}
+
+	result := &Result{}
+	if len(reprobe) > 0 {
+		var err error
+		result, err = d.DetectAllWithResult(ctx, reprobe)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Carry over the installations of agents that were not re-probed,
+	// dropping any whose agent left the catalog.
+	inCatalog := make(map[string]bool, len(agents))
+	for _, def := range agents {
+		inCatalog[def.ID] = true
+	}
+	for _, inst := range snap.Installations {
+		if inCatalog[inst.AgentID] && !changed[inst.AgentID] {
+			result.Installations = append(result.Installations, inst)
+			result.Reused++
+		}
+	}
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+// hasNewPathExecutable reports whether one of the agent's executables is on
+// PATH somewhere known does not cover.
+func (d *Detector) hasNewPathExecutable(def catalog.AgentDef, known map[string]bool) bool {
+	if d.platform == nil {
+		return false
+	}
+	for _, name := range def.Detection.Executables {
+		for _, match := range strategies.FindPathMatches(d.platform, name) {
+			if !known[match.Path] && !known[match.Target] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package detector
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode returns info's inode number, which changes when a package
+// manager replaces a file rather than rewriting it.
+func fileInode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino) //nolint:unconvert // Ino is uint32 on some platforms
+	}
+	return 0
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package detector
+
+import "os"
+
+// fileInode returns 0: os.FileInfo carries no file index on Windows, so
+// fingerprints rely on size and modification time there.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,169 @@
+package detector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+func writeFingerprintExecutable(t *testing.T, dir, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho 1.0.0\n"), 0o755); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFingerprintExecutable(t, dir, "agentx")
+	inst := &agent.Installation{AgentID: "x", ExecutablePath: path}
+
+	first := Fingerprint(inst)
+	if first == "" || first == "missing" {
+		t.Fatalf("Fingerprint() = %q, want a fingerprint", first)
+	}
+	if again := Fingerprint(inst); again != first {
+		t.Errorf("Fingerprint() changed without a change on disk: %q != %q", again, first)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	touched := Fingerprint(inst)
+	if touched == first {
+		t.Error("Fingerprint() did not change with the executable's mtime")
+	}
+	// Only the executable line moved; the directory line is untouched.
+	_, firstDirs, _ := strings.Cut(first, "\n")
+	_, touchedDirs, _ := strings.Cut(touched, "\n")
+	if firstDirs != touchedDirs {
+		t.Errorf("directory fingerprint changed: %q != %q", touchedDirs, firstDirs)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := Fingerprint(inst); got != "missing" {
+		t.Errorf("Fingerprint() = %q after removal, want missing", got)
+	}
+
+	if got := Fingerprint(&agent.Installation{AgentID: "x"}); got != "" {
+		t.Errorf("Fingerprint() = %q without a path, want empty", got)
+	}
+}
+
+func TestPackageRoot(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/usr/local/lib/node_modules/@acme/cli/bin/cli.js", "/usr/local/lib/node_modules"},
+		{"/opt/homebrew/Cellar/aider/0.80.0/bin/aider", "/opt/homebrew/Cellar/aider"},
+		{"/usr/local/bin/claude", "/usr/local/bin"},
+	}
+
+	for _, tt := range tests {
+		target := filepath.FromSlash(tt.target)
+		if got := packageRoot(target); got != filepath.FromSlash(tt.want) {
+			t.Errorf("packageRoot(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRedetect(t *testing.T) {
+	binDir := t.TempDir()
+	otherDir := t.TempDir()
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+otherDir)
+
+	path := writeFingerprintExecutable(t, binDir, "agentx")
+	recorded := &agent.Installation{AgentID: "x", Method: agent.InstallMethodNPM, ExecutablePath: path}
+
+	agents := []catalog.AgentDef{
+		{
+			ID:             "x",
+			InstallMethods: map[string]catalog.InstallMethodDef{"npm": {Method: "npm"}},
+			Detection:      catalog.DetectionDef{Executables: []string{"agentx"}},
+		},
+		{
+			ID:             "y",
+			InstallMethods: map[string]catalog.InstallMethodDef{"brew": {Method: "brew"}},
+			Detection:      catalog.DetectionDef{Executables: []string{"agenty"}},
+		},
+	}
+
+	d := &Detector{platform: platform.Current()}
+	d.RegisterStrategy(&mockStrategy{
+		name:       "npm",
+		method:     agent.InstallMethodNPM,
+		applicable: true,
+		installations: []*agent.Installation{
+			{AgentID: "x", Method: agent.InstallMethodNPM, ExecutablePath: path, InstalledVersion: agent.Version{Major: 2}},
+		},
+	})
+
+	snap := Snapshot{
+		Installations: []*agent.Installation{recorded},
+		Fingerprints:  Fingerprints([]*agent.Installation{recorded}),
+	}
+	ctx := context.Background()
+
+	t.Run("unchanged", func(t *testing.T) {
+		result, err := d.Redetect(ctx, agents, snap)
+		if err != nil {
+			t.Fatalf("Redetect() error = %v", err)
+		}
+		if len(result.Strategies) != 0 {
+			t.Errorf("Strategies = %+v, want none run", result.Strategies)
+		}
+		if result.Reused != 1 || len(result.Installations) != 1 || result.Installations[0] != recorded {
+			t.Errorf("Installations = %+v, Reused = %d; want the recorded one carried over", result.Installations, result.Reused)
+		}
+	})
+
+	t.Run("new on PATH", func(t *testing.T) {
+		defer os.Remove(writeFingerprintExecutable(t, otherDir, "agenty"))
+
+		result, err := d.Redetect(ctx, agents, snap)
+		if err != nil {
+			t.Fatalf("Redetect() error = %v", err)
+		}
+		if len(result.Strategies) == 0 {
+			t.Error("Redetect() ran no strategies for an executable new on PATH")
+		}
+		if result.Reused != 1 {
+			t.Errorf("Reused = %d, want x carried over while y is probed", result.Reused)
+		}
+	})
+
+	t.Run("changed executable", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := d.Redetect(ctx, agents, snap)
+		if err != nil {
+			t.Fatalf("Redetect() error = %v", err)
+		}
+		if result.Reused != 0 {
+			t.Errorf("Reused = %d, want the changed installation re-probed", result.Reused)
+		}
+		if len(result.Installations) != 1 || result.Installations[0].InstalledVersion.Major != 2 {
+			t.Errorf("Installations = %+v, want the re-probed version", result.Installations)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package detector
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
+)
+
+// Values of the "path_status" metadata detection sets on installations.
+const (
+	// PathStatusActive marks the copy of an executable a shell runs.
+	PathStatusActive = "active"
+	// PathStatusShadowed marks a copy on PATH behind the active one.
+	PathStatusShadowed = "shadowed"
+	// PathStatusOffPath marks an installation whose executable is not on
+	// PATH at all.
+	PathStatusOffPath = "off_path"
+)
+
+// annotatePathStatus records where each installation's executable sits on
+// PATH, whichever strategy found it: "path_rank" is its 1-based position
+// among the copies of the executable, "path_status" is PathStatusActive or
+// PathStatusShadowed, and a shadowed copy gets "shadowed_by", the path of
+// the copy that wins. Installations whose executable is not on PATH are
+// marked PathStatusOffPath.
+func (d *Detector) annotatePathStatus(agents []catalog.AgentDef, installations []*agent.Installation) {
+	if d.platform == nil {
+		return
+	}
+
+	defs := make(map[string]catalog.AgentDef, len(agents))
+	for _, def := range agents {
+		defs[def.ID] = def
+	}
+	matchesByName := make(map[string][]strategies.PathMatch)
+
+	for _, inst := range installations {
+		if inst.ExecutablePath == "" {
+			continue
+		}
+		target, err := filepath.EvalSymlinks(inst.ExecutablePath)
+		if err != nil {
+			target = inst.ExecutablePath
+		}
+
+		rank, matches := 0, []strategies.PathMatch(nil)
+		for _, name := range defs[inst.AgentID].Detection.Executables {
+			found, ok := matchesByName[name]
+			if !ok {
+				found = strategies.FindPathMatches(d.platform, name)
+				matchesByName[name] = found
+			}
+			for i, match := range found {
+				if match.Path == inst.ExecutablePath || match.Target == target {
+					rank, matches = i+1, found
+					break
+				}
+			}
+			if rank > 0 {
+				break
+			}
+		}
+
+		if inst.Metadata == nil {
+			inst.Metadata = make(map[string]string)
+		}
+		delete(inst.Metadata, "shadowed_by")
+		switch {
+		case rank == 0:
+			delete(inst.Metadata, "path_rank")
+			inst.Metadata["path_status"] = PathStatusOffPath
+		case rank == 1:
+			inst.Metadata["path_rank"] = "1"
+			inst.Metadata["path_status"] = PathStatusActive
+		default:
+			inst.Metadata["path_rank"] = strconv.Itoa(rank)
+			inst.Metadata["path_status"] = PathStatusShadowed
+			inst.Metadata["shadowed_by"] = matches[0].Path
+		}
+	}
+}
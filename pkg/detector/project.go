@@ -0,0 +1,42 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+// DetectProject detects the agents installed locally in the project
+// containing dir: npm packages in a node_modules and Python packages in a
+// .venv, in dir or any directory above it up to the repository root.
+// Global installations are not looked for. The project strategy runs under
+// its configured deadline, and its outcome is reported like that of any
+// strategy in DetectAllWithResult.
+func (d *Detector) DetectProject(ctx context.Context, agents []catalog.AgentDef, dir string) (*Result, error) {
+	start := time.Now()
+
+	installations, report := d.runStrategy(ctx, NewProjectStrategy(d.platform, dir), agents)
+	result := &Result{
+		Installations: deduplicateInstallations(installations),
+		Strategies:    []StrategyResult{report},
+	}
+	if report.Err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("%s detection failed: %w", report.Name, report.Err))
+	}
+
+	now := time.Now()
+	for _, inst := range result.Installations {
+		if inst.DetectedAt.IsZero() {
+			inst.DetectedAt = now
+		}
+		inst.LastChecked = now
+	}
+	result.Duration = now.Sub(start)
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("detection interrupted: %w", err)
+	}
+	return result, nil
+}
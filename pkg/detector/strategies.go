@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
@@ -24,3 +25,39 @@ func NewPipStrategy(p platform.Platform) Strategy {
 func NewBrewStrategy(p platform.Platform) Strategy {
 	return strategies.NewBrewStrategy(p)
 }
+
+// NewCargoStrategy creates a new cargo install detection strategy.
+func NewCargoStrategy(p platform.Platform) Strategy {
+	return strategies.NewCargoStrategy(p)
+}
+
+// NewGoStrategy creates a new go install detection strategy.
+func NewGoStrategy(p platform.Platform) Strategy {
+	return strategies.NewGoStrategy(p)
+}
+
+// NewBunStrategy creates a new bun global package detection strategy.
+func NewBunStrategy(p platform.Platform) Strategy {
+	return strategies.NewBunStrategy(p)
+}
+
+// NewNixStrategy creates a new Nix profile detection strategy.
+func NewNixStrategy(p platform.Platform) Strategy {
+	return strategies.NewNixStrategy(p)
+}
+
+// NewSignatureStrategy creates a new catalog signature detection strategy.
+func NewSignatureStrategy(p platform.Platform) Strategy {
+	return strategies.NewSignatureStrategy(p)
+}
+
+// NewCustomPathStrategy creates a strategy searching the custom paths in cfg.
+func NewCustomPathStrategy(p platform.Platform, cfg *config.Config) Strategy {
+	return strategies.NewCustomPathStrategy(p, cfg)
+}
+
+// NewProjectStrategy creates a strategy detecting the agents installed in
+// the project containing dir.
+func NewProjectStrategy(p platform.Platform, dir string) Strategy {
+	return strategies.NewProjectStrategy(p, dir)
+}
@@ -4,14 +4,15 @@ package strategies
 import (
 	"context"
 	"os/exec"
-	"regexp"
-	"runtime"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/versionparse"
 )
 
 // binaryDetectConcurrency caps concurrent --version subprocess launches.
@@ -49,19 +50,29 @@ var binaryMethods = []string{"native", "binary", "curl"}
 
 // Detect scans for installed agents and returns found installations.
 //
-// Matching (LookPath + isPackageManagerPath) runs sequentially so we remain
-// deterministic with respect to the input slice order. Version extraction
-// (which shells out per match) is parallelized with a bounded semaphore — a
-// typical multi-agent run previously did N sequential --version invocations.
+// Every copy of an agent's executable on PATH is reported, not just the
+// first: a native install shadowed by (or shadowing) another copy shows up
+// as its own installation, with "path_status" metadata saying whether it is
+// the "active" copy a shell runs or a "shadowed" one. Copies owned by a
+// package manager are left to that manager's strategy. A release install
+// in agentmgr's bin directory is reported with the binary method, and as
+// "off_path" when that directory is not on PATH.
+//
+// Matching runs sequentially so we remain deterministic with respect to
+// the input slice order. Version extraction (which shells out per match) is
+// parallelized with a bounded semaphore — a typical multi-agent run
+// previously did N sequential --version invocations.
 func (s *BinaryStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
-	// Phase 1: synchronously resolve which agents match a binary on PATH and
-	// allocate a deterministic slot for each. This avoids racing on LookPath
-	// output and preserves the deterministic ordering contract.
+	// Phase 1: synchronously resolve which agents match binaries on PATH and
+	// allocate a deterministic slot for each. This avoids racing on PATH
+	// lookups and preserves the deterministic ordering contract.
 	type pending struct {
 		agentDef   catalog.AgentDef
 		methodName string
 		executable string
-		path       string
+		match      PathMatch
+		rank       int
+		activePath string
 	}
 
 	pendings := make([]pending, 0, len(agents))
@@ -81,20 +92,36 @@ func (s *BinaryStrategy) Detect(ctx context.Context, agents []catalog.AgentDef)
 		}
 
 		for _, executable := range agentDef.Detection.Executables {
-			path, err := s.platform.FindExecutable(executable)
-			if err != nil {
-				continue // Not found, try next executable
+			matches := FindPathMatches(s.platform, executable)
+			found := false
+			for i, match := range matches {
+				if match.Owner != "" {
+					continue
+				}
+				pendings = append(pendings, pending{
+					agentDef:   agentDef,
+					methodName: s.methodFor(agentDef, methodName, match.Path),
+					executable: executable,
+					match:      match,
+					rank:       i + 1,
+					activePath: matches[0].Path,
+				})
+				found = true
+			}
+			// A release install in agentmgr's bin directory is found
+			// even when that directory is not on PATH.
+			if path := s.releaseCopy(executable, matches); path != "" {
+				pendings = append(pendings, pending{
+					agentDef:   agentDef,
+					methodName: s.methodFor(agentDef, methodName, path),
+					executable: executable,
+					match:      PathMatch{Path: path, Target: path},
+				})
+				found = true
 			}
-			if isPackageManagerPath(path) {
-				continue
+			if found {
+				break // Found the agent, move to next
 			}
-			pendings = append(pendings, pending{
-				agentDef:   agentDef,
-				methodName: methodName,
-				executable: executable,
-				path:       path,
-			})
-			break // Found the agent, move to next
 		}
 	}
 
@@ -111,17 +138,28 @@ func (s *BinaryStrategy) Detect(ctx context.Context, agents []catalog.AgentDef)
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			version := s.getVersion(ctx, p.agentDef, p.path)
+			metadata := map[string]string{
+				"detected_by": "binary",
+				"executable":  p.executable,
+				"path_status": "active",
+				"path_rank":   strconv.Itoa(p.rank),
+			}
+			switch {
+			case p.rank == 0:
+				metadata["path_status"] = "off_path"
+				delete(metadata, "path_rank")
+			case !p.match.Active:
+				metadata["path_status"] = "shadowed"
+				metadata["shadowed_by"] = p.activePath
+			}
+
 			installations[idx] = &agent.Installation{
 				AgentID:          p.agentDef.ID,
 				AgentName:        p.agentDef.Name,
 				Method:           agent.InstallMethod(p.methodName),
-				InstalledVersion: version,
-				ExecutablePath:   p.path,
-				Metadata: map[string]string{
-					"detected_by": "binary",
-					"executable":  p.executable,
-				},
+				InstalledVersion: s.getVersion(ctx, p.agentDef, p.match.Path),
+				ExecutablePath:   p.match.Path,
+				Metadata:         metadata,
 			}
 		}(i, pendings[i])
 	}
@@ -130,8 +168,65 @@ func (s *BinaryStrategy) Detect(ctx context.Context, agents []catalog.AgentDef)
 	return installations, nil
 }
 
+// releaseBinDir returns the directory agentmgr installs release
+// executables into, as providers.BinaryProvider.BinDir does, or "" when
+// the platform has no data directory.
+func (s *BinaryStrategy) releaseBinDir() string {
+	dataDir := s.platform.GetDataDir()
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, "bin")
+}
+
+// releaseCopy returns the executable in agentmgr's bin directory when it
+// is there but not among matches, the copies on PATH, or "".
+func (s *BinaryStrategy) releaseCopy(executable string, matches []PathMatch) string {
+	dir := s.releaseBinDir()
+	if dir == "" {
+		return ""
+	}
+	for _, candidate := range executableCandidates(filepath.Join(dir, executable)) {
+		if !isExecutableFile(candidate) {
+			continue
+		}
+		target, err := filepath.EvalSymlinks(candidate)
+		if err != nil {
+			target = candidate
+		}
+		for _, match := range matches {
+			if match.Path == candidate || match.Target == target {
+				return ""
+			}
+		}
+		return candidate
+	}
+	return ""
+}
+
+// methodFor returns the install method of the copy at path: binary for
+// one agentmgr installed from a release, when the catalog has that
+// method, and methodName otherwise.
+func (s *BinaryStrategy) methodFor(agentDef catalog.AgentDef, methodName, path string) string {
+	dir := s.releaseBinDir()
+	if dir == "" || filepath.Clean(filepath.Dir(path)) != filepath.Clean(dir) {
+		return methodName
+	}
+	if _, ok := agentDef.InstallMethods["binary"]; ok {
+		return "binary"
+	}
+	return methodName
+}
+
 // getVersion extracts the version from the executable.
 func (s *BinaryStrategy) getVersion(ctx context.Context, agentDef catalog.AgentDef, path string) agent.Version {
+	return ExecutableVersion(ctx, agentDef, path)
+}
+
+// ExecutableVersion runs the agent's version command against the executable
+// at path, in place of the command's first word, and parses its output. It
+// returns the zero Version when there is no command or it fails.
+func ExecutableVersion(ctx context.Context, agentDef catalog.AgentDef, path string) agent.Version {
 	if agentDef.Detection.VersionCmd == "" {
 		return agent.Version{}
 	}
@@ -151,116 +246,11 @@ func (s *BinaryStrategy) getVersion(ctx context.Context, agentDef catalog.AgentD
 		return agent.Version{}
 	}
 
-	// Extract version using regex if provided
-	versionStr := strings.TrimSpace(string(output))
-	if agentDef.Detection.VersionRegex != "" {
-		re, err := regexp.Compile(agentDef.Detection.VersionRegex)
-		if err == nil {
-			matches := re.FindStringSubmatch(versionStr)
-			if len(matches) > 1 {
-				versionStr = matches[1]
-			}
-		}
-	} else {
-		// Try common patterns
-		versionStr = extractVersionFromOutput(versionStr)
-	}
-
-	version, _ := agent.ParseVersion(versionStr)
-	return version
-}
-
-// extractVersionFromOutput tries to extract a version number from command output.
-func extractVersionFromOutput(output string) string {
-	// Common version patterns
-	patterns := []string{
-		`v?(\d+\.\d+\.\d+(?:-[a-zA-Z0-9.]+)?)`,
-		`version\s+v?(\d+\.\d+\.\d+)`,
-		`(\d+\.\d+\.\d+)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(output)
-		if len(matches) > 1 {
-			return matches[1]
-		}
-	}
-
-	return ""
+	return versionparse.Parse(string(output), agentDef.Detection.VersionRegex)
 }
 
 // isPackageManagerPath checks if a path belongs to a package manager installation.
 // This helps avoid reporting npm/pip/brew/etc. installations as "native".
 func isPackageManagerPath(path string) bool {
-	// Normalize path separators for comparison
-	normalizedPath := strings.ToLower(path)
-	if runtime.GOOS == "windows" {
-		normalizedPath = strings.ReplaceAll(normalizedPath, "\\", "/")
-	}
-
-	// Package manager path patterns to exclude from native detection
-	packageManagerPatterns := []string{
-		// npm/node paths
-		"/node_modules/",
-		"/npm/",
-		"/node/",
-		"/.npm/",
-		"/pnpm/",
-		"/yarn/",
-		"/.bun/",
-		"/fnm/",
-		"/.nvm/",
-		"/.volta/",
-		"/asdf/installs/nodejs/",
-		"/mise/installs/node/",
-
-		// Python paths
-		"/pip/",
-		"/pipx/",
-		"/site-packages/",
-		"/.local/pipx/",
-		"/.pyenv/",
-		"/conda/",
-		"/virtualenv/",
-		"/venv/",
-		"/.venv/",
-		"/uv/",
-		"/asdf/installs/python/",
-		"/mise/installs/python/",
-
-		// Homebrew paths
-		"/homebrew/",
-		"/cellar/",
-		"/linuxbrew/",
-
-		// Go paths
-		"/go/bin/",
-		"/gopath/",
-
-		// Rust/Cargo paths
-		"/.cargo/",
-
-		// Ruby paths
-		"/.gem/",
-		"/.rbenv/",
-		"/.rvm/",
-		"/asdf/installs/ruby/",
-
-		// Generic version managers
-		"/.asdf/",
-		"/mise/",
-		"/rtx/",
-
-		// Scoop (Windows)
-		"/scoop/",
-	}
-
-	for _, pattern := range packageManagerPatterns {
-		if strings.Contains(normalizedPath, pattern) {
-			return true
-		}
-	}
-
-	return false
+	return pathOwner(path) != ""
 }
@@ -0,0 +1,146 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// BunStrategy detects agents installed with `bun add -g` by reading the
+// package.json files in bun's global node_modules.
+type BunStrategy struct {
+	platform platform.Platform
+
+	// bunInstall is $BUN_INSTALL, or ~/.bun.
+	bunInstall string
+}
+
+// NewBunStrategy creates a new bun detection strategy.
+func NewBunStrategy(p platform.Platform) *BunStrategy {
+	return &BunStrategy{platform: p, bunInstall: defaultBunInstall()}
+}
+
+// defaultBunInstall returns bun's install root.
+func defaultBunInstall() string {
+	if dir := os.Getenv("BUN_INSTALL"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".bun")
+}
+
+// Name returns the strategy name.
+func (s *BunStrategy) Name() string {
+	return "bun"
+}
+
+// Method returns the install method this strategy detects.
+func (s *BunStrategy) Method() agent.InstallMethod {
+	return agent.MethodBun
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *BunStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Reads files; bun itself need not be on PATH
+}
+
+// bunPackage is the part of a package.json the strategy reads.
+type bunPackage struct {
+	Version string `json:"version"`
+}
+
+// Detect scans bun's global packages for agents.
+func (s *BunStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	if s.bunInstall == "" {
+		return nil, nil
+	}
+	modules := filepath.Join(s.bunInstall, "install", "global", "node_modules")
+	if _, err := os.Stat(modules); err != nil {
+		return nil, nil
+	}
+
+	var installations []*agent.Installation
+	for _, agentDef := range agents {
+		// "bunx" runs a package on demand without installing it globally.
+		bunMethod, hasBun := agentDef.InstallMethods["bun"]
+		if !hasBun {
+			continue
+		}
+
+		packageName := bunMethod.Package
+		if packageName == "" {
+			packageName = extractBunPackageName(bunMethod.Command)
+		}
+		if packageName == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(modules, filepath.FromSlash(packageName), "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg bunPackage
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			continue
+		}
+
+		version, _ := agent.ParseVersion(pkg.Version)
+		installations = append(installations, &agent.Installation{
+			AgentID:          agentDef.ID,
+			AgentName:        agentDef.Name,
+			Method:           agent.MethodBun,
+			InstalledVersion: version,
+			ExecutablePath:   s.findExecutable(agentDef),
+			IsGlobal:         true,
+			Metadata: map[string]string{
+				"detected_by": "bun",
+				"package":     packageName,
+			},
+		})
+	}
+
+	return installations, nil
+}
+
+// findExecutable returns the agent's executable in bun's bin directory.
+func (s *BunStrategy) findExecutable(agentDef catalog.AgentDef) string {
+	for _, name := range agentDef.Detection.Executables {
+		for _, path := range executableCandidates(filepath.Join(s.bunInstall, "bin", name)) {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// extractBunPackageName extracts the package from a command such as
+// "bun add -g @scope/pkg" or "bun install -g pkg@1.2.3".
+func extractBunPackageName(command string) string {
+	parts := strings.Fields(command)
+	for i, part := range parts {
+		if part != "add" && part != "install" && part != "i" {
+			continue
+		}
+		for j := i + 1; j < len(parts); j++ {
+			if strings.HasPrefix(parts[j], "-") {
+				continue
+			}
+			pkg := parts[j]
+			if idx := strings.LastIndex(pkg, "@"); idx > 0 {
+				pkg = pkg[:idx]
+			}
+			return pkg
+		}
+	}
+	return ""
+}
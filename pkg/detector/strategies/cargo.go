@@ -0,0 +1,221 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// CargoStrategy detects agents installed with `cargo install` by reading
+// cargo's install tracking file, $CARGO_HOME/.crates2.json.
+type CargoStrategy struct {
+	platform platform.Platform
+
+	// cargoHome is $CARGO_HOME, or ~/.cargo.
+	cargoHome string
+}
+
+// NewCargoStrategy creates a new cargo detection strategy.
+func NewCargoStrategy(p platform.Platform) *CargoStrategy {
+	return &CargoStrategy{platform: p, cargoHome: defaultCargoHome()}
+}
+
+// defaultCargoHome returns where cargo keeps installed crates.
+func defaultCargoHome() string {
+	if dir := os.Getenv("CARGO_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cargo")
+}
+
+// Name returns the strategy name.
+func (s *CargoStrategy) Name() string {
+	return "cargo"
+}
+
+// Method returns the install method this strategy detects.
+func (s *CargoStrategy) Method() agent.InstallMethod {
+	return agent.MethodCargo
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *CargoStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Reads a file; cargo itself need not be on PATH
+}
+
+// cratesInstall is one entry of .crates2.json's "installs" map.
+type cratesInstall struct {
+	Bins []string `json:"bins"`
+}
+
+// cratesFile is cargo's .crates2.json.
+type cratesFile struct {
+	Installs map[string]cratesInstall `json:"installs"`
+}
+
+// installedCrate is a parsed .crates2.json entry.
+type installedCrate struct {
+	name    string
+	version string
+	source  string
+	bins    []string
+}
+
+// Detect scans for cargo-installed agents.
+func (s *CargoStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	crates, err := s.getInstalledCrates()
+	if err != nil {
+		return nil, err
+	}
+	if len(crates) == 0 {
+		return nil, nil
+	}
+
+	var installations []*agent.Installation
+	for _, agentDef := range agents {
+		cargoMethod, hasCargo := agentDef.InstallMethods["cargo"]
+		if !hasCargo {
+			continue
+		}
+
+		crate, found := matchCrate(crates, cargoMethod, agentDef.Detection.Executables)
+		if !found {
+			continue
+		}
+
+		version, _ := agent.ParseVersion(crate.version)
+		installations = append(installations, &agent.Installation{
+			AgentID:          agentDef.ID,
+			AgentName:        agentDef.Name,
+			Method:           agent.MethodCargo,
+			InstalledVersion: version,
+			ExecutablePath:   s.binPath(crate, agentDef.Detection.Executables),
+			IsGlobal:         true,
+			Metadata: map[string]string{
+				"detected_by": "cargo",
+				"package":     crate.name,
+				"source":      crate.source,
+			},
+		})
+	}
+
+	return installations, nil
+}
+
+// getInstalledCrates reads .crates2.json, keyed by crate name. A missing
+// file means nothing was installed with cargo; one that cannot be read or
+// parsed is an error.
+func (s *CargoStrategy) getInstalledCrates() (map[string]installedCrate, error) {
+	if s.cargoHome == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.cargoHome, ".crates2.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .crates2.json: %w", err)
+	}
+
+	var file cratesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse .crates2.json: %w", err)
+	}
+
+	crates := make(map[string]installedCrate, len(file.Installs))
+	for key, install := range file.Installs {
+		crate, ok := parseCrateKey(key)
+		if !ok {
+			continue
+		}
+		crate.bins = install.Bins
+		crates[crate.name] = crate
+	}
+	return crates, nil
+}
+
+// parseCrateKey splits a .crates2.json key of the form
+// "name version (source)", for example
+// "aichat 0.28.0 (registry+https://github.com/rust-lang/crates.io-index)".
+func parseCrateKey(key string) (installedCrate, bool) {
+	name, rest, ok := strings.Cut(key, " ")
+	if !ok {
+		return installedCrate{}, false
+	}
+	version, source, _ := strings.Cut(rest, " ")
+	source = strings.TrimSuffix(strings.TrimPrefix(source, "("), ")")
+	return installedCrate{name: name, version: version, source: source}, name != "" && version != ""
+}
+
+// matchCrate finds the crate for an agent: by the catalog package name, or
+// failing that (crates installed from git often differ) by a crate that
+// installed one of the agent's executables.
+func matchCrate(crates map[string]installedCrate, method catalog.InstallMethodDef, executables []string) (installedCrate, bool) {
+	name := method.Package
+	if name == "" {
+		name = extractCargoPackageName(method.Command)
+	}
+	if crate, ok := crates[name]; ok && name != "" {
+		return crate, true
+	}
+
+	for _, crate := range crates {
+		for _, bin := range crate.bins {
+			if executableNamed(bin, executables) {
+				return crate, true
+			}
+		}
+	}
+	return installedCrate{}, false
+}
+
+// binPath returns the installed path of the crate binary matching one of
+// executables, or of its first binary.
+func (s *CargoStrategy) binPath(crate installedCrate, executables []string) string {
+	if len(crate.bins) == 0 {
+		return ""
+	}
+	bin := crate.bins[0]
+	for _, b := range crate.bins {
+		if executableNamed(b, executables) {
+			bin = b
+			break
+		}
+	}
+	return filepath.Join(s.cargoHome, "bin", bin)
+}
+
+// extractCargoPackageName extracts the crate name from a cargo install
+// command such as "cargo install aichat --locked".
+func extractCargoPackageName(command string) string {
+	parts := strings.Fields(command)
+	for i, part := range parts {
+		if part != "install" {
+			continue
+		}
+		for j := i + 1; j < len(parts); j++ {
+			if strings.HasPrefix(parts[j], "-") {
+				// Flags taking a value
+				if parts[j] == "--git" || parts[j] == "--version" || parts[j] == "--branch" || parts[j] == "--tag" || parts[j] == "--rev" {
+					j++
+				}
+				continue
+			}
+			return parts[j]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package strategies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// CustomPathStrategy detects agents in the directories and files listed
+// under agents.<id>.custom_paths in the config, for installs kept off PATH
+// such as /opt/tools/<agent>/bin or a project's virtualenv.
+type CustomPathStrategy struct {
+	platform platform.Platform
+	cfg      *config.Config
+}
+
+// NewCustomPathStrategy creates a strategy searching cfg's custom paths.
+// The config is read on every Detect, so edits made while a long-running
+// process holds it are picked up.
+func NewCustomPathStrategy(p platform.Platform, cfg *config.Config) *CustomPathStrategy {
+	return &CustomPathStrategy{platform: p, cfg: cfg}
+}
+
+// Name returns the strategy name.
+func (s *CustomPathStrategy) Name() string {
+	return "custom_path"
+}
+
+// Method returns the install method this strategy detects. Installations
+// report the method implied by where each copy lives; native is the
+// fallback.
+func (s *CustomPathStrategy) Method() agent.InstallMethod {
+	return agent.MethodNative
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *CustomPathStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Custom paths are plain file checks
+}
+
+// Detect searches each agent's custom paths for its catalog executables.
+//
+// An entry may name a directory, searched for the executables, or an
+// executable itself. "~" and environment variables ($VAR, ${VAR}) are
+// expanded, and glob patterns match every directory or file they cover.
+func (s *CustomPathStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	if s.cfg == nil || len(s.cfg.Agents) == 0 {
+		return nil, nil
+	}
+
+	var installations []*agent.Installation
+	for _, agentDef := range agents {
+		entries := s.cfg.GetAgentConfig(agentDef.ID).CustomPaths
+		if len(entries) == 0 || len(agentDef.Detection.Executables) == 0 {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			for _, path := range findInCustomPath(entry, agentDef.Detection.Executables) {
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+
+				installations = append(installations, &agent.Installation{
+					AgentID:          agentDef.ID,
+					AgentName:        agentDef.Name,
+					Method:           customPathMethod(agentDef, path),
+					InstalledVersion: ExecutableVersion(ctx, agentDef, path),
+					ExecutablePath:   path,
+					Metadata: map[string]string{
+						"detected_by": "custom_path",
+						"custom_path": entry,
+					},
+				})
+			}
+		}
+	}
+
+	return installations, nil
+}
+
+// findInCustomPath returns the executables found through one custom_paths
+// entry, in glob order. A file matched by a pattern only counts when it is
+// named like one of the executables; a file named without wildcards is
+// taken as given.
+func findInCustomPath(entry string, executables []string) []string {
+	pattern := ExpandUserPath(entry)
+	if pattern == "" {
+		return nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+	literal := !hasGlobMeta(pattern)
+
+	var found []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			if isExecutableFile(match) && (literal || executableNamed(match, executables)) {
+				found = append(found, match)
+			}
+			continue
+		}
+
+		for _, name := range executables {
+			for _, candidate := range executableCandidates(filepath.Join(match, name)) {
+				if isExecutableFile(candidate) {
+					found = append(found, candidate)
+					break
+				}
+			}
+		}
+	}
+	return found
+}
+
+// customPathMethod picks the install method for an executable found in a
+// custom path: the package manager owning the path when the catalog offers
+// that method (a virtualenv's bin is pip), otherwise the agent's binary
+// method, otherwise native.
+func customPathMethod(agentDef catalog.AgentDef, path string) agent.InstallMethod {
+	if owner := pathOwner(path); owner != "" {
+		if _, ok := agentDef.InstallMethods[owner]; ok {
+			return agent.InstallMethod(owner)
+		}
+	}
+	for _, m := range binaryMethods {
+		if _, ok := agentDef.InstallMethods[m]; ok {
+			return agent.InstallMethod(m)
+		}
+	}
+	return agent.MethodNative
+}
+
+// ExpandUserPath expands environment variables and a leading "~" in path.
+// It returns "" when path needs a home directory and none is known.
+func ExpandUserPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// hasGlobMeta reports whether path contains filepath.Match wildcards.
+func hasGlobMeta(path string) bool {
+	magic := `*?[`
+	if runtime.GOOS != "windows" {
+		magic = `*?[\`
+	}
+	return strings.ContainsAny(path, magic)
+}
+
+// executableNamed reports whether path's file name is one of names,
+// ignoring a Windows ".exe" suffix.
+func executableNamed(path string, names []string) bool {
+	base := filepath.Base(path)
+	windows := runtime.GOOS == "windows"
+	if windows {
+		base = strings.TrimSuffix(strings.ToLower(base), ".exe")
+	}
+	for _, name := range names {
+		if base == name || (windows && base == strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// executableCandidates returns the file names an executable may have at
+// path: the name itself, plus name.exe on Windows.
+func executableCandidates(path string) []string {
+	if runtime.GOOS == "windows" && !strings.HasSuffix(strings.ToLower(path), ".exe") {
+		return []string{path + ".exe", path}
+	}
+	return []string{path}
+}
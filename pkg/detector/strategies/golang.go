@@ -0,0 +1,162 @@
+package strategies
+
+import (
+	"context"
+	"debug/buildinfo"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// GoStrategy detects agents installed with `go install`. It reads the build
+// information embedded in binaries in the Go bin directories, the same data
+// `go version -m` prints, so the go toolchain need not be installed.
+type GoStrategy struct {
+	platform platform.Platform
+
+	// binDirs are $GOBIN, or each $GOPATH entry's bin, or ~/go/bin.
+	binDirs []string
+}
+
+// NewGoStrategy creates a new go install detection strategy.
+func NewGoStrategy(p platform.Platform) *GoStrategy {
+	return &GoStrategy{platform: p, binDirs: defaultGoBinDirs()}
+}
+
+// defaultGoBinDirs returns where `go install` puts binaries.
+func defaultGoBinDirs() []string {
+	if dir := os.Getenv("GOBIN"); dir != "" {
+		return []string{dir}
+	}
+
+	var dirs []string
+	for _, gopath := range filepath.SplitList(os.Getenv("GOPATH")) {
+		if gopath != "" {
+			dirs = append(dirs, filepath.Join(gopath, "bin"))
+		}
+	}
+	if len(dirs) > 0 {
+		return dirs
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, "go", "bin")}
+}
+
+// Name returns the strategy name.
+func (s *GoStrategy) Name() string {
+	return "go"
+}
+
+// Method returns the install method this strategy detects.
+func (s *GoStrategy) Method() agent.InstallMethod {
+	return agent.MethodGo
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *GoStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Reads binaries directly
+}
+
+// Detect scans the Go bin directories for agents built from their catalog
+// package.
+func (s *GoStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	var installations []*agent.Installation
+
+	for _, agentDef := range agents {
+		goMethod, hasGo := agentDef.InstallMethods["go"]
+		if !hasGo {
+			continue
+		}
+
+		pkg := goMethod.Package
+		if pkg == "" {
+			pkg = extractGoPackagePath(goMethod.Command)
+		}
+		if pkg == "" {
+			continue
+		}
+
+		if inst := s.detectAgent(ctx, agentDef, pkg); inst != nil {
+			installations = append(installations, inst)
+		}
+	}
+
+	return installations, nil
+}
+
+// detectAgent looks for one of the agent's executables built from pkg.
+func (s *GoStrategy) detectAgent(ctx context.Context, agentDef catalog.AgentDef, pkg string) *agent.Installation {
+	for _, dir := range s.binDirs {
+		for _, name := range agentDef.Detection.Executables {
+			for _, path := range executableCandidates(filepath.Join(dir, name)) {
+				info, err := buildinfo.ReadFile(path)
+				if err != nil {
+					continue
+				}
+				if !goPackageMatches(info.Path, info.Main.Path, pkg) {
+					continue
+				}
+
+				// Binaries built from a checkout report "(devel)"; ask the
+				// binary itself then.
+				version, err := agent.ParseVersion(info.Main.Version)
+				if err != nil {
+					version = ExecutableVersion(ctx, agentDef, path)
+				}
+
+				return &agent.Installation{
+					AgentID:          agentDef.ID,
+					AgentName:        agentDef.Name,
+					Method:           agent.MethodGo,
+					InstalledVersion: version,
+					ExecutablePath:   path,
+					IsGlobal:         true,
+					Metadata: map[string]string{
+						"detected_by": "go",
+						"package":     info.Path,
+						"module":      info.Main.Path,
+						"go_version":  info.GoVersion,
+					},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// goPackageMatches reports whether a binary with main package mainPkg from
+// module mod was built from the catalog package pkg. The catalog may name
+// the main package or just the module.
+func goPackageMatches(mainPkg, mod, pkg string) bool {
+	if mainPkg == pkg || mod == pkg {
+		return true
+	}
+	return mod != "" && strings.HasPrefix(pkg, mod+"/")
+}
+
+// extractGoPackagePath extracts the package path from a command such as
+// "go install github.com/owner/repo/cmd/tool@latest".
+func extractGoPackagePath(command string) string {
+	parts := strings.Fields(command)
+	for i, part := range parts {
+		if part != "install" || i == 0 || parts[i-1] != "go" {
+			continue
+		}
+		for j := i + 1; j < len(parts); j++ {
+			if strings.HasPrefix(parts[j], "-") {
+				continue
+			}
+			pkg, _, _ := strings.Cut(parts[j], "@")
+			return pkg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// defaultNixStore is where Nix keeps built packages.
+const defaultNixStore = "/nix/store"
+
+// NixStrategy detects agents installed into a Nix profile with `nix profile
+// install` or `nix-env -i`. An executable in a profile's bin directory
+// links into the store path of the package that provides it, whose name
+// carries the version; `nix profile` manifests add the attribute or flake
+// it was installed from.
+type NixStrategy struct {
+	platform platform.Platform
+
+	// profiles are the profile directories to search, most specific first.
+	profiles []string
+	// store is the Nix store directory.
+	store string
+}
+
+// NewNixStrategy creates a new Nix detection strategy.
+func NewNixStrategy(p platform.Platform) *NixStrategy {
+	return &NixStrategy{platform: p, profiles: defaultNixProfiles(), store: defaultNixStore}
+}
+
+// defaultNixProfiles returns the user's profile, in its classic and XDG
+// locations, and the system default profile.
+func defaultNixProfiles() []string {
+	var profiles []string
+	if home, err := os.UserHomeDir(); err == nil {
+		profiles = append(profiles, filepath.Join(home, ".nix-profile"))
+
+		state := os.Getenv("XDG_STATE_HOME")
+		if state == "" {
+			state = filepath.Join(home, ".local", "state")
+		}
+		profiles = append(profiles, filepath.Join(state, "nix", "profile"))
+	}
+	return append(profiles, "/nix/var/nix/profiles/default")
+}
+
+// Name returns the strategy name.
+func (s *NixStrategy) Name() string {
+	return "nix"
+}
+
+// Method returns the install method this strategy detects.
+func (s *NixStrategy) Method() agent.InstallMethod {
+	return agent.MethodNix
+}
+
+// IsApplicable returns true if Nix can be installed on the platform.
+func (s *NixStrategy) IsApplicable(p platform.Platform) bool {
+	return p.ID() != platform.Windows
+}
+
+// nixElement is one package in a `nix profile` manifest.json.
+type nixElement struct {
+	AttrPath    string   `json:"attrPath"`
+	OriginalURL string   `json:"originalUrl"`
+	StorePaths  []string `json:"storePaths"`
+}
+
+// nixProfile is a profile directory with its manifest's elements indexed
+// by store path.
+type nixProfile struct {
+	dir      string
+	elements map[string]nixElement
+}
+
+// Detect scans the Nix profiles for agents.
+func (s *NixStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	profiles := s.loadProfiles()
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	var installations []*agent.Installation
+	for _, agentDef := range agents {
+		if _, hasNix := agentDef.InstallMethods["nix"]; !hasNix {
+			continue
+		}
+		if inst := s.detectAgent(agentDef, profiles); inst != nil {
+			installations = append(installations, inst)
+		}
+	}
+
+	return installations, nil
+}
+
+// detectAgent returns the first profile executable of the agent that comes
+// from the Nix store.
+func (s *NixStrategy) detectAgent(agentDef catalog.AgentDef, profiles []nixProfile) *agent.Installation {
+	for _, profile := range profiles {
+		for _, name := range agentDef.Detection.Executables {
+			path := filepath.Join(profile.dir, "bin", name)
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue
+			}
+			storePath, pkgName, pkgVersion, ok := s.parseStorePath(target)
+			if !ok {
+				continue
+			}
+
+			version, _ := agent.ParseVersion(pkgVersion)
+			metadata := map[string]string{
+				"detected_by": "nix",
+				"package":     pkgName,
+				"profile":     profile.dir,
+				"store_path":  storePath,
+			}
+			if element, ok := profile.elements[storePath]; ok {
+				if element.AttrPath != "" {
+					metadata["attr_path"] = element.AttrPath
+				}
+				if element.OriginalURL != "" {
+					metadata["flake"] = element.OriginalURL
+				}
+			}
+
+			return &agent.Installation{
+				AgentID:          agentDef.ID,
+				AgentName:        agentDef.Name,
+				Method:           agent.MethodNix,
+				InstalledVersion: version,
+				ExecutablePath:   path,
+				InstallPath:      storePath,
+				IsGlobal:         true,
+				Metadata:         metadata,
+			}
+		}
+	}
+	return nil
+}
+
+// loadProfiles returns the profiles that exist, each once even when
+// several locations link to it.
+func (s *NixStrategy) loadProfiles() []nixProfile {
+	seen := make(map[string]bool)
+	var profiles []nixProfile
+	for _, dir := range s.profiles {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		profiles = append(profiles, nixProfile{dir: dir, elements: readNixManifest(dir)})
+	}
+	return profiles
+}
+
+// readNixManifest indexes the elements of a profile's manifest.json by
+// store path. Version 2 manifests list elements in an array, version 3
+// in a map keyed by name. Profiles managed by nix-env have no
+// manifest.json and yield nil.
+func readNixManifest(dir string) map[string]nixElement {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	var list []nixElement
+	if err := json.Unmarshal(manifest.Elements, &list); err != nil {
+		var byName map[string]nixElement
+		if err := json.Unmarshal(manifest.Elements, &byName); err != nil {
+			return nil
+		}
+		for _, element := range byName {
+			list = append(list, element)
+		}
+	}
+
+	elements := make(map[string]nixElement)
+	for _, element := range list {
+		for _, storePath := range element.StorePaths {
+			elements[storePath] = element
+		}
+	}
+	return elements
+}
+
+// parseStorePath splits a path inside the store, such as
+// /nix/store/<hash>-tenere-0.11.1/bin/tenere, into the store path
+// (/nix/store/<hash>-tenere-0.11.1) and the package name and version.
+func (s *NixStrategy) parseStorePath(path string) (storePath, name, version string, ok bool) {
+	rel, err := filepath.Rel(s.store, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", "", "", false
+	}
+
+	entry, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	_, pkg, found := strings.Cut(entry, "-") // drop the hash
+	if !found || pkg == "" {
+		return "", "", "", false
+	}
+
+	// The version starts at the first dash followed by a digit:
+	// "kubectl-ai-0.0.20" is kubectl-ai at 0.0.20.
+	name = pkg
+	for i := 0; i < len(pkg)-1; i++ {
+		if pkg[i] == '-' && pkg[i+1] >= '0' && pkg[i+1] <= '9' {
+			name, version = pkg[:i], pkg[i+1:]
+			break
+		}
+	}
+
+	return filepath.Join(s.store, entry), name, version, true
+}
@@ -0,0 +1,138 @@
+package strategies
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// pathOwners maps path fragments to the package manager or version manager
+// that owns executables below them. The first match wins, so version
+// managers come before the runtimes they install.
+var pathOwners = []struct {
+	pattern string
+	owner   string
+}{
+	// Generic version managers
+	{"/.asdf/", "asdf"},
+	{"/asdf/installs/", "asdf"},
+	{"/mise/", "mise"},
+	{"/rtx/", "rtx"},
+	{"/fnm/", "fnm"},
+	{"/.nvm/", "nvm"},
+	{"/.volta/", "volta"},
+	{"/.pyenv/", "pyenv"},
+	{"/.rbenv/", "rbenv"},
+	{"/.rvm/", "rvm"},
+
+	// npm/node paths
+	{"/pnpm/", "pnpm"},
+	{"/yarn/", "yarn"},
+	{"/.bun/", "bun"},
+	{"/node_modules/", "npm"},
+	{"/npm/", "npm"},
+	{"/.npm/", "npm"},
+	{"/node/", "npm"},
+
+	// Python paths
+	{"/pipx/", "pipx"},
+	{"/uv/", "uv"},
+	{"/conda/", "conda"},
+	{"/site-packages/", "pip"},
+	{"/pip/", "pip"},
+	{"/virtualenv/", "pip"},
+	{"/venv/", "pip"},
+	{"/.venv/", "pip"},
+
+	// Homebrew paths
+	{"/homebrew/", "brew"},
+	{"/cellar/", "brew"},
+	{"/linuxbrew/", "brew"},
+
+	// Go paths
+	{"/go/bin/", "go"},
+	{"/gopath/", "go"},
+
+	// Rust/Cargo paths
+	{"/.cargo/", "cargo"},
+
+	// Ruby paths
+	{"/.gem/", "gem"},
+
+	// Nix profiles and store
+	{"/.nix-profile/", "nix"},
+	{"/nix/profile/", "nix"},
+	{"/nix/store/", "nix"},
+
+	// Scoop (Windows)
+	{"/scoop/", "scoop"},
+}
+
+// pathOwner returns the package manager that owns path, or "" for a path
+// that looks like a native install.
+func pathOwner(path string) string {
+	// Normalize path separators for comparison
+	normalizedPath := strings.ToLower(path)
+	if runtime.GOOS == "windows" {
+		normalizedPath = strings.ReplaceAll(normalizedPath, "\\", "/")
+	}
+
+	for _, o := range pathOwners {
+		if strings.Contains(normalizedPath, o.pattern) {
+			return o.owner
+		}
+	}
+	return ""
+}
+
+// PathMatch is one copy of an executable found on PATH.
+type PathMatch struct {
+	// Path is where the executable was found, in its PATH directory.
+	Path string
+	// Target is Path with symlinks resolved.
+	Target string
+	// Owner is the package manager the copy belongs to ("npm", "brew",
+	// "pipx", ...), judged from Path and then Target, or "" for a native
+	// install.
+	Owner string
+	// Active is set on the first match: the copy a shell runs.
+	Active bool
+}
+
+// FindPathMatches returns every copy of the executable name on PATH, in PATH
+// order. Entries that resolve to the same file, such as /bin and /usr/bin
+// on merged-/usr systems or two symlinks to one install, are reported once,
+// at their first position.
+func FindPathMatches(p platform.Platform, name string) []PathMatch {
+	paths, err := p.FindExecutables(name)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(paths))
+	matches := make([]PathMatch, 0, len(paths))
+	for _, path := range paths {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			target = path
+		}
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+
+		owner := pathOwner(path)
+		if owner == "" {
+			owner = pathOwner(target)
+		}
+		matches = append(matches, PathMatch{
+			Path:   path,
+			Target: target,
+			Owner:  owner,
+			Active: len(matches) == 0,
+		})
+	}
+	return matches
+}
@@ -0,0 +1,310 @@
+package strategies
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// projectMarkers are the entries that make a directory a project root.
+var projectMarkers = []string{"node_modules", ".venv", "package-lock.json", "pyproject.toml", "uv.lock"}
+
+// ProjectStrategy detects agents installed into a project rather than
+// globally: npm packages in node_modules and Python packages in a .venv
+// virtual environment. It walks up from its directory, so from inside a
+// workspace package it also finds dependencies hoisted to the repository
+// root.
+type ProjectStrategy struct {
+	platform platform.Platform
+	dir      string
+}
+
+// NewProjectStrategy creates a strategy detecting the agents installed in
+// the project containing dir.
+func NewProjectStrategy(p platform.Platform, dir string) *ProjectStrategy {
+	return &ProjectStrategy{platform: p, dir: dir}
+}
+
+// Name returns the strategy name.
+func (s *ProjectStrategy) Name() string {
+	return "project"
+}
+
+// Method returns npm, the most common project-local method. Python
+// installations are reported as pip, or as uv in a project with a uv.lock.
+func (s *ProjectStrategy) Method() agent.InstallMethod {
+	return agent.MethodNPM
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *ProjectStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Reads files; no package manager is needed
+}
+
+// Detect scans every project root from the strategy's directory upwards,
+// nearest first, for agents. Each installation has IsGlobal unset and the
+// project root as its InstallPath.
+func (s *ProjectStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	var installations []*agent.Installation
+	for _, root := range projectRoots(s.dir) {
+		for _, agentDef := range agents {
+			if err := ctx.Err(); err != nil {
+				return installations, err
+			}
+			if inst := detectProjectNPM(root, agentDef); inst != nil {
+				installations = append(installations, inst)
+			}
+			if inst := detectProjectPython(root, agentDef); inst != nil {
+				installations = append(installations, inst)
+			}
+		}
+	}
+	return installations, nil
+}
+
+// projectRoots returns dir and each of its ancestors that holds one of the
+// projectMarkers, nearest first. The walk ends at the repository root, the
+// first directory holding .git, and never climbs to the user's home
+// directory or above it, so stray node_modules there are not taken for the
+// project's.
+func projectRoots(dir string) []string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+
+	var roots []string
+	for {
+		for _, marker := range projectMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				roots = append(roots, dir)
+				break
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return roots
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || (home != "" && parent == home) {
+			return roots
+		}
+		dir = parent
+	}
+}
+
+// detectProjectNPM returns the agent's npm package installed in root's
+// node_modules, or nil.
+func detectProjectNPM(root string, agentDef catalog.AgentDef) *agent.Installation {
+	npmMethod, ok := agentDef.InstallMethods["npm"]
+	if !ok {
+		return nil
+	}
+	packageName := npmMethod.Package
+	if packageName == "" {
+		packageName = extractNPMPackageName(npmMethod.Command)
+	}
+	if packageName == "" {
+		return nil
+	}
+
+	modules := filepath.Join(root, "node_modules")
+	path := findProjectExecutable(filepath.Join(modules, ".bin"), agentDef)
+
+	// The package's own package.json is authoritative; package-lock.json
+	// covers a package whose directory was pruned but whose bin link
+	// remains.
+	version := readPackageJSONVersion(filepath.Join(modules, filepath.FromSlash(packageName), "package.json"))
+	if version == "" {
+		if path == "" {
+			return nil
+		}
+		version = packageLockVersion(root, packageName)
+	}
+
+	parsed, _ := agent.ParseVersion(version)
+	return &agent.Installation{
+		AgentID:          agentDef.ID,
+		AgentName:        agentDef.Name,
+		Method:           agent.MethodNPM,
+		InstalledVersion: parsed,
+		ExecutablePath:   path,
+		InstallPath:      root,
+		Metadata: map[string]string{
+			"detected_by": "project",
+			"package":     packageName,
+		},
+	}
+}
+
+// detectProjectPython returns the agent's Python package installed in
+// root's .venv, or nil. The method is uv when the project has a uv.lock.
+func detectProjectPython(root string, agentDef catalog.AgentDef) *agent.Installation {
+	var packageName string
+	for _, key := range []string{"uv", "pip", "pipx"} {
+		if m, ok := agentDef.InstallMethods[key]; ok {
+			if packageName = extractPipPackageName(m.Package, m.Command); packageName != "" {
+				break
+			}
+		}
+	}
+	if packageName == "" {
+		return nil
+	}
+
+	venv := filepath.Join(root, ".venv")
+	version := distInfoVersion(venv, packageName)
+	path := findProjectExecutable(venvBinDir(venv), agentDef)
+	if version == "" && path == "" {
+		return nil
+	}
+
+	method := agent.MethodPip
+	if _, err := os.Stat(filepath.Join(root, "uv.lock")); err == nil {
+		method = agent.MethodUV
+		if version == "" {
+			version = uvLockVersion(root, packageName)
+		}
+	}
+
+	parsed, _ := agent.ParseVersion(version)
+	return &agent.Installation{
+		AgentID:          agentDef.ID,
+		AgentName:        agentDef.Name,
+		Method:           method,
+		InstalledVersion: parsed,
+		ExecutablePath:   path,
+		InstallPath:      root,
+		Metadata: map[string]string{
+			"detected_by": "project",
+			"package":     packageName,
+		},
+	}
+}
+
+// findProjectExecutable returns the first of the agent's executables in
+// dir. On Windows npm links commands as .cmd shims.
+func findProjectExecutable(dir string, agentDef catalog.AgentDef) string {
+	for _, name := range agentDef.Detection.Executables {
+		path := filepath.Join(dir, name)
+		candidates := executableCandidates(path)
+		if runtime.GOOS == "windows" {
+			candidates = append([]string{path + ".cmd"}, candidates...)
+		}
+		for _, candidate := range candidates {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// venvBinDir returns the directory of a virtual environment's scripts.
+func venvBinDir(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts")
+	}
+	return filepath.Join(venv, "bin")
+}
+
+// readPackageJSONVersion returns the version in a package.json, or "".
+func readPackageJSONVersion(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var pkg bunPackage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// packageLockVersion returns the version package-lock.json records for a
+// top-level dependency, or "".
+func packageLockVersion(root, packageName string) string {
+	data, err := os.ReadFile(filepath.Join(root, "package-lock.json"))
+	if err != nil {
+		return ""
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return ""
+	}
+	return lock.Packages["node_modules/"+packageName].Version
+}
+
+// distInfoVersion returns the version of a package installed in a virtual
+// environment, read from its <name>-<version>.dist-info directory, or "".
+func distInfoVersion(venv, packageName string) string {
+	pattern := filepath.Join(venv, "lib", "python*", "site-packages", "*.dist-info")
+	if runtime.GOOS == "windows" {
+		pattern = filepath.Join(venv, "Lib", "site-packages", "*.dist-info")
+	}
+
+	want := normalizePythonName(packageName)
+	matches, _ := filepath.Glob(pattern)
+	for _, match := range matches {
+		name, version, ok := strings.Cut(strings.TrimSuffix(filepath.Base(match), ".dist-info"), "-")
+		if ok && normalizePythonName(name) == want {
+			return version
+		}
+	}
+	return ""
+}
+
+// uvLockVersion returns the version uv.lock pins for a package, or "".
+func uvLockVersion(root, packageName string) string {
+	f, err := os.Open(filepath.Join(root, "uv.lock"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// uv.lock is TOML with one [[package]] table per package, each opening
+	// with its name and version lines.
+	want := normalizePythonName(packageName)
+	var inPackage bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "["):
+			inPackage = false
+		case strings.HasPrefix(line, "name = "):
+			inPackage = normalizePythonName(tomlString(line)) == want
+		case inPackage && strings.HasPrefix(line, "version = "):
+			return tomlString(line)
+		}
+	}
+	return ""
+}
+
+// tomlString returns the quoted value of a `key = "value"` line.
+func tomlString(line string) string {
+	_, value, _ := strings.Cut(line, "=")
+	return strings.Trim(strings.TrimSpace(value), `"`)
+}
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName normalizes a Python package name as PEP 503 does,
+// so "Foo.Bar", "foo-bar" and "foo_bar" compare equal.
+func normalizePythonName(name string) string {
+	return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
@@ -0,0 +1,226 @@
+package strategies
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// signatureCheckTimeout bounds a single signature check command.
+const signatureCheckTimeout = 10 * time.Second
+
+// signatureSkipMethods are install methods whose signatures are left to the
+// package manager strategies, which read the manager's own inventory once
+// per run. Their check commands ("npm list -g ...", "pipx list --json")
+// would otherwise run once per agent, and several exit 0 whether or not the
+// package is installed.
+var signatureSkipMethods = map[string]bool{
+	"npm":      true,
+	"pip":      true,
+	"pipx":     true,
+	"uv":       true,
+	"brew":     true,
+	"homebrew": true,
+	"cargo":    true,
+	"go":       true,
+	"bun":      true,
+	"nix":      true,
+}
+
+// SignatureStrategy detects agents from the per-method signatures in the
+// catalog's detection.signatures. It finds installs no package manager
+// knows about, such as git clones, source builds and binaries unpacked
+// outside PATH.
+type SignatureStrategy struct {
+	platform platform.Platform
+
+	// home replaces a leading "~" and anchors relative signature paths.
+	home string
+}
+
+// NewSignatureStrategy creates a new signature detection strategy.
+func NewSignatureStrategy(p platform.Platform) *SignatureStrategy {
+	home, _ := os.UserHomeDir()
+	return &SignatureStrategy{platform: p, home: home}
+}
+
+// Name returns the strategy name.
+func (s *SignatureStrategy) Name() string {
+	return "signature"
+}
+
+// Method returns the install method this strategy detects. Installations
+// carry the method named by the matching signature; native is reported here
+// because most signatures describe native or source installs.
+func (s *SignatureStrategy) Method() agent.InstallMethod {
+	return agent.MethodNative
+}
+
+// IsApplicable returns true if this strategy can run on the given platform.
+func (s *SignatureStrategy) IsApplicable(p platform.Platform) bool {
+	return true // Signatures are plain file checks and shell commands
+}
+
+// signatureMatch records what satisfied a signature.
+type signatureMatch struct {
+	path   string // matched file or directory; empty for check commands
+	source string // "path", "path_pattern" or "check_cmd"
+}
+
+// Detect checks every agent's signatures and returns an installation for
+// each one that matches, tagged with the signature's install method.
+func (s *SignatureStrategy) Detect(ctx context.Context, agents []catalog.AgentDef) ([]*agent.Installation, error) {
+	var installations []*agent.Installation
+
+	for _, agentDef := range agents {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Map order is random; sort so results are deterministic.
+		methods := make([]string, 0, len(agentDef.Detection.Signatures))
+		for method := range agentDef.Detection.Signatures {
+			if !signatureSkipMethods[method] {
+				methods = append(methods, method)
+			}
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			match, ok := s.match(ctx, agentDef.Detection.Signatures[method])
+			if !ok {
+				continue
+			}
+			installations = append(installations, s.installation(ctx, agentDef, method, match))
+		}
+	}
+
+	return installations, nil
+}
+
+// match reports whether sig is satisfied. Listed paths are alternatives and
+// are tried first, then PathPattern. CheckCmd only decides signatures that
+// name no files: when files are listed but absent, a check such as
+// "command -v foo" would just rediscover what the binary strategy reports.
+func (s *SignatureStrategy) match(ctx context.Context, sig catalog.SignatureDef) (signatureMatch, bool) {
+	for _, p := range sig.Paths {
+		path := s.expand(p)
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return signatureMatch{path: path, source: "path"}, true
+		}
+	}
+
+	if sig.PathPattern != "" {
+		if pattern := s.expand(sig.PathPattern); pattern != "" {
+			matches, err := filepath.Glob(pattern)
+			if err == nil && len(matches) > 0 {
+				return signatureMatch{path: matches[0], source: "path_pattern"}, true
+			}
+		}
+	}
+
+	if sig.CheckCmd != "" && len(sig.Paths) == 0 && sig.PathPattern == "" {
+		if s.runCheck(ctx, sig.CheckCmd) {
+			return signatureMatch{source: "check_cmd"}, true
+		}
+	}
+
+	return signatureMatch{}, false
+}
+
+// installation builds the installation for a matched signature. A matched
+// executable becomes the ExecutablePath; any other file or directory (a
+// cloned project, say) becomes the InstallPath.
+func (s *SignatureStrategy) installation(ctx context.Context, agentDef catalog.AgentDef, method string, match signatureMatch) *agent.Installation {
+	inst := &agent.Installation{
+		AgentID:   agentDef.ID,
+		AgentName: agentDef.Name,
+		Method:    agent.InstallMethod(method),
+		Metadata: map[string]string{
+			"detected_by": "signature",
+			"signature":   match.source,
+		},
+	}
+
+	executable := ""
+	switch {
+	case match.path != "" && isExecutableFile(match.path):
+		executable = match.path
+	case match.path != "":
+		inst.InstallPath = match.path
+		if info, err := os.Stat(match.path); err == nil && !info.IsDir() {
+			inst.InstallPath = filepath.Dir(match.path)
+		}
+	default:
+		for _, name := range agentDef.Detection.Executables {
+			if path, err := s.platform.FindExecutable(name); err == nil {
+				executable = path
+				break
+			}
+		}
+	}
+
+	if match.path != "" {
+		inst.Metadata["signature_path"] = match.path
+	}
+	if executable != "" {
+		inst.ExecutablePath = executable
+		inst.InstalledVersion = ExecutableVersion(ctx, agentDef, executable)
+	}
+
+	return inst
+}
+
+// expand resolves a signature path: a leading "~" is the home directory and
+// relative paths are taken relative to it. It returns "" when a path needs
+// the home directory and none is known.
+func (s *SignatureStrategy) expand(path string) string {
+	switch {
+	case path == "~":
+		path = ""
+	case strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`):
+		path = path[2:]
+	case filepath.IsAbs(path) || strings.HasPrefix(path, "/"):
+		return filepath.Clean(path)
+	}
+
+	if s.home == "" {
+		return ""
+	}
+	return filepath.Join(s.home, path)
+}
+
+// runCheck runs a signature's check command through the platform shell and
+// reports whether it exited successfully.
+func (s *SignatureStrategy) runCheck(ctx context.Context, command string) bool {
+	ctx, cancel := context.WithTimeout(ctx, signatureCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.platform.GetShell(), s.platform.GetShellArg(), command)
+	return cmd.Run() == nil
+}
+
+// isExecutableFile reports whether path is a regular file that can be run.
+// Windows has no execute bit, so any regular file counts there.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
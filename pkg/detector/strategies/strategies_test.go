@@ -3,24 +3,30 @@ package strategies
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
 // mockPlatform implements platform.Platform for testing
 type mockPlatform struct {
 	executables     map[string]string
-	executablePaths map[string]string // maps executable name to full path
+	executablePaths map[string]string   // maps executable name to full path
+	pathMatches     map[string][]string // every PATH match, for FindExecutables
 }
 
 func newMockPlatform() *mockPlatform {
 	return &mockPlatform{
 		executables:     make(map[string]string),
 		executablePaths: make(map[string]string),
+		pathMatches:     make(map[string][]string),
 	}
 }
 
@@ -42,6 +48,9 @@ func (m *mockPlatform) FindExecutable(name string) (string, error) {
 	return "", exec.ErrNotFound
 }
 func (m *mockPlatform) FindExecutables(name string) ([]string, error) {
+	if paths, ok := m.pathMatches[name]; ok {
+		return paths, nil
+	}
 	if path, ok := m.executablePaths[name]; ok {
 		return []string{path}, nil
 	}
@@ -166,32 +175,6 @@ func TestBinaryStrategyIsApplicable(t *testing.T) {
 	}
 }
 
-func TestExtractVersionFromOutput(t *testing.T) {
-	tests := []struct {
-		output   string
-		expected string
-	}{
-		{"version 1.2.3", "1.2.3"},
-		{"v1.2.3", "1.2.3"},
-		{"1.2.3", "1.2.3"},
-		{"claude-code version 1.0.5", "1.0.5"},
-		{"aider v0.50.0", "0.50.0"},
-		{"Version: 2.0.0-beta.1", "2.0.0-beta.1"},
-		{"some text v3.4.5 more text", "3.4.5"},
-		{"no version here", ""},
-		{"", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.output, func(t *testing.T) {
-			result := extractVersionFromOutput(tt.output)
-			if result != tt.expected {
-				t.Errorf("extractVersionFromOutput(%q) = %q, want %q", tt.output, result, tt.expected)
-			}
-		})
-	}
-}
-
 // ========== Pip Strategy Tests ==========
 
 func TestNewPipStrategy(t *testing.T) {
@@ -443,43 +426,6 @@ func TestExtractBrewPackageName(t *testing.T) {
 
 // ========== Additional Version Extraction Tests ==========
 
-func TestExtractVersionFromOutput_EdgeCases(t *testing.T) {
-	tests := []struct {
-		name     string
-		output   string
-		expected string
-	}{
-		{"version with v prefix", "v1.2.3", "1.2.3"},
-		{"version without v prefix", "1.2.3", "1.2.3"},
-		{"version word prefix", "version 1.2.3", "1.2.3"},
-		{"Version capitalized", "Version: 1.2.3", "1.2.3"},
-		{"prerelease version", "v1.0.0-alpha.1", "1.0.0-alpha.1"},
-		{"beta version", "2.0.0-beta.5", "2.0.0-beta.5"},
-		{"rc version", "3.0.0-rc.1", "3.0.0-rc.1"},
-		{"multiline output first line", "v1.2.3\nsome other text", "1.2.3"},
-		{"multiline output middle", "some text\nv1.2.3\nmore text", "1.2.3"},
-		{"version in sentence", "claude-code version 1.0.5 is installed", "1.0.5"},
-		{"version with extra info", "aider v0.50.0 (Python 3.11)", "0.50.0"},
-		{"no version number", "installed successfully", ""},
-		{"only text", "hello world", ""},
-		{"empty string", "", ""},
-		{"whitespace only", "   \n  \t  ", ""},
-		{"version at end", "Package version is 4.5.6", "4.5.6"},
-		{"version with build metadata", "1.0.0-beta.1+build.123", "1.0.0-beta.1"},
-		{"two digit version", "v10.20.30", "10.20.30"},
-		{"version with leading zeros", "v01.02.03", "01.02.03"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractVersionFromOutput(tt.output)
-			if result != tt.expected {
-				t.Errorf("extractVersionFromOutput(%q) = %q, want %q", tt.output, result, tt.expected)
-			}
-		})
-	}
-}
-
 // ========== NPM Package Name Extraction Edge Cases ==========
 
 func TestExtractNPMPackageName_EdgeCases(t *testing.T) {
@@ -1532,3 +1478,930 @@ func TestBinaryStrategy_Detect_ParallelPreservesOrder(t *testing.T) {
 		}
 	}
 }
+
+// ========== Signature Strategy Tests ==========
+
+func TestSignatureStrategyBasics(t *testing.T) {
+	strategy := NewSignatureStrategy(newMockPlatform())
+	if strategy.Name() != "signature" {
+		t.Errorf("Name() = %q, want %q", strategy.Name(), "signature")
+	}
+	if strategy.Method() != agent.MethodNative {
+		t.Errorf("Method() = %v, want %v", strategy.Method(), agent.MethodNative)
+	}
+	if !strategy.IsApplicable(newMockPlatform()) {
+		t.Error("IsApplicable() = false, want true")
+	}
+}
+
+func TestSignatureStrategyExpand(t *testing.T) {
+	s := &SignatureStrategy{home: "/home/user"}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", "/home/user"},
+		{"~/.local/bin/foo", "/home/user/.local/bin/foo"},
+		{"dexter/package.json", "/home/user/dexter/package.json"},
+		{"/usr/local/bin/foo", "/usr/local/bin/foo"},
+	}
+	for _, tt := range tests {
+		if got := s.expand(tt.path); got != filepath.FromSlash(tt.want) {
+			t.Errorf("expand(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got := (&SignatureStrategy{}).expand("~/foo"); got != "" {
+		t.Errorf("expand without a home = %q, want empty", got)
+	}
+}
+
+func TestSignatureStrategyDetect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixtures are shell scripts and signatures use POSIX commands")
+	}
+
+	home, err := filepath.Abs(filepath.Join("testdata", "signature", "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plat := newMockPlatform()
+	plat.executablePaths["checked"] = "/opt/checked/bin/checked"
+	strategy := &SignatureStrategy{platform: plat, home: home}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "fakeagent", Name: "Fake Agent",
+			Detection: catalog.DetectionDef{
+				VersionCmd: "fakeagent --version",
+				Signatures: map[string]catalog.SignatureDef{
+					"native": {Paths: []string{"~/.local/bin/missing", "~/.local/bin/fakeagent"}},
+				},
+			},
+		},
+		{
+			ID: "dexterish", Name: "Dexterish",
+			Detection: catalog.DetectionDef{
+				Signatures: map[string]catalog.SignatureDef{
+					"git": {Paths: []string{"projects/dexterish/package.json"}},
+				},
+			},
+		},
+		{
+			ID: "globagent", Name: "Glob Agent",
+			Detection: catalog.DetectionDef{
+				VersionCmd: "globagent --version",
+				Signatures: map[string]catalog.SignatureDef{
+					"binary": {PathPattern: "~/tools/globagent-*/bin/globagent"},
+				},
+			},
+		},
+		{
+			ID: "checked", Name: "Checked",
+			Detection: catalog.DetectionDef{
+				Executables: []string{"checked"},
+				Signatures: map[string]catalog.SignatureDef{
+					"scoop":  {CheckCmd: "exit 0"},
+					"native": {CheckCmd: "exit 1"},
+					// Files are listed, so the check is not consulted.
+					"binary": {CheckCmd: "exit 0", Paths: []string{"~/.local/bin/checked"}},
+					// Left to the go strategy.
+					"go": {CheckCmd: "exit 0"},
+				},
+			},
+		},
+		{
+			// Left to the npm strategy.
+			ID: "npmagent", Name: "NPM Agent",
+			Detection: catalog.DetectionDef{
+				Signatures: map[string]catalog.SignatureDef{
+					"npm": {CheckCmd: "exit 0"},
+				},
+			},
+		},
+		{
+			ID: "absent", Name: "Absent",
+			Detection: catalog.DetectionDef{
+				Signatures: map[string]catalog.SignatureDef{
+					"native": {Paths: []string{"~/.absent/bin/absent"}, PathPattern: "~/tools/absent-*"},
+				},
+			},
+		},
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	got := make(map[string]*agent.Installation)
+	for _, inst := range installations {
+		got[inst.AgentID+":"+string(inst.Method)] = inst
+	}
+	if len(got) != 4 {
+		t.Errorf("got %d installations (%v), want 4", len(installations), got)
+	}
+
+	if inst := got["fakeagent:native"]; inst == nil {
+		t.Error("fakeagent not detected from its second path")
+	} else {
+		if inst.ExecutablePath != filepath.Join(home, ".local", "bin", "fakeagent") {
+			t.Errorf("fakeagent ExecutablePath = %q", inst.ExecutablePath)
+		}
+		if inst.InstalledVersion.String() != "1.2.3" {
+			t.Errorf("fakeagent version = %q, want 1.2.3", inst.InstalledVersion.String())
+		}
+		if inst.Metadata["detected_by"] != "signature" || inst.Metadata["signature"] != "path" {
+			t.Errorf("fakeagent metadata = %v", inst.Metadata)
+		}
+	}
+
+	if inst := got["dexterish:git"]; inst == nil {
+		t.Error("dexterish clone not detected")
+	} else {
+		if inst.InstallPath != filepath.Join(home, "projects", "dexterish") {
+			t.Errorf("dexterish InstallPath = %q", inst.InstallPath)
+		}
+		if inst.ExecutablePath != "" {
+			t.Errorf("dexterish ExecutablePath = %q, want empty", inst.ExecutablePath)
+		}
+	}
+
+	if inst := got["globagent:binary"]; inst == nil {
+		t.Error("globagent not detected from its path pattern")
+	} else {
+		if inst.Metadata["signature"] != "path_pattern" {
+			t.Errorf("globagent signature = %q, want path_pattern", inst.Metadata["signature"])
+		}
+		if inst.InstalledVersion.String() != "2.0.1" {
+			t.Errorf("globagent version = %q, want 2.0.1", inst.InstalledVersion.String())
+		}
+	}
+
+	if inst := got["checked:scoop"]; inst == nil {
+		t.Error("checked not detected from its check command")
+	} else if inst.ExecutablePath != "/opt/checked/bin/checked" {
+		t.Errorf("checked ExecutablePath = %q, want the PATH match", inst.ExecutablePath)
+	}
+}
+
+// ========== PATH Match Tests ==========
+
+func TestPathOwner(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/usr/local/lib/node_modules/@anthropic-ai/claude-code/cli.js", "npm"},
+		{"/Users/kevin/.bun/bin/app", "bun"},
+		{"/Users/kevin/.local/pipx/venvs/aider/bin/aider", "pipx"},
+		{"/opt/homebrew/bin/droid", "brew"},
+		{"/Users/kevin/.asdf/installs/nodejs/20.0.0/bin/node", "asdf"},
+		{"/Users/kevin/.local/share/mise/installs/python/3.12/bin/app", "mise"},
+		{"/Users/kevin/.cargo/bin/app", "cargo"},
+		{"/nix/store/abc123-tenere-0.11.1/bin/tenere", "nix"},
+		{"/usr/local/bin/claude", ""},
+	}
+	for _, tt := range tests {
+		if got := pathOwner(tt.path); got != tt.want {
+			t.Errorf("pathOwner(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// writeExecutable creates an executable file at dir/name.
+func writeExecutable(t *testing.T, dir, name string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBinaryStrategyReportsShadowedCopies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses symlinks")
+	}
+
+	root := t.TempDir()
+	active := writeExecutable(t, filepath.Join(root, "local", "bin"), "claude")
+	npmCopy := writeExecutable(t, filepath.Join(root, "npm", "bin"), "claude")
+	stale := writeExecutable(t, filepath.Join(root, "opt", "bin"), "claude")
+
+	// A second PATH entry linking to the active copy is the same install.
+	linkDir := filepath.Join(root, "link")
+	if err := os.MkdirAll(linkDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(linkDir, "claude")
+	if err := os.Symlink(active, link); err != nil {
+		t.Fatal(err)
+	}
+
+	plat := newMockPlatform()
+	plat.pathMatches["claude"] = []string{active, link, npmCopy, stale}
+
+	matches := FindPathMatches(plat, "claude")
+	if len(matches) != 3 {
+		t.Fatalf("FindPathMatches() = %+v, want 3 distinct copies", matches)
+	}
+	if !matches[0].Active || matches[0].Path != active || matches[0].Owner != "" {
+		t.Errorf("matches[0] = %+v, want the active native copy", matches[0])
+	}
+	if matches[1].Active || matches[1].Owner != "npm" {
+		t.Errorf("matches[1] = %+v, want a shadowed npm copy", matches[1])
+	}
+
+	strategy := NewBinaryStrategy(plat)
+	installations, err := strategy.Detect(context.Background(), []catalog.AgentDef{{
+		ID:             "claude-code",
+		Name:           "Claude Code",
+		InstallMethods: map[string]catalog.InstallMethodDef{"native": {Method: "native"}},
+		Detection:      catalog.DetectionDef{Executables: []string{"claude"}},
+	}})
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+	if len(installations) != 2 {
+		t.Fatalf("Detect() returned %d installations, want the two native copies", len(installations))
+	}
+
+	first, second := installations[0], installations[1]
+	if first.ExecutablePath != active || first.Metadata["path_status"] != "active" || first.Metadata["path_rank"] != "1" {
+		t.Errorf("first installation = %s %v", first.ExecutablePath, first.Metadata)
+	}
+	if second.ExecutablePath != stale || second.Metadata["path_status"] != "shadowed" ||
+		second.Metadata["path_rank"] != "3" || second.Metadata["shadowed_by"] != active {
+		t.Errorf("second installation = %s %v", second.ExecutablePath, second.Metadata)
+	}
+	if first.Key() == second.Key() {
+		t.Error("shadowed copy should have its own installation key")
+	}
+}
+
+// ========== Custom Path Strategy Tests ==========
+
+func TestCustomPathStrategyDetect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on the execute bit")
+	}
+
+	root := t.TempDir()
+	t.Setenv("AGENTMGR_TEST_TOOLS", filepath.Join(root, "opt", "tools"))
+
+	toolsCopy := writeExecutable(t, filepath.Join(root, "opt", "tools", "aider", "bin"), "aider")
+	venvA := writeExecutable(t, filepath.Join(root, "projects", "a", ".venv", "bin"), "aider")
+	venvB := writeExecutable(t, filepath.Join(root, "projects", "b", ".venv", "bin"), "aider")
+	explicit := writeExecutable(t, filepath.Join(root, "bin"), "aider-dev")
+	// Matched by the glob but named differently: ignored.
+	writeExecutable(t, filepath.Join(root, "loose"), "other")
+
+	cfg := &config.Config{}
+	cfg.Agents = map[string]config.AgentConfig{
+		"aider": {CustomPaths: []string{
+			"$AGENTMGR_TEST_TOOLS/aider/bin",
+			filepath.Join(root, "projects", "*", ".venv", "bin"),
+			explicit,
+			filepath.Join(root, "loose", "*"),
+			// Listed twice through different entries: reported once.
+			"${AGENTMGR_TEST_TOOLS}/aider/bin/aider",
+			filepath.Join(root, "missing"),
+		}},
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID:   "aider",
+			Name: "Aider",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"pip":    {Method: "pip"},
+				"native": {Method: "native"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"aider"}},
+		},
+		{
+			// No custom paths configured.
+			ID:        "claude-code",
+			Name:      "Claude Code",
+			Detection: catalog.DetectionDef{Executables: []string{"claude"}},
+		},
+	}
+
+	strategy := NewCustomPathStrategy(newMockPlatform(), cfg)
+	if strategy.Name() != "custom_path" {
+		t.Errorf("Name() = %q, want custom_path", strategy.Name())
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	want := map[string]agent.InstallMethod{
+		toolsCopy: agent.MethodNative,
+		venvA:     agent.MethodPip,
+		venvB:     agent.MethodPip,
+		explicit:  agent.MethodNative,
+	}
+	if len(installations) != len(want) {
+		for _, inst := range installations {
+			t.Logf("found %s (%s)", inst.ExecutablePath, inst.Method)
+		}
+		t.Fatalf("got %d installations, want %d", len(installations), len(want))
+	}
+	for _, inst := range installations {
+		method, ok := want[inst.ExecutablePath]
+		if !ok {
+			t.Errorf("unexpected installation at %s", inst.ExecutablePath)
+			continue
+		}
+		if inst.Method != method {
+			t.Errorf("%s: Method = %s, want %s", inst.ExecutablePath, inst.Method, method)
+		}
+		if inst.AgentID != "aider" || inst.Metadata["detected_by"] != "custom_path" || inst.Metadata["custom_path"] == "" {
+			t.Errorf("%s: installation = %+v", inst.ExecutablePath, inst)
+		}
+	}
+}
+
+func TestCustomPathStrategyNoConfig(t *testing.T) {
+	strategy := NewCustomPathStrategy(newMockPlatform(), nil)
+	installations, err := strategy.Detect(context.Background(), []catalog.AgentDef{{ID: "aider"}})
+	if err != nil || len(installations) != 0 {
+		t.Errorf("Detect() = %v, %v; want nothing", installations, err)
+	}
+}
+
+func TestExpandUserPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	t.Setenv("AGENTMGR_TEST_DIR", "/opt/tools")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"~", home},
+		{"~/bin", filepath.Join(home, "bin")},
+		{"$AGENTMGR_TEST_DIR/aider", "/opt/tools/aider"},
+		{"${AGENTMGR_TEST_DIR}/*/bin", "/opt/tools/*/bin"},
+		{"/usr/local/bin", "/usr/local/bin"},
+	}
+	for _, tt := range tests {
+		if got := ExpandUserPath(tt.in); got != tt.want {
+			t.Errorf("ExpandUserPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCargoStrategyDetect(t *testing.T) {
+	cargoHome, err := filepath.Abs(filepath.Join("testdata", "cargo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := &CargoStrategy{platform: newMockPlatform(), cargoHome: cargoHome}
+
+	if strategy.Name() != "cargo" || strategy.Method() != agent.MethodCargo {
+		t.Errorf("Name/Method = %q/%q", strategy.Name(), strategy.Method())
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "aichat", Name: "AIChat",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"cargo": {Method: "cargo", Command: "cargo install aichat --locked"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"aichat"}},
+		},
+		{
+			// Installed from git under a different crate name; matched by
+			// its binary.
+			ID: "tenere", Name: "Tenere",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"cargo": {Method: "cargo", Package: "tenere"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"tenere"}},
+		},
+		{
+			ID: "missing", Name: "Missing",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"cargo": {Method: "cargo", Package: "missing"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"missing"}},
+		},
+		{
+			// No cargo method.
+			ID: "aichat-npm", Name: "AIChat npm",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"npm": {Method: "npm", Package: "aichat"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"aichat"}},
+		},
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 2 {
+		t.Fatalf("Detect() returned %d installations, want 2", len(installations))
+	}
+
+	byID := make(map[string]*agent.Installation)
+	for _, inst := range installations {
+		byID[inst.AgentID] = inst
+	}
+
+	aichat := byID["aichat"]
+	if aichat == nil {
+		t.Fatal("aichat not detected")
+	}
+	if aichat.InstalledVersion.String() != "0.28.0" {
+		t.Errorf("aichat version = %q, want 0.28.0", aichat.InstalledVersion.String())
+	}
+	if want := filepath.Join(cargoHome, "bin", "aichat"); aichat.ExecutablePath != want {
+		t.Errorf("aichat path = %q, want %q", aichat.ExecutablePath, want)
+	}
+	if aichat.Metadata["source"] != "registry+https://github.com/rust-lang/crates.io-index" {
+		t.Errorf("aichat source = %q", aichat.Metadata["source"])
+	}
+
+	tenere := byID["tenere"]
+	if tenere == nil {
+		t.Fatal("tenere not detected")
+	}
+	if tenere.Metadata["package"] != "tenere-cli" || tenere.InstalledVersion.String() != "0.11.1" {
+		t.Errorf("tenere = %s %s, want tenere-cli 0.11.1", tenere.Metadata["package"], tenere.InstalledVersion.String())
+	}
+
+	// No tracking file: nothing installed, no error.
+	empty := &CargoStrategy{platform: newMockPlatform(), cargoHome: t.TempDir()}
+	if installations, err := empty.Detect(context.Background(), agents); err != nil || len(installations) != 0 {
+		t.Errorf("Detect() without .crates2.json = %v, %v; want none", installations, err)
+	}
+
+	// A tracking file that does not parse is reported, not taken for none.
+	broken := &CargoStrategy{platform: newMockPlatform(), cargoHome: t.TempDir()}
+	if err := os.WriteFile(filepath.Join(broken.cargoHome, ".crates2.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broken.Detect(context.Background(), agents); err == nil {
+		t.Errorf("Detect() with a malformed .crates2.json error = %v, want a parse error", err)
+	}
+}
+
+func TestParseCrateKey(t *testing.T) {
+	crate, ok := parseCrateKey("aichat 0.28.0 (registry+https://github.com/rust-lang/crates.io-index)")
+	if !ok || crate.name != "aichat" || crate.version != "0.28.0" || crate.source != "registry+https://github.com/rust-lang/crates.io-index" {
+		t.Errorf("parseCrateKey() = %+v, %v", crate, ok)
+	}
+	if _, ok := parseCrateKey("aichat"); ok {
+		t.Error("parseCrateKey(\"aichat\") should fail")
+	}
+}
+
+func TestExtractCargoPackageName(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"cargo install aichat", "aichat"},
+		{"cargo install --locked aichat", "aichat"},
+		{"cargo install --git https://github.com/pythops/tenere tenere", "tenere"},
+		{"cargo build", ""},
+	}
+	for _, tt := range tests {
+		if got := extractCargoPackageName(tt.command); got != tt.want {
+			t.Errorf("extractCargoPackageName(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestGoStrategyDetect(t *testing.T) {
+	// The test binary carries build info for this module, like any binary
+	// put in GOBIN by go install.
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binDir := t.TempDir()
+	name := "goagent"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if err := os.WriteFile(filepath.Join(binDir, name), data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeExecutable(t, binDir, "notgo")
+
+	strategy := &GoStrategy{platform: newMockPlatform(), binDirs: []string{t.TempDir(), binDir}}
+	if strategy.Name() != "go" || strategy.Method() != agent.MethodGo {
+		t.Errorf("Name/Method = %q/%q", strategy.Name(), strategy.Method())
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "goagent", Name: "Go Agent",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"go": {Method: "go", Command: "go install github.com/kevinelliott/agentmanager/cmd/agentmgr@latest"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"goagent"}},
+		},
+		{
+			// Same binary name, built from another module.
+			ID: "other", Name: "Other",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"go": {Method: "go", Package: "github.com/example/goagent"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"goagent"}},
+		},
+		{
+			// Not a Go binary.
+			ID: "notgo", Name: "Not Go",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"go": {Method: "go", Package: "github.com/example/notgo"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"notgo"}},
+		},
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 1 {
+		t.Fatalf("Detect() returned %d installations, want 1", len(installations))
+	}
+	inst := installations[0]
+	if inst.AgentID != "goagent" || inst.Method != agent.MethodGo {
+		t.Errorf("installation = %s/%s, want goagent/go", inst.AgentID, inst.Method)
+	}
+	if inst.Metadata["module"] != "github.com/kevinelliott/agentmanager" {
+		t.Errorf("module = %q", inst.Metadata["module"])
+	}
+	if inst.Metadata["go_version"] == "" {
+		t.Error("go_version metadata missing")
+	}
+}
+
+func TestGoPackageMatches(t *testing.T) {
+	tests := []struct {
+		mainPkg, mod, pkg string
+		want              bool
+	}{
+		{"github.com/a/b/cmd/tool", "github.com/a/b", "github.com/a/b/cmd/tool", true},
+		{"github.com/a/b/cmd/tool", "github.com/a/b", "github.com/a/b", true},
+		{"github.com/a/b", "github.com/a/b", "github.com/a/b/cmd/other", true},
+		{"github.com/a/bc", "github.com/a/bc", "github.com/a/b", false},
+		{"github.com/x/y", "github.com/x/y", "github.com/a/b", false},
+	}
+	for _, tt := range tests {
+		if got := goPackageMatches(tt.mainPkg, tt.mod, tt.pkg); got != tt.want {
+			t.Errorf("goPackageMatches(%q, %q, %q) = %v, want %v", tt.mainPkg, tt.mod, tt.pkg, got, tt.want)
+		}
+	}
+
+	if got := extractGoPackagePath("go install github.com/a/b/cmd/tool@v1.2.3"); got != "github.com/a/b/cmd/tool" {
+		t.Errorf("extractGoPackagePath() = %q", got)
+	}
+}
+
+func TestBunStrategyDetect(t *testing.T) {
+	bunInstall, err := filepath.Abs(filepath.Join("testdata", "bun"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := &BunStrategy{platform: newMockPlatform(), bunInstall: bunInstall}
+	if strategy.Name() != "bun" || strategy.Method() != agent.MethodBun {
+		t.Errorf("Name/Method = %q/%q", strategy.Name(), strategy.Method())
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "bunagent", Name: "Bun Agent",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"bun": {Method: "bun", Command: "bun add -g @acme/bunagent@latest"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"bunagent"}},
+		},
+		{
+			ID: "missing", Name: "Missing",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"bun": {Method: "bun", Package: "missing"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"missing"}},
+		},
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 1 {
+		t.Fatalf("Detect() returned %d installations, want 1", len(installations))
+	}
+	inst := installations[0]
+	if inst.AgentID != "bunagent" || inst.InstalledVersion.String() != "1.4.2" {
+		t.Errorf("installation = %s %s, want bunagent 1.4.2", inst.AgentID, inst.InstalledVersion.String())
+	}
+	if want := filepath.Join(bunInstall, "bin", "bunagent"); inst.ExecutablePath != want {
+		t.Errorf("path = %q, want %q", inst.ExecutablePath, want)
+	}
+
+	if got := extractBunPackageName("bun install -g pkg@1.2.3"); got != "pkg" {
+		t.Errorf("extractBunPackageName() = %q, want pkg", got)
+	}
+}
+
+func TestNixStrategyDetect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Nix is not available on Windows")
+	}
+
+	root := t.TempDir()
+	store := filepath.Join(root, "store")
+	storePath := filepath.Join(store, "0c5nsbz1bhzw2y0h9xgc0ddm3ljqlk2c-kubectl-ai-0.0.20")
+	binary := writeExecutable(t, filepath.Join(storePath, "bin"), "kubectl-ai")
+
+	profile := filepath.Join(root, "profile")
+	if err := os.MkdirAll(filepath.Join(profile, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(binary, filepath.Join(profile, "bin", "kubectl-ai")); err != nil {
+		t.Fatal(err)
+	}
+	// Not from the store.
+	writeExecutable(t, filepath.Join(profile, "bin"), "loose")
+
+	manifest := fmt.Sprintf(`{"version": 3, "elements": {"kubectl-ai": {
+		"active": true,
+		"attrPath": "legacyPackages.x86_64-linux.kubectl-ai",
+		"originalUrl": "flake:nixpkgs",
+		"storePaths": [%q]
+	}}}`, storePath)
+	if err := os.WriteFile(filepath.Join(profile, "manifest.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second location linking to the same profile is searched once.
+	alias := filepath.Join(root, ".nix-profile")
+	if err := os.Symlink(profile, alias); err != nil {
+		t.Fatal(err)
+	}
+
+	strategy := &NixStrategy{
+		platform: newMockPlatform(),
+		profiles: []string{alias, profile, filepath.Join(root, "missing")},
+		store:    store,
+	}
+	if strategy.Name() != "nix" || strategy.Method() != agent.MethodNix {
+		t.Errorf("Name/Method = %q/%q", strategy.Name(), strategy.Method())
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "kubectl-ai", Name: "kubectl-ai",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"nix": {Method: "nix", Command: "nix profile install nixpkgs#kubectl-ai"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"kubectl-ai"}},
+		},
+		{
+			ID: "loose", Name: "Loose",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"nix": {Method: "nix"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"loose"}},
+		},
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 1 {
+		t.Fatalf("Detect() returned %d installations, want 1", len(installations))
+	}
+	inst := installations[0]
+	if inst.AgentID != "kubectl-ai" || inst.InstalledVersion.String() != "0.0.20" {
+		t.Errorf("installation = %s %s, want kubectl-ai 0.0.20", inst.AgentID, inst.InstalledVersion.String())
+	}
+	if want := filepath.Join(alias, "bin", "kubectl-ai"); inst.ExecutablePath != want {
+		t.Errorf("path = %q, want %q", inst.ExecutablePath, want)
+	}
+	if inst.InstallPath != storePath {
+		t.Errorf("install path = %q, want %q", inst.InstallPath, storePath)
+	}
+	if inst.Metadata["attr_path"] != "legacyPackages.x86_64-linux.kubectl-ai" || inst.Metadata["flake"] != "flake:nixpkgs" {
+		t.Errorf("manifest metadata = %v", inst.Metadata)
+	}
+}
+
+func TestNixParseStorePath(t *testing.T) {
+	s := &NixStrategy{store: "/nix/store"}
+	tests := []struct {
+		path, storePath, name, version string
+		ok                             bool
+	}{
+		{"/nix/store/abc-tenere-0.11.1/bin/tenere", "/nix/store/abc-tenere-0.11.1", "tenere", "0.11.1", true},
+		{"/nix/store/abc-kubectl-ai-0.0.20/bin/kubectl-ai", "/nix/store/abc-kubectl-ai-0.0.20", "kubectl-ai", "0.0.20", true},
+		{"/nix/store/abc-aider-chat-with-playwright", "/nix/store/abc-aider-chat-with-playwright", "aider-chat-with-playwright", "", true},
+		{"/usr/bin/tenere", "", "", "", false},
+		{"/nix/store", "", "", "", false},
+	}
+	for _, tt := range tests {
+		storePath, name, version, ok := s.parseStorePath(tt.path)
+		if storePath != tt.storePath || name != tt.name || version != tt.version || ok != tt.ok {
+			t.Errorf("parseStorePath(%q) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.path, storePath, name, version, ok, tt.storePath, tt.name, tt.version, tt.ok)
+		}
+	}
+}
+
+// ========== Project Strategy Tests ==========
+
+func writeProjectFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectStrategyDetect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture uses the POSIX virtual environment layout")
+	}
+
+	root := t.TempDir()
+	modules := filepath.Join(root, "node_modules")
+	writeExecutable(t, filepath.Join(modules, ".bin"), "npmagent")
+	writeProjectFile(t, filepath.Join(modules, "@acme", "npmagent", "package.json"), `{"version": "2.3.4"}`)
+	writeProjectFile(t, filepath.Join(root, "package-lock.json"),
+		`{"packages": {"node_modules/@acme/npmagent": {"version": "2.3.4"}}}`)
+
+	writeExecutable(t, filepath.Join(root, ".venv", "bin"), "pyagent")
+	if err := os.MkdirAll(filepath.Join(root, ".venv", "lib", "python3.12", "site-packages", "py_agent-0.7.0.dist-info"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectFile(t, filepath.Join(root, "uv.lock"), "version = 1\n\n[[package]]\nname = \"other\"\nversion = \"9.9.9\"\n\n[[package]]\nname = \"py-agent\"\nversion = \"0.7.0\"\n")
+
+	// Detection starts in a workspace package below the project root.
+	start := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(start, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	agents := []catalog.AgentDef{
+		{
+			ID: "npmagent", Name: "NPM Agent",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"npm": {Method: "npm", Command: "npm install -g @acme/npmagent"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"npmagent"}},
+		},
+		{
+			ID: "pyagent", Name: "Py Agent",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"pip": {Method: "pip", Package: "py-agent"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"pyagent"}},
+		},
+		{
+			ID: "missing", Name: "Missing",
+			InstallMethods: map[string]catalog.InstallMethodDef{
+				"npm": {Method: "npm", Package: "missing"},
+				"pip": {Method: "pip", Package: "missing"},
+			},
+			Detection: catalog.DetectionDef{Executables: []string{"missing"}},
+		},
+	}
+
+	strategy := NewProjectStrategy(newMockPlatform(), start)
+	if strategy.Name() != "project" || !strategy.IsApplicable(newMockPlatform()) {
+		t.Errorf("Name/IsApplicable = %q/%v", strategy.Name(), strategy.IsApplicable(newMockPlatform()))
+	}
+
+	installations, err := strategy.Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 2 {
+		t.Fatalf("Detect() returned %d installations, want 2: %+v", len(installations), installations)
+	}
+
+	tests := []struct {
+		inst    *agent.Installation
+		id      string
+		method  agent.InstallMethod
+		version string
+		path    string
+	}{
+		{installations[0], "npmagent", agent.MethodNPM, "2.3.4", filepath.Join(modules, ".bin", "npmagent")},
+		{installations[1], "pyagent", agent.MethodUV, "0.7.0", filepath.Join(root, ".venv", "bin", "pyagent")},
+	}
+	for _, tt := range tests {
+		inst := tt.inst
+		if inst.AgentID != tt.id || inst.Method != tt.method || inst.InstalledVersion.String() != tt.version {
+			t.Errorf("installation = %s %s %s, want %s %s %s", inst.AgentID, inst.Method, inst.InstalledVersion.String(), tt.id, tt.method, tt.version)
+		}
+		if inst.ExecutablePath != tt.path {
+			t.Errorf("%s path = %q, want %q", tt.id, inst.ExecutablePath, tt.path)
+		}
+		if inst.IsGlobal || inst.InstallPath != root {
+			t.Errorf("%s IsGlobal/InstallPath = %v/%q, want false/%q", tt.id, inst.IsGlobal, inst.InstallPath, root)
+		}
+	}
+}
+
+func TestProjectStrategyLockFileVersions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture uses the POSIX virtual environment layout")
+	}
+
+	// Only the executables and lock files are present: the versions come
+	// from package-lock.json and uv.lock.
+	root := t.TempDir()
+	writeExecutable(t, filepath.Join(root, "node_modules", ".bin"), "npmagent")
+	writeProjectFile(t, filepath.Join(root, "package-lock.json"),
+		`{"packages": {"node_modules/npmagent": {"version": "1.0.1"}}}`)
+	writeExecutable(t, filepath.Join(root, ".venv", "bin"), "pyagent")
+	writeProjectFile(t, filepath.Join(root, "uv.lock"), "[[package]]\nname = \"Py_Agent\"\nversion = \"0.8.0\"\n")
+
+	agents := []catalog.AgentDef{
+		{
+			ID:             "npmagent",
+			InstallMethods: map[string]catalog.InstallMethodDef{"npm": {Method: "npm", Package: "npmagent"}},
+			Detection:      catalog.DetectionDef{Executables: []string{"npmagent"}},
+		},
+		{
+			ID:             "pyagent",
+			InstallMethods: map[string]catalog.InstallMethodDef{"uv": {Method: "uv", Command: "uv tool install py-agent"}},
+			Detection:      catalog.DetectionDef{Executables: []string{"pyagent"}},
+		},
+	}
+
+	installations, err := NewProjectStrategy(newMockPlatform(), root).Detect(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 2 {
+		t.Fatalf("Detect() returned %d installations, want 2", len(installations))
+	}
+	if got := installations[0].InstalledVersion.String(); got != "1.0.1" {
+		t.Errorf("npm version = %s, want 1.0.1 from package-lock.json", got)
+	}
+	if got := installations[1].InstalledVersion.String(); got != "0.8.0" {
+		t.Errorf("uv version = %s, want 0.8.0 from uv.lock", got)
+	}
+}
+
+func TestProjectRoots(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFile(t, filepath.Join(root, "pyproject.toml"), "")
+	nested := filepath.Join(root, "a", "b")
+	writeProjectFile(t, filepath.Join(nested, "package-lock.json"), "{}")
+
+	roots := projectRoots(filepath.Join(nested, "src"))
+	if len(roots) < 2 || roots[0] != nested || roots[1] != root {
+		t.Errorf("projectRoots() = %v, want %q then %q first", roots, nested, root)
+	}
+}
+
+func TestProjectRootsStopsAtRepositoryRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	writeProjectFile(t, filepath.Join(home, "package-lock.json"), "{}")
+	repo := filepath.Join(home, "src", "repo")
+	writeProjectFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeProjectFile(t, filepath.Join(filepath.Dir(repo), "pyproject.toml"), "")
+	writeProjectFile(t, filepath.Join(repo, "pyproject.toml"), "")
+	nested := filepath.Join(repo, "pkg")
+	writeProjectFile(t, filepath.Join(nested, "package-lock.json"), "{}")
+
+	roots := projectRoots(nested)
+	if len(roots) != 2 || roots[0] != nested || roots[1] != repo {
+		t.Errorf("projectRoots() = %v, want [%s %s]", roots, nested, repo)
+	}
+
+	// Outside any repository the walk stops below the home directory.
+	outside := filepath.Join(home, "scratch", "tool")
+	writeProjectFile(t, filepath.Join(outside, "uv.lock"), "")
+	roots = projectRoots(outside)
+	if len(roots) != 1 || roots[0] != outside {
+		t.Errorf("projectRoots() = %v, want [%s]", roots, outside)
+	}
+}
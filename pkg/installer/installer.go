@@ -4,6 +4,9 @@ package installer
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
@@ -11,119 +14,214 @@ import (
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
-// Manager orchestrates installation operations.
+// Manager orchestrates installation operations. Each catalog install
+// method is routed to the provider registered for it; NewManager registers
+// the built-in providers and RegisterProvider adds more.
 type Manager struct {
 	npm    *providers.NPMProvider
 	pip    *providers.PipProvider
 	brew   *providers.BrewProvider
+	bun    *providers.BunProvider
+	cargo  *providers.CargoProvider
+	golang *providers.GoProvider
+	binary *providers.BinaryProvider
 	native *providers.NativeProvider
 	plat   platform.Platform
+
+	mu        sync.RWMutex
+	providers map[string]providers.Provider // catalog method -> provider
 }
 
 // NewManager creates a new installation manager.
 func NewManager(p platform.Platform) *Manager {
-	return &Manager{
-		npm:    providers.NewNPMProvider(p),
-		pip:    providers.NewPipProvider(p),
-		brew:   providers.NewBrewProvider(p),
-		native: providers.NewNativeProvider(p),
-		plat:   p,
+	m := &Manager{
+		npm:       providers.NewNPMProvider(p),
+		pip:       providers.NewPipProvider(p),
+		brew:      providers.NewBrewProvider(p),
+		bun:       providers.NewBunProvider(p),
+		cargo:     providers.NewCargoProvider(p),
+		golang:    providers.NewGoProvider(p),
+		binary:    providers.NewBinaryProvider(p),
+		native:    providers.NewNativeProvider(p),
+		plat:      p,
+		providers: make(map[string]providers.Provider),
 	}
-}
-
-// Install installs an agent using the specified method.
-func (m *Manager) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*providers.Result, error) {
-	switch method.Method {
-	case "npm":
-		if !m.npm.IsAvailable() {
-			return nil, fmt.Errorf("npm is not available")
-		}
-		return m.npm.Install(ctx, agentDef, method, force)
-
-	case "pip", "pipx", "uv":
-		if !m.pip.IsAvailable() {
-			return nil, fmt.Errorf("pip/pipx/uv is not available")
-		}
-		return m.pip.Install(ctx, agentDef, method, force)
 
-	case "brew", "brew-cask":
-		if !m.brew.IsAvailable() {
-			return nil, fmt.Errorf("brew is not available")
-		}
-		return m.brew.Install(ctx, agentDef, method, force)
+	// Register default providers
+	m.RegisterProvider(m.npm)
+	m.RegisterProvider(m.pip)
+	m.RegisterProvider(m.brew)
+	m.RegisterProvider(m.bun)
+	m.RegisterProvider(m.cargo)
+	m.RegisterProvider(m.golang)
+	m.RegisterProvider(m.binary)
+	m.RegisterProvider(m.native)
 
-	case "native", "curl", "binary", "bun", "bunx", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-		return m.native.Install(ctx, agentDef, method, force)
+	return m
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported install method: %s", method.Method)
+// RegisterProvider routes each of p's methods to p, replacing the provider
+// registered for that method before.
+func (m *Manager) RegisterProvider(p providers.Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, method := range p.Methods() {
+		m.providers[method] = p
 	}
 }
 
-// Update updates an installed agent.
-func (m *Manager) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*providers.Result, error) {
-	switch method.Method {
-	case "npm", "bun", "bunx":
-		if method.Method == "npm" && !m.npm.IsAvailable() {
-			return nil, fmt.Errorf("npm is not available")
-		}
-		if (method.Method == "bun" || method.Method == "bunx") && !m.plat.IsExecutableInPath("bun") {
-			return nil, fmt.Errorf("bun is not available")
-		}
-		// bun/bunx use npm-compatible commands, but if they have explicit update_cmd, use native
-		if (method.Method == "bun" || method.Method == "bunx") && method.UpdateCmd != "" {
-			return m.native.Update(ctx, inst, agentDef, method)
-		}
-		return m.npm.Update(ctx, inst, agentDef, method)
+// Provider returns the provider registered for a catalog install method.
+func (m *Manager) Provider(method string) (providers.Provider, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.providers[method]
+	return p, ok
+}
 
-	case "pip", "pipx", "uv":
-		if !m.pip.IsAvailable() {
-			return nil, fmt.Errorf("pip/pipx/uv is not available")
-		}
-		return m.pip.Update(ctx, inst, agentDef, method)
+// provider returns the provider for method, or an error when no provider
+// handles the method or the one that does is not available.
+func (m *Manager) provider(method string) (providers.Provider, error) {
+	p, ok := m.Provider(method)
+	if !ok {
+		return nil, fmt.Errorf("unsupported install method: %s", method)
+	}
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("%s is not available", unavailableName(p))
+	}
+	return p, nil
+}
 
-	case "brew", "brew-cask":
-		if !m.brew.IsAvailable() {
-			return nil, fmt.Errorf("brew is not available")
-		}
-		return m.brew.Update(ctx, inst, agentDef, method)
+// unavailableName is how provider's errors name p. The pip provider runs
+// whichever of pip, pipx and uv a method asks for, so it is named after
+// all three.
+func unavailableName(p providers.Provider) string {
+	if p.Name() == "pip" {
+		return strings.Join(p.Methods(), "/")
+	}
+	return p.Name()
+}
 
-	case "native", "curl", "binary", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-		// All of these use their update_cmd or command directly via the native provider
-		return m.native.Update(ctx, inst, agentDef, method)
+// Install installs an agent using the specified method. A version set on
+// ctx with providers.WithTargetVersion is installed instead of the latest;
+// only npm, pip/pipx/uv, Homebrew and binary installs from GitHub releases
+// support this.
+func (m *Manager) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*providers.Result, error) {
+	p, err := m.provider(method.Method)
+	if err != nil {
+		return nil, err
+	}
+	return p.Install(ctx, agentDef, method, force)
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported install method: %s", method.Method)
+// Update updates an installed agent. Like Install, it moves to the version
+// set with providers.WithTargetVersion when there is one, which may be
+// older than the installed version (a rollback).
+//
+// Once the provider is done, the agent is verified: its version command
+// must run and report the new version, and the catalog's smoke test, if
+// any, must pass. An agent failing that is put back to the version it had
+// and a *VerificationError returned.
+func (m *Manager) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*providers.Result, error) {
+	p, err := m.provider(method.Method)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.Update(ctx, inst, agentDef, method)
+	if err != nil {
+		return nil, err
 	}
+	if err := m.verifyUpdate(ctx, p, inst, agentDef, method, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // Uninstall removes an installed agent.
 func (m *Manager) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
-	switch method.Method {
-	case "npm":
-		if !m.npm.IsAvailable() {
-			return fmt.Errorf("npm is not available")
-		}
-		return m.npm.Uninstall(ctx, inst, method)
+	p, err := m.provider(method.Method)
+	if err != nil {
+		return err
+	}
+	return p.Uninstall(ctx, inst, method)
+}
 
-	case "pip", "pipx", "uv":
-		if !m.pip.IsAvailable() {
-			return fmt.Errorf("pip/pipx/uv is not available")
-		}
-		return m.pip.Uninstall(ctx, inst, method)
+// PlanInstall returns what Install would do, without doing it: the
+// commands it would run, with their working directory, environment and
+// whether they need administrator rights. Planning may query a registry to
+// resolve versions. Only providers implementing providers.Planner (all
+// the built-in ones) support it.
+func (m *Manager) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*providers.Plan, error) {
+	p, planner, err := m.planner(method.Method)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planner.PlanInstall(ctx, agentDef, method, force)
+	if err != nil {
+		return nil, err
+	}
+	return completePlan(ctx, plan, p, agentDef.ID, method, providers.OperationInstall), nil
+}
 
-	case "brew", "brew-cask":
-		if !m.brew.IsAvailable() {
-			return fmt.Errorf("brew is not available")
-		}
-		return m.brew.Uninstall(ctx, inst, method)
+// PlanUpdate returns what Update would do, without doing it, ending with
+// the commands that verify the updated agent.
+func (m *Manager) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*providers.Plan, error) {
+	p, planner, err := m.planner(method.Method)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planner.PlanUpdate(ctx, inst, agentDef, method)
+	if err != nil {
+		return nil, err
+	}
+	plan.Commands = append(plan.Commands, m.verificationCommands(agentDef, inst.ExecutablePath)...)
+	return completePlan(ctx, plan, p, agentDef.ID, method, providers.OperationUpdate), nil
+}
 
-	case "native", "curl", "binary", "bun", "bunx", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-		return m.native.Uninstall(ctx, inst, method)
+// PlanUninstall returns what Uninstall would do, without doing it.
+func (m *Manager) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*providers.Plan, error) {
+	p, planner, err := m.planner(method.Method)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planner.PlanUninstall(ctx, inst, method)
+	if err != nil {
+		return nil, err
+	}
+	return completePlan(ctx, plan, p, inst.AgentID, method, providers.OperationUninstall), nil
+}
+
+// planner returns the provider for method and its providers.Planner.
+func (m *Manager) planner(method string) (providers.Provider, providers.Planner, error) {
+	p, err := m.provider(method)
+	if err != nil {
+		return nil, nil, err
+	}
+	planner, ok := p.(providers.Planner)
+	if !ok {
+		return nil, nil, fmt.Errorf("dry run not supported for %s", method)
+	}
+	return p, planner, nil
+}
 
-	default:
-		return fmt.Errorf("unsupported install method: %s", method.Method)
+// completePlan fills in the parts of a provider's plan the provider leaves
+// to the manager. Commands without a directory run in agentmgr's own, so
+// that is reported.
+func completePlan(ctx context.Context, plan *providers.Plan, p providers.Provider, agentID string, method catalog.InstallMethodDef, op providers.Operation) *providers.Plan {
+	plan.AgentID = agentID
+	plan.Method = method.Method
+	plan.Operation = op
+	plan.Provider = p.Name()
+	plan.TargetVersion = providers.TargetVersion(ctx)
+	if plan.Commands == nil {
+		plan.Commands = []providers.Command{}
+	}
+	wd, _ := os.Getwd()
+	for i := range plan.Commands {
+		if plan.Commands[i].Dir == "" {
+			plan.Commands[i].Dir = wd
+		}
 	}
+	return plan
 }
 
 // GetAvailableMethods returns the installation methods available for an agent on this platform.
@@ -144,20 +242,7 @@ func (m *Manager) GetAvailableMethods(agentDef catalog.AgentDef) []catalog.Insta
 			continue
 		}
 
-		// Check if provider is available
-		available := false
-		switch method.Method {
-		case "npm":
-			available = m.npm.IsAvailable()
-		case "pip", "pipx", "uv":
-			available = m.pip.IsAvailable()
-		case "brew", "brew-cask":
-			available = m.brew.IsAvailable()
-		case "native", "curl", "binary", "bun", "bunx", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-			available = true
-		}
-
-		if available {
+		if m.IsMethodAvailable(method.Method) {
 			methods = append(methods, method)
 		}
 	}
@@ -167,46 +252,48 @@ func (m *Manager) GetAvailableMethods(agentDef catalog.AgentDef) []catalog.Insta
 
 // IsMethodAvailable checks if a specific install method is available on this system.
 func (m *Manager) IsMethodAvailable(method string) bool {
-	switch method {
-	case "npm":
-		return m.npm.IsAvailable()
-	case "pip", "pipx", "uv":
-		return m.pip.IsAvailable()
-	case "brew", "brew-cask":
-		return m.brew.IsAvailable()
-	case "native", "curl", "binary", "bun", "bunx", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-		return true
-	default:
-		return false
-	}
+	_, err := m.provider(method)
+	return err == nil
 }
 
 // GetLatestVersion returns the latest version available for an agent using the specified method.
 func (m *Manager) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
-	switch method.Method {
-	case "npm":
-		if !m.npm.IsAvailable() {
-			return agent.Version{}, fmt.Errorf("npm is not available")
-		}
-		return m.npm.GetLatestVersion(ctx, method)
-
-	case "pip", "pipx", "uv":
-		if !m.pip.IsAvailable() {
-			return agent.Version{}, fmt.Errorf("pip/pipx/uv is not available")
-		}
-		return m.pip.GetLatestVersion(ctx, method)
-
-	case "brew", "brew-cask":
-		if !m.brew.IsAvailable() {
-			return agent.Version{}, fmt.Errorf("brew is not available")
-		}
-		return m.brew.GetLatestVersion(ctx, method)
+	p, err := m.provider(method.Method)
+	if err != nil {
+		return agent.Version{}, err
+	}
+	return p.GetLatestVersion(ctx, method)
+}
 
-	case "native", "curl", "binary", "bun", "bunx", "cargo", "go", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git":
-		// These methods don't have a standard registry to check
-		return agent.Version{}, fmt.Errorf("version checking not supported for %s", method.Method)
+// ListVersions returns every version the registry behind method offers, in
+// no particular order. Only providers implementing providers.VersionLister
+// (npm, PyPI-backed methods and Homebrew among the built-in ones) expose a
+// version list; other methods return an error. The package manager itself
+// need not be installed, since listers such as PyPI's query the registry
+// directly.
+func (m *Manager) ListVersions(ctx context.Context, method catalog.InstallMethodDef) ([]agent.Version, error) {
+	p, ok := m.Provider(method.Method)
+	if !ok {
+		return nil, fmt.Errorf("unsupported install method: %s", method.Method)
+	}
+	lister, ok := p.(providers.VersionLister)
+	if !ok {
+		return nil, fmt.Errorf("listing versions not supported for %s", method.Method)
+	}
+	return lister.ListVersions(ctx, method)
+}
 
-	default:
-		return agent.Version{}, fmt.Errorf("unsupported install method: %s", method.Method)
+// ResolveVersion returns the newest version offered for method that
+// satisfies constraints. Callers pass the result to
+// providers.WithTargetVersion to update to it.
+func (m *Manager) ResolveVersion(ctx context.Context, method catalog.InstallMethodDef, constraints agent.VersionConstraints) (agent.Version, error) {
+	versions, err := m.ListVersions(ctx, method)
+	if err != nil {
+		return agent.Version{}, err
+	}
+	v, ok := constraints.Latest(versions)
+	if !ok {
+		return agent.Version{}, fmt.Errorf("no %s release satisfies %s", method.Method, constraints)
 	}
+	return v, nil
 }
@@ -1,9 +1,13 @@
 package installer
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/kevinelliott/agentmanager/pkg/agent"
 	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
 	"github.com/kevinelliott/agentmanager/pkg/platform"
 )
 
@@ -189,6 +193,64 @@ func TestUpdateUnsupportedMethod(t *testing.T) {
 	}
 }
 
+func TestUpdateTargetVersionUnsupportedForNative(t *testing.T) {
+	p := platform.Current()
+	m := NewManager(p)
+
+	agentDef := catalog.AgentDef{
+		ID:   "test-agent",
+		Name: "Test Agent",
+	}
+
+	method := catalog.InstallMethodDef{
+		Method:  "native",
+		Command: "true",
+	}
+
+	ctx := providers.WithTargetVersion(context.Background(), "1.2.3")
+	_, err := m.Update(ctx, &agent.Installation{AgentID: "test-agent"}, agentDef, method)
+	if err == nil {
+		t.Error("Update() should refuse a target version for native installs")
+	}
+}
+
+func TestInstallTargetVersionUnsupportedForNative(t *testing.T) {
+	p := platform.Current()
+	m := NewManager(p)
+
+	agentDef := catalog.AgentDef{
+		ID:   "test-agent",
+		Name: "Test Agent",
+	}
+
+	method := catalog.InstallMethodDef{
+		Method:  "native",
+		Command: "true",
+	}
+
+	ctx := providers.WithTargetVersion(context.Background(), "1.2.3")
+	if _, err := m.Install(ctx, agentDef, method, false); err == nil {
+		t.Error("Install() should refuse a target version for native installs")
+	}
+}
+
+func TestListVersionsUnsupportedMethod(t *testing.T) {
+	p := platform.Current()
+	m := NewManager(p)
+
+	method := catalog.InstallMethodDef{
+		Method: "native",
+	}
+
+	if _, err := m.ListVersions(context.Background(), method); err == nil {
+		t.Error("ListVersions() should return error for methods without a version list")
+	}
+	constraints, _ := agent.ParseVersionConstraints("^1.0")
+	if _, err := m.ResolveVersion(context.Background(), method, constraints); err == nil {
+		t.Error("ResolveVersion() should return error for methods without a version list")
+	}
+}
+
 func TestUninstallUnsupportedMethod(t *testing.T) {
 	p := platform.Current()
 	m := NewManager(p)
@@ -1163,3 +1225,212 @@ func TestManagerFieldsInitialized(t *testing.T) {
 		t.Error("platform ID should match")
 	}
 }
+
+// fakeProvider is a providers.Provider recording the calls it receives.
+type fakeProvider struct {
+	methods   []string
+	available bool
+	installed []string
+}
+
+func (f *fakeProvider) Name() string      { return "fake" }
+func (f *fakeProvider) Methods() []string { return f.methods }
+func (f *fakeProvider) IsAvailable() bool { return f.available }
+
+func (f *fakeProvider) Install(_ context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, _ bool) (*providers.Result, error) {
+	f.installed = append(f.installed, agentDef.ID)
+	return &providers.Result{AgentID: agentDef.ID, Method: agent.InstallMethod(method.Method)}, nil
+}
+
+func (f *fakeProvider) Update(_ context.Context, _ *agent.Installation, agentDef catalog.AgentDef, _ catalog.InstallMethodDef) (*providers.Result, error) {
+	return &providers.Result{AgentID: agentDef.ID}, nil
+}
+
+func (f *fakeProvider) Uninstall(context.Context, *agent.Installation, catalog.InstallMethodDef) error {
+	return nil
+}
+
+func (f *fakeProvider) GetLatestVersion(context.Context, catalog.InstallMethodDef) (agent.Version, error) {
+	return agent.Version{Major: 4, Minor: 2}, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	m := NewManager(platform.Current())
+	fake := &fakeProvider{methods: []string{"asdf", "native"}, available: true}
+	m.RegisterProvider(fake)
+
+	if got, ok := m.Provider("asdf"); !ok || got != fake {
+		t.Fatalf("Provider(asdf) = %v, %v; want the registered provider", got, ok)
+	}
+	if !m.IsMethodAvailable("asdf") {
+		t.Error("IsMethodAvailable(asdf) = false after registering a provider for it")
+	}
+
+	// A registered provider replaces the built-in one for its methods.
+	for _, method := range []string{"asdf", "native"} {
+		if _, err := m.Install(context.Background(), catalog.AgentDef{ID: "a-" + method}, catalog.InstallMethodDef{Method: method}, false); err != nil {
+			t.Errorf("Install(%s) error = %v", method, err)
+		}
+	}
+	if len(fake.installed) != 2 {
+		t.Errorf("provider installed %v, want both agents", fake.installed)
+	}
+	if p, _ := m.Provider("curl"); p != m.native {
+		t.Error("methods the provider does not list should keep their provider")
+	}
+
+	v, err := m.GetLatestVersion(context.Background(), catalog.InstallMethodDef{Method: "asdf"})
+	if err != nil || v.String() != "4.2.0" {
+		t.Errorf("GetLatestVersion(asdf) = %v, %v; want 4.2.0", v, err)
+	}
+	if _, err := m.ListVersions(context.Background(), catalog.InstallMethodDef{Method: "asdf"}); err == nil {
+		t.Error("ListVersions() should fail for a provider without a version list")
+	}
+}
+
+func TestRegisterProviderNotAvailable(t *testing.T) {
+	m := NewManager(platform.Current())
+	m.RegisterProvider(&fakeProvider{methods: []string{"asdf"}})
+
+	_, err := m.Install(context.Background(), catalog.AgentDef{ID: "a"}, catalog.InstallMethodDef{Method: "asdf"}, false)
+	if err == nil || err.Error() != "fake is not available" {
+		t.Errorf("Install() error = %v, want %q", err, "fake is not available")
+	}
+	if m.IsMethodAvailable("asdf") {
+		t.Error("IsMethodAvailable(asdf) = true for an unavailable provider")
+	}
+}
+
+// fakeLister is a fakeProvider with a version list.
+type fakeLister struct {
+	fakeProvider
+}
+
+func (f *fakeLister) ListVersions(context.Context, catalog.InstallMethodDef) ([]agent.Version, error) {
+	return []agent.Version{{Major: 1}, {Major: 1, Minor: 1}}, nil
+}
+
+func TestListVersionsWithoutPackageManager(t *testing.T) {
+	m := NewManager(platform.Current())
+	m.RegisterProvider(&fakeLister{fakeProvider{methods: []string{"asdf"}}})
+
+	// Registries are queried directly, so the tool need not be installed.
+	versions, err := m.ListVersions(context.Background(), catalog.InstallMethodDef{Method: "asdf"})
+	if err != nil || len(versions) != 2 {
+		t.Errorf("ListVersions() = %v, %v; want both versions", versions, err)
+	}
+	if _, err := m.ListVersions(context.Background(), catalog.InstallMethodDef{Method: "unsupported-method"}); err == nil {
+		t.Error("ListVersions() should fail for an unsupported method")
+	}
+}
+
+// fakePlanner is a fakeProvider that also plans.
+type fakePlanner struct {
+	fakeProvider
+}
+
+func (f *fakePlanner) PlanInstall(_ context.Context, _ catalog.AgentDef, _ catalog.InstallMethodDef, force bool) (*providers.Plan, error) {
+	argv := []string{"asdf", "install"}
+	if force {
+		argv = append(argv, "--force")
+	}
+	return &providers.Plan{Commands: []providers.Command{{Argv: argv}}}, nil
+}
+
+func (f *fakePlanner) PlanUpdate(context.Context, *agent.Installation, catalog.AgentDef, catalog.InstallMethodDef) (*providers.Plan, error) {
+	return &providers.Plan{Commands: []providers.Command{{Argv: []string{"asdf", "upgrade"}, Dir: "/tmp"}}}, nil
+}
+
+func (f *fakePlanner) PlanUninstall(context.Context, *agent.Installation, catalog.InstallMethodDef) (*providers.Plan, error) {
+	return &providers.Plan{Steps: []string{"remove /opt/asdf"}}, nil
+}
+
+func TestPlan(t *testing.T) {
+	m := NewManager(platform.Current())
+	fake := &fakePlanner{fakeProvider{methods: []string{"asdf"}, available: true}}
+	m.RegisterProvider(fake)
+
+	method := catalog.InstallMethodDef{Method: "asdf"}
+	ctx := providers.WithTargetVersion(context.Background(), "1.2.3")
+	plan, err := m.PlanInstall(ctx, catalog.AgentDef{ID: "a"}, method, true)
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if plan.AgentID != "a" || plan.Method != "asdf" || plan.Operation != providers.OperationInstall || plan.Provider != "fake" || plan.TargetVersion != "1.2.3" {
+		t.Errorf("PlanInstall() = %+v", plan)
+	}
+	if len(plan.Commands) != 1 || len(plan.Commands[0].Argv) != 3 || plan.Commands[0].Dir == "" {
+		t.Errorf("PlanInstall() commands = %+v, want the forced install in the working directory", plan.Commands)
+	}
+	if len(fake.installed) != 0 {
+		t.Error("planning must not install")
+	}
+
+	inst := &agent.Installation{AgentID: "a", ExecutablePath: "/opt/asdf/bin/a"}
+	plan, err = m.PlanUpdate(context.Background(), inst, catalog.AgentDef{ID: "a"}, method)
+	if err != nil {
+		t.Fatalf("PlanUpdate() error = %v", err)
+	}
+	if plan.Operation != providers.OperationUpdate || plan.Commands[0].Dir != "/tmp" || plan.TargetVersion != "" {
+		t.Errorf("PlanUpdate() = %+v", plan)
+	}
+	if len(plan.Commands) != 1 {
+		t.Errorf("PlanUpdate() commands = %+v, want no verification without a version command", plan.Commands)
+	}
+
+	// The update is followed by its verification.
+	verified := catalog.AgentDef{ID: "a", Detection: catalog.DetectionDef{VersionCmd: "a --version", SmokeTestCmd: "a doctor"}}
+	plan, err = m.PlanUpdate(context.Background(), inst, verified, method)
+	if err != nil {
+		t.Fatalf("PlanUpdate() error = %v", err)
+	}
+	if len(plan.Commands) != 3 {
+		t.Fatalf("PlanUpdate() commands = %+v, want the update, version command and smoke test", plan.Commands)
+	}
+	if got := strings.Join(plan.Commands[1].Argv, " "); got != "/opt/asdf/bin/a --version" {
+		t.Errorf("version command = %q, want it run against the installed executable", got)
+	}
+	if argv := plan.Commands[2].Argv; len(argv) != 3 || argv[2] != "a doctor" || plan.Commands[2].Dir == "" {
+		t.Errorf("smoke test command = %+v, want the script run by the shell", plan.Commands[2])
+	}
+
+	plan, err = m.PlanUninstall(context.Background(), inst, method)
+	if err != nil {
+		t.Fatalf("PlanUninstall() error = %v", err)
+	}
+	if plan.Operation != providers.OperationUninstall || plan.Commands == nil || len(plan.Steps) != 1 {
+		t.Errorf("PlanUninstall() = %+v", plan)
+	}
+}
+
+func TestPlanNotSupported(t *testing.T) {
+	m := NewManager(platform.Current())
+	m.RegisterProvider(&fakeProvider{methods: []string{"asdf"}, available: true})
+
+	_, err := m.PlanInstall(context.Background(), catalog.AgentDef{ID: "a"}, catalog.InstallMethodDef{Method: "asdf"}, false)
+	if err == nil || err.Error() != "dry run not supported for asdf" {
+		t.Errorf("PlanInstall() error = %v, want %q", err, "dry run not supported for asdf")
+	}
+}
+
+func TestPlanNative(t *testing.T) {
+	m := NewManager(platform.Current())
+	method := catalog.InstallMethodDef{Method: "native", Command: "sudo install-agent --yes"}
+
+	plan, err := m.PlanInstall(context.Background(), catalog.AgentDef{ID: "a"}, method, false)
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if len(plan.Commands) != 1 {
+		t.Fatalf("PlanInstall() commands = %+v, want one", plan.Commands)
+	}
+	c := plan.Commands[0]
+	if c.Argv[len(c.Argv)-1] != method.Command || !c.Elevated {
+		t.Errorf("PlanInstall() command = %+v, want the catalog command run elevated through the shell", c)
+	}
+
+	ctx := providers.WithTargetVersion(context.Background(), "1.0.0")
+	if _, err := m.PlanInstall(ctx, catalog.AgentDef{ID: "a"}, method, false); err == nil {
+		t.Error("PlanInstall() should refuse a target version for a native method")
+	}
+}
@@ -0,0 +1,550 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+var (
+	binaryLatestVersionCache sync.Map // owner/repo -> latestVersionEntry
+	binaryLatestVersionGroup singleflight.Group
+)
+
+// githubRepoRegex matches the owner and repository of a GitHub URL.
+var githubRepoRegex = regexp.MustCompile(`github\.com/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)`)
+
+// ownerRepoRegex matches a bare "owner/repo".
+var ownerRepoRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// BinaryProvider installs prebuilt binaries from GitHub releases. For a
+// method with a release definition it downloads the asset for the current
+// platform, verifies it against the release's published SHA-256 sums,
+// unpacks it and installs the executables into agentmgr's own bin
+// directory, recording each install in a manifest so updates and
+// uninstalls touch exactly the files it wrote. Other methods run the
+// catalog's commands like the native provider.
+type BinaryProvider struct {
+	platform platform.Platform
+	native   *NativeProvider
+
+	// binDir receives the executables; manifestDir holds one manifest per
+	// installed agent.
+	binDir      string
+	manifestDir string
+}
+
+// NewBinaryProvider creates a new binary provider installing into the bin
+// directory under the platform's data directory.
+func NewBinaryProvider(p platform.Platform) *BinaryProvider {
+	provider := &BinaryProvider{platform: p, native: NewNativeProvider(p)}
+	if p != nil {
+		provider.binDir = filepath.Join(p.GetDataDir(), "bin")
+		provider.manifestDir = filepath.Join(p.GetDataDir(), "binaries")
+	}
+	return provider
+}
+
+// Name returns the provider name.
+func (p *BinaryProvider) Name() string {
+	return "binary"
+}
+
+// Method returns the install method this provider handles.
+func (p *BinaryProvider) Method() agent.InstallMethod {
+	return agent.MethodBinary
+}
+
+// Methods returns the catalog install methods this provider handles.
+func (p *BinaryProvider) Methods() []string {
+	return []string{"binary"}
+}
+
+// IsAvailable returns true - binary downloads need no package manager.
+func (p *BinaryProvider) IsAvailable() bool {
+	return true
+}
+
+// BinDir returns the directory release executables are installed into.
+// It needs to be on PATH for the agents to be found.
+func (p *BinaryProvider) BinDir() string {
+	return p.binDir
+}
+
+// binaryManifest records the files one release install wrote.
+type binaryManifest struct {
+	AgentID     string    `json:"agent_id"`
+	Repo        string    `json:"repo"`
+	Tag         string    `json:"tag"`
+	Asset       string    `json:"asset"`
+	Dir         string    `json:"dir"`
+	Files       []string  `json:"files"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// owns reports whether the manifest lists path.
+func (m *binaryManifest) owns(path string) bool {
+	return m != nil && slices.Contains(m.Files, path)
+}
+
+func (p *BinaryProvider) manifestPath(agentID string) string {
+	return filepath.Join(p.manifestDir, agentID+".json")
+}
+
+// loadManifest returns the manifest of the agent's release install, or nil
+// when agentmgr did not install it from a release.
+func (p *BinaryProvider) loadManifest(agentID string) (*binaryManifest, error) {
+	if p.manifestDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(p.manifestPath(agentID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install manifest: %w", err)
+	}
+	var m binaryManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse install manifest: %w", err)
+	}
+	return &m, nil
+}
+
+func (p *BinaryProvider) saveManifest(m *binaryManifest) error {
+	if err := os.MkdirAll(p.manifestDir, 0o755); err != nil {
+		return fmt.Errorf("failed to write install manifest: %w", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write install manifest: %w", err)
+	}
+	tmp := p.manifestPath(m.AgentID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write install manifest: %w", err)
+	}
+	if err := os.Rename(tmp, p.manifestPath(m.AgentID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write install manifest: %w", err)
+	}
+	return nil
+}
+
+// installManifest returns the manifest of the release install inst is, or
+// nil when inst is some other copy of the agent, such as one from the
+// vendor's installer, that the native fallback handles.
+func (p *BinaryProvider) installManifest(inst *agent.Installation) (*binaryManifest, error) {
+	m, err := p.loadManifest(inst.AgentID)
+	if err != nil || !m.owns(inst.ExecutablePath) {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Install installs an agent from its GitHub release, or by running the
+// catalog's install command when the method defines no release.
+func (p *BinaryProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
+	def := releaseDef(method)
+	if def == nil || p.binDir == "" {
+		result, err := p.native.Install(ctx, agentDef, method, force)
+		if result != nil {
+			result.Method = agent.MethodBinary
+		}
+		return result, err
+	}
+
+	prev, err := p.loadManifest(agentDef.ID)
+	if err != nil {
+		return nil, err
+	}
+	return p.installRelease(ctx, agentDef, def, prev, force)
+}
+
+// Update updates a binary installation. Agents agentmgr installed from a
+// release, when inst is that install, move to the latest release (or the requested version); others
+// run the catalog's update command.
+func (p *BinaryProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
+	prev, err := p.installManifest(inst)
+	if err != nil {
+		return nil, err
+	}
+	def := releaseDef(method)
+	if prev == nil || def == nil {
+		result, err := p.native.Update(ctx, inst, agentDef, method)
+		if result != nil {
+			result.Method = agent.MethodBinary
+		}
+		return result, err
+	}
+
+	result, err := p.installRelease(ctx, agentDef, def, prev, false)
+	if result != nil {
+		result.FromVersion = inst.InstalledVersion
+	}
+	return result, err
+}
+
+// Uninstall removes a binary installation: exactly the files of its
+// manifest for a release install, otherwise whatever the catalog's
+// uninstall command or the native fallback removes.
+func (p *BinaryProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	m, err := p.installManifest(inst)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return p.native.Uninstall(ctx, inst, method)
+	}
+
+	for _, file := range m.Files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+	}
+	if err := os.Remove(p.manifestPath(inst.AgentID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove install manifest: %w", err)
+	}
+	return nil
+}
+
+// PlanInstall returns what Install would do: the release download for a
+// method with a release definition, otherwise the catalog's command.
+func (p *BinaryProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	def := releaseDef(method)
+	if def == nil || p.binDir == "" {
+		return p.native.PlanInstall(ctx, agentDef, method, force)
+	}
+
+	prev, err := p.loadManifest(agentDef.ID)
+	if err != nil {
+		return nil, err
+	}
+	return p.planRelease(ctx, agentDef, def, prev, force)
+}
+
+// PlanUpdate returns what Update would do.
+func (p *BinaryProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	prev, err := p.installManifest(inst)
+	if err != nil {
+		return nil, err
+	}
+	def := releaseDef(method)
+	if prev == nil || def == nil {
+		return p.native.PlanUpdate(ctx, inst, agentDef, method)
+	}
+	return p.planRelease(ctx, agentDef, def, prev, false)
+}
+
+// PlanUninstall returns what Uninstall would do.
+func (p *BinaryProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	m, err := p.installManifest(inst)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return p.native.PlanUninstall(ctx, inst, method)
+	}
+
+	var steps []string
+	for _, file := range m.Files {
+		steps = append(steps, "remove "+file)
+	}
+	steps = append(steps, "remove "+p.manifestPath(inst.AgentID))
+	return &Plan{Steps: steps}, nil
+}
+
+// installRelease installs the agent's release asset for this platform,
+// replacing the files of prev, the manifest of the current install if any.
+// The target version comes from the context; the latest release is used
+// otherwise. Nothing is downloaded when prev already has that release.
+func (p *BinaryProvider) installRelease(ctx context.Context, agentDef catalog.AgentDef, def *catalog.ReleaseDef, prev *binaryManifest, force bool) (*Result, error) {
+	start := time.Now()
+
+	release, err := fetchGitHubRelease(ctx, def.Repo, TargetVersion(ctx))
+	if err != nil {
+		return nil, err
+	}
+	version, _ := agent.ParseVersion(release.TagName)
+
+	result := &Result{
+		AgentID:     agentDef.ID,
+		AgentName:   agentDef.Name,
+		Method:      agent.MethodBinary,
+		Version:     version,
+		InstallPath: p.binDir,
+	}
+	if prev != nil && prev.Tag == release.TagName && !force {
+		if len(prev.Files) > 0 {
+			result.ExecutablePath = prev.Files[0]
+		}
+		result.Output = fmt.Sprintf("%s %s is already installed", agentDef.Name, release.TagName)
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	osID, arch := string(p.platform.ID()), p.platform.Architecture()
+	asset, err := platformAsset(release, def, osID, arch)
+	if err != nil {
+		return nil, err
+	}
+	assetName := asset.Name
+	want, err := releaseChecksum(ctx, release, def, osID, arch, assetName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(p.binDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", p.binDir, err)
+	}
+	// Staging inside binDir keeps the final renames on one file system.
+	staging, err := os.MkdirTemp(p.binDir, ".download-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	fmt.Fprintf(ProgressWriter(ctx), "Downloading %s\n", asset.DownloadURL)
+	download := filepath.Join(staging, "asset")
+	f, err := os.Create(download)
+	if err != nil {
+		return nil, err
+	}
+	got, err := downloadReleaseAsset(ctx, asset.DownloadURL, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", assetName, got, want)
+	}
+
+	files, err := unpackReleaseAsset(download, assetName, staging)
+	if err != nil {
+		return nil, err
+	}
+	names := releaseBinaries(agentDef, def)
+	picked := pickExecutables(files, names)
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("release asset %s contains none of the executables %s", assetName, strings.Join(names, ", "))
+	}
+
+	// Check every destination before replacing any, so a conflict leaves
+	// the bin directory untouched.
+	type move struct{ src, dest string }
+	var moves []move
+	var installed []string
+	for _, name := range names {
+		f, ok := picked[name]
+		if !ok {
+			continue
+		}
+		dest := filepath.Join(p.binDir, executableFileName(name))
+		if _, err := os.Stat(dest); err == nil && !prev.owns(dest) && !force {
+			return nil, fmt.Errorf("%s already exists and was not installed by agentmgr; use --force to replace it", dest)
+		}
+		moves = append(moves, move{f.path, dest})
+		installed = append(installed, dest)
+	}
+
+	for i, m := range moves {
+		if err := replaceExecutable(m.src, m.dest); err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			// Record the files already moved so that uninstalling, or
+			// installing again, still treats them as agentmgr's. The
+			// release stays that of prev, so the next update retries.
+			partial := &binaryManifest{AgentID: agentDef.ID, Repo: def.Repo, Dir: p.binDir, InstalledAt: time.Now()}
+			if prev != nil {
+				partial.Tag, partial.Asset, partial.Files = prev.Tag, prev.Asset, slices.Clone(prev.Files)
+			}
+			for _, dest := range installed[:i] {
+				if !partial.owns(dest) {
+					partial.Files = append(partial.Files, dest)
+				}
+			}
+			if serr := p.saveManifest(partial); serr != nil {
+				return nil, fmt.Errorf("%w (%v)", err, serr)
+			}
+			return nil, err
+		}
+	}
+	if prev != nil {
+		for _, old := range prev.Files {
+			if !slices.Contains(installed, old) {
+				os.Remove(old)
+			}
+		}
+	}
+
+	if err := p.saveManifest(&binaryManifest{
+		AgentID:     agentDef.ID,
+		Repo:        def.Repo,
+		Tag:         release.TagName,
+		Asset:       assetName,
+		Dir:         p.binDir,
+		Files:       installed,
+		InstalledAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	result.ExecutablePath = installed[0]
+	result.WasUpdated = prev != nil
+	result.Output = fmt.Sprintf("Installed %s %s into %s", agentDef.Name, release.TagName, p.binDir)
+	if !p.binDirOnPath() {
+		result.Output += fmt.Sprintf("\nAdd %s to your PATH to run it.", p.binDir)
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// planRelease describes what installRelease would do. It looks up the
+// release but downloads nothing.
+func (p *BinaryProvider) planRelease(ctx context.Context, agentDef catalog.AgentDef, def *catalog.ReleaseDef, prev *binaryManifest, force bool) (*Plan, error) {
+	release, err := fetchGitHubRelease(ctx, def.Repo, TargetVersion(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.Tag == release.TagName && !force {
+		return &Plan{Steps: []string{fmt.Sprintf("nothing to do: %s %s is already installed", agentDef.Name, release.TagName)}}, nil
+	}
+
+	osID, arch := string(p.platform.ID()), p.platform.Architecture()
+	asset, err := platformAsset(release, def, osID, arch)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := checksumsAsset(release, def, osID, arch, asset.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var dests []string
+	for _, name := range releaseBinaries(agentDef, def) {
+		dests = append(dests, filepath.Join(p.binDir, executableFileName(name)))
+	}
+	steps := []string{
+		"download " + asset.DownloadURL,
+		"verify its SHA-256 sum against " + checksums.DownloadURL,
+		fmt.Sprintf("install %s from %s", strings.Join(dests, ", "), asset.Name),
+	}
+	if prev != nil {
+		for _, old := range prev.Files {
+			if !slices.Contains(dests, old) {
+				steps = append(steps, "remove "+old)
+			}
+		}
+	}
+	steps = append(steps, "write "+p.manifestPath(agentDef.ID))
+	return &Plan{Steps: steps}, nil
+}
+
+// platformAsset returns the release asset for osID and arch.
+func platformAsset(release *githubRelease, def *catalog.ReleaseDef, osID, arch string) (releaseAsset, error) {
+	name, err := releaseAssetName(def, osID, arch, release.TagName)
+	if err != nil {
+		return releaseAsset{}, err
+	}
+	asset, ok := release.asset(name)
+	if !ok {
+		return releaseAsset{}, fmt.Errorf("release %s of %s has no asset %s", release.TagName, def.Repo, name)
+	}
+	return asset, nil
+}
+
+// releaseBinaries returns the names of the executables a release install
+// takes from the asset.
+func releaseBinaries(agentDef catalog.AgentDef, def *catalog.ReleaseDef) []string {
+	if len(def.Binaries) > 0 {
+		return def.Binaries
+	}
+	return agentDef.Detection.Executables
+}
+
+// replaceExecutable moves src to dest, replacing any file there. Windows
+// cannot overwrite a running executable but can rename it, so there the
+// old file is moved aside first.
+func replaceExecutable(src, dest string) error {
+	if err := os.Chmod(src, 0o755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := dest + ".old"
+		os.Remove(old)
+		if err := os.Rename(dest, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to replace %s: %w", dest, err)
+		}
+		defer os.Remove(old)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return fmt.Errorf("failed to install %s: %w", dest, err)
+	}
+	return nil
+}
+
+// binDirOnPath reports whether the bin directory is on PATH.
+func (p *BinaryProvider) binDirOnPath() bool {
+	for _, dir := range p.platform.GetPathDirs() {
+		if filepath.Clean(dir) == filepath.Clean(p.binDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLatestVersion returns the version of the latest GitHub release of the
+// repository named by the method's release definition, its "repo" or
+// "download_url" metadata or its command. GITHUB_TOKEN, when set, raises the API rate limit.
+func (p *BinaryProvider) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
+	repo := githubRepo(method)
+	if repo == "" {
+		return agent.Version{}, fmt.Errorf("version checking not supported for %s", method.Method)
+	}
+
+	return cachedLatestVersion(&binaryLatestVersionCache, &binaryLatestVersionGroup, strings.ToLower(repo), func() (agent.Version, error) {
+		release, err := fetchGitHubRelease(ctx, repo, "")
+		if err != nil {
+			return agent.Version{}, err
+		}
+		version, err := agent.ParseVersion(release.TagName)
+		if err != nil {
+			return agent.Version{}, fmt.Errorf("release tag %q of %s is not a version: %w", release.TagName, repo, err)
+		}
+		return version, nil
+	})
+}
+
+// githubRepo returns the "owner/repo" a method downloads its releases
+// from, or "" when it names no GitHub repository.
+func githubRepo(method catalog.InstallMethodDef) string {
+	if method.Release != nil && ownerRepoRegex.MatchString(method.Release.Repo) {
+		return method.Release.Repo
+	}
+	if repo := method.Metadata["repo"]; ownerRepoRegex.MatchString(repo) {
+		return repo
+	}
+	for _, s := range []string{method.Metadata["repo"], method.Metadata["download_url"], method.Command, method.Package} {
+		if m := githubRepoRegex.FindStringSubmatch(s); m != nil {
+			return m[1] + "/" + strings.TrimSuffix(m[2], ".git")
+		}
+	}
+	return ""
+}
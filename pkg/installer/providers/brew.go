@@ -1,12 +1,10 @@
 package providers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"os/exec"
 	"strings"
 	"sync"
@@ -62,6 +60,11 @@ func (p *BrewProvider) Method() agent.InstallMethod {
 	return agent.MethodBrew
 }
 
+// Methods returns the catalog install methods this provider handles.
+func (p *BrewProvider) Methods() []string {
+	return []string{"brew", "brew-cask"}
+}
+
 // IsAvailable returns true if brew is available.
 func (p *BrewProvider) IsAvailable() bool {
 	return p.platform.ID() != platform.Windows && p.platform.IsExecutableInPath("brew")
@@ -71,33 +74,21 @@ func (p *BrewProvider) IsAvailable() bool {
 func (p *BrewProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
 	start := time.Now()
 
-	packageName, isCask := p.parseBrewPackage(method)
-	if packageName == "" {
-		return nil, fmt.Errorf("could not determine brew package name")
+	commands, formula, isCask, err := p.installCommand(ctx, method, force)
+	if err != nil {
+		return nil, err
 	}
 
-	// Build install command
-	args := []string{"install"}
-	if isCask {
-		args = append(args, "--cask")
-	}
-	if force {
-		args = append(args, "--force")
+	stdout, stderr, err := commands[0].run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("brew install failed: %w\n%s%s", err, stderr, FormatInstallError("brew", "install", stderr))
 	}
-	args = append(args, packageName)
-
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, "brew", args...)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("brew install failed: %w\n%s%s", err, stderr.String(), FormatInstallError("brew", "install", stderr.String()))
+	if err := runLinkCommands(ctx, commands[1:]); err != nil {
+		return nil, err
 	}
 
 	// Get installed version
-	version := p.getInstalledVersion(ctx, packageName, isCask)
+	version := p.getInstalledVersion(ctx, formula, isCask)
 
 	// Find executable
 	execPath := p.findExecutable(agentDef)
@@ -109,7 +100,7 @@ func (p *BrewProvider) Install(ctx context.Context, agentDef catalog.AgentDef, m
 		Version:        version,
 		ExecutablePath: execPath,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 	}, nil
 }
 
@@ -117,35 +108,26 @@ func (p *BrewProvider) Install(ctx context.Context, agentDef catalog.AgentDef, m
 func (p *BrewProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
 	start := time.Now()
 
-	packageName, isCask := p.parseBrewPackage(method)
-	if packageName == "" {
-		return nil, fmt.Errorf("could not determine brew package name")
+	commands, formula, isCask, err := p.updateCommand(ctx, method)
+	if err != nil {
+		return nil, err
 	}
 
 	fromVersion := inst.InstalledVersion
 
-	// Run upgrade command
-	args := []string{"upgrade"}
-	if isCask {
-		args = append(args, "--cask")
-	}
-	args = append(args, packageName)
-
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, "brew", args...)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
-
-	if err := cmd.Run(); err != nil {
+	stdout, stderr, err := commands[0].run(ctx)
+	if err != nil {
 		// brew upgrade returns error if already up to date
-		if !strings.Contains(stderr.String(), "already installed") {
-			return nil, fmt.Errorf("brew upgrade failed: %w\n%s%s", err, stderr.String(), FormatInstallError("brew", "upgrade", stderr.String()))
+		if !strings.Contains(stderr, "already installed") {
+			return nil, fmt.Errorf("brew upgrade failed: %w\n%s%s", err, stderr, FormatInstallError("brew", "upgrade", stderr))
 		}
 	}
+	if err := runLinkCommands(ctx, commands[1:]); err != nil {
+		return nil, err
+	}
 
 	// Get new version
-	toVersion := p.getInstalledVersion(ctx, packageName, isCask)
+	toVersion := p.getInstalledVersion(ctx, formula, isCask)
 
 	return &Result{
 		AgentID:        agentDef.ID,
@@ -154,7 +136,7 @@ func (p *BrewProvider) Update(ctx context.Context, inst *agent.Installation, age
 		FromVersion:    fromVersion,
 		Version:        toVersion,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 		WasUpdated:     toVersion.IsNewerThan(fromVersion),
 		ExecutablePath: inst.ExecutablePath,
 	}, nil
@@ -162,28 +144,141 @@ func (p *BrewProvider) Update(ctx context.Context, inst *agent.Installation, age
 
 // Uninstall removes a Homebrew-installed agent.
 func (p *BrewProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	command, err := p.uninstallCommand(method)
+	if err != nil {
+		return err
+	}
+
+	if _, stderr, err := command.run(ctx); err != nil {
+		return fmt.Errorf("brew uninstall failed: %w\n%s", err, stderr)
+	}
+
+	return nil
+}
+
+// PlanInstall returns the command Install would run. Resolving a target
+// version may query `brew info`.
+func (p *BrewProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	commands, _, _, err := p.installCommand(ctx, method, force)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(commands...), nil
+}
+
+// PlanUpdate returns the command Update would run.
+func (p *BrewProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	commands, _, _, err := p.updateCommand(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(commands...), nil
+}
+
+// PlanUninstall returns the command Uninstall would run.
+func (p *BrewProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	command, err := p.uninstallCommand(method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// installCommand returns the commands Install runs, the formula it
+// installs and whether that is a cask. The install command comes first,
+// followed by any linkCommands.
+func (p *BrewProvider) installCommand(ctx context.Context, method catalog.InstallMethodDef, force bool) ([]Command, string, bool, error) {
 	packageName, isCask := p.parseBrewPackage(method)
 	if packageName == "" {
-		return fmt.Errorf("could not determine brew package name")
+		return nil, "", false, fmt.Errorf("could not determine brew package name")
+	}
+
+	formula, err := p.resolveFormula(ctx, packageName, isCask)
+	if err != nil {
+		return nil, "", false, err
 	}
 
-	args := []string{"uninstall"}
+	args := []string{"brew", "install"}
 	if isCask {
 		args = append(args, "--cask")
 	}
-	args = append(args, packageName)
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, formula)
+	commands := append([]Command{newCommand(args...)}, p.linkCommands(ctx, packageName, formula, isCask)...)
+	return commands, formula, isCask, nil
+}
 
-	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, "brew", args...)
-	cmd.Stderr = &stderr
+// updateCommand returns the commands Update runs, the formula it upgrades
+// and whether that is a cask, in the same form as installCommand.
+func (p *BrewProvider) updateCommand(ctx context.Context, method catalog.InstallMethodDef) ([]Command, string, bool, error) {
+	packageName, isCask := p.parseBrewPackage(method)
+	if packageName == "" {
+		return nil, "", false, fmt.Errorf("could not determine brew package name")
+	}
+
+	// Homebrew only ships the current release of a formula, so a target
+	// version other than that release means installing the versioned
+	// formula (e.g. node@20) that provides it.
+	formula, err := p.resolveFormula(ctx, packageName, isCask)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	args := []string{"brew", "upgrade"}
+	if formula != packageName {
+		args = []string{"brew", "install"}
+	}
+	if isCask {
+		args = append(args, "--cask")
+	}
+	args = append(args, formula)
+	commands := append([]Command{newCommand(args...)}, p.linkCommands(ctx, packageName, formula, isCask)...)
+	return commands, formula, isCask, nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("brew uninstall failed: %w\n%s", err, stderr.String())
+// linkCommands returns the commands that put formula's executables on
+// PATH after it is installed in place of packageName. A versioned formula
+// (e.g. node@20) is keg-only, and installing it leaves an installed
+// packageName linked, so packageName is unlinked and formula force-linked.
+// Other formulae and casks need nothing.
+func (p *BrewProvider) linkCommands(ctx context.Context, packageName, formula string, isCask bool) []Command {
+	if isCask || formula == packageName {
+		return nil
+	}
+	var commands []Command
+	if !p.getInstalledVersion(ctx, packageName, false).IsZero() {
+		commands = append(commands, newCommand("brew", "unlink", packageName))
 	}
+	return append(commands, newCommand("brew", "link", "--force", "--overwrite", formula))
+}
 
+// runLinkCommands runs the linkCommands of an install or update.
+func runLinkCommands(ctx context.Context, commands []Command) error {
+	for _, command := range commands {
+		if _, stderr, err := command.run(ctx); err != nil {
+			return fmt.Errorf("%s failed: %w\n%s", strings.Join(command.Argv[:2], " "), err, stderr)
+		}
+	}
 	return nil
 }
 
+// uninstallCommand returns the command Uninstall runs.
+func (p *BrewProvider) uninstallCommand(method catalog.InstallMethodDef) (Command, error) {
+	packageName, isCask := p.parseBrewPackage(method)
+	if packageName == "" {
+		return Command{}, fmt.Errorf("could not determine brew package name")
+	}
+
+	args := []string{"brew", "uninstall"}
+	if isCask {
+		args = append(args, "--cask")
+	}
+	args = append(args, packageName)
+	return newCommand(args...), nil
+}
+
 // parseBrewPackage extracts the package name and determines if it's a cask.
 //
 // Cask is detected from method.Metadata using either of two keys:
@@ -358,6 +453,122 @@ func (p *BrewProvider) fetchLatestVersionUncached(ctx context.Context, packageNa
 	return version, nil
 }
 
+// ListVersions returns the versions Homebrew can install for a package:
+// the current release plus, for formulae, the release of each versioned
+// formula (e.g. node@20). Casks only ever offer their current version.
+func (p *BrewProvider) ListVersions(ctx context.Context, method catalog.InstallMethodDef) ([]agent.Version, error) {
+	packageName, isCask := p.parseBrewPackage(method)
+	if packageName == "" {
+		return nil, fmt.Errorf("could not determine brew package name")
+	}
+
+	formulae, err := p.listFormulaVersions(ctx, packageName, isCask)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]agent.Version, 0, len(formulae))
+	for _, f := range formulae {
+		versions = append(versions, f.Version)
+	}
+	return versions, nil
+}
+
+// brewFormulaVersion pairs a formula name with the version it installs.
+type brewFormulaVersion struct {
+	Name    string
+	Version agent.Version
+}
+
+// listFormulaVersions returns packageName and each of its versioned
+// formulae with the version they install, the main formula first.
+func (p *BrewProvider) listFormulaVersions(ctx context.Context, packageName string, isCask bool) ([]brewFormulaVersion, error) {
+	if isCask {
+		latest, err := p.fetchLatestVersionUncached(ctx, packageName, true)
+		if err != nil {
+			return nil, err
+		}
+		return []brewFormulaVersion{{Name: packageName, Version: latest}}, nil
+	}
+
+	output, err := exec.CommandContext(ctx, "brew", "info", "--json=v2", packageName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("brew info failed: %w", err)
+	}
+	formulae, versioned, err := parseBrewVersionsJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versioned) > 0 {
+		args := append([]string{"info", "--json=v2"}, versioned...)
+		if output, err := exec.CommandContext(ctx, "brew", args...).Output(); err == nil {
+			if more, _, err := parseBrewVersionsJSON(output); err == nil {
+				formulae = append(formulae, more...)
+			}
+		}
+	}
+
+	return formulae, nil
+}
+
+// resolveFormula returns the formula to install for the version requested
+// via WithTargetVersion. With no target it is packageName itself; otherwise
+// it is whichever of packageName or its versioned formulae ships exactly
+// that version, since Homebrew cannot install arbitrary old releases.
+func (p *BrewProvider) resolveFormula(ctx context.Context, packageName string, isCask bool) (string, error) {
+	target := TargetVersion(ctx)
+	if target == "" {
+		return packageName, nil
+	}
+	want, err := agent.ParseVersion(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %w", target, err)
+	}
+
+	formulae, err := p.listFormulaVersions(ctx, packageName, isCask)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range formulae {
+		if f.Version.Equals(want) {
+			return f.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no Homebrew formula provides %s %s", packageName, target)
+}
+
+// parseBrewVersionsJSON extracts the name and stable version of every
+// formula in `brew info --json=v2` output, along with the names of any
+// versioned formulae they reference.
+func parseBrewVersionsJSON(output []byte) ([]brewFormulaVersion, []string, error) {
+	var result struct {
+		Formulae []struct {
+			Name     string `json:"name"`
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+			VersionedFormulae []string `json:"versioned_formulae"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse brew info: %w", err)
+	}
+
+	var (
+		formulae  []brewFormulaVersion
+		versioned []string
+	)
+	for _, f := range result.Formulae {
+		if f.Versions.Stable != "" {
+			if v, err := agent.ParseVersion(f.Versions.Stable); err == nil {
+				formulae = append(formulae, brewFormulaVersion{Name: f.Name, Version: v})
+			}
+		}
+		versioned = append(versioned, f.VersionedFormulae...)
+	}
+	return formulae, versioned, nil
+}
+
 // findExecutable attempts to find the executable for an agent.
 func (p *BrewProvider) findExecutable(agentDef catalog.AgentDef) string {
 	for _, exec := range agentDef.Detection.Executables {
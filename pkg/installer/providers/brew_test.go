@@ -1,9 +1,16 @@
 package providers
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
 )
 
 // TestCaskVersionParsesCleanly guards the end-to-end symptom that reached the
@@ -23,3 +30,83 @@ func TestCaskVersionParsesCleanly(t *testing.T) {
 		t.Errorf("version unexpectedly zero")
 	}
 }
+
+func TestParseBrewVersionsJSON(t *testing.T) {
+	output := []byte(`{
+		"formulae": [
+			{"name": "node", "versions": {"stable": "22.3.0"}, "versioned_formulae": ["node@20", "node@18"]},
+			{"name": "node@20", "versions": {"stable": "20.15.0"}, "versioned_formulae": []}
+		],
+		"casks": []
+	}`)
+
+	formulae, versioned, err := parseBrewVersionsJSON(output)
+	if err != nil {
+		t.Fatalf("parseBrewVersionsJSON returned error: %v", err)
+	}
+	if len(formulae) != 2 {
+		t.Fatalf("formulae = %v, want 2 entries", formulae)
+	}
+	if formulae[0].Name != "node" || formulae[0].Version.String() != "22.3.0" {
+		t.Errorf("formulae[0] = %s %s, want node 22.3.0", formulae[0].Name, formulae[0].Version)
+	}
+	if formulae[1].Name != "node@20" || formulae[1].Version.String() != "20.15.0" {
+		t.Errorf("formulae[1] = %s %s, want node@20 20.15.0", formulae[1].Name, formulae[1].Version)
+	}
+	if len(versioned) != 2 || versioned[0] != "node@20" || versioned[1] != "node@18" {
+		t.Errorf("versioned formulae = %v, want [node@20 node@18]", versioned)
+	}
+
+	if _, _, err := parseBrewVersionsJSON([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestBrewPlanVersionedFormulaRelinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake brew is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+"info --json=v2 node") echo '{"formulae":[{"name":"node","versions":{"stable":"22.3.0"},"versioned_formulae":["node@20"],"installed":[{"version":"22.3.0"}]}]}' ;;
+"info --json=v2 node@20") echo '{"formulae":[{"name":"node@20","versions":{"stable":"20.15.0"}}]}' ;;
+*) exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "brew"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p := NewBrewProvider(newMockPlatform())
+	method := catalog.InstallMethodDef{Method: "brew", Package: "node"}
+	ctx := WithTargetVersion(context.Background(), "20.15.0")
+	inst := &agent.Installation{AgentID: "node", InstalledVersion: agent.MustParseVersion("22.3.0")}
+
+	want := [][]string{
+		{"brew", "install", "node@20"},
+		{"brew", "unlink", "node"},
+		{"brew", "link", "--force", "--overwrite", "node@20"},
+	}
+	plan, err := p.PlanUpdate(ctx, inst, catalog.AgentDef{ID: "node"}, method)
+	if err != nil {
+		t.Fatalf("PlanUpdate() error = %v", err)
+	}
+	var got [][]string
+	for _, c := range plan.Commands {
+		got = append(got, c.Argv)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanUpdate() commands = %v, want %v", got, want)
+	}
+
+	// The current release is the unversioned formula, which needs no relinking.
+	plan, err = p.PlanInstall(WithTargetVersion(context.Background(), "22.3.0"), catalog.AgentDef{ID: "node"}, method, false)
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if len(plan.Commands) != 1 || strings.Join(plan.Commands[0].Argv, " ") != "brew install node" {
+		t.Errorf("PlanInstall() commands = %v, want only brew install node", plan.Commands)
+	}
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+var (
+	bunLatestVersionCache sync.Map // package -> latestVersionEntry
+	bunLatestVersionGroup singleflight.Group
+)
+
+// BunProvider handles bun and bunx installations. Installs and uninstalls
+// run the catalog's commands; updates use the npm-compatible registry
+// unless the catalog gives an explicit update_cmd, and latest versions
+// come from the npm registry.
+type BunProvider struct {
+	platform platform.Platform
+	npm      *NPMProvider
+	native   *NativeProvider
+}
+
+// NewBunProvider creates a new bun provider.
+func NewBunProvider(p platform.Platform) *BunProvider {
+	return &BunProvider{
+		platform: p,
+		npm:      NewNPMProvider(p),
+		native:   NewNativeProvider(p),
+	}
+}
+
+// Name returns the provider name.
+func (p *BunProvider) Name() string {
+	return "bun"
+}
+
+// Method returns the install method this provider handles.
+func (p *BunProvider) Method() agent.InstallMethod {
+	return agent.MethodBun
+}
+
+// Methods returns the catalog install methods this provider handles.
+func (p *BunProvider) Methods() []string {
+	return []string{"bun", "bunx"}
+}
+
+// IsAvailable returns true if bun is installed.
+func (p *BunProvider) IsAvailable() bool {
+	return p.platform.IsExecutableInPath("bun")
+}
+
+// Install installs an agent by running the catalog's install command.
+func (p *BunProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
+	return p.native.Install(ctx, agentDef, method, force)
+}
+
+// Update updates a bun-installed agent.
+func (p *BunProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
+	if method.UpdateCmd != "" {
+		return p.native.Update(ctx, inst, agentDef, method)
+	}
+	return p.npm.Update(ctx, inst, agentDef, method)
+}
+
+// Uninstall removes a bun-installed agent.
+func (p *BunProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	return p.native.Uninstall(ctx, inst, method)
+}
+
+// PlanInstall returns the catalog command Install would run.
+func (p *BunProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	return p.native.PlanInstall(ctx, agentDef, method, force)
+}
+
+// PlanUpdate returns the command Update would run.
+func (p *BunProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	if method.UpdateCmd != "" {
+		return p.native.PlanUpdate(ctx, inst, agentDef, method)
+	}
+	return p.npm.PlanUpdate(ctx, inst, agentDef, method)
+}
+
+// PlanUninstall returns what Uninstall would do.
+func (p *BunProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	return p.native.PlanUninstall(ctx, inst, method)
+}
+
+// GetLatestVersion returns the version the npm registry, which bun
+// installs from, tags as latest.
+func (p *BunProvider) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
+	packageName := method.Package
+	if packageName == "" {
+		packageName = extractBunPackage(method.Command)
+	}
+	if packageName == "" {
+		return agent.Version{}, fmt.Errorf("could not determine bun package name")
+	}
+
+	return cachedLatestVersion(&bunLatestVersionCache, &bunLatestVersionGroup, strings.ToLower(packageName), func() (agent.Version, error) {
+		endpoint := npmRegistryURL + "/-/package/" + url.PathEscape(packageName) + "/dist-tags"
+		body, err := fetchRegistry(ctx, "the npm registry", endpoint, nil)
+		if err != nil {
+			return agent.Version{}, err
+		}
+
+		var tags struct {
+			Latest string `json:"latest"`
+		}
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return agent.Version{}, fmt.Errorf("could not parse npm registry response: %w", err)
+		}
+		if tags.Latest == "" {
+			return agent.Version{}, fmt.Errorf("%s has no latest tag", packageName)
+		}
+		return agent.ParseVersion(tags.Latest)
+	})
+}
+
+// extractBunPackage extracts the package from a command such as
+// "bun add -g @scope/pkg", "bun install -g pkg" or "bunx pkg@latest".
+func extractBunPackage(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if field != "add" && field != "install" && field != "i" && field != "bunx" && field != "x" {
+			continue
+		}
+		for _, arg := range fields[i+1:] {
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			if idx := strings.LastIndex(arg, "@"); idx > 0 {
+				arg = arg[:idx]
+			}
+			return arg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+var (
+	cargoLatestVersionCache sync.Map // crate -> latestVersionEntry
+	cargoLatestVersionGroup singleflight.Group
+)
+
+// CargoProvider handles `cargo install` installations. Installs, updates
+// and uninstalls run the catalog's commands; latest versions come from the
+// crates.io sparse index.
+type CargoProvider struct {
+	platform platform.Platform
+	native   *NativeProvider
+}
+
+// NewCargoProvider creates a new cargo provider.
+func NewCargoProvider(p platform.Platform) *CargoProvider {
+	return &CargoProvider{platform: p, native: NewNativeProvider(p)}
+}
+
+// Name returns the provider name.
+func (p *CargoProvider) Name() string {
+	return "cargo"
+}
+
+// Method returns the install method this provider handles.
+func (p *CargoProvider) Method() agent.InstallMethod {
+	return agent.MethodCargo
+}
+
+// Methods returns the catalog install methods this provider handles.
+func (p *CargoProvider) Methods() []string {
+	return []string{"cargo"}
+}
+
+// IsAvailable returns true if cargo is installed.
+func (p *CargoProvider) IsAvailable() bool {
+	return p.platform.IsExecutableInPath("cargo")
+}
+
+// Install installs an agent by running the catalog's install command.
+func (p *CargoProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
+	result, err := p.native.Install(ctx, agentDef, method, force)
+	if result != nil {
+		result.Method = agent.MethodCargo
+	}
+	return result, err
+}
+
+// Update updates a cargo-installed agent.
+func (p *CargoProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
+	result, err := p.native.Update(ctx, inst, agentDef, method)
+	if result != nil {
+		result.Method = agent.MethodCargo
+	}
+	return result, err
+}
+
+// Uninstall removes a cargo-installed agent.
+func (p *CargoProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	return p.native.Uninstall(ctx, inst, method)
+}
+
+// PlanInstall returns the catalog command Install would run.
+func (p *CargoProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	return p.native.PlanInstall(ctx, agentDef, method, force)
+}
+
+// PlanUpdate returns the catalog command Update would run.
+func (p *CargoProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	return p.native.PlanUpdate(ctx, inst, agentDef, method)
+}
+
+// PlanUninstall returns what Uninstall would do.
+func (p *CargoProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	return p.native.PlanUninstall(ctx, inst, method)
+}
+
+// GetLatestVersion returns the newest release of a crate on crates.io,
+// skipping yanked versions and prereleases. Crates installed from git or a
+// local path have no registry version.
+func (p *CargoProvider) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
+	crate, err := extractCargoCrate(method)
+	if err != nil {
+		return agent.Version{}, err
+	}
+
+	return cachedLatestVersion(&cargoLatestVersionCache, &cargoLatestVersionGroup, crate, func() (agent.Version, error) {
+		body, err := fetchRegistry(ctx, "crates.io", cratesIndexURL+"/"+cratesIndexPath(crate), nil)
+		if err != nil {
+			return agent.Version{}, err
+		}
+		return parseCratesIndex(body)
+	})
+}
+
+// extractCargoCrate returns the crate a cargo install method installs from
+// crates.io.
+func extractCargoCrate(method catalog.InstallMethodDef) (string, error) {
+	fields := strings.Fields(method.Command)
+	for _, field := range fields {
+		if field == "--git" || field == "--path" {
+			return "", fmt.Errorf("version checking not supported for cargo installs from %s", strings.TrimPrefix(field, "--"))
+		}
+	}
+	if method.Package != "" {
+		return strings.ToLower(method.Package), nil
+	}
+
+	// Flags that take a value, whose value is not the crate.
+	valueFlags := map[string]bool{
+		"--version": true, "--vers": true, "--root": true, "--index": true, "--registry": true,
+		"--branch": true, "--tag": true, "--rev": true, "--bin": true, "--features": true, "-F": true,
+	}
+	for i := 0; i < len(fields); i++ {
+		if fields[i] != "install" {
+			continue
+		}
+		for j := i + 1; j < len(fields); j++ {
+			switch {
+			case valueFlags[fields[j]]:
+				j++
+			case strings.HasPrefix(fields[j], "-"):
+			default:
+				crate, _, _ := strings.Cut(fields[j], "@")
+				return strings.ToLower(crate), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("could not determine crate name")
+}
+
+// cratesIndexPath returns the path of a crate's file in the crates.io
+// index: 1/a, 2/ab, 3/a/abc, and ab/cd/abcd... for longer names.
+func cratesIndexPath(crate string) string {
+	switch len(crate) {
+	case 1:
+		return "1/" + crate
+	case 2:
+		return "2/" + crate
+	case 3:
+		return "3/" + crate[:1] + "/" + crate
+	default:
+		return crate[:2] + "/" + crate[2:4] + "/" + crate
+	}
+}
+
+// parseCratesIndex returns the newest stable, non-yanked version in a
+// crates.io index file, which holds one JSON object per published version.
+func parseCratesIndex(body []byte) (agent.Version, error) {
+	var versions []agent.Version
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRegistryResponse)
+	for scanner.Scan() {
+		var entry struct {
+			Vers   string `json:"vers"`
+			Yanked bool   `json:"yanked"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Yanked {
+			continue
+		}
+		if v, err := agent.ParseVersion(entry.Vers); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return agent.Version{}, fmt.Errorf("could not parse crates.io index: %w", err)
+	}
+
+	latest, ok := agent.VersionConstraints(nil).Latest(versions)
+	if !ok {
+		return agent.Version{}, fmt.Errorf("crates.io lists no stable release")
+	}
+	return latest, nil
+}
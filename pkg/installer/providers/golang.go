@@ -0,0 +1,167 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+var (
+	goLatestVersionCache sync.Map // package path -> latestVersionEntry
+	goLatestVersionGroup singleflight.Group
+)
+
+// GoProvider handles `go install` installations. Installs, updates and
+// uninstalls run the catalog's commands; latest versions come from the Go
+// module proxy.
+type GoProvider struct {
+	platform platform.Platform
+	native   *NativeProvider
+}
+
+// NewGoProvider creates a new go install provider.
+func NewGoProvider(p platform.Platform) *GoProvider {
+	return &GoProvider{platform: p, native: NewNativeProvider(p)}
+}
+
+// Name returns the provider name.
+func (p *GoProvider) Name() string {
+	return "go"
+}
+
+// Method returns the install method this provider handles.
+func (p *GoProvider) Method() agent.InstallMethod {
+	return agent.MethodGo
+}
+
+// Methods returns the catalog install methods this provider handles.
+func (p *GoProvider) Methods() []string {
+	return []string{"go"}
+}
+
+// IsAvailable returns true if the go toolchain is installed.
+func (p *GoProvider) IsAvailable() bool {
+	return p.platform.IsExecutableInPath("go")
+}
+
+// Install installs an agent by running the catalog's install command.
+func (p *GoProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
+	result, err := p.native.Install(ctx, agentDef, method, force)
+	if result != nil {
+		result.Method = agent.MethodGo
+	}
+	return result, err
+}
+
+// Update updates a go-installed agent.
+func (p *GoProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
+	result, err := p.native.Update(ctx, inst, agentDef, method)
+	if result != nil {
+		result.Method = agent.MethodGo
+	}
+	return result, err
+}
+
+// Uninstall removes a go-installed agent.
+func (p *GoProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	return p.native.Uninstall(ctx, inst, method)
+}
+
+// PlanInstall returns the catalog command Install would run.
+func (p *GoProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	return p.native.PlanInstall(ctx, agentDef, method, force)
+}
+
+// PlanUpdate returns the catalog command Update would run.
+func (p *GoProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	return p.native.PlanUpdate(ctx, inst, agentDef, method)
+}
+
+// PlanUninstall returns what Uninstall would do.
+func (p *GoProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	return p.native.PlanUninstall(ctx, inst, method)
+}
+
+// GetLatestVersion returns the version the module proxy resolves @latest
+// to for the module providing the installed package.
+func (p *GoProvider) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
+	pkg := extractGoPackage(method)
+	if pkg == "" {
+		return agent.Version{}, fmt.Errorf("could not determine go package path")
+	}
+
+	return cachedLatestVersion(&goLatestVersionCache, &goLatestVersionGroup, pkg, func() (agent.Version, error) {
+		return fetchGoLatest(ctx, pkg)
+	})
+}
+
+// fetchGoLatest asks the proxy for @latest of pkg and, while the proxy
+// knows no such module, of each parent path in turn, since a command
+// usually lives in a subdirectory such as cmd/<name> of its module.
+func fetchGoLatest(ctx context.Context, pkg string) (agent.Version, error) {
+	for path := pkg; strings.Contains(path, "/"); path = path[:strings.LastIndex(path, "/")] {
+		body, err := fetchRegistry(ctx, "the Go module proxy", goProxyURL+"/"+escapeModulePath(path)+"/@latest", nil)
+		if errors.Is(err, errRegistryNotFound) {
+			continue
+		}
+		if err != nil {
+			return agent.Version{}, err
+		}
+
+		var info struct {
+			Version string `json:"Version"`
+		}
+		if err := json.Unmarshal(body, &info); err != nil {
+			return agent.Version{}, fmt.Errorf("could not parse module proxy response: %w", err)
+		}
+		return agent.ParseVersion(info.Version)
+	}
+	return agent.Version{}, fmt.Errorf("the Go module proxy has no module providing %s", pkg)
+}
+
+// extractGoPackage returns the package path a go install method installs,
+// from its package field or a command such as "go install example.com/x/cmd/y@latest".
+func extractGoPackage(method catalog.InstallMethodDef) string {
+	pkg := method.Package
+	if pkg == "" {
+		fields := strings.Fields(method.Command)
+		for i, field := range fields {
+			if field != "install" {
+				continue
+			}
+			for _, arg := range fields[i+1:] {
+				if !strings.HasPrefix(arg, "-") {
+					pkg = arg
+					break
+				}
+			}
+			break
+		}
+	}
+	pkg, _, _ = strings.Cut(pkg, "@")
+	return pkg
+}
+
+// escapeModulePath applies the module proxy's case encoding, which writes
+// each upper-case letter as '!' followed by its lower-case form.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
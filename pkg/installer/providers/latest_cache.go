@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"github.com/kevinelliott/agentmanager/pkg/agent"
 )
 
@@ -39,3 +41,30 @@ func loadLatestVersionEntry(cache *sync.Map, key string) (latestVersionEntry, bo
 func shouldCacheLatestVersionError(err error) bool {
 	return err == nil || (!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded))
 }
+
+// cachedLatestVersion returns the latest version cached under key, or calls
+// fetch to look it up. Concurrent lookups of the same key share one fetch,
+// and its result is cached for registryLatestVersionTTL unless ctx ended.
+func cachedLatestVersion(cache *sync.Map, group *singleflight.Group, key string, fetch func() (agent.Version, error)) (agent.Version, error) {
+	if entry, ok := loadLatestVersionEntry(cache, key); ok {
+		return entry.version, entry.err
+	}
+
+	v, _, _ := group.Do(key, func() (any, error) {
+		if entry, ok := loadLatestVersionEntry(cache, key); ok {
+			return entry, nil
+		}
+		version, err := fetch()
+		entry := latestVersionEntry{version: version, err: err, cachedAt: time.Now()}
+		if shouldCacheLatestVersionError(err) {
+			cache.Store(key, entry)
+		}
+		return entry, nil
+	})
+
+	entry, ok := v.(latestVersionEntry)
+	if !ok {
+		return fetch()
+	}
+	return entry.version, entry.err
+}
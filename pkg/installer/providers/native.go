@@ -1,10 +1,8 @@
 package providers
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -35,18 +33,26 @@ func (p *NativeProvider) Method() agent.InstallMethod {
 	return agent.MethodNative
 }
 
+// Methods returns the catalog install methods this provider handles: every
+// method whose install, update and uninstall are the catalog's own
+// commands.
+func (p *NativeProvider) Methods() []string {
+	return []string{"native", "curl", "scoop", "chocolatey", "powershell", "winget", "dmg", "krew", "nix", "git"}
+}
+
 // IsAvailable returns true - native install is always available.
 func (p *NativeProvider) IsAvailable() bool {
 	return true
 }
 
-// Install installs an agent via native method.
+// Install installs an agent via native method. The catalog's command
+// decides the version, so a target version is refused.
 func (p *NativeProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
 	start := time.Now()
 
-	command := method.Command
-	if command == "" {
-		return nil, fmt.Errorf("no install command specified")
+	command, err := p.installScript(ctx, method)
+	if err != nil {
+		return nil, err
 	}
 
 	// Execute the install command
@@ -76,13 +82,9 @@ func (p *NativeProvider) Install(ctx context.Context, agentDef catalog.AgentDef,
 func (p *NativeProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
 	start := time.Now()
 
-	command := method.UpdateCmd
-	if command == "" {
-		// Fall back to running the install command again
-		command = method.Command
-	}
-	if command == "" {
-		return nil, fmt.Errorf("no update command specified")
+	command, err := p.updateScript(ctx, method)
+	if err != nil {
+		return nil, err
 	}
 
 	fromVersion := inst.InstalledVersion
@@ -109,6 +111,12 @@ func (p *NativeProvider) Update(ctx context.Context, inst *agent.Installation, a
 	}, nil
 }
 
+// GetLatestVersion is not supported: native methods have no standard
+// registry to check.
+func (p *NativeProvider) GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error) {
+	return agent.Version{}, fmt.Errorf("version checking not supported for %s", method.Method)
+}
+
 // Uninstall removes a native-installed agent.
 func (p *NativeProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
 	command := method.UninstallCmd
@@ -135,23 +143,78 @@ func (p *NativeProvider) Uninstall(ctx context.Context, inst *agent.Installation
 	return nil
 }
 
-// executeCommand runs a shell command, tee-ing output to any progress writer
-// attached to ctx via WithProgressWriter.
-func (p *NativeProvider) executeCommand(ctx context.Context, command string) (string, error) {
-	shell := p.platform.GetShell()
-	shellArg := p.platform.GetShellArg()
+// PlanInstall returns the shell command Install would run.
+func (p *NativeProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	script, err := p.installScript(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(p.shellCommand(script)), nil
+}
 
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, shell, shellArg, command)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
+// PlanUpdate returns the shell command Update would run.
+func (p *NativeProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	script, err := p.updateScript(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(p.shellCommand(script)), nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("%w\n%s", err, stderr.String())
+// PlanUninstall returns the shell command Uninstall would run, or the
+// executable it would remove when the catalog has no uninstall command.
+func (p *NativeProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	if method.UninstallCmd != "" {
+		return commandPlan(p.shellCommand(method.UninstallCmd)), nil
 	}
+	if inst.ExecutablePath == "" {
+		return nil, fmt.Errorf("no uninstall command and no executable path")
+	}
+	return &Plan{Steps: []string{"remove " + inst.ExecutablePath}}, nil
+}
+
+// installScript returns the catalog command Install runs. The command
+// decides the version, so a target version is refused.
+func (p *NativeProvider) installScript(ctx context.Context, method catalog.InstallMethodDef) (string, error) {
+	if target := TargetVersion(ctx); target != "" {
+		return "", fmt.Errorf("installing a specific version is not supported for %s", method.Method)
+	}
+	if method.Command == "" {
+		return "", fmt.Errorf("no install command specified")
+	}
+	return method.Command, nil
+}
 
-	return stdout.String(), nil
+// updateScript returns the catalog command Update runs, falling back to
+// the install command.
+func (p *NativeProvider) updateScript(ctx context.Context, method catalog.InstallMethodDef) (string, error) {
+	if target := TargetVersion(ctx); target != "" {
+		return "", fmt.Errorf("updating to a specific version is not supported for %s", method.Method)
+	}
+	command := method.UpdateCmd
+	if command == "" {
+		// Fall back to running the install command again
+		command = method.Command
+	}
+	if command == "" {
+		return "", fmt.Errorf("no update command specified")
+	}
+	return command, nil
+}
+
+// shellCommand returns the command running script in the platform's shell.
+func (p *NativeProvider) shellCommand(script string) Command {
+	return newCommand(p.platform.GetShell(), p.platform.GetShellArg(), script)
+}
+
+// executeCommand runs a shell command, tee-ing output to any progress writer
+// attached to ctx via WithProgressWriter.
+func (p *NativeProvider) executeCommand(ctx context.Context, command string) (string, error) {
+	stdout, stderr, err := p.shellCommand(command).run(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w\n%s", err, stderr)
+	}
+	return stdout, nil
 }
 
 // getInstalledVersion gets the installed version of an agent.
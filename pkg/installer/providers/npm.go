@@ -2,10 +2,9 @@
 package providers
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"os/exec"
 	"strings"
 	"sync"
@@ -43,6 +42,11 @@ func (p *NPMProvider) Method() agent.InstallMethod {
 	return agent.MethodNPM
 }
 
+// Methods returns the catalog install methods this provider handles.
+func (p *NPMProvider) Methods() []string {
+	return []string{"npm"}
+}
+
 // IsAvailable returns true if npm is available.
 func (p *NPMProvider) IsAvailable() bool {
 	return p.platform.IsExecutableInPath("npm")
@@ -52,29 +56,14 @@ func (p *NPMProvider) IsAvailable() bool {
 func (p *NPMProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
 	start := time.Now()
 
-	packageName := method.Package
-	if packageName == "" {
-		packageName = extractNPMPackage(method.Command)
-	}
-	if packageName == "" {
-		return nil, fmt.Errorf("could not determine npm package name")
-	}
-
-	// Build install command
-	args := []string{"install", "-g"}
-	if force {
-		args = append(args, "--force")
+	command, packageName, err := p.installCommand(ctx, method, force)
+	if err != nil {
+		return nil, err
 	}
-	args = append(args, packageName)
-
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, "npm", args...)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("npm install failed: %w\n%s%s", err, stderr.String(), formatNPMPermissionHint(stderr.String()))
+	stdout, stderr, err := command.run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("npm install failed: %w\n%s%s", err, stderr, formatNPMPermissionHint(stderr))
 	}
 
 	// Get installed version
@@ -90,7 +79,7 @@ func (p *NPMProvider) Install(ctx context.Context, agentDef catalog.AgentDef, me
 		Version:        version,
 		ExecutablePath: execPath,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 	}, nil
 }
 
@@ -98,25 +87,17 @@ func (p *NPMProvider) Install(ctx context.Context, agentDef catalog.AgentDef, me
 func (p *NPMProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
 	start := time.Now()
 
-	packageName := method.Package
-	if packageName == "" {
-		packageName = extractNPMPackage(method.Command)
-	}
-	if packageName == "" {
-		return nil, fmt.Errorf("could not determine npm package name")
+	command, packageName, err := p.updateCommand(ctx, method)
+	if err != nil {
+		return nil, err
 	}
 
 	fromVersion := inst.InstalledVersion
 
 	// Run update command
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, "npm", "update", "-g", packageName)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("npm update failed: %w\n%s%s", err, stderr.String(), formatNPMPermissionHint(stderr.String()))
+	stdout, stderr, err := command.run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("npm update failed: %w\n%s%s", err, stderr, formatNPMPermissionHint(stderr))
 	}
 
 	// Get new version
@@ -129,7 +110,7 @@ func (p *NPMProvider) Update(ctx context.Context, inst *agent.Installation, agen
 		FromVersion:    fromVersion,
 		Version:        toVersion,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 		WasUpdated:     toVersion.IsNewerThan(fromVersion),
 		ExecutablePath: inst.ExecutablePath,
 	}, nil
@@ -137,23 +118,100 @@ func (p *NPMProvider) Update(ctx context.Context, inst *agent.Installation, agen
 
 // Uninstall removes an npm-installed agent.
 func (p *NPMProvider) Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error {
+	command, err := p.uninstallCommand(method)
+	if err != nil {
+		return err
+	}
+
+	if _, stderr, err := command.run(ctx); err != nil {
+		return fmt.Errorf("npm uninstall failed: %w\n%s%s", err, stderr, formatNPMPermissionHint(stderr))
+	}
+
+	return nil
+}
+
+// PlanInstall returns the command Install would run.
+func (p *NPMProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	command, _, err := p.installCommand(ctx, method, force)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// PlanUpdate returns the command Update would run.
+func (p *NPMProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	command, _, err := p.updateCommand(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// PlanUninstall returns the command Uninstall would run.
+func (p *NPMProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	command, err := p.uninstallCommand(method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// npmPackage returns the package an npm method installs.
+func npmPackage(method catalog.InstallMethodDef) (string, error) {
 	packageName := method.Package
 	if packageName == "" {
 		packageName = extractNPMPackage(method.Command)
 	}
 	if packageName == "" {
-		return fmt.Errorf("could not determine npm package name")
+		return "", fmt.Errorf("could not determine npm package name")
+	}
+	return packageName, nil
+}
+
+// installCommand returns the command installing the method's package, and
+// the package.
+func (p *NPMProvider) installCommand(ctx context.Context, method catalog.InstallMethodDef, force bool) (Command, string, error) {
+	packageName, err := npmPackage(method)
+	if err != nil {
+		return Command{}, "", err
+	}
+
+	args := []string{"npm", "install", "-g"}
+	if force {
+		args = append(args, "--force")
+	}
+	if target := TargetVersion(ctx); target != "" {
+		args = append(args, packageName+"@"+target)
+	} else {
+		args = append(args, packageName)
 	}
+	return newCommand(args...), packageName, nil
+}
 
-	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, "npm", "uninstall", "-g", packageName)
-	cmd.Stderr = &stderr
+// updateCommand returns the command updating the method's package, and
+// the package.
+func (p *NPMProvider) updateCommand(ctx context.Context, method catalog.InstallMethodDef) (Command, string, error) {
+	packageName, err := npmPackage(method)
+	if err != nil {
+		return Command{}, "", err
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("npm uninstall failed: %w\n%s%s", err, stderr.String(), formatNPMPermissionHint(stderr.String()))
+	// `npm update` only moves within the range npm recorded at install
+	// time, so a specific target is installed explicitly instead.
+	if target := TargetVersion(ctx); target != "" {
+		return newCommand("npm", "install", "-g", packageName+"@"+target), packageName, nil
 	}
+	return newCommand("npm", "update", "-g", packageName), packageName, nil
+}
 
-	return nil
+// uninstallCommand returns the command removing the method's package.
+func (p *NPMProvider) uninstallCommand(method catalog.InstallMethodDef) (Command, error) {
+	packageName, err := npmPackage(method)
+	if err != nil {
+		return Command{}, err
+	}
+	return newCommand("npm", "uninstall", "-g", packageName), nil
 }
 
 // getInstalledVersion gets the installed version of an npm package.
@@ -243,6 +301,46 @@ func (p *NPMProvider) fetchLatestVersionUncached(ctx context.Context, packageNam
 	return version, nil
 }
 
+// ListVersions returns every version of an npm package published to the registry.
+func (p *NPMProvider) ListVersions(ctx context.Context, method catalog.InstallMethodDef) ([]agent.Version, error) {
+	packageName := method.Package
+	if packageName == "" {
+		packageName = extractNPMPackage(method.Command)
+	}
+	if packageName == "" {
+		return nil, fmt.Errorf("could not determine npm package name")
+	}
+
+	cmd := exec.CommandContext(ctx, "npm", "view", packageName, "versions", "--json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("npm view failed: %w", err)
+	}
+
+	return parseNPMVersionsJSON(output)
+}
+
+// parseNPMVersionsJSON parses `npm view <pkg> versions --json` output. npm
+// prints a bare string instead of an array when only one version exists.
+func parseNPMVersionsJSON(output []byte) ([]agent.Version, error) {
+	var raw []string
+	if err := json.Unmarshal(output, &raw); err != nil {
+		var single string
+		if json.Unmarshal(output, &single) != nil {
+			return nil, fmt.Errorf("failed to parse npm versions: %w", err)
+		}
+		raw = []string{single}
+	}
+
+	versions := make([]agent.Version, 0, len(raw))
+	for _, s := range raw {
+		if v, err := agent.ParseVersion(s); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
 // extractNPMPackage extracts the package name from an npm install command.
 func extractNPMPackage(command string) string {
 	parts := strings.Fields(command)
@@ -1,13 +1,12 @@
 package providers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os/exec"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +24,10 @@ var pypiHTTPClient = &http.Client{
 	Timeout: 15 * time.Second,
 }
 
+// pypiBaseURL is the PyPI JSON API root. It is a variable so tests can
+// point it at an httptest server.
+var pypiBaseURL = "https://pypi.org/pypi"
+
 var (
 	pipLatestVersionCache sync.Map // "<method>:<package>" -> latestVersionEntry
 	pipLatestVersionGroup singleflight.Group
@@ -50,6 +53,11 @@ func (p *PipProvider) Method() agent.InstallMethod {
 	return agent.MethodPip
 }
 
+// Methods returns the catalog install methods this provider handles.
+func (p *PipProvider) Methods() []string {
+	return []string{"pip", "pipx", "uv"}
+}
+
 // IsAvailable returns true if pip, pipx, or uv is available.
 func (p *PipProvider) IsAvailable() bool {
 	return p.platform.IsExecutableInPath("pip") ||
@@ -62,19 +70,15 @@ func (p *PipProvider) IsAvailable() bool {
 func (p *PipProvider) Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error) {
 	start := time.Now()
 
-	manager, args, packageName, err := p.buildInstallCommand(method, force)
+	command, packageName, err := p.installCommand(ctx, method, force)
 	if err != nil {
 		return nil, err
 	}
+	manager := command.Argv[0]
 
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, manager, args...)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("%s install failed: %w\n%s%s", manager, err, stderr.String(), FormatInstallError(manager, "install", stderr.String()))
+	stdout, stderr, err := command.run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s install failed: %w\n%s%s", manager, err, stderr, FormatInstallError(manager, "install", stderr))
 	}
 
 	// Get installed version
@@ -90,7 +94,7 @@ func (p *PipProvider) Install(ctx context.Context, agentDef catalog.AgentDef, me
 		Version:        version,
 		ExecutablePath: execPath,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 	}, nil
 }
 
@@ -98,21 +102,17 @@ func (p *PipProvider) Install(ctx context.Context, agentDef catalog.AgentDef, me
 func (p *PipProvider) Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error) {
 	start := time.Now()
 
-	manager, args, packageName, err := p.buildUpdateCommand(method)
+	command, packageName, err := p.updateCommand(ctx, method)
 	if err != nil {
 		return nil, err
 	}
+	manager := command.Argv[0]
 
 	fromVersion := inst.InstalledVersion
 
-	var stdout, stderr bytes.Buffer
-	progress := ProgressWriter(ctx)
-	cmd := exec.CommandContext(ctx, manager, args...)
-	cmd.Stdout = io.MultiWriter(&stdout, progress)
-	cmd.Stderr = io.MultiWriter(&stderr, progress)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("%s update failed: %w\n%s%s", manager, err, stderr.String(), FormatInstallError(manager, "update", stderr.String()))
+	stdout, stderr, err := command.run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s update failed: %w\n%s%s", manager, err, stderr, FormatInstallError(manager, "update", stderr))
 	}
 
 	// Get new version
@@ -125,7 +125,7 @@ func (p *PipProvider) Update(ctx context.Context, inst *agent.Installation, agen
 		FromVersion:    fromVersion,
 		Version:        toVersion,
 		Duration:       time.Since(start),
-		Output:         stdout.String(),
+		Output:         stdout,
 		WasUpdated:     toVersion.IsNewerThan(fromVersion),
 		ExecutablePath: inst.ExecutablePath,
 	}, nil
@@ -138,17 +138,67 @@ func (p *PipProvider) Uninstall(ctx context.Context, inst *agent.Installation, m
 		return err
 	}
 
-	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, manager, args...)
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("%s uninstall failed: %w\n%s", manager, err, stderr.String())
+	command := newCommand(append([]string{manager}, args...)...)
+	if _, stderr, err := command.run(ctx); err != nil {
+		return fmt.Errorf("%s uninstall failed: %w\n%s", manager, err, stderr)
 	}
 
 	return nil
 }
 
+// PlanInstall returns the command Install would run.
+func (p *PipProvider) PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error) {
+	command, _, err := p.installCommand(ctx, method, force)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// PlanUpdate returns the command Update would run.
+func (p *PipProvider) PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error) {
+	command, _, err := p.updateCommand(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(command), nil
+}
+
+// PlanUninstall returns the command Uninstall would run.
+func (p *PipProvider) PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error) {
+	manager, args, _, err := p.buildUninstallCommand(method)
+	if err != nil {
+		return nil, err
+	}
+	return commandPlan(newCommand(append([]string{manager}, args...)...)), nil
+}
+
+// installCommand returns the command Install runs, and the package.
+func (p *PipProvider) installCommand(ctx context.Context, method catalog.InstallMethodDef, force bool) (Command, string, error) {
+	manager, args, packageName, err := p.buildInstallCommand(method, force)
+	if err != nil {
+		return Command{}, "", err
+	}
+	// The package is always the last argument; pin it with a PEP 440
+	// requirement when a specific version was asked for.
+	if target := TargetVersion(ctx); target != "" {
+		args[len(args)-1] = packageName + "==" + target
+	}
+	return newCommand(append([]string{manager}, args...)...), packageName, nil
+}
+
+// updateCommand returns the command Update runs, and the package.
+func (p *PipProvider) updateCommand(ctx context.Context, method catalog.InstallMethodDef) (Command, string, error) {
+	manager, args, packageName, err := p.buildUpdateCommand(method)
+	if target := TargetVersion(ctx); target != "" {
+		manager, args, packageName, err = p.buildVersionedInstallCommand(method, target)
+	}
+	if err != nil {
+		return Command{}, "", err
+	}
+	return newCommand(append([]string{manager}, args...)...), packageName, nil
+}
+
 // buildInstallCommand builds the install command for the appropriate package manager.
 func (p *PipProvider) buildInstallCommand(method catalog.InstallMethodDef, force bool) (string, []string, string, error) {
 	methodName := method.Method
@@ -229,6 +279,35 @@ func (p *PipProvider) buildUpdateCommand(method catalog.InstallMethodDef) (strin
 	}
 }
 
+// buildVersionedInstallCommand builds a command that installs exactly
+// version, replacing whatever is installed. pipx and uv refuse to touch an
+// existing tool without --force; pip handles up- and downgrades itself.
+func (p *PipProvider) buildVersionedInstallCommand(method catalog.InstallMethodDef, version string) (string, []string, string, error) {
+	packageName := method.Package
+	if packageName == "" {
+		packageName = extractPipPackage(method.Command)
+	}
+	if packageName == "" {
+		return "", nil, "", fmt.Errorf("could not determine package name")
+	}
+	requirement := packageName + "==" + version
+
+	switch method.Method {
+	case "pipx":
+		return "pipx", []string{"install", "--force", requirement}, packageName, nil
+
+	case "uv":
+		return "uv", []string{"tool", "install", "--force", requirement}, packageName, nil
+
+	default: // pip
+		manager := "pip3"
+		if !p.platform.IsExecutableInPath("pip3") {
+			manager = "pip"
+		}
+		return manager, []string{"install", requirement}, packageName, nil
+	}
+}
+
 // buildUninstallCommand builds the uninstall command.
 func (p *PipProvider) buildUninstallCommand(method catalog.InstallMethodDef) (string, []string, string, error) {
 	methodName := method.Method
@@ -400,7 +479,7 @@ func (p *PipProvider) fetchLatestVersionUncached(ctx context.Context, methodName
 // call and silently lost context (timeouts / cancellation). The shared
 // http.Client reuses connections and honors ctx.
 func (p *PipProvider) getLatestFromPyPI(ctx context.Context, packageName string) (agent.Version, error) {
-	url := fmt.Sprintf("https://pypi.org/pypi/%s/json", packageName)
+	url := fmt.Sprintf("%s/%s/json", pypiBaseURL, packageName)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -438,6 +517,89 @@ func (p *PipProvider) getLatestFromPyPI(ctx context.Context, packageName string)
 	return version, nil
 }
 
+// ListVersions returns every non-yanked release of a package on PyPI.
+// pipx and uv install from PyPI too, so the method only matters for
+// resolving the package name.
+func (p *PipProvider) ListVersions(ctx context.Context, method catalog.InstallMethodDef) ([]agent.Version, error) {
+	packageName := method.Package
+	if packageName == "" {
+		packageName = extractPipPackage(method.Command)
+	}
+	if packageName == "" {
+		return nil, fmt.Errorf("could not determine package name")
+	}
+
+	url := fmt.Sprintf("%s/%s/json", pypiBaseURL, packageName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build PyPI request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "AgentManager/1.0")
+
+	resp, err := pypiHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from PyPI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PyPI returned HTTP %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Releases map[string][]struct {
+			Yanked bool `json:"yanked"`
+		} `json:"releases"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("could not parse PyPI response: %w", err)
+	}
+
+	versions := make([]agent.Version, 0, len(payload.Releases))
+	for raw, files := range payload.Releases {
+		// A release with no files cannot be installed, and one whose files
+		// were all yanked is not meant to be picked by a resolver.
+		yanked := true
+		for _, f := range files {
+			if !f.Yanked {
+				yanked = false
+				break
+			}
+		}
+		if yanked {
+			continue
+		}
+		versions = append(versions, parsePEP440Version(raw))
+	}
+	return versions, nil
+}
+
+// pep440Regex splits a PEP 440 version into its release segment and the
+// remainder (pre-, post- or dev-release suffix).
+var pep440Regex = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(.*)$`)
+
+// parsePEP440Version parses a PyPI version string. ParseVersion alone would
+// read "1.2.0rc1" as the final 1.2.0 release, so pre- and dev-releases are
+// mapped onto semver prereleases. Post-releases compare equal to their base
+// release. Raw always keeps the original string for `pkg==<raw>` installs.
+func parsePEP440Version(s string) agent.Version {
+	m := pep440Regex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		v, _ := agent.ParseVersion(s)
+		return v
+	}
+
+	release, suffix := m[1], strings.ToLower(strings.TrimLeft(m[2], ".-_"))
+	if suffix != "" && !strings.HasPrefix(suffix, "post") {
+		release += "-" + strings.NewReplacer(".", "", "_", "", "-", "").Replace(suffix)
+	}
+
+	v, _ := agent.ParseVersion(release)
+	v.Raw = s
+	return v
+}
+
 // extractPipPackage extracts the package name from a pip install command.
 func extractPipPackage(command string) string {
 	parts := strings.Fields(command)
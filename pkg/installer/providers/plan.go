@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+// Operation names what a Plan does.
+type Operation string
+
+const (
+	OperationInstall   Operation = "install"
+	OperationUpdate    Operation = "update"
+	OperationUninstall Operation = "uninstall"
+)
+
+// Command is one external command a provider runs.
+type Command struct {
+	// Argv is the program followed by its arguments.
+	Argv []string `json:"argv"`
+	// Dir is the working directory. Empty means agentmgr's own.
+	Dir string `json:"dir,omitempty"`
+	// Env lists the KEY=value variables set on top of agentmgr's
+	// environment.
+	Env []string `json:"env,omitempty"`
+	// Elevated reports that the command runs with administrator rights,
+	// through sudo or a similar tool.
+	Elevated bool `json:"elevated"`
+}
+
+// Plan describes an install, update or uninstall without carrying it out.
+// Providers fill in Commands and Steps; installer.Manager fills in the
+// rest.
+type Plan struct {
+	AgentID   string    `json:"agent"`
+	Method    string    `json:"method"`
+	Operation Operation `json:"operation"`
+	Provider  string    `json:"provider"`
+	// TargetVersion is the version asked for with WithTargetVersion, or ""
+	// for the latest.
+	TargetVersion string `json:"target_version,omitempty"`
+	// Commands are run in order.
+	Commands []Command `json:"commands"`
+	// Steps describe work done without running a command, such as
+	// downloads and file removals.
+	Steps []string `json:"steps,omitempty"`
+}
+
+// Planner is implemented by providers that can describe an operation
+// without carrying it out. Planning may query a registry, but never
+// changes the system.
+type Planner interface {
+	PlanInstall(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Plan, error)
+	PlanUpdate(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Plan, error)
+	PlanUninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) (*Plan, error)
+}
+
+// newCommand returns the command running argv.
+func newCommand(argv ...string) Command {
+	return Command{Argv: argv, Elevated: needsElevation(argv)}
+}
+
+// commandPlan returns a plan running commands.
+func commandPlan(commands ...Command) *Plan {
+	return &Plan{Commands: commands}
+}
+
+// elevationTools are the programs that run a command as an administrator.
+var elevationTools = map[string]bool{"sudo": true, "doas": true, "pkexec": true, "runas": true, "gsudo": true}
+
+// needsElevation reports whether argv, or a shell script in it, runs
+// something through an elevation tool.
+func needsElevation(argv []string) bool {
+	for _, arg := range argv {
+		if strings.Contains(strings.ToLower(arg), "-verb runas") {
+			return true
+		}
+		words := strings.FieldsFunc(arg, func(r rune) bool {
+			return strings.ContainsRune(" \t\n;&|()`$", r)
+		})
+		for _, word := range words {
+			if elevationTools[strings.ToLower(strings.TrimSuffix(filepath.Base(word), ".exe"))] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the command line, quoting arguments a POSIX shell would
+// split.
+func (c Command) String() string {
+	quoted := make([]string, len(c.Argv))
+	for i, arg := range c.Argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;()<>*?[]#~!{}") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// run runs the command, tee-ing its output to any progress writer attached
+// to ctx via WithProgressWriter, and returns what it wrote to stdout and
+// stderr.
+func (c Command) run(ctx context.Context) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	progress := ProgressWriter(ctx)
+	cmd := exec.CommandContext(ctx, c.Argv[0], c.Argv[1:]...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdout = io.MultiWriter(&stdout, progress)
+	cmd.Stderr = io.MultiWriter(&stderr, progress)
+
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
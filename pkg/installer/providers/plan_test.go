@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+func TestPlanArgv(t *testing.T) {
+	plat := newMockPlatform()
+	plat.executables["pipx"] = "/usr/local/bin/pipx"
+	ctx := context.Background()
+	pinned := WithTargetVersion(ctx, "1.2.3")
+	inst := &agent.Installation{AgentID: "a", ExecutablePath: "/usr/local/bin/a"}
+	def := catalog.AgentDef{ID: "a"}
+
+	npm := NewNPMProvider(plat)
+	npmMethod := catalog.InstallMethodDef{Method: "npm", Package: "@acme/agent"}
+	pipx := NewPipProvider(plat)
+	pipxMethod := catalog.InstallMethodDef{Method: "pipx", Package: "acme-agent"}
+	native := NewNativeProvider(plat)
+	nativeMethod := catalog.InstallMethodDef{Method: "curl", Command: "curl -fsSL https://example.com/install.sh | bash"}
+
+	tests := []struct {
+		name string
+		plan func() (*Plan, error)
+		want []string
+	}{
+		{"npm install", func() (*Plan, error) { return npm.PlanInstall(ctx, def, npmMethod, true) },
+			[]string{"npm", "install", "-g", "--force", "@acme/agent"}},
+		{"npm install pinned", func() (*Plan, error) { return npm.PlanInstall(pinned, def, npmMethod, false) },
+			[]string{"npm", "install", "-g", "@acme/agent@1.2.3"}},
+		{"npm update", func() (*Plan, error) { return npm.PlanUpdate(ctx, inst, def, npmMethod) },
+			[]string{"npm", "update", "-g", "@acme/agent"}},
+		{"npm uninstall", func() (*Plan, error) { return npm.PlanUninstall(ctx, inst, npmMethod) },
+			[]string{"npm", "uninstall", "-g", "@acme/agent"}},
+		{"pipx install pinned", func() (*Plan, error) { return pipx.PlanInstall(pinned, def, pipxMethod, false) },
+			[]string{"pipx", "install", "acme-agent==1.2.3"}},
+		{"pipx update pinned", func() (*Plan, error) { return pipx.PlanUpdate(pinned, inst, def, pipxMethod) },
+			[]string{"pipx", "install", "--force", "acme-agent==1.2.3"}},
+		{"native install", func() (*Plan, error) { return native.PlanInstall(ctx, def, nativeMethod, false) },
+			[]string{"/bin/bash", "-c", nativeMethod.Command}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.plan()
+			if err != nil {
+				t.Fatalf("plan error = %v", err)
+			}
+			if len(plan.Commands) != 1 || !reflect.DeepEqual(plan.Commands[0].Argv, tt.want) {
+				t.Errorf("plan commands = %+v, want %q", plan.Commands, tt.want)
+			}
+		})
+	}
+
+	// Without an uninstall command the native provider removes the
+	// executable itself.
+	plan, err := native.PlanUninstall(ctx, inst, nativeMethod)
+	if err != nil {
+		t.Fatalf("PlanUninstall() error = %v", err)
+	}
+	if len(plan.Commands) != 0 || len(plan.Steps) != 1 || plan.Steps[0] != "remove /usr/local/bin/a" {
+		t.Errorf("PlanUninstall() = %+v, want a single removal step", plan)
+	}
+}
+
+func TestNeedsElevation(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want bool
+	}{
+		{[]string{"npm", "install", "-g", "agent"}, false},
+		{[]string{"sudo", "npm", "install", "-g", "agent"}, true},
+		{[]string{"/bin/sh", "-c", "curl -fsSL https://example.com/i.sh | sudo bash"}, true},
+		{[]string{"/bin/sh", "-c", "cd /tmp && doas make install"}, true},
+		{[]string{"powershell", "-Command", "Start-Process msiexec -Verb RunAs"}, true},
+		{[]string{"/bin/sh", "-c", "echo pseudo-sudoku"}, false},
+	}
+	for _, tt := range tests {
+		if got := needsElevation(tt.argv); got != tt.want {
+			t.Errorf("needsElevation(%q) = %v, want %v", tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	c := Command{Argv: []string{"/bin/sh", "-c", "echo 'hi' | cat", ""}}
+	want := `/bin/sh -c 'echo '\''hi'\'' | cat' ''`
+	if got := c.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestBinaryProviderPlan(t *testing.T) {
+	ctx := context.Background()
+	rs := newReleaseServer(t, "v1.0.0", "v1.1.0")
+	p := newTestBinaryProvider(t)
+
+	plan, err := p.PlanInstall(ctx, relagentDef, relagentMethod, false)
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	steps := strings.Join(plan.Steps, "\n")
+	for _, want := range []string{
+		rs.URL + "/download/v1.1.0/relagent-1.1.0-darwin-x64.tar.gz",
+		rs.URL + "/download/v1.1.0/checksums.txt",
+		filepath.Join(p.binDir, "relagent"),
+		p.manifestPath("relagent"),
+	} {
+		if !strings.Contains(steps, want) {
+			t.Errorf("PlanInstall() steps %q do not mention %s", plan.Steps, want)
+		}
+	}
+	if _, err := os.Stat(p.binDir); !os.IsNotExist(err) {
+		t.Error("planning must not create the bin directory")
+	}
+
+	result, err := p.Install(ctx, relagentDef, relagentMethod, false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	inst := result.Installation(true)
+
+	plan, err = p.PlanUpdate(ctx, inst, relagentDef, relagentMethod)
+	if err != nil {
+		t.Fatalf("PlanUpdate() error = %v", err)
+	}
+	if len(plan.Steps) != 1 || !strings.Contains(plan.Steps[0], "already installed") {
+		t.Errorf("PlanUpdate() steps = %q, want nothing to do", plan.Steps)
+	}
+
+	plan, err = p.PlanUninstall(ctx, inst, relagentMethod)
+	if err != nil {
+		t.Fatalf("PlanUninstall() error = %v", err)
+	}
+	want := []string{"remove " + result.ExecutablePath, "remove " + p.manifestPath("relagent")}
+	if !reflect.DeepEqual(plan.Steps, want) {
+		t.Errorf("PlanUninstall() steps = %q, want %q", plan.Steps, want)
+	}
+}
@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+// Provider installs, updates and removes agents for one family of install
+// methods and looks up their latest versions. installer.Manager routes each
+// catalog install method to the provider registered for it, so a program
+// embedding agentmgr can support a method of its own by registering a
+// Provider with installer.Manager.RegisterProvider.
+type Provider interface {
+	// Name returns the provider name. An unavailable provider is reported
+	// as "<name> is not available".
+	Name() string
+
+	// Methods returns the catalog install methods (InstallMethodDef.Method
+	// values) the provider handles.
+	Methods() []string
+
+	// IsAvailable returns true if the tooling the provider runs is present.
+	IsAvailable() bool
+
+	// Install installs an agent. A version set on ctx with
+	// WithTargetVersion is installed instead of the latest, or refused by
+	// providers that cannot pin one.
+	Install(ctx context.Context, agentDef catalog.AgentDef, method catalog.InstallMethodDef, force bool) (*Result, error)
+
+	// Update updates an installed agent, to the version set on ctx with
+	// WithTargetVersion when there is one.
+	Update(ctx context.Context, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef) (*Result, error)
+
+	// Uninstall removes an installed agent.
+	Uninstall(ctx context.Context, inst *agent.Installation, method catalog.InstallMethodDef) error
+
+	// GetLatestVersion returns the latest version the method's registry
+	// offers, or an error for methods without one.
+	GetLatestVersion(ctx context.Context, method catalog.InstallMethodDef) (agent.Version, error)
+}
+
+// VersionLister is implemented by providers whose registry lists every
+// published version, which version constraints are resolved against.
+type VersionLister interface {
+	ListVersions(ctx context.Context, method catalog.InstallMethodDef) ([]agent.Version, error)
+}
+
+var (
+	_ Provider      = (*NPMProvider)(nil)
+	_ Provider      = (*PipProvider)(nil)
+	_ Provider      = (*BrewProvider)(nil)
+	_ Provider      = (*BunProvider)(nil)
+	_ Provider      = (*CargoProvider)(nil)
+	_ Provider      = (*GoProvider)(nil)
+	_ Provider      = (*BinaryProvider)(nil)
+	_ Provider      = (*NativeProvider)(nil)
+	_ VersionLister = (*NPMProvider)(nil)
+	_ VersionLister = (*PipProvider)(nil)
+	_ VersionLister = (*BrewProvider)(nil)
+)
@@ -2,6 +2,9 @@ package providers
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
 
@@ -14,6 +17,7 @@ import (
 type mockPlatform struct {
 	executables map[string]string
 	id          platform.ID
+	dataDir     string
 }
 
 func newMockPlatform() *mockPlatform {
@@ -23,10 +27,15 @@ func newMockPlatform() *mockPlatform {
 	}
 }
 
-func (m *mockPlatform) ID() platform.ID                                             { return m.id }
-func (m *mockPlatform) Architecture() string                                        { return "amd64" }
-func (m *mockPlatform) Name() string                                                { return "macOS" }
-func (m *mockPlatform) GetDataDir() string                                          { return "/tmp/data" }
+func (m *mockPlatform) ID() platform.ID      { return m.id }
+func (m *mockPlatform) Architecture() string { return "amd64" }
+func (m *mockPlatform) Name() string         { return "macOS" }
+func (m *mockPlatform) GetDataDir() string {
+	if m.dataDir != "" {
+		return m.dataDir
+	}
+	return "/tmp/data"
+}
 func (m *mockPlatform) GetConfigDir() string                                        { return "/tmp/config" }
 func (m *mockPlatform) GetCacheDir() string                                         { return "/tmp/cache" }
 func (m *mockPlatform) GetLogDir() string                                           { return "/tmp/log" }
@@ -1286,3 +1295,167 @@ func TestResultFields(t *testing.T) {
 		t.Error("WasUpdated should be true")
 	}
 }
+
+func TestParseNPMVersionsJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "array",
+			output: `["1.0.0", "1.1.0-beta.1", "2.0.0"]`,
+			want:   []string{"1.0.0", "1.1.0-beta.1", "2.0.0"},
+		},
+		{
+			name:   "single version is a bare string",
+			output: `"0.1.0"`,
+			want:   []string{"0.1.0"},
+		},
+		{
+			name:    "invalid",
+			output:  `{"error": "E404"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := parseNPMVersionsJSON([]byte(tt.output))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(versions) != len(tt.want) {
+				t.Fatalf("versions = %v, want %v", versions, tt.want)
+			}
+			for i, v := range versions {
+				if v.String() != tt.want[i] {
+					t.Errorf("versions[%d] = %q, want %q", i, v.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePEP440Version(t *testing.T) {
+	tests := []struct {
+		input          string
+		wantPrerelease string
+		wantString     string
+	}{
+		{"1.2.0", "", "1.2.0"},
+		{"1.2.0rc1", "rc1", "1.2.0rc1"},
+		{"0.50.1.dev3", "dev3", "0.50.1.dev3"},
+		{"2.0.0.post1", "", "2.0.0.post1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := parsePEP440Version(tt.input)
+			if v.Prerelease != tt.wantPrerelease {
+				t.Errorf("Prerelease = %q, want %q", v.Prerelease, tt.wantPrerelease)
+			}
+			if v.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", v.String(), tt.wantString)
+			}
+		})
+	}
+
+	final := parsePEP440Version("1.2.0")
+	if !final.IsNewerThan(parsePEP440Version("1.2.0rc1")) {
+		t.Error("1.2.0 should be newer than 1.2.0rc1")
+	}
+}
+
+func TestPipProviderListVersions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/aider-chat/json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"releases": {
+			"0.40.0": [{"yanked": false}],
+			"0.41.0": [{"yanked": true}],
+			"0.42.0rc1": [{"yanked": false}],
+			"0.43.0": []
+		}}`))
+	}))
+	defer srv.Close()
+
+	orig := pypiBaseURL
+	pypiBaseURL = srv.URL
+	defer func() { pypiBaseURL = orig }()
+
+	provider := NewPipProvider(newMockPlatform())
+	versions, err := provider.ListVersions(context.Background(), catalog.InstallMethodDef{
+		Method:  "pipx",
+		Package: "aider-chat",
+	})
+	if err != nil {
+		t.Fatalf("ListVersions returned error: %v", err)
+	}
+
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.String())
+	}
+	sort.Strings(got)
+	want := []string{"0.40.0", "0.42.0rc1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("versions = %v, want %v (yanked and empty releases skipped)", got, want)
+	}
+}
+
+func TestPipProviderBuildVersionedInstallCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      catalog.InstallMethodDef
+		wantManager string
+		wantArgs    []string
+	}{
+		{
+			name:        "pipx",
+			method:      catalog.InstallMethodDef{Method: "pipx", Package: "aider-chat"},
+			wantManager: "pipx",
+			wantArgs:    []string{"install", "--force", "aider-chat==0.42.0"},
+		},
+		{
+			name:        "uv",
+			method:      catalog.InstallMethodDef{Method: "uv", Package: "aider-chat"},
+			wantManager: "uv",
+			wantArgs:    []string{"tool", "install", "--force", "aider-chat==0.42.0"},
+		},
+		{
+			name:        "pip",
+			method:      catalog.InstallMethodDef{Method: "pip", Package: "aider-chat"},
+			wantManager: "pip",
+			wantArgs:    []string{"install", "aider-chat==0.42.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewPipProvider(newMockPlatform())
+			manager, args, pkg, err := provider.buildVersionedInstallCommand(tt.method, "0.42.0")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if manager != tt.wantManager {
+				t.Errorf("manager = %q, want %q", manager, tt.wantManager)
+			}
+			if pkg != "aider-chat" {
+				t.Errorf("package = %q, want %q", pkg, "aider-chat")
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// registryHTTPClient is shared by the providers that look up latest
+// versions over HTTP, so repeated lookups reuse connections.
+var registryHTTPClient = &http.Client{
+	Timeout: 15 * time.Second,
+}
+
+// Registry API roots. They are variables so tests can point them at an
+// httptest server.
+var (
+	cratesIndexURL = "https://index.crates.io"
+	goProxyURL     = "https://proxy.golang.org"
+	npmRegistryURL = "https://registry.npmjs.org"
+	githubAPIURL   = "https://api.github.com"
+)
+
+// maxRegistryResponse bounds how much of a registry response is read. A
+// crates.io index file lists every published version of a crate.
+const maxRegistryResponse = 8 << 20
+
+// errRegistryNotFound is wrapped by fetchRegistry when the registry has no
+// such package or module.
+var errRegistryNotFound = errors.New("not found")
+
+// fetchRegistry GETs url and returns the response body. registry names the
+// service in errors; header is added to the request.
+func fetchRegistry(ctx context.Context, registry, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s request: %w", registry, err)
+	}
+	req.Header.Set("User-Agent", "AgentManager/1.0")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := registryHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from %s: %w", registry, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %w", registry, errRegistryNotFound)
+	default:
+		return nil, fmt.Errorf("%s returned HTTP %d", registry, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", registry, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,220 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+// Each test looks up a name no other test uses, since latest versions are
+// cached for the whole package.
+
+func TestCargoProviderGetLatestVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ca/rg/cargo-agent" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.Join([]string{
+			`{"name":"cargo-agent","vers":"0.9.0","yanked":false}`,
+			`{"name":"cargo-agent","vers":"1.2.0","yanked":false}`,
+			`{"name":"cargo-agent","vers":"1.3.0","yanked":true}`,
+			`{"name":"cargo-agent","vers":"1.4.0-beta.1","yanked":false}`,
+		}, "\n")))
+	}))
+	defer srv.Close()
+
+	orig := cratesIndexURL
+	cratesIndexURL = srv.URL
+	defer func() { cratesIndexURL = orig }()
+
+	provider := NewCargoProvider(newMockPlatform())
+	version, err := provider.GetLatestVersion(context.Background(), catalog.InstallMethodDef{
+		Method:  "cargo",
+		Command: "cargo install --locked cargo-agent",
+	})
+	if err != nil {
+		t.Fatalf("GetLatestVersion returned error: %v", err)
+	}
+	if version.String() != "1.2.0" {
+		t.Errorf("version = %s, want 1.2.0 (yanked and prerelease skipped)", version)
+	}
+}
+
+func TestCratesIndexPath(t *testing.T) {
+	tests := map[string]string{
+		"a":     "1/a",
+		"ab":    "2/ab",
+		"abc":   "3/a/abc",
+		"serde": "se/rd/serde",
+	}
+	for crate, want := range tests {
+		if got := cratesIndexPath(crate); got != want {
+			t.Errorf("cratesIndexPath(%q) = %q, want %q", crate, got, want)
+		}
+	}
+}
+
+func TestExtractCargoCrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  catalog.InstallMethodDef
+		want    string
+		wantErr bool
+	}{
+		{"package field", catalog.InstallMethodDef{Package: "Agent-CLI"}, "agent-cli", false},
+		{"plain command", catalog.InstallMethodDef{Command: "cargo install agent-cli"}, "agent-cli", false},
+		{"value flags", catalog.InstallMethodDef{Command: "cargo install --root /opt --features full agent-cli"}, "agent-cli", false},
+		{"pinned version", catalog.InstallMethodDef{Command: "cargo install agent-cli@1.0.0"}, "agent-cli", false},
+		{"git install", catalog.InstallMethodDef{Command: "cargo install --git https://github.com/acme/agent"}, "", true},
+		{"no crate", catalog.InstallMethodDef{Command: "cargo install --locked"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractCargoCrate(tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractCargoCrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("extractCargoCrate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoProviderGetLatestVersion(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path != "/github.com/acme/!go!agent/@latest" {
+			http.Error(w, "not found", http.StatusGone)
+			return
+		}
+		w.Write([]byte(`{"Version":"v0.7.1","Time":"2026-01-02T03:04:05Z"}`))
+	}))
+	defer srv.Close()
+
+	orig := goProxyURL
+	goProxyURL = srv.URL
+	defer func() { goProxyURL = orig }()
+
+	provider := NewGoProvider(newMockPlatform())
+	version, err := provider.GetLatestVersion(context.Background(), catalog.InstallMethodDef{
+		Method:  "go",
+		Command: "go install github.com/acme/GoAgent/cmd/goagent@latest",
+	})
+	if err != nil {
+		t.Fatalf("GetLatestVersion returned error: %v", err)
+	}
+	if version.String() != "v0.7.1" {
+		t.Errorf("version = %s, want v0.7.1", version)
+	}
+
+	want := []string{
+		"/github.com/acme/!go!agent/cmd/goagent/@latest",
+		"/github.com/acme/!go!agent/cmd/@latest",
+		"/github.com/acme/!go!agent/@latest",
+	}
+	if strings.Join(requested, ",") != strings.Join(want, ",") {
+		t.Errorf("requested %v, want %v", requested, want)
+	}
+}
+
+func TestExtractGoPackage(t *testing.T) {
+	tests := []struct {
+		method catalog.InstallMethodDef
+		want   string
+	}{
+		{catalog.InstallMethodDef{Package: "example.com/tool@v1.0.0"}, "example.com/tool"},
+		{catalog.InstallMethodDef{Command: "go install example.com/x/cmd/y@latest"}, "example.com/x/cmd/y"},
+		{catalog.InstallMethodDef{Command: "go install -v example.com/x"}, "example.com/x"},
+		{catalog.InstallMethodDef{Command: "go build ./..."}, ""},
+	}
+	for _, tt := range tests {
+		if got := extractGoPackage(tt.method); got != tt.want {
+			t.Errorf("extractGoPackage(%+v) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestBunProviderGetLatestVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/-/package/@acme%2Fbun-agent/dist-tags" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"latest":"3.1.4","next":"4.0.0-rc.1"}`))
+	}))
+	defer srv.Close()
+
+	orig := npmRegistryURL
+	npmRegistryURL = srv.URL
+	defer func() { npmRegistryURL = orig }()
+
+	provider := NewBunProvider(newMockPlatform())
+	version, err := provider.GetLatestVersion(context.Background(), catalog.InstallMethodDef{
+		Method:  "bun",
+		Command: "bun add -g @acme/bun-agent@latest",
+	})
+	if err != nil {
+		t.Fatalf("GetLatestVersion returned error: %v", err)
+	}
+	if version.String() != "3.1.4" {
+		t.Errorf("version = %s, want 3.1.4", version)
+	}
+}
+
+func TestBinaryProviderGetLatestVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/binary-agent/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Accept") != "application/vnd.github+json" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Write([]byte(`{"tag_name":"v2.5.0","name":"Binary Agent 2.5.0"}`))
+	}))
+	defer srv.Close()
+
+	orig := githubAPIURL
+	githubAPIURL = srv.URL
+	defer func() { githubAPIURL = orig }()
+
+	provider := NewBinaryProvider(newMockPlatform())
+	version, err := provider.GetLatestVersion(context.Background(), catalog.InstallMethodDef{
+		Method:   "binary",
+		Metadata: map[string]string{"download_url": "https://github.com/acme/binary-agent/releases/latest/download/agent.tar.gz"},
+	})
+	if err != nil {
+		t.Fatalf("GetLatestVersion returned error: %v", err)
+	}
+	if version.String() != "v2.5.0" {
+		t.Errorf("version = %s, want v2.5.0", version)
+	}
+
+	if _, err := provider.GetLatestVersion(context.Background(), catalog.InstallMethodDef{Method: "binary"}); err == nil {
+		t.Error("GetLatestVersion without a repository should fail")
+	}
+}
+
+func TestGithubRepo(t *testing.T) {
+	tests := []struct {
+		method catalog.InstallMethodDef
+		want   string
+	}{
+		{catalog.InstallMethodDef{Metadata: map[string]string{"repo": "acme/agent"}}, "acme/agent"},
+		{catalog.InstallMethodDef{Metadata: map[string]string{"repo": "https://github.com/acme/agent.git"}}, "acme/agent"},
+		{catalog.InstallMethodDef{Command: "curl -fsSL https://github.com/acme/agent/releases/download/v1/install.sh | sh"}, "acme/agent"},
+		{catalog.InstallMethodDef{Command: "curl -fsSL https://example.com/install.sh | sh"}, ""},
+	}
+	for _, tt := range tests {
+		if got := githubRepo(tt.method); got != tt.want {
+			t.Errorf("githubRepo(%+v) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
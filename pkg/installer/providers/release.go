@@ -0,0 +1,419 @@
+package providers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+)
+
+// releaseDownloadClient downloads release assets. It has no overall
+// timeout, since assets can be large; callers bound downloads with ctx.
+var releaseDownloadClient = &http.Client{}
+
+// maxReleaseAsset caps the size of a downloaded asset and of each file
+// extracted from it.
+const maxReleaseAsset = 1 << 30
+
+// defaultChecksumsAsset is the asset holding a release's SHA-256 sums.
+const defaultChecksumsAsset = "checksums.txt"
+
+// archAliases are the names release assets commonly use for an
+// architecture, preferred first.
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x64", "x86_64"},
+	"arm64": {"arm64", "aarch64"},
+}
+
+// unsupportedArchives are asset suffixes the binary installer cannot unpack.
+var unsupportedArchives = []string{".tar.xz", ".tar.bz2", ".tar.zst", ".7z", ".dmg", ".pkg", ".msi", ".deb", ".rpm", ".appimage"}
+
+// githubRelease is the part of a GitHub release the installer reads.
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// asset returns the release's asset called name.
+func (r *githubRelease) asset(name string) (releaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return releaseAsset{}, false
+}
+
+// fetchGitHubRelease returns the release of repo tagged tag, or its latest
+// release when tag is empty. A tag is tried with and without a "v" prefix.
+func fetchGitHubRelease(ctx context.Context, repo, tag string) (*githubRelease, error) {
+	header := http.Header{"Accept": []string{"application/vnd.github+json"}}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	endpoints := []string{githubAPIURL + "/repos/" + repo + "/releases/latest"}
+	if tag != "" {
+		endpoints = []string{githubAPIURL + "/repos/" + repo + "/releases/tags/v" + strings.TrimPrefix(tag, "v")}
+		if !strings.HasPrefix(tag, "v") {
+			endpoints = append(endpoints, githubAPIURL+"/repos/"+repo+"/releases/tags/"+tag)
+		}
+	}
+
+	var body []byte
+	var err error
+	for _, endpoint := range endpoints {
+		body, err = fetchRegistry(ctx, "GitHub", endpoint, header)
+		if !errors.Is(err, errRegistryNotFound) {
+			break
+		}
+	}
+	if errors.Is(err, errRegistryNotFound) && tag != "" {
+		return nil, fmt.Errorf("%s has no release %s", repo, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("could not parse GitHub release: %w", err)
+	}
+	return &release, nil
+}
+
+// releaseDef returns how method installs from GitHub releases, or nil when
+// it does not. Besides the release field, it understands the older
+// metadata form: a GitHub download_url plus artifact_<os>_<arch> keys.
+func releaseDef(method catalog.InstallMethodDef) *catalog.ReleaseDef {
+	if method.Release != nil {
+		def := *method.Release
+		if def.Repo == "" {
+			def.Repo = githubRepo(method)
+		}
+		if def.Repo == "" || len(def.Assets) == 0 {
+			return nil
+		}
+		return &def
+	}
+
+	assets := make(map[string]string)
+	for key, value := range method.Metadata {
+		if platformKey, ok := strings.CutPrefix(key, "artifact_"); ok {
+			assets[platformKey] = strings.ReplaceAll(value, "VERSION", "{version}")
+		}
+	}
+	repo := githubRepo(method)
+	if len(assets) == 0 || repo == "" {
+		return nil
+	}
+	return &catalog.ReleaseDef{Repo: repo, Assets: assets}
+}
+
+// releaseAssetName returns the asset def installs on the given platform
+// from the release tagged tag.
+func releaseAssetName(def *catalog.ReleaseDef, osID, arch, tag string) (string, error) {
+	aliases, ok := archAliases[arch]
+	if !ok {
+		aliases = []string{arch}
+	}
+
+	keys := make([]string, 0, len(aliases)+2)
+	for _, alias := range aliases {
+		keys = append(keys, osID+"_"+alias)
+	}
+	keys = append(keys, osID, "default")
+
+	for _, key := range keys {
+		if name, ok := def.Assets[key]; ok {
+			return expandReleaseTemplate(name, osID, arch, tag), nil
+		}
+	}
+	return "", fmt.Errorf("%s publishes no release asset for %s/%s", def.Repo, osID, arch)
+}
+
+// expandReleaseTemplate fills in the placeholders of an asset name.
+func expandReleaseTemplate(name, osID, arch, tag string) string {
+	return strings.NewReplacer(
+		"{version}", strings.TrimPrefix(tag, "v"),
+		"{tag}", tag,
+		"{os}", osID,
+		"{arch}", arch,
+	).Replace(name)
+}
+
+// releaseChecksum returns the SHA-256 sum the release publishes for asset,
+// from the checksums asset or, failing that, an "<asset>.sha256" file.
+func releaseChecksum(ctx context.Context, release *githubRelease, def *catalog.ReleaseDef, osID, arch, asset string) (string, error) {
+	source, err := checksumsAsset(release, def, osID, arch, asset)
+	if err != nil {
+		return "", err
+	}
+
+	var sums strings.Builder
+	if _, err := downloadReleaseAsset(ctx, source.DownloadURL, &sums); err != nil {
+		return "", err
+	}
+	sum := parseChecksums(sums.String(), asset, source.Name == asset+".sha256")
+	if sum == "" {
+		return "", fmt.Errorf("%s of %s %s lists no checksum for %s", source.Name, def.Repo, release.TagName, asset)
+	}
+	return sum, nil
+}
+
+// checksumsAsset returns the release asset holding the SHA-256 sum of
+// asset: the definition's checksums file, or else <asset>.sha256.
+func checksumsAsset(release *githubRelease, def *catalog.ReleaseDef, osID, arch, asset string) (releaseAsset, error) {
+	checksums := def.Checksums
+	if checksums == "" {
+		checksums = defaultChecksumsAsset
+	}
+	checksums = expandReleaseTemplate(checksums, osID, arch, release.TagName)
+
+	source, ok := release.asset(checksums)
+	if !ok {
+		if source, ok = release.asset(asset + ".sha256"); !ok {
+			return releaseAsset{}, fmt.Errorf("release %s of %s publishes no checksums for %s; refusing to install an unverified download", release.TagName, def.Repo, asset)
+		}
+	}
+	return source, nil
+}
+
+// parseChecksums returns the SHA-256 sum for asset in sha256sum output
+// ("<hex>  <name>" lines, the name possibly starred for binary mode). With
+// lone, for an "<asset>.sha256" file, a line holding just a sum applies to
+// asset too; a shared checksums file must name the asset.
+func parseChecksums(sums, asset string, lone bool) string {
+	scanner := bufio.NewScanner(strings.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case lone && len(fields) == 1 && isSHA256(fields[0]):
+			return strings.ToLower(fields[0])
+		case len(fields) >= 2 && path.Base(strings.TrimPrefix(fields[len(fields)-1], "*")) == asset:
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+// isSHA256 reports whether s is a hex-encoded SHA-256 sum.
+func isSHA256(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && len(s) == sha256.Size*2
+}
+
+// downloadReleaseAsset writes the asset at url to w and returns its
+// SHA-256 sum.
+func downloadReleaseAsset(ctx context.Context, url string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := releaseDownloadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(resp.Body, maxReleaseAsset+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if n > maxReleaseAsset {
+		return "", fmt.Errorf("failed to download %s: larger than %d bytes", url, maxReleaseAsset)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// archiveFile is a regular file unpacked from a release asset.
+type archiveFile struct {
+	name       string // base name inside the archive
+	path       string // where it was unpacked
+	executable bool
+}
+
+// unpackReleaseAsset unpacks the regular files of the asset downloaded to
+// file into dir. An asset that is not an archive is the executable itself.
+func unpackReleaseAsset(file, asset, dir string) ([]archiveFile, error) {
+	lower := strings.ToLower(asset)
+	for _, suffix := range unsupportedArchives {
+		if strings.HasSuffix(lower, suffix) {
+			return nil, fmt.Errorf("unsupported release asset format: %s", asset)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		return unpackTarGz(file, dir)
+	case strings.HasSuffix(lower, ".zip"):
+		return unpackZip(file, dir)
+	default:
+		return []archiveFile{{name: asset, path: file, executable: true}}, nil
+	}
+}
+
+func unpackTarGz(file, dir string) ([]archiveFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	var files []archiveFile
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Base(hdr.Name)
+		unpacked, err := writeArchiveFile(dir, len(files), name, tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{
+			name:       name,
+			path:       unpacked,
+			executable: hdr.Mode&0o111 != 0 || strings.HasSuffix(strings.ToLower(name), ".exe"),
+		})
+	}
+}
+
+func unpackZip(file, dir string) ([]archiveFile, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer zr.Close()
+
+	var files []archiveFile
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		name := path.Base(zf.Name)
+		unpacked, err := writeArchiveFile(dir, len(files), name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{
+			name:       name,
+			path:       unpacked,
+			executable: zf.Mode()&0o111 != 0 || strings.HasSuffix(strings.ToLower(name), ".exe"),
+		})
+	}
+	return files, nil
+}
+
+// writeArchiveFile writes one archive member into dir. Members are stored
+// under their index, so entry names never reach the file system.
+func writeArchiveFile(dir string, index int, name string, r io.Reader) (string, error) {
+	dest := filepath.Join(dir, fmt.Sprintf("%d.bin", index))
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o755)
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, maxReleaseAsset+1))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to unpack %s: %w", name, err)
+	}
+	if n > maxReleaseAsset {
+		return "", fmt.Errorf("failed to unpack %s: larger than %d bytes", name, maxReleaseAsset)
+	}
+	return dest, nil
+}
+
+// pickExecutables matches the unpacked files to the executables to
+// install. Names the asset does not contain are skipped, since agents
+// often list aliases; an asset holding a single executable provides the
+// first name whatever the file is called.
+func pickExecutables(files []archiveFile, names []string) map[string]archiveFile {
+	picked := make(map[string]archiveFile)
+	for _, name := range names {
+		for _, f := range files {
+			if executableFileName(f.name) == executableFileName(name) {
+				picked[name] = f
+				break
+			}
+		}
+	}
+	if len(picked) > 0 || len(names) == 0 {
+		return picked
+	}
+
+	var only []archiveFile
+	for _, f := range files {
+		if f.executable {
+			only = append(only, f)
+		}
+	}
+	if len(only) == 1 {
+		picked[names[0]] = only[0]
+	}
+	return picked
+}
+
+// executableFileName returns the file name an executable is installed
+// under: name itself, with ".exe" on Windows, compared case-insensitively
+// there.
+func executableFileName(name string) string {
+	if runtime.GOOS != "windows" {
+		return name
+	}
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".exe") {
+		name += ".exe"
+	}
+	return name
+}
@@ -0,0 +1,460 @@
+package providers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/detector/strategies"
+)
+
+// releaseServer serves GitHub releases of acme/relagent, each holding a
+// darwin/amd64 tar.gz with a "relagent" executable and a checksums.txt.
+type releaseServer struct {
+	*httptest.Server
+	latest string
+	assets map[string][]byte // "<tag>/<name>" -> content
+}
+
+func newReleaseServer(t *testing.T, tags ...string) *releaseServer {
+	t.Helper()
+	rs := &releaseServer{assets: make(map[string][]byte), latest: tags[len(tags)-1]}
+	for _, tag := range tags {
+		name := "relagent-" + strings.TrimPrefix(tag, "v") + "-darwin-x64.tar.gz"
+		archive := tarGz(t, map[string]string{
+			"relagent/README.md": "docs",
+			"relagent/relagent":  "#!/bin/sh\necho " + tag,
+		})
+		sum := sha256.Sum256(archive)
+		rs.assets[tag+"/"+name] = archive
+		rs.assets[tag+"/checksums.txt"] = []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+	}
+
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/acme/relagent/releases/latest":
+			rs.writeRelease(w, rs.latest)
+		case strings.HasPrefix(r.URL.Path, "/repos/acme/relagent/releases/tags/"):
+			tag := strings.TrimPrefix(r.URL.Path, "/repos/acme/relagent/releases/tags/")
+			if !rs.hasTag(tag) {
+				http.NotFound(w, r)
+				return
+			}
+			rs.writeRelease(w, tag)
+		case strings.HasPrefix(r.URL.Path, "/download/"):
+			content, ok := rs.assets[strings.TrimPrefix(r.URL.Path, "/download/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(rs.Close)
+
+	orig := githubAPIURL
+	githubAPIURL = rs.URL
+	t.Cleanup(func() { githubAPIURL = orig })
+	return rs
+}
+
+func (rs *releaseServer) hasTag(tag string) bool {
+	for key := range rs.assets {
+		if strings.HasPrefix(key, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *releaseServer) writeRelease(w http.ResponseWriter, tag string) {
+	release := githubRelease{TagName: tag}
+	for key := range rs.assets {
+		if name, ok := strings.CutPrefix(key, tag+"/"); ok {
+			release.Assets = append(release.Assets, releaseAsset{Name: name, DownloadURL: rs.URL + "/download/" + key})
+		}
+	}
+	json.NewEncoder(w).Encode(release)
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0o644)
+		if !strings.HasSuffix(name, ".md") {
+			mode = 0o755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func newTestBinaryProvider(t *testing.T) *BinaryProvider {
+	t.Helper()
+	p := NewBinaryProvider(newMockPlatform())
+	dir := t.TempDir()
+	p.binDir = filepath.Join(dir, "bin")
+	p.manifestDir = filepath.Join(dir, "binaries")
+	return p
+}
+
+var relagentDef = catalog.AgentDef{
+	ID:        "relagent",
+	Name:      "Release Agent",
+	Detection: catalog.DetectionDef{Executables: []string{"relagent", "ra"}},
+}
+
+var relagentMethod = catalog.InstallMethodDef{
+	Method: "binary",
+	Release: &catalog.ReleaseDef{
+		Repo:   "acme/relagent",
+		Assets: map[string]string{"darwin_x64": "relagent-{version}-{os}-x64.tar.gz"},
+	},
+}
+
+func TestBinaryProviderReleaseLifecycle(t *testing.T) {
+	ctx := context.Background()
+	rs := newReleaseServer(t, "v1.0.0", "v1.1.0")
+	p := newTestBinaryProvider(t)
+	exe := filepath.Join(p.binDir, "relagent")
+
+	result, err := p.Install(WithTargetVersion(ctx, "1.0.0"), relagentDef, relagentMethod, false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.ExecutablePath != exe || result.Version.String() != "v1.0.0" {
+		t.Errorf("Install() = %s %s, want %s v1.0.0", result.ExecutablePath, result.Version, exe)
+	}
+	if content, _ := os.ReadFile(exe); !strings.Contains(string(content), "v1.0.0") {
+		t.Errorf("installed executable = %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(p.binDir, "README.md")); err == nil {
+		t.Error("non-executable archive files should not be installed")
+	}
+
+	inst := result.Installation(true)
+	result, err = p.Update(ctx, inst, relagentDef, relagentMethod)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !result.WasUpdated || result.Version.String() != "v1.1.0" || result.FromVersion.String() != "v1.0.0" {
+		t.Errorf("Update() = %+v, want v1.0.0 -> v1.1.0", result)
+	}
+	if content, _ := os.ReadFile(exe); !strings.Contains(string(content), "v1.1.0") {
+		t.Errorf("updated executable = %q", content)
+	}
+
+	// Updating again finds the latest release installed and downloads nothing.
+	delete(rs.assets, "v1.1.0/relagent-1.1.0-darwin-x64.tar.gz")
+	result, err = p.Update(ctx, result.Installation(true), relagentDef, relagentMethod)
+	if err != nil {
+		t.Fatalf("second Update() error = %v", err)
+	}
+	if result.WasUpdated {
+		t.Error("second Update() should be a no-op")
+	}
+
+	if err := p.Uninstall(ctx, inst, relagentMethod); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(exe); !os.IsNotExist(err) {
+		t.Errorf("executable still present after Uninstall(): %v", err)
+	}
+	if m, _ := p.loadManifest("relagent"); m != nil {
+		t.Errorf("manifest still present after Uninstall(): %+v", m)
+	}
+	entries, _ := os.ReadDir(p.binDir)
+	if len(entries) != 0 {
+		t.Errorf("bin directory holds %d entries after Uninstall(), want 0", len(entries))
+	}
+}
+
+func TestBinaryProviderInstallIsDetected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the release executable is a shell script")
+	}
+	newReleaseServer(t, "v1.0.0")
+	plat := newMockPlatform()
+	plat.dataDir = t.TempDir()
+	p := NewBinaryProvider(plat)
+
+	if _, err := p.Install(context.Background(), relagentDef, relagentMethod, false); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	// The bin directory is not on the mock platform's PATH.
+	agentDef := relagentDef
+	agentDef.InstallMethods = map[string]catalog.InstallMethodDef{"native": {Method: "native"}, "binary": relagentMethod}
+	agentDef.Detection.VersionCmd = "relagent --version"
+	installations, err := strategies.NewBinaryStrategy(plat).Detect(context.Background(), []catalog.AgentDef{agentDef})
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(installations) != 1 {
+		t.Fatalf("Detect() found %d installations, want the release install", len(installations))
+	}
+	inst := installations[0]
+	if inst.ExecutablePath != filepath.Join(p.BinDir(), "relagent") || inst.Method != agent.MethodBinary {
+		t.Errorf("detected %s via %s, want %s via binary", inst.ExecutablePath, inst.Method, filepath.Join(p.BinDir(), "relagent"))
+	}
+	if inst.InstalledVersion.String() != "1.0.0" || inst.Metadata["path_status"] != "off_path" {
+		t.Errorf("detected version %s, metadata %v; want 1.0.0 off PATH", inst.InstalledVersion, inst.Metadata)
+	}
+}
+
+func TestBinaryProviderRejectsBadChecksum(t *testing.T) {
+	rs := newReleaseServer(t, "v2.0.0")
+	rs.assets["v2.0.0/checksums.txt"] = []byte(strings.Repeat("0", 64) + "  relagent-2.0.0-darwin-x64.tar.gz\n")
+	p := newTestBinaryProvider(t)
+
+	_, err := p.Install(context.Background(), relagentDef, relagentMethod, false)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Install() error = %v, want a checksum mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.binDir, "relagent")); !os.IsNotExist(err) {
+		t.Error("executable installed despite the checksum mismatch")
+	}
+
+	delete(rs.assets, "v2.0.0/checksums.txt")
+	_, err = p.Install(context.Background(), relagentDef, relagentMethod, false)
+	if err == nil || !strings.Contains(err.Error(), "publishes no checksums") {
+		t.Fatalf("Install() error = %v, want a missing checksums error", err)
+	}
+}
+
+func TestBinaryProviderKeepsForeignFiles(t *testing.T) {
+	newReleaseServer(t, "v3.0.0")
+	p := newTestBinaryProvider(t)
+	if err := os.MkdirAll(p.binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	foreign := filepath.Join(p.binDir, "relagent")
+	if err := os.WriteFile(foreign, []byte("mine"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.Install(context.Background(), relagentDef, relagentMethod, false); err == nil {
+		t.Fatal("Install() replaced a file agentmgr did not install")
+	}
+	if _, err := p.Install(context.Background(), relagentDef, relagentMethod, true); err != nil {
+		t.Fatalf("Install() with force error = %v", err)
+	}
+}
+
+func TestBinaryProviderRecordsPartialInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows moves the blocking directory aside instead of failing")
+	}
+	ctx := context.Background()
+	rs := newReleaseServer(t, "v4.0.0")
+	name := "relagent-4.0.0-darwin-x64.tar.gz"
+	archive := tarGz(t, map[string]string{
+		"relagent/relagent": "#!/bin/sh\necho v4.0.0",
+		"relagent/ra":       "#!/bin/sh\necho v4.0.0",
+	})
+	sum := sha256.Sum256(archive)
+	rs.assets["v4.0.0/"+name] = archive
+	rs.assets["v4.0.0/checksums.txt"] = []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+
+	// A non-empty directory where ra goes makes its move fail after
+	// relagent's succeeded.
+	p := newTestBinaryProvider(t)
+	blocker := filepath.Join(p.binDir, "ra")
+	if err := os.MkdirAll(filepath.Join(blocker, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Install(ctx, relagentDef, relagentMethod, true); err == nil {
+		t.Fatal("Install() error = nil, want the failed move of ra")
+	}
+
+	exe := filepath.Join(p.binDir, "relagent")
+	m, err := p.loadManifest("relagent")
+	if err != nil || m == nil {
+		t.Fatalf("loadManifest() = %v, %v; want the partial install recorded", m, err)
+	}
+	if len(m.Files) != 1 || m.Files[0] != exe || m.Tag != "" {
+		t.Errorf("manifest = %+v, want only %s and no release", m, exe)
+	}
+
+	// The next install owns relagent and retries the release.
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.Install(ctx, relagentDef, relagentMethod, false)
+	if err != nil {
+		t.Fatalf("second Install() error = %v", err)
+	}
+	if m, _ := p.loadManifest("relagent"); m == nil || m.Tag != "v4.0.0" || len(m.Files) != 2 {
+		t.Errorf("manifest after retry = %+v, want v4.0.0 with both executables", m)
+	}
+	if err := p.Uninstall(ctx, result.Installation(true), relagentMethod); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(p.binDir); len(entries) != 0 {
+		t.Errorf("bin directory holds %d entries after Uninstall(), want 0", len(entries))
+	}
+}
+
+func TestBinaryProviderUninstallWithoutManifest(t *testing.T) {
+	p := newTestBinaryProvider(t)
+	exe := filepath.Join(t.TempDir(), "relagent")
+	if err := os.WriteFile(exe, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := &agent.Installation{AgentID: "relagent", ExecutablePath: exe}
+	if err := p.Uninstall(context.Background(), inst, catalog.InstallMethodDef{Method: "binary"}); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(exe); !os.IsNotExist(err) {
+		t.Error("Uninstall() without a manifest should remove the executable")
+	}
+}
+
+func TestBinaryProviderLeavesReleaseInstallForOtherCopies(t *testing.T) {
+	ctx := context.Background()
+	newReleaseServer(t, "v1.0.0")
+	p := newTestBinaryProvider(t)
+	if _, err := p.Install(ctx, relagentDef, relagentMethod, false); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	release := filepath.Join(p.binDir, "relagent")
+
+	// A copy from the vendor's installer is not the release install.
+	other := filepath.Join(t.TempDir(), "relagent")
+	if err := os.WriteFile(other, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	inst := &agent.Installation{AgentID: "relagent", ExecutablePath: other}
+
+	plan, err := p.PlanUninstall(ctx, inst, relagentMethod)
+	if err != nil {
+		t.Fatalf("PlanUninstall() error = %v", err)
+	}
+	if steps := strings.Join(plan.Steps, "\n"); strings.Contains(steps, release) {
+		t.Errorf("PlanUninstall() steps = %q, want none touching %s", steps, release)
+	}
+	if err := p.Uninstall(ctx, inst, relagentMethod); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Error("Uninstall() should remove the other copy")
+	}
+	if _, err := os.Stat(release); err != nil {
+		t.Errorf("Uninstall() of the other copy removed the release install: %v", err)
+	}
+	if m, _ := p.loadManifest("relagent"); m == nil {
+		t.Error("Uninstall() of the other copy removed the release manifest")
+	}
+}
+
+func TestReleaseAssetName(t *testing.T) {
+	def := &catalog.ReleaseDef{
+		Repo: "acme/agent",
+		Assets: map[string]string{
+			"linux_x86_64":  "agent-{version}-x86_64-linux.tar.gz",
+			"darwin":        "agent-{tag}-macos.zip",
+			"windows_arm64": "agent-{os}-{arch}.zip",
+		},
+	}
+	tests := []struct {
+		os, arch string
+		want     string
+		wantErr  bool
+	}{
+		{"linux", "amd64", "agent-1.2.3-x86_64-linux.tar.gz", false},
+		{"darwin", "arm64", "agent-v1.2.3-macos.zip", false},
+		{"windows", "arm64", "agent-windows-arm64.zip", false},
+		{"linux", "arm64", "", true},
+	}
+	for _, tt := range tests {
+		got, err := releaseAssetName(def, tt.os, tt.arch, "v1.2.3")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("releaseAssetName(%s, %s) = %q, %v; want %q", tt.os, tt.arch, got, err, tt.want)
+		}
+	}
+}
+
+func TestReleaseDefFromMetadata(t *testing.T) {
+	def := releaseDef(catalog.InstallMethodDef{
+		Method: "binary",
+		Metadata: map[string]string{
+			"download_url":          "https://github.com/acme/agent/releases",
+			"artifact_linux_arm64":  "agent-VERSION-aarch64-linux.tar.gz",
+			"artifact_darwin_amd64": "agent-darwin.tar.gz",
+		},
+	})
+	if def == nil {
+		t.Fatal("releaseDef() = nil, want a definition from the metadata")
+	}
+	if def.Repo != "acme/agent" || def.Assets["linux_arm64"] != "agent-{version}-aarch64-linux.tar.gz" {
+		t.Errorf("releaseDef() = %+v", def)
+	}
+
+	if def := releaseDef(catalog.InstallMethodDef{Method: "binary", Command: "curl -fsSL https://example.com/install.sh | sh"}); def != nil {
+		t.Errorf("releaseDef() = %+v for a command-only method, want nil", def)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	sums := fmt.Sprintf("%s  other.tar.gz\n%s *dist/agent.tar.gz\n", strings.Repeat("cd", 32), strings.ToUpper(sum))
+	if got := parseChecksums(sums, "agent.tar.gz", false); got != sum {
+		t.Errorf("parseChecksums() = %q, want %q", got, sum)
+	}
+	if got := parseChecksums(sum+"\n", "agent.tar.gz", true); got != sum {
+		t.Errorf("parseChecksums() of a lone sum = %q, want %q", got, sum)
+	}
+	if got := parseChecksums(sums, "missing.zip", false); got != "" {
+		t.Errorf("parseChecksums() = %q for a missing asset, want empty", got)
+	}
+
+	// A shared checksums file whose first line is a bare sum does not
+	// vouch for every asset.
+	if got := parseChecksums(sum+"\n", "agent.tar.gz", false); got != "" {
+		t.Errorf("parseChecksums() of a lone sum in a checksums file = %q, want empty", got)
+	}
+	if got := parseChecksums(strings.Repeat("zz", 32)+"\n", "agent.tar.gz", true); got != "" {
+		t.Errorf("parseChecksums() of a lone non-hex line = %q, want empty", got)
+	}
+}
+
+func TestPickExecutables(t *testing.T) {
+	files := []archiveFile{
+		{name: "LICENSE"},
+		{name: "agent-x86_64-unknown-linux-musl", executable: true},
+	}
+	picked := pickExecutables(files, []string{"agent"})
+	if f, ok := picked["agent"]; !ok || f.name != "agent-x86_64-unknown-linux-musl" {
+		t.Errorf("pickExecutables() = %+v, want the lone executable as agent", picked)
+	}
+
+	files = append(files, archiveFile{name: "helper", executable: true})
+	if picked := pickExecutables(files, []string{"agent"}); len(picked) != 0 {
+		t.Errorf("pickExecutables() = %+v, want nothing when the executable is ambiguous", picked)
+	}
+}
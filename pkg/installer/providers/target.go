@@ -0,0 +1,29 @@
+package providers
+
+import "context"
+
+// targetVersionKey is the context key used to ask a provider for a specific
+// version instead of the registry's latest.
+type targetVersionKey struct{}
+
+// WithTargetVersion returns a new context asking providers to install or
+// update to exactly version rather than the latest release. It travels on
+// the context (like WithProgressWriter) so the Install/Update signatures
+// stay the same for every provider.
+//
+// Passing an empty version is a no-op and returns ctx unchanged.
+func WithTargetVersion(ctx context.Context, version string) context.Context {
+	if version == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, targetVersionKey{}, version)
+}
+
+// TargetVersion returns the version requested via WithTargetVersion, or ""
+// when the caller wants the latest release.
+func TargetVersion(ctx context.Context) string {
+	if v, ok := ctx.Value(targetVersionKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTargetVersion_DefaultIsEmpty(t *testing.T) {
+	if got := TargetVersion(context.Background()); got != "" {
+		t.Errorf("TargetVersion(empty ctx) = %q, want empty", got)
+	}
+}
+
+func TestTargetVersion_RoundTrip(t *testing.T) {
+	ctx := WithTargetVersion(context.Background(), "2.1.4")
+	if got := TargetVersion(ctx); got != "2.1.4" {
+		t.Errorf("TargetVersion = %q, want %q", got, "2.1.4")
+	}
+}
+
+func TestWithTargetVersion_EmptyIsNoop(t *testing.T) {
+	ctx := WithTargetVersion(context.Background(), "2.1.4")
+	ctx = WithTargetVersion(ctx, "")
+	if got := TargetVersion(ctx); got != "2.1.4" {
+		t.Errorf("empty re-assignment clobbered prior target: got %q", got)
+	}
+}
@@ -20,3 +20,30 @@ type Result struct {
 	Output         string
 	WasUpdated     bool // For updates
 }
+
+// Installation describes the installation r produced, for callers that
+// need one before the next detection run. It returns nil when r is nil.
+func (r *Result) Installation(isGlobal bool) *agent.Installation {
+	if r == nil {
+		return nil
+	}
+	return &agent.Installation{
+		AgentID:          r.AgentID,
+		AgentName:        r.AgentName,
+		Method:           r.Method,
+		InstalledVersion: r.Version,
+		ExecutablePath:   r.ExecutablePath,
+		InstallPath:      r.InstallPath,
+		IsGlobal:         isGlobal,
+		DetectedAt:       time.Now(),
+	}
+}
+
+// VersionString returns the installed version as a string, or "" when r is
+// nil (the operation failed) or the provider could not determine it.
+func (r *Result) VersionString() string {
+	if r == nil || r.Version.IsZero() {
+		return ""
+	}
+	return r.Version.String()
+}
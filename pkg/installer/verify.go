@@ -0,0 +1,182 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/versionparse"
+)
+
+// verifyCommandTimeout bounds each command run to verify an update, so an
+// agent that hangs fails verification instead of stalling the update.
+var verifyCommandTimeout = 30 * time.Second
+
+// VerificationError is returned by Manager.Update when the updated agent
+// failed its post-update check and the previous version was reinstalled,
+// or an attempt was made to.
+type VerificationError struct {
+	AgentID string
+	// FromVersion is the version the update started from, the one
+	// reinstalled.
+	FromVersion agent.Version
+	// Version is the version the updated agent reported, zero when its
+	// version command failed.
+	Version agent.Version
+	// Err is why verification failed.
+	Err error
+	// RollbackErr is why reinstalling FromVersion failed, or nil when the
+	// rollback succeeded.
+	RollbackErr error
+}
+
+func (e *VerificationError) Error() string {
+	msg := fmt.Sprintf("%s failed verification after update (%v)", e.AgentID, e.Err)
+	if e.RollbackErr != nil {
+		return msg + "; rollback failed: " + e.RollbackErr.Error()
+	}
+	return msg + "; rolled back to " + e.FromVersion.String()
+}
+
+func (e *VerificationError) Unwrap() error { return e.Err }
+
+// RolledBack reports whether the previous version was reinstalled.
+func (e *VerificationError) RolledBack() bool { return e.RollbackErr == nil }
+
+// verifyUpdate checks an agent p just updated from inst: its version
+// command must succeed and, when the update was meant to change the
+// version, report a different one than before; its smoke test, if the
+// catalog has one, must pass. When the check fails, the version inst had
+// is reinstalled and a *VerificationError returned.
+func (m *Manager) verifyUpdate(ctx context.Context, p providers.Provider, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef, result *providers.Result) error {
+	path := result.ExecutablePath
+	if path == "" {
+		path = inst.ExecutablePath
+	}
+	version, err := m.verify(ctx, agentDef, path, inst.InstalledVersion, updateChangesVersion(ctx, inst, result))
+	if err == nil {
+		if result.Version.IsZero() {
+			result.Version = version
+		}
+		return nil
+	}
+
+	fmt.Fprintf(providers.ProgressWriter(ctx), "%s failed verification: %v\nrolling back to %s\n", agentDef.ID, err, inst.InstalledVersion)
+	return &VerificationError{
+		AgentID:     agentDef.ID,
+		FromVersion: inst.InstalledVersion,
+		Version:     version,
+		Err:         err,
+		RollbackErr: m.restore(ctx, p, inst, agentDef, method, path, version),
+	}
+}
+
+// restore reinstalls the version inst had before an update that left the
+// agent at version, and checks the result still runs.
+func (m *Manager) restore(ctx context.Context, p providers.Provider, inst *agent.Installation, agentDef catalog.AgentDef, method catalog.InstallMethodDef, path string, version agent.Version) error {
+	if inst.InstalledVersion.IsZero() {
+		return fmt.Errorf("previous version unknown")
+	}
+	updated := *inst
+	updated.InstalledVersion = version
+	if _, err := p.Update(providers.WithTargetVersion(ctx, inst.InstalledVersion.String()), &updated, agentDef, method); err != nil {
+		return err
+	}
+	_, err := m.verify(ctx, agentDef, path, agent.Version{}, false)
+	return err
+}
+
+// updateChangesVersion reports whether an update from inst to result was
+// meant to leave a different version installed: it asked for another
+// version, the package manager reports one, or inst already knew of a
+// newer release.
+func updateChangesVersion(ctx context.Context, inst *agent.Installation, result *providers.Result) bool {
+	if target := providers.TargetVersion(ctx); target != "" {
+		v, err := agent.ParseVersion(target)
+		return err != nil || !v.Equals(inst.InstalledVersion)
+	}
+	if inst.HasUpdate() {
+		return true
+	}
+	return !result.Version.IsZero() && !result.Version.Equals(inst.InstalledVersion)
+}
+
+// verify runs the agent's version command against the executable at path
+// and then its smoke test, returning the version reported. With
+// wantChange, a report of from fails too. Agents without a version command
+// are only smoke tested.
+func (m *Manager) verify(ctx context.Context, agentDef catalog.AgentDef, path string, from agent.Version, wantChange bool) (agent.Version, error) {
+	var version agent.Version
+	if args := versionArgv(agentDef, path); len(args) > 0 {
+		output, err := runVerifyCommand(ctx, args)
+		if err != nil {
+			return version, fmt.Errorf("%s: %w\n%s", agentDef.Detection.VersionCmd, err, output)
+		}
+		version = versionparse.Parse(string(output), agentDef.Detection.VersionRegex)
+		switch {
+		case version.IsZero():
+			return version, fmt.Errorf("%s reported no version", agentDef.Detection.VersionCmd)
+		case wantChange && version.Equals(from):
+			return version, fmt.Errorf("version is still %s", version)
+		}
+	}
+
+	if args := m.smokeTestArgv(agentDef); len(args) > 0 {
+		output, err := runVerifyCommand(ctx, args)
+		if err != nil {
+			return version, fmt.Errorf("smoke test failed: %w\n%s", err, output)
+		}
+	}
+	return version, nil
+}
+
+// runVerifyCommand runs argv under verifyCommandTimeout and returns its
+// combined output.
+func runVerifyCommand(ctx context.Context, argv []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, verifyCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	// No input: an agent that prompts reads EOF from the null device and
+	// fails rather than waiting on the terminal.
+	cmd.Stdin = nil
+	// Children left holding the output open must not outlast the timeout.
+	cmd.WaitDelay = time.Second
+	return cmd.CombinedOutput()
+}
+
+// versionArgv returns the agent's version command run against the
+// executable at path, or nil when the agent has none.
+func versionArgv(agentDef catalog.AgentDef, path string) []string {
+	args := strings.Fields(agentDef.Detection.VersionCmd)
+	if len(args) > 0 && path != "" {
+		args[0] = path
+	}
+	return args
+}
+
+// smokeTestArgv returns the agent's smoke test run by the platform shell,
+// or nil when the agent has none.
+func (m *Manager) smokeTestArgv(agentDef catalog.AgentDef) []string {
+	if agentDef.Detection.SmokeTestCmd == "" {
+		return nil
+	}
+	return []string{m.plat.GetShell(), m.plat.GetShellArg(), agentDef.Detection.SmokeTestCmd}
+}
+
+// verificationCommands returns the commands Update runs to verify the
+// agent at path once it is updated.
+func (m *Manager) verificationCommands(agentDef catalog.AgentDef, path string) []providers.Command {
+	var commands []providers.Command
+	for _, argv := range [][]string{versionArgv(agentDef, path), m.smokeTestArgv(agentDef)} {
+		if len(argv) > 0 {
+			commands = append(commands, providers.Command{Argv: argv})
+		}
+	}
+	return commands
+}
@@ -0,0 +1,198 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/installer/providers"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+)
+
+// scriptProvider updates an agent whose executable is a shell script
+// printing the version in a file next to it. Update writes the target
+// version into that file, or next when there is no target; a broken
+// version leaves a script that fails. The result carries reported, the
+// version the package manager would claim.
+type scriptProvider struct {
+	fakeProvider
+	dir      string
+	next     string
+	reported string
+	targets  []string
+}
+
+func newScriptProvider(t *testing.T, installed, next string) *scriptProvider {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the agent")
+	}
+	p := &scriptProvider{
+		fakeProvider: fakeProvider{methods: []string{"script"}, available: true},
+		dir:          t.TempDir(),
+		next:         next,
+	}
+	script := "#!/bin/sh\nv=$(cat \"$(dirname \"$0\")/version\")\n[ \"$v\" = broken ] && exit 1\necho \"script-agent $v\"\n"
+	if err := os.WriteFile(p.path(), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p.write(installed)
+	return p
+}
+
+func (p *scriptProvider) path() string { return filepath.Join(p.dir, "agent") }
+
+func (p *scriptProvider) write(version string) {
+	_ = os.WriteFile(filepath.Join(p.dir, "version"), []byte(version), 0o644)
+}
+
+func (p *scriptProvider) Update(ctx context.Context, _ *agent.Installation, agentDef catalog.AgentDef, _ catalog.InstallMethodDef) (*providers.Result, error) {
+	target := providers.TargetVersion(ctx)
+	p.targets = append(p.targets, target)
+	if target == "" {
+		target = p.next
+	}
+	p.write(target)
+	result := &providers.Result{AgentID: agentDef.ID, ExecutablePath: p.path()}
+	if p.reported != "" && len(p.targets) == 1 {
+		result.Version = agent.MustParseVersion(p.reported)
+	}
+	return result, nil
+}
+
+func TestUpdateVerification(t *testing.T) {
+	tests := []struct {
+		name        string
+		next        string
+		reported    string
+		target      string
+		smokeTest   string
+		wantVersion string
+		wantErr     string
+		latest      string
+	}{
+		{"new version", "1.1.0", "", "", "", "1.1.0", "", ""},
+		{"target version", "", "", "1.0.5", "", "1.0.5", "", ""},
+		{"already up to date", "1.0.0", "1.0.0", "", "", "1.0.0", "", ""},
+		{"version command fails", "broken", "", "", "", "", "exit status 1", ""},
+		{"version unchanged", "1.0.0", "1.1.0", "", "", "", "version is still 1.0.0", ""},
+		{"smoke test fails", "1.1.0", "", "", `test "$(cat $DIR/version)" != 1.1.0`, "", "smoke test failed", ""},
+		{"smoke test passes", "1.1.0", "", "", "true", "1.1.0", "", ""},
+		{"update known but version unchanged", "1.0.0", "", "", "", "", "version is still 1.0.0", "1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newScriptProvider(t, "1.0.0", tt.next)
+			p.reported = tt.reported
+			m := NewManager(platform.Current())
+			m.RegisterProvider(p)
+
+			inst := &agent.Installation{AgentID: "script-agent", InstalledVersion: agent.MustParseVersion("1.0.0"), ExecutablePath: p.path()}
+			if tt.latest != "" {
+				latest := agent.MustParseVersion(tt.latest)
+				inst.LatestVersion = &latest
+			}
+			agentDef := catalog.AgentDef{ID: "script-agent", Detection: catalog.DetectionDef{VersionCmd: "agent --version", SmokeTestCmd: strings.ReplaceAll(tt.smokeTest, "$DIR", p.dir)}}
+			ctx := context.Background()
+			if tt.target != "" {
+				ctx = providers.WithTargetVersion(ctx, tt.target)
+			}
+
+			result, err := m.Update(ctx, inst, agentDef, catalog.InstallMethodDef{Method: "script"})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Update() error = %v", err)
+				}
+				if result.Version.String() != tt.wantVersion {
+					t.Errorf("Version = %s, want %s", result.Version, tt.wantVersion)
+				}
+				return
+			}
+
+			var verr *VerificationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Update() error = %v, want a *VerificationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Update() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			if !verr.RolledBack() {
+				t.Errorf("RolledBack() = false, RollbackErr = %v", verr.RollbackErr)
+			}
+			if got := p.targets[len(p.targets)-1]; got != "1.0.0" {
+				t.Errorf("rollback target = %q, want 1.0.0", got)
+			}
+			version, _ := os.ReadFile(filepath.Join(p.dir, "version"))
+			if string(version) != "1.0.0" {
+				t.Errorf("installed version after rollback = %s, want 1.0.0", version)
+			}
+		})
+	}
+}
+
+func TestUpdateVerificationTimeout(t *testing.T) {
+	orig := verifyCommandTimeout
+	verifyCommandTimeout = 100 * time.Millisecond
+	defer func() { verifyCommandTimeout = orig }()
+
+	p := newScriptProvider(t, "1.0.0", "1.1.0")
+	m := NewManager(platform.Current())
+	m.RegisterProvider(p)
+
+	inst := &agent.Installation{AgentID: "script-agent", InstalledVersion: agent.MustParseVersion("1.0.0"), ExecutablePath: p.path()}
+	agentDef := catalog.AgentDef{ID: "script-agent", Detection: catalog.DetectionDef{VersionCmd: "agent --version", SmokeTestCmd: "sleep 10"}}
+
+	start := time.Now()
+	_, err := m.Update(context.Background(), inst, agentDef, catalog.InstallMethodDef{Method: "script"})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Update() took %v; a hung smoke test held it up", elapsed)
+	}
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), "smoke test failed") {
+		t.Fatalf("Update() error = %v, want a failed smoke test", err)
+	}
+}
+
+func TestUpdateRollbackFailure(t *testing.T) {
+	p := newScriptProvider(t, "1.0.0", "broken")
+	m := NewManager(platform.Current())
+	m.RegisterProvider(p)
+
+	// Without the previous version there is nothing to go back to.
+	inst := &agent.Installation{AgentID: "script-agent", ExecutablePath: p.path()}
+	agentDef := catalog.AgentDef{ID: "script-agent", Detection: catalog.DetectionDef{VersionCmd: "agent --version"}}
+
+	_, err := m.Update(context.Background(), inst, agentDef, catalog.InstallMethodDef{Method: "script"})
+	var verr *VerificationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Update() error = %v, want a *VerificationError", err)
+	}
+	if verr.RolledBack() {
+		t.Error("RolledBack() = true, want false")
+	}
+	if !strings.Contains(err.Error(), "rollback failed: previous version unknown") {
+		t.Errorf("Update() error = %q", err)
+	}
+	if len(p.targets) != 1 {
+		t.Errorf("Update called %d times, want 1", len(p.targets))
+	}
+}
+
+func TestUpdateWithoutVersionCommand(t *testing.T) {
+	p := newScriptProvider(t, "1.0.0", "broken")
+	m := NewManager(platform.Current())
+	m.RegisterProvider(p)
+
+	inst := &agent.Installation{AgentID: "script-agent", InstalledVersion: agent.MustParseVersion("1.0.0"), ExecutablePath: p.path()}
+	if _, err := m.Update(context.Background(), inst, catalog.AgentDef{ID: "script-agent"}, catalog.InstallMethodDef{Method: "script"}); err != nil {
+		t.Errorf("Update() error = %v, want nil without anything to verify", err)
+	}
+}
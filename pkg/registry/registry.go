@@ -0,0 +1,396 @@
+// Package registry provides a storage-backed agent.Registry, so a program
+// can embed agentmgr's detection with a single constructor instead of
+// wiring storage, catalog, detector and installer together by hand.
+//
+//	reg, err := registry.Open(ctx, cfg, agent.DefaultRegistryOptions())
+//	if err != nil {
+//		return err
+//	}
+//	defer reg.Close()
+//
+//	if err := reg.Refresh(ctx); err != nil {
+//		return err
+//	}
+//	installations, err := reg.List(ctx)
+//
+// Installations are kept in the database's installations table. Refresh
+// runs the same detect-and-version-check pipeline as `agentmgr agent list`
+// and brings the table in line with what it found, announcing each
+// installation that was added, updated or removed.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/internal/orchestrator"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/catalog"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/installer"
+	"github.com/kevinelliott/agentmanager/pkg/platform"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// eventBuffer is how many events Events holds before further events are
+// dropped for a consumer that is not keeping up.
+const eventBuffer = 100
+
+// defaultRefreshInterval is used when AutoRefresh is on without a
+// positive RefreshInterval.
+const defaultRefreshInterval = time.Hour
+
+// pipeline is the subset of orchestrator.Pipeline the registry uses.
+type pipeline interface {
+	DetectAndCheckVersions(ctx context.Context, opts orchestrator.Options) (*orchestrator.Result, error)
+}
+
+// Registry is an agent.ObservableRegistry backed by a storage.Store.
+// Construct it with Open or New and release it with Close.
+type Registry struct {
+	cfg       *config.Config
+	store     storage.Store
+	pipeline  pipeline
+	opts      agent.RegistryOptions
+	ownsStore bool
+
+	// refreshMu serializes refreshes, so a manual Refresh and the
+	// background one never sync the table at the same time.
+	refreshMu sync.Mutex
+
+	mu        sync.RWMutex
+	observers []agent.RegistryObserver
+	events    chan agent.RegistryEvent
+	closed    bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ agent.ObservableRegistry = (*Registry)(nil)
+
+// Open opens agentmgr's database in the platform's data directory and
+// returns a registry backed by it. Close also closes the database.
+func Open(ctx context.Context, cfg *config.Config, opts agent.RegistryOptions) (*Registry, error) {
+	plat := platform.Current()
+
+	store, err := storage.NewSQLiteStore(plat.GetDataDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	if err := store.Initialize(ctx); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	r := New(cfg, plat, store, opts)
+	r.ownsStore = true
+	return r, nil
+}
+
+// New returns a registry keeping its installations in store, which must
+// already be initialized. The caller keeps ownership of store. A nil cfg
+// uses config.Default().
+//
+// opts.IncludeHidden controls whether agents configured as hidden are
+// listed. opts.PlatformFilter has no effect, since detection only ever
+// covers the current platform.
+func New(cfg *config.Config, plat platform.Platform, store storage.Store, opts agent.RegistryOptions) *Registry {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+	p := orchestrator.NewFromManagers(
+		cfg,
+		plat,
+		store,
+		catalog.NewManager(cfg, store),
+		detector.New(plat, detector.WithConfig(cfg)),
+		installer.NewManager(plat),
+	)
+	return newRegistry(cfg, store, p, opts)
+}
+
+func newRegistry(cfg *config.Config, store storage.Store, p pipeline, opts agent.RegistryOptions) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Registry{
+		cfg:      cfg,
+		store:    store,
+		pipeline: p,
+		opts:     opts,
+		events:   make(chan agent.RegistryEvent, eventBuffer),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	if opts.AutoRefresh {
+		interval := time.Duration(opts.RefreshInterval) * time.Second
+		if interval <= 0 {
+			interval = defaultRefreshInterval
+		}
+		go r.autoRefresh(ctx, interval)
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// autoRefresh refreshes right away and then every interval until ctx is
+// canceled. It honors the detection cache, so within the cache TTL a tick
+// only re-reads the cache, and once it expires the agents are re-detected
+// incrementally.
+func (r *Registry) autoRefresh(ctx context.Context, interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Failures are announced as error events; the next tick retries.
+		_ = r.refresh(ctx, false)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the background refresh, closes the Events channel and, for
+// a registry from Open, closes the database.
+func (r *Registry) Close() error {
+	r.cancel()
+	<-r.done
+
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.events)
+	}
+	r.mu.Unlock()
+
+	if r.ownsStore {
+		return r.store.Close()
+	}
+	return nil
+}
+
+// Events returns a channel receiving every registry event, including the
+// refreshed event that ends each refresh and an error event for each
+// refresh that failed. Events are dropped while the channel is full. The
+// channel is closed by Close.
+func (r *Registry) Events() <-chan agent.RegistryEvent {
+	return r.events
+}
+
+// Subscribe adds an observer. Observers are called synchronously, before
+// the event is sent on Events.
+func (r *Registry) Subscribe(observer agent.RegistryObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// Unsubscribe removes an observer. Unknown observers are ignored.
+func (r *Registry) Unsubscribe(observer agent.RegistryObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, o := range r.observers {
+		if o == observer {
+			r.observers = append(r.observers[:i], r.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// emit delivers event to the observers and the Events channel.
+func (r *Registry) emit(event agent.RegistryEvent) {
+	// Observers are called without the lock, so they may subscribe or
+	// unsubscribe from within OnRegistryEvent.
+	r.mu.RLock()
+	observers := append([]agent.RegistryObserver(nil), r.observers...)
+	r.mu.RUnlock()
+
+	for _, o := range observers {
+		o.OnRegistryEvent(event)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.events <- event:
+	default:
+		// Channel full, skip
+	}
+}
+
+// List returns all registered installations.
+func (r *Registry) List(ctx context.Context) ([]agent.Installation, error) {
+	return r.list(ctx, nil)
+}
+
+// Get returns the installation with the given key.
+func (r *Registry) Get(ctx context.Context, key string) (*agent.Installation, error) {
+	inst, err := r.store.GetInstallation(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if inst == nil {
+		return nil, fmt.Errorf("installation not found: %s", key)
+	}
+	return inst, nil
+}
+
+// GetByAgent returns the installations of one agent.
+func (r *Registry) GetByAgent(ctx context.Context, agentID string) ([]agent.Installation, error) {
+	return r.list(ctx, &agent.Filter{AgentID: agentID})
+}
+
+// GetByMethod returns the installations made with one install method.
+func (r *Registry) GetByMethod(ctx context.Context, method agent.InstallMethod) ([]agent.Installation, error) {
+	return r.list(ctx, &agent.Filter{Method: method})
+}
+
+// Count returns the number of registered installations.
+func (r *Registry) Count(ctx context.Context) (int, error) {
+	installations, err := r.list(ctx, nil)
+	return len(installations), err
+}
+
+// CountWithUpdates returns the number of installations with an update
+// available.
+func (r *Registry) CountWithUpdates(ctx context.Context) (int, error) {
+	hasUpdate := true
+	installations, err := r.list(ctx, &agent.Filter{HasUpdate: &hasUpdate})
+	return len(installations), err
+}
+
+// list returns the stored installations matching filter, leaving out
+// hidden agents unless the options include them.
+func (r *Registry) list(ctx context.Context, filter *agent.Filter) ([]agent.Installation, error) {
+	stored, err := r.store.ListInstallations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	installations := make([]agent.Installation, 0, len(stored))
+	for _, inst := range stored {
+		if !r.opts.IncludeHidden && r.cfg.IsAgentHidden(inst.AgentID) {
+			continue
+		}
+		installations = append(installations, *inst)
+	}
+	return installations, nil
+}
+
+// Register adds or replaces an installation, for instance one just made by
+// the installer, without waiting for the next refresh. An installation
+// that detection no longer finds is removed again by the next refresh.
+func (r *Registry) Register(ctx context.Context, installation agent.Installation) error {
+	prev, err := r.store.GetInstallation(ctx, installation.Key())
+	if err != nil {
+		return err
+	}
+	if err := r.store.SaveInstallation(ctx, &installation); err != nil {
+		return err
+	}
+
+	switch {
+	case prev == nil:
+		r.emit(agent.RegistryEvent{Type: agent.RegistryEventAdded, Installation: &installation})
+	case !prev.InstalledVersion.Equals(installation.InstalledVersion):
+		r.emit(agent.RegistryEvent{Type: agent.RegistryEventUpdated, Installation: &installation, Previous: prev})
+	}
+	return nil
+}
+
+// Unregister removes the installation with the given key.
+func (r *Registry) Unregister(ctx context.Context, key string) error {
+	prev, err := r.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := r.store.DeleteInstallation(ctx, key); err != nil {
+		return err
+	}
+
+	r.emit(agent.RegistryEvent{Type: agent.RegistryEventRemoved, Previous: prev})
+	return nil
+}
+
+// Refresh re-detects every agent, bypassing the detection cache, and
+// updates the registered installations to match.
+func (r *Registry) Refresh(ctx context.Context) error {
+	return r.refresh(ctx, true)
+}
+
+// refresh runs the pipeline and syncs the installations table with its
+// result, announcing every difference.
+func (r *Registry) refresh(ctx context.Context, force bool) error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	err := r.sync(ctx, force)
+	if err != nil {
+		r.emit(agent.RegistryEvent{Type: agent.RegistryEventError, Error: err})
+		return err
+	}
+	r.emit(agent.RegistryEvent{Type: agent.RegistryEventRefreshed})
+	return nil
+}
+
+func (r *Registry) sync(ctx context.Context, force bool) error {
+	res, err := r.pipeline.DetectAndCheckVersions(ctx, orchestrator.Options{ForceRefresh: force})
+	if err != nil {
+		return fmt.Errorf("refresh failed: %w", err)
+	}
+
+	stored, err := r.store.ListInstallations(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("refresh failed: %w", err)
+	}
+
+	// Saving every installation, changed or not, keeps LatestVersion and
+	// LastChecked current.
+	for _, inst := range res.Installations {
+		if err := r.store.SaveInstallation(ctx, inst); err != nil {
+			return fmt.Errorf("refresh failed: %w", err)
+		}
+	}
+
+	// An installation whose strategy failed may only have gone unseen,
+	// so it stays registered; see detector.Result.Unseen.
+	detection := &detector.Result{Installations: res.Installations}
+	if res.Detection != nil {
+		detection.Strategies = res.Detection.Strategies
+	}
+	unseen := make(map[string]bool)
+	for _, inst := range detection.Unseen(stored) {
+		unseen[inst.Key()] = true
+	}
+	var changes []agent.RegistryEvent
+	for _, event := range detection.Changes(stored) {
+		if event.Type == agent.RegistryEventRemoved && unseen[event.Previous.Key()] {
+			continue
+		}
+		changes = append(changes, event)
+	}
+
+	for _, event := range changes {
+		if event.Type == agent.RegistryEventRemoved {
+			if err := r.store.DeleteInstallation(ctx, event.Previous.Key()); err != nil {
+				return fmt.Errorf("refresh failed: %w", err)
+			}
+		}
+	}
+	for _, event := range changes {
+		r.emit(event)
+	}
+	return nil
+}
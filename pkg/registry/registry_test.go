@@ -0,0 +1,321 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/internal/orchestrator"
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+	"github.com/kevinelliott/agentmanager/pkg/config"
+	"github.com/kevinelliott/agentmanager/pkg/detector"
+	"github.com/kevinelliott/agentmanager/pkg/storage"
+)
+
+// fakePipeline answers each refresh with the installations it holds.
+type fakePipeline struct {
+	mu            sync.Mutex
+	installations []*agent.Installation
+	strategies    []detector.StrategyResult
+	err           error
+	calls         []orchestrator.Options
+}
+
+func (f *fakePipeline) DetectAndCheckVersions(_ context.Context, opts orchestrator.Options) (*orchestrator.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, opts)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([]*agent.Installation, len(f.installations))
+	for i, inst := range f.installations {
+		cp := *inst
+		out[i] = &cp
+	}
+	return &orchestrator.Result{Installations: out, Detection: &detector.Result{Installations: out, Strategies: f.strategies}}, nil
+}
+
+func (f *fakePipeline) set(installations ...*agent.Installation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.installations = installations
+}
+
+// recorder is an agent.RegistryObserver that keeps every event.
+type recorder struct {
+	mu     sync.Mutex
+	events []agent.RegistryEvent
+}
+
+func (r *recorder) OnRegistryEvent(event agent.RegistryEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) types() []agent.RegistryEventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]agent.RegistryEventType, 0, len(r.events))
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func newTestStore(t *testing.T) *storage.SQLiteStore {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	if err := store.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func makeInstallation(id string, major int) *agent.Installation {
+	return &agent.Installation{
+		AgentID:          id,
+		AgentName:        id,
+		Method:           agent.InstallMethodNPM,
+		ExecutablePath:   "/usr/local/bin/" + id,
+		InstalledVersion: agent.Version{Major: major},
+	}
+}
+
+func TestRefreshSyncsInstallations(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	p := &fakePipeline{}
+	p.set(makeInstallation("aider", 1), makeInstallation("claude", 1))
+
+	reg := newRegistry(config.Default(), store, p, agent.DefaultRegistryOptions())
+	defer reg.Close()
+	rec := &recorder{}
+	reg.Subscribe(rec)
+
+	if err := reg.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(p.calls) != 1 || !p.calls[0].ForceRefresh {
+		t.Errorf("pipeline calls = %+v, want one forced refresh", p.calls)
+	}
+	if n, _ := reg.Count(ctx); n != 2 {
+		t.Errorf("Count() = %d, want 2", n)
+	}
+
+	// aider upgraded, claude uninstalled, codex installed.
+	p.set(makeInstallation("aider", 2), makeInstallation("codex", 1))
+	if err := reg.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	want := []agent.RegistryEventType{
+		agent.RegistryEventAdded, agent.RegistryEventAdded, agent.RegistryEventRefreshed,
+		agent.RegistryEventAdded, agent.RegistryEventUpdated, agent.RegistryEventRemoved, agent.RegistryEventRefreshed,
+	}
+	if got := rec.types(); len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("events[%d] = %s, want %s", i, got[i], want[i])
+			}
+		}
+	}
+
+	aider, err := reg.GetByAgent(ctx, "aider")
+	if err != nil {
+		t.Fatalf("GetByAgent() error = %v", err)
+	}
+	if len(aider) != 1 || aider[0].InstalledVersion.Major != 2 {
+		t.Errorf("GetByAgent(aider) = %+v, want version 2", aider)
+	}
+	if _, err := reg.Get(ctx, makeInstallation("claude", 1).Key()); err == nil {
+		t.Error("Get() found claude after it was removed")
+	}
+
+	// Events mirror what observers saw.
+	if got := len(reg.Events()); got != len(want) {
+		t.Errorf("Events() holds %d events, want %d", got, len(want))
+	}
+}
+
+func TestRefreshKeepsInstallationsOfFailedStrategies(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	aider := makeInstallation("aider", 1)
+	aider.Method = agent.InstallMethodPipx
+	aider.Metadata = map[string]string{detector.StrategyKey: "pip"}
+	claude := makeInstallation("claude", 1)
+	claude.Metadata = map[string]string{detector.StrategyKey: "npm"}
+	p := &fakePipeline{}
+	p.set(aider, claude)
+
+	reg := newRegistry(config.Default(), store, p, agent.DefaultRegistryOptions())
+	defer reg.Close()
+	if err := reg.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// The pip strategy times out and claude is uninstalled.
+	rec := &recorder{}
+	reg.Subscribe(rec)
+	p.mu.Lock()
+	p.installations = nil
+	p.strategies = []detector.StrategyResult{
+		{Name: "pip", Method: agent.InstallMethodPip, TimedOut: true, Err: context.DeadlineExceeded},
+		{Name: "npm", Method: agent.InstallMethodNPM},
+	}
+	p.mu.Unlock()
+	if err := reg.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if got := rec.types(); len(got) != 2 || got[0] != agent.RegistryEventRemoved || got[1] != agent.RegistryEventRefreshed {
+		t.Errorf("events = %v, want claude removed and a refresh", got)
+	}
+	if _, err := reg.Get(ctx, aider.Key()); err != nil {
+		t.Errorf("Get(aider) error = %v, want it kept while pip failed", err)
+	}
+	if _, err := reg.Get(ctx, claude.Key()); err == nil {
+		t.Error("Get() found claude after it was removed")
+	}
+}
+
+func TestRefreshErrorEmitsErrorEvent(t *testing.T) {
+	store := newTestStore(t)
+	p := &fakePipeline{err: errors.New("catalog unreachable")}
+
+	reg := newRegistry(config.Default(), store, p, agent.DefaultRegistryOptions())
+	defer reg.Close()
+
+	if err := reg.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() error = nil, want the pipeline's error")
+	}
+	event := <-reg.Events()
+	if event.Type != agent.RegistryEventError || event.Error == nil {
+		t.Errorf("event = %+v, want an error event", event)
+	}
+}
+
+func TestRegisterAndUnregister(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	reg := newRegistry(config.Default(), store, &fakePipeline{}, agent.DefaultRegistryOptions())
+	defer reg.Close()
+	rec := &recorder{}
+	reg.Subscribe(rec)
+
+	inst := makeInstallation("aider", 1)
+	if err := reg.Register(ctx, *inst); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	// Re-registering the same version is not a change.
+	if err := reg.Register(ctx, *inst); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	inst.LatestVersion = &agent.Version{Major: 2}
+	if n, _ := reg.CountWithUpdates(ctx); n != 0 {
+		t.Errorf("CountWithUpdates() = %d before the latest version is known, want 0", n)
+	}
+	if err := reg.Register(ctx, *inst); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if n, _ := reg.CountWithUpdates(ctx); n != 1 {
+		t.Errorf("CountWithUpdates() = %d, want 1", n)
+	}
+
+	byMethod, err := reg.GetByMethod(ctx, agent.InstallMethodNPM)
+	if err != nil || len(byMethod) != 1 {
+		t.Errorf("GetByMethod(npm) = %+v, %v; want aider", byMethod, err)
+	}
+
+	if err := reg.Unregister(ctx, inst.Key()); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if err := reg.Unregister(ctx, inst.Key()); err == nil {
+		t.Error("Unregister() of a missing key should fail")
+	}
+
+	got := rec.types()
+	if len(got) != 2 || got[0] != agent.RegistryEventAdded || got[1] != agent.RegistryEventRemoved {
+		t.Errorf("events = %v, want added then removed", got)
+	}
+
+	reg.Unsubscribe(rec)
+	if err := reg.Register(ctx, *inst); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if n := len(rec.types()); n != 2 {
+		t.Errorf("observer received %d events after Unsubscribe, want 2", n)
+	}
+}
+
+func TestListHidesHiddenAgents(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	cfg := config.Default()
+	cfg.Agents = map[string]config.AgentConfig{"aider": {Hidden: true}}
+
+	for _, inst := range []*agent.Installation{makeInstallation("aider", 1), makeInstallation("claude", 1)} {
+		if err := store.SaveInstallation(ctx, inst); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := newRegistry(cfg, store, &fakePipeline{}, agent.DefaultRegistryOptions())
+	defer reg.Close()
+	if list, _ := reg.List(ctx); len(list) != 1 || list[0].AgentID != "claude" {
+		t.Errorf("List() = %+v, want only claude", list)
+	}
+
+	opts := agent.DefaultRegistryOptions()
+	opts.IncludeHidden = true
+	withHidden := newRegistry(cfg, store, &fakePipeline{}, opts)
+	defer withHidden.Close()
+	if list, _ := withHidden.List(ctx); len(list) != 2 {
+		t.Errorf("List() with IncludeHidden = %+v, want both", list)
+	}
+}
+
+func TestAutoRefresh(t *testing.T) {
+	store := newTestStore(t)
+	p := &fakePipeline{}
+	p.set(makeInstallation("aider", 1))
+
+	opts := agent.DefaultRegistryOptions()
+	opts.AutoRefresh = true
+	reg := newRegistry(config.Default(), store, p, opts)
+
+	timeout := time.After(5 * time.Second)
+	for refreshed := false; !refreshed; {
+		select {
+		case event := <-reg.Events():
+			refreshed = event.Type == agent.RegistryEventRefreshed
+		case <-timeout:
+			t.Fatal("no refreshed event from the background refresh")
+		}
+	}
+
+	if err := reg.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.calls) == 0 || p.calls[0].ForceRefresh {
+		t.Errorf("pipeline calls = %+v, want a cache-aware refresh", p.calls)
+	}
+
+	// Close closes the events channel once the refresh loop has stopped.
+	for range reg.Events() {
+	}
+}
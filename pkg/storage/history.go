@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+)
+
+// NewInstallationEvent returns an unsaved event describing action applied
+// to inst, heading towards toVersion (which may be empty when unknown
+// until the operation finishes).
+func NewInstallationEvent(action UpdateAction, inst *agent.Installation, toVersion string) *UpdateEvent {
+	return &UpdateEvent{
+		AgentID:       inst.AgentID,
+		AgentName:     inst.AgentName,
+		Action:        action,
+		InstallMethod: string(inst.Method),
+		FromVersion:   inst.InstalledVersion.String(),
+		ToVersion:     toVersion,
+	}
+}
+
+// RecordEvent saves event with the given status (normally pending or
+// running) and returns it for SetEventStatus and FinishEvent.
+//
+// Recording history is best-effort and must never block the operation it
+// describes: a nil store or a failed write returns nil, and the other
+// helpers treat a nil event as a no-op.
+func RecordEvent(ctx context.Context, store Store, event *UpdateEvent, status UpdateStatus) *UpdateEvent {
+	if store == nil || event == nil {
+		return nil
+	}
+	event.Status = status
+	if event.StartedAt.IsZero() {
+		event.StartedAt = time.Now()
+	}
+	if err := store.SaveUpdateEvent(ctx, event); err != nil {
+		return nil
+	}
+	return event
+}
+
+// SetEventStatus moves a recorded event to status, e.g. from pending to
+// running when a queued operation starts.
+func SetEventStatus(ctx context.Context, store Store, event *UpdateEvent, status UpdateStatus) {
+	if store == nil || event == nil {
+		return
+	}
+	event.Status = status
+	_ = store.SaveUpdateEvent(ctx, event)
+}
+
+// FinishEvent marks a recorded event completed, failed, cancelled (when
+// err is a context cancellation) or rolled back (when err has a
+// RolledBack method reporting true, as installer.VerificationError does).
+// A non-empty toVersion replaces the planned version with the one actually
+// installed.
+func FinishEvent(ctx context.Context, store Store, event *UpdateEvent, toVersion string, err error) {
+	if store == nil || event == nil {
+		return
+	}
+
+	now := time.Now()
+	event.CompletedAt = &now
+	switch {
+	case err == nil:
+		event.Status = UpdateStatusCompleted
+		if toVersion != "" {
+			event.ToVersion = toVersion
+		}
+	case errors.Is(err, context.Canceled):
+		event.Status = UpdateStatusCancelled
+		event.ErrorMessage = err.Error()
+	case rolledBack(err):
+		event.Status = UpdateStatusRolledBack
+		event.ErrorMessage = err.Error()
+	default:
+		event.Status = UpdateStatusFailed
+		event.ErrorMessage = err.Error()
+	}
+
+	// The operation's own context may be what just got cancelled; the
+	// outcome still needs to be written.
+	_ = store.SaveUpdateEvent(context.WithoutCancel(ctx), event)
+}
+
+// rolledBack reports whether err says the operation was undone.
+func rolledBack(err error) bool {
+	var rb interface{ RolledBack() bool }
+	return errors.As(err, &rb) && rb.RolledBack()
+}
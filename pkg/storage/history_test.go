@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+)
+
+func TestRecordEventNilStore(t *testing.T) {
+	event := &UpdateEvent{AgentID: "aider"}
+	if got := RecordEvent(context.Background(), nil, event, UpdateStatusRunning); got != nil {
+		t.Errorf("RecordEvent() with nil store = %v, want nil", got)
+	}
+
+	// The follow-up helpers must accept the nil result.
+	SetEventStatus(context.Background(), nil, nil, UpdateStatusRunning)
+	FinishEvent(context.Background(), nil, nil, "", nil)
+}
+
+// rollbackError is an error reporting whether the operation was undone.
+type rollbackError struct{ rolledBack bool }
+
+func (e rollbackError) Error() string    { return "verification failed" }
+func (e rollbackError) RolledBack() bool { return e.rolledBack }
+
+func TestFinishEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		toVersion     string
+		wantStatus    UpdateStatus
+		wantToVersion string
+		wantError     string
+	}{
+		{"success", nil, "1.2.3", UpdateStatusCompleted, "1.2.3", ""},
+		{"success keeps planned version", nil, "", UpdateStatusCompleted, "1.2.0", ""},
+		{"failure", errors.New("npm exited 1"), "", UpdateStatusFailed, "1.2.0", "npm exited 1"},
+		{"cancelled", fmt.Errorf("update: %w", context.Canceled), "", UpdateStatusCancelled, "1.2.0", "update: context canceled"},
+		{"rolled back", rollbackError{true}, "", UpdateStatusRolledBack, "1.2.0", "verification failed"},
+		{"rollback failed", rollbackError{false}, "", UpdateStatusFailed, "1.2.0", "verification failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, cleanup := setupTestStore(t)
+			defer cleanup()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			inst := &agent.Installation{
+				AgentID:          "aider",
+				AgentName:        "Aider",
+				Method:           agent.InstallMethodPip,
+				InstalledVersion: agent.MustParseVersion("1.1.0"),
+			}
+			event := RecordEvent(ctx, store, NewInstallationEvent(UpdateActionUpdate, inst, "1.2.0"), UpdateStatusRunning)
+			if event == nil {
+				t.Fatal("RecordEvent() returned nil")
+			}
+
+			// A cancelled operation context must not stop the outcome
+			// from being written.
+			cancel()
+			FinishEvent(ctx, store, event, tt.toVersion, tt.err)
+
+			history, err := store.GetUpdateHistory(context.Background(), "aider", 1)
+			if err != nil {
+				t.Fatalf("GetUpdateHistory() error = %v", err)
+			}
+			if len(history) != 1 {
+				t.Fatalf("GetUpdateHistory() count = %d, want 1", len(history))
+			}
+			got := history[0]
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if got.FromVersion != "1.1.0" {
+				t.Errorf("FromVersion = %q, want %q", got.FromVersion, "1.1.0")
+			}
+			if got.ToVersion != tt.wantToVersion {
+				t.Errorf("ToVersion = %q, want %q", got.ToVersion, tt.wantToVersion)
+			}
+			if got.ErrorMessage != tt.wantError {
+				t.Errorf("ErrorMessage = %q, want %q", got.ErrorMessage, tt.wantError)
+			}
+			if got.CompletedAt == nil {
+				t.Error("CompletedAt should be set")
+			}
+		})
+	}
+}
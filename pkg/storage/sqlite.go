@@ -4,9 +4,10 @@
 // currentSchemaVersion represents the most recent schema. On Initialize,
 // migrate() reads PRAGMA user_version; if it already equals
 // currentSchemaVersion, all DDL is skipped. Otherwise the full migration set
-// runs (idempotent via `IF NOT EXISTS`) and user_version is bumped. Future
-// schema changes should increment currentSchemaVersion and append new DDL
-// blocks that bring older databases forward (v1 -> v2 -> ... -> N).
+// runs (idempotent via `IF NOT EXISTS`, and addColumn for added columns)
+// and user_version is bumped. Future schema changes should increment
+// currentSchemaVersion and append new DDL blocks that bring older databases
+// forward (v1 -> v2 -> ... -> N).
 package storage
 
 import (
@@ -25,7 +26,12 @@ import (
 
 // currentSchemaVersion is the latest schema version applied by migrate().
 // Bump this and append migration steps when the schema changes.
-const currentSchemaVersion = 1
+//
+//	v1: initial schema
+//	v2: update_events.action
+//	v3: detection_fingerprints
+//	v4: registry_events
+const currentSchemaVersion = 4
 
 // SQLiteStore implements Store using SQLite.
 type SQLiteStore struct {
@@ -174,6 +180,31 @@ func (s *SQLiteStore) migrate(ctx context.Context) error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+
+		// Fingerprints of the cached installations, keyed by installation key
+		`CREATE TABLE IF NOT EXISTS detection_fingerprints (
+			key TEXT PRIMARY KEY,
+			fingerprint TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Changes detection noticed between runs that agentmgr did not make
+		`CREATE TABLE IF NOT EXISTS registry_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			installation_key TEXT NOT NULL,
+			agent_id TEXT NOT NULL,
+			agent_name TEXT NOT NULL,
+			install_method TEXT NOT NULL,
+			executable_path TEXT,
+			from_version TEXT,
+			to_version TEXT,
+			detected_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Index on agent_id for registry event lookups
+		`CREATE INDEX IF NOT EXISTS idx_registry_events_agent_id ON registry_events(agent_id)`,
 	}
 
 	for _, migration := range migrations {
@@ -182,6 +213,12 @@ func (s *SQLiteStore) migrate(ctx context.Context) error {
 		}
 	}
 
+	// v2: events record whether they were an install, update or uninstall.
+	// Rows written before v2 were all updates.
+	if err := s.addColumn(ctx, "update_events", "action", "TEXT NOT NULL DEFAULT 'update'"); err != nil {
+		return err
+	}
+
 	// Stamp the schema version. PRAGMA doesn't accept bound parameters, so
 	// we interpolate the integer constant directly (safe: not user input).
 	stampStmt := fmt.Sprintf("PRAGMA user_version = %d", currentSchemaVersion)
@@ -192,6 +229,41 @@ func (s *SQLiteStore) migrate(ctx context.Context) error {
 	return nil
 }
 
+// addColumn adds column to table unless it already exists. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so this keeps column migrations idempotent like
+// the CREATE ... IF NOT EXISTS statements.
+func (s *SQLiteStore) addColumn(ctx context.Context, table, column, decl string) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, typ  string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultVal, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl)); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
 // SaveInstallation saves or updates an installation record.
 func (s *SQLiteStore) SaveInstallation(ctx context.Context, inst *agent.Installation) error {
 	record := FromInstallation(inst)
@@ -373,14 +445,17 @@ func (s *SQLiteStore) DeleteInstallation(ctx context.Context, key string) error
 func (s *SQLiteStore) SaveUpdateEvent(ctx context.Context, event *UpdateEvent) error {
 	if event.ID == 0 {
 		// Insert new event
+		if event.Action == "" {
+			event.Action = UpdateActionUpdate
+		}
 		query := `
 			INSERT INTO update_events (
-				agent_id, agent_name, install_method, from_version, to_version,
+				agent_id, agent_name, action, install_method, from_version, to_version,
 				status, error_message, started_at, completed_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 		result, err := s.db.ExecContext(ctx, query,
-			event.AgentID, event.AgentName, event.InstallMethod, event.FromVersion, event.ToVersion,
+			event.AgentID, event.AgentName, event.Action, event.InstallMethod, event.FromVersion, event.ToVersion,
 			event.Status, event.ErrorMessage, event.StartedAt, event.CompletedAt,
 		)
 		if err != nil {
@@ -393,14 +468,15 @@ func (s *SQLiteStore) SaveUpdateEvent(ctx context.Context, event *UpdateEvent) e
 		}
 		event.ID = id
 	} else {
-		// Update existing event
+		// Update existing event. to_version is refreshed too because the
+		// version actually installed is only known once the update ends.
 		query := `
 			UPDATE update_events SET
-				status = ?, error_message = ?, completed_at = ?
+				to_version = ?, status = ?, error_message = ?, completed_at = ?
 			WHERE id = ?
 		`
 		_, err := s.db.ExecContext(ctx, query,
-			event.Status, event.ErrorMessage, event.CompletedAt, event.ID,
+			event.ToVersion, event.Status, event.ErrorMessage, event.CompletedAt, event.ID,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to update event: %w", err)
@@ -410,18 +486,19 @@ func (s *SQLiteStore) SaveUpdateEvent(ctx context.Context, event *UpdateEvent) e
 	return nil
 }
 
-// GetUpdateHistory retrieves update history for an agent.
+// GetUpdateHistory retrieves update history for an agent, newest first.
+// An empty agentID returns the history of every agent.
 func (s *SQLiteStore) GetUpdateHistory(ctx context.Context, agentID string, limit int) ([]*UpdateEvent, error) {
 	query := `
-		SELECT id, agent_id, agent_name, install_method, from_version, to_version,
+		SELECT id, agent_id, agent_name, action, install_method, from_version, to_version,
 			status, error_message, started_at, completed_at
 		FROM update_events
-		WHERE agent_id = ?
-		ORDER BY started_at DESC
+		WHERE ? = '' OR agent_id = ?
+		ORDER BY started_at DESC, id DESC
 		LIMIT ?
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, agentID, limit)
+	rows, err := s.db.QueryContext(ctx, query, agentID, agentID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get update history: %w", err)
 	}
@@ -431,16 +508,19 @@ func (s *SQLiteStore) GetUpdateHistory(ctx context.Context, agentID string, limi
 	for rows.Next() {
 		var event UpdateEvent
 		var completedAt sql.NullTime
+		var errorMessage sql.NullString
 
 		err := rows.Scan(
-			&event.ID, &event.AgentID, &event.AgentName, &event.InstallMethod,
-			&event.FromVersion, &event.ToVersion, &event.Status, &event.ErrorMessage,
+			&event.ID, &event.AgentID, &event.AgentName, &event.Action, &event.InstallMethod,
+			&event.FromVersion, &event.ToVersion, &event.Status, &errorMessage,
 			&event.StartedAt, &completedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan update event: %w", err)
 		}
 
+		event.ErrorMessage = errorMessage.String
+
 		if completedAt.Valid {
 			event.CompletedAt = &completedAt.Time
 		}
@@ -455,6 +535,78 @@ func (s *SQLiteStore) GetUpdateHistory(ctx context.Context, agentID string, limi
 	return events, nil
 }
 
+// SaveRegistryEvents records changes detection noticed between two runs.
+func (s *SQLiteStore) SaveRegistryEvents(ctx context.Context, events []*RegistryEventRecord) error {
+	query := `
+		INSERT INTO registry_events (
+			type, installation_key, agent_id, agent_name, install_method,
+			executable_path, from_version, to_version, detected_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, event := range events {
+		result, err := s.db.ExecContext(ctx, query,
+			event.Type, event.Key, event.AgentID, event.AgentName, event.InstallMethod,
+			event.ExecutablePath, event.FromVersion, event.ToVersion, event.DetectedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save registry event: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		event.ID = id
+	}
+
+	return nil
+}
+
+// GetRegistryEvents retrieves recorded registry events for an agent, newest
+// first. An empty agentID returns the events of every agent.
+func (s *SQLiteStore) GetRegistryEvents(ctx context.Context, agentID string, limit int) ([]*RegistryEventRecord, error) {
+	query := `
+		SELECT id, type, installation_key, agent_id, agent_name, install_method,
+			executable_path, from_version, to_version, detected_at
+		FROM registry_events
+		WHERE ? = '' OR agent_id = ?
+		ORDER BY detected_at DESC, id DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, agentID, agentID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registry events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*RegistryEventRecord
+	for rows.Next() {
+		var event RegistryEventRecord
+		var execPath, fromVersion, toVersion sql.NullString
+
+		err := rows.Scan(
+			&event.ID, &event.Type, &event.Key, &event.AgentID, &event.AgentName, &event.InstallMethod,
+			&execPath, &fromVersion, &toVersion, &event.DetectedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan registry event: %w", err)
+		}
+
+		event.ExecutablePath = execPath.String
+		event.FromVersion = fromVersion.String
+		event.ToVersion = toVersion.String
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registry events: %w", err)
+	}
+
+	return events, nil
+}
+
 // SaveCatalogCache stores the catalog cache.
 func (s *SQLiteStore) SaveCatalogCache(ctx context.Context, data []byte, etag string) error {
 	query := `
@@ -594,15 +746,67 @@ func (s *SQLiteStore) GetDetectionCache(ctx context.Context) ([]*agent.Installat
 	return installations, cachedAt, nil
 }
 
-// ClearDetectionCache removes the detection cache.
+// ClearDetectionCache removes the detection cache and its fingerprints.
 func (s *SQLiteStore) ClearDetectionCache(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM detection_cache WHERE id = 1")
 	if err != nil {
 		return fmt.Errorf("failed to clear detection cache: %w", err)
 	}
+	_, err = s.db.ExecContext(ctx, "DELETE FROM detection_fingerprints")
+	if err != nil {
+		return fmt.Errorf("failed to clear detection fingerprints: %w", err)
+	}
 	return nil
 }
 
+// SaveDetectionFingerprints replaces the stored installation fingerprints.
+func (s *SQLiteStore) SaveDetectionFingerprints(ctx context.Context, fingerprints map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM detection_fingerprints"); err != nil {
+		return fmt.Errorf("failed to clear detection fingerprints: %w", err)
+	}
+	for key, fingerprint := range fingerprints {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO detection_fingerprints (key, fingerprint) VALUES (?, ?)", key, fingerprint)
+		if err != nil {
+			return fmt.Errorf("failed to save detection fingerprint: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save detection fingerprints: %w", err)
+	}
+	return nil
+}
+
+// GetDetectionFingerprints returns the stored installation fingerprints,
+// keyed by installation key.
+func (s *SQLiteStore) GetDetectionFingerprints(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT key, fingerprint FROM detection_fingerprints")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get detection fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[string]string)
+	for rows.Next() {
+		var key, fingerprint string
+		if err := rows.Scan(&key, &fingerprint); err != nil {
+			return nil, fmt.Errorf("failed to scan detection fingerprint: %w", err)
+		}
+		fingerprints[key] = fingerprint
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get detection fingerprints: %w", err)
+	}
+	return fingerprints, nil
+}
+
 // GetDetectionCacheTime returns when the detection cache was last updated.
 func (s *SQLiteStore) GetDetectionCacheTime(ctx context.Context) (time.Time, error) {
 	query := "SELECT cached_at FROM detection_cache WHERE id = 1"
@@ -413,9 +413,10 @@ func TestSaveAndGetUpdateEvent(t *testing.T) {
 		t.Error("Event ID should be set after insert")
 	}
 
-	// Update event
+	// Update event, recording the version that actually got installed
 	completedAt := now.Add(time.Minute)
 	event.Status = UpdateStatusCompleted
+	event.ToVersion = "2.0.1"
 	event.CompletedAt = &completedAt
 
 	err = store.SaveUpdateEvent(ctx, event)
@@ -436,6 +437,9 @@ func TestSaveAndGetUpdateEvent(t *testing.T) {
 	if retrieved.Status != UpdateStatusCompleted {
 		t.Errorf("Status = %q, want %q", retrieved.Status, UpdateStatusCompleted)
 	}
+	if retrieved.ToVersion != "2.0.1" {
+		t.Errorf("ToVersion = %q, want %q", retrieved.ToVersion, "2.0.1")
+	}
 	if retrieved.CompletedAt == nil {
 		t.Error("CompletedAt should be set")
 	}
@@ -479,6 +483,50 @@ func TestGetUpdateHistoryOrdering(t *testing.T) {
 	}
 }
 
+func TestGetUpdateHistoryAllAgents(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	base := time.Now()
+	events := []*UpdateEvent{
+		{AgentID: "aider", AgentName: "Aider", Action: UpdateActionInstall, InstallMethod: "pip", ToVersion: "0.50.0", Status: UpdateStatusCompleted, StartedAt: base},
+		{AgentID: "claude-code", AgentName: "Claude Code", InstallMethod: "npm", FromVersion: "1.0.0", ToVersion: "1.1.0", Status: UpdateStatusFailed, ErrorMessage: "boom", StartedAt: base.Add(time.Minute)},
+		{AgentID: "aider", AgentName: "Aider", Action: UpdateActionUninstall, InstallMethod: "pip", FromVersion: "0.50.0", Status: UpdateStatusCompleted, StartedAt: base.Add(2 * time.Minute)},
+	}
+	for _, event := range events {
+		if err := store.SaveUpdateEvent(ctx, event); err != nil {
+			t.Fatalf("SaveUpdateEvent() error = %v", err)
+		}
+	}
+
+	history, err := store.GetUpdateHistory(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("GetUpdateHistory() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("GetUpdateHistory() count = %d, want 3", len(history))
+	}
+
+	wantActions := []UpdateAction{UpdateActionUninstall, UpdateActionUpdate, UpdateActionInstall}
+	for i, want := range wantActions {
+		if history[i].Action != want {
+			t.Errorf("history[%d].Action = %q, want %q", i, history[i].Action, want)
+		}
+	}
+	if history[1].ErrorMessage != "boom" {
+		t.Errorf("ErrorMessage = %q, want %q", history[1].ErrorMessage, "boom")
+	}
+
+	aider, err := store.GetUpdateHistory(ctx, "aider", 10)
+	if err != nil {
+		t.Fatalf("GetUpdateHistory() error = %v", err)
+	}
+	if len(aider) != 2 {
+		t.Errorf("GetUpdateHistory(aider) count = %d, want 2", len(aider))
+	}
+}
+
 func TestCatalogCache(t *testing.T) {
 	store, cleanup := setupTestStore(t)
 	defer cleanup()
@@ -551,6 +599,111 @@ func TestCatalogCacheEmpty(t *testing.T) {
 	}
 }
 
+func TestDetectionFingerprints(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	got, err := store.GetDetectionFingerprints(ctx)
+	if err != nil {
+		t.Fatalf("GetDetectionFingerprints() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("fingerprints = %v, want none before any save", got)
+	}
+
+	if err := store.SaveDetectionFingerprints(ctx, map[string]string{"a:npm:": "fp-a", "b:brew:": "fp-b"}); err != nil {
+		t.Fatalf("SaveDetectionFingerprints() error = %v", err)
+	}
+	// A second save replaces the first rather than merging into it.
+	if err := store.SaveDetectionFingerprints(ctx, map[string]string{"a:npm:": "fp-a2"}); err != nil {
+		t.Fatalf("SaveDetectionFingerprints() error = %v", err)
+	}
+
+	got, err = store.GetDetectionFingerprints(ctx)
+	if err != nil {
+		t.Fatalf("GetDetectionFingerprints() error = %v", err)
+	}
+	if len(got) != 1 || got["a:npm:"] != "fp-a2" {
+		t.Errorf("fingerprints = %v, want only a:npm: = fp-a2", got)
+	}
+
+	if err := store.ClearDetectionCache(ctx); err != nil {
+		t.Fatalf("ClearDetectionCache() error = %v", err)
+	}
+	got, err = store.GetDetectionFingerprints(ctx)
+	if err != nil {
+		t.Fatalf("GetDetectionFingerprints() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("fingerprints = %v, want none after ClearDetectionCache", got)
+	}
+}
+
+func TestSaveAndGetRegistryEvents(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	prev := &agent.Installation{
+		AgentID:          "aider",
+		AgentName:        "Aider",
+		Method:           agent.InstallMethodPip,
+		ExecutablePath:   "/usr/local/bin/aider",
+		InstalledVersion: agent.Version{Major: 0, Minor: 80},
+	}
+	next := *prev
+	next.InstalledVersion = agent.Version{Major: 0, Minor: 81}
+	added := &agent.Installation{AgentID: "claude-code", AgentName: "Claude Code", Method: agent.InstallMethodNPM}
+
+	earlier := time.Now().Add(-time.Hour).Truncate(time.Second)
+	later := earlier.Add(30 * time.Minute)
+	records := []*RegistryEventRecord{
+		NewRegistryEventRecord(agent.RegistryEvent{Type: agent.RegistryEventUpdated, Installation: &next, Previous: prev}, earlier),
+		NewRegistryEventRecord(agent.RegistryEvent{Type: agent.RegistryEventAdded, Installation: added}, later),
+		NewRegistryEventRecord(agent.RegistryEvent{Type: agent.RegistryEventRemoved, Previous: prev}, later),
+	}
+	if err := store.SaveRegistryEvents(ctx, records); err != nil {
+		t.Fatalf("SaveRegistryEvents() error = %v", err)
+	}
+	for _, record := range records {
+		if record.ID == 0 {
+			t.Errorf("record %+v was not assigned an ID", record)
+		}
+	}
+
+	all, err := store.GetRegistryEvents(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("GetRegistryEvents() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d events, want 3", len(all))
+	}
+	// Newest first; the two later events come back by descending ID.
+	if all[0].Type != agent.RegistryEventRemoved || all[1].Type != agent.RegistryEventAdded || all[2].Type != agent.RegistryEventUpdated {
+		t.Errorf("order = %s, %s, %s; want removed, added, updated", all[0].Type, all[1].Type, all[2].Type)
+	}
+
+	updated := all[2]
+	if updated.FromVersion != "0.80.0" || updated.ToVersion != "0.81.0" {
+		t.Errorf("updated versions = %q -> %q, want 0.80.0 -> 0.81.0", updated.FromVersion, updated.ToVersion)
+	}
+	if updated.Key != prev.Key() || updated.ExecutablePath != "/usr/local/bin/aider" || updated.InstallMethod != "pip" {
+		t.Errorf("updated record = %+v", updated)
+	}
+	if removed := all[0]; removed.FromVersion != "0.80.0" || removed.ToVersion != "" {
+		t.Errorf("removed versions = %q -> %q, want 0.80.0 -> empty", removed.FromVersion, removed.ToVersion)
+	}
+
+	aider, err := store.GetRegistryEvents(ctx, "aider", 1)
+	if err != nil {
+		t.Fatalf("GetRegistryEvents(aider) error = %v", err)
+	}
+	if len(aider) != 1 || aider[0].AgentID != "aider" || aider[0].Type != agent.RegistryEventRemoved {
+		t.Errorf("GetRegistryEvents(aider, 1) = %+v, want the removal", aider)
+	}
+}
+
 func TestSettings(t *testing.T) {
 	store, cleanup := setupTestStore(t)
 	defer cleanup()
@@ -699,3 +852,51 @@ func TestMigrateRunsWhenBehind(t *testing.T) {
 		t.Errorf("user_version = %d, want %d", v, currentSchemaVersion)
 	}
 }
+
+// TestMigrateAddsActionColumn verifies that a v1 database, whose
+// update_events table predates the action column, gains it on migrate and
+// reports existing rows as updates.
+func TestMigrateAddsActionColumn(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	stmts := []string{
+		"DROP TABLE update_events",
+		`CREATE TABLE update_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			agent_id TEXT NOT NULL,
+			agent_name TEXT NOT NULL,
+			install_method TEXT NOT NULL,
+			from_version TEXT NOT NULL,
+			to_version TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error_message TEXT,
+			started_at DATETIME NOT NULL,
+			completed_at DATETIME
+		)`,
+		`INSERT INTO update_events (agent_id, agent_name, install_method, from_version, to_version, status, started_at)
+			VALUES ('aider', 'Aider', 'pip', '0.50.0', '0.51.0', 'completed', CURRENT_TIMESTAMP)`,
+		"PRAGMA user_version = 1",
+	}
+	for _, stmt := range stmts {
+		if _, err := store.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+
+	if err := store.migrate(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	history, err := store.GetUpdateHistory(ctx, "aider", 10)
+	if err != nil {
+		t.Fatalf("GetUpdateHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("GetUpdateHistory() count = %d, want 1", len(history))
+	}
+	if history[0].Action != UpdateActionUpdate {
+		t.Errorf("Action = %q, want %q", history[0].Action, UpdateActionUpdate)
+	}
+}
@@ -22,10 +22,16 @@ type Store interface {
 	ListInstallations(ctx context.Context, filter *agent.Filter) ([]*agent.Installation, error)
 	DeleteInstallation(ctx context.Context, key string) error
 
-	// Update history operations
+	// Update history operations. GetUpdateHistory returns events newest
+	// first; an empty agentID returns events for every agent.
 	SaveUpdateEvent(ctx context.Context, event *UpdateEvent) error
 	GetUpdateHistory(ctx context.Context, agentID string, limit int) ([]*UpdateEvent, error)
 
+	// Registry event operations. GetRegistryEvents returns events newest
+	// first; an empty agentID returns events for every agent.
+	SaveRegistryEvents(ctx context.Context, events []*RegistryEventRecord) error
+	GetRegistryEvents(ctx context.Context, agentID string, limit int) ([]*RegistryEventRecord, error)
+
 	// Catalog cache operations
 	SaveCatalogCache(ctx context.Context, data []byte, etag string) error
 	GetCatalogCache(ctx context.Context) ([]byte, string, time.Time, error)
@@ -35,6 +41,8 @@ type Store interface {
 	GetDetectionCache(ctx context.Context) ([]*agent.Installation, time.Time, error)
 	ClearDetectionCache(ctx context.Context) error
 	GetDetectionCacheTime(ctx context.Context) (time.Time, error)
+	SaveDetectionFingerprints(ctx context.Context, fingerprints map[string]string) error
+	GetDetectionFingerprints(ctx context.Context) (map[string]string, error)
 	SetLastUpdateCheckTime(ctx context.Context, t time.Time) error
 	GetLastUpdateCheckTime(ctx context.Context) (time.Time, error)
 
@@ -44,11 +52,12 @@ type Store interface {
 	DeleteSetting(ctx context.Context, key string) error
 }
 
-// UpdateEvent represents a recorded update event.
+// UpdateEvent represents a recorded install, update or uninstall.
 type UpdateEvent struct {
 	ID            int64
 	AgentID       string
 	AgentName     string
+	Action        UpdateAction
 	InstallMethod string
 	FromVersion   string
 	ToVersion     string
@@ -67,8 +76,64 @@ const (
 	UpdateStatusCompleted UpdateStatus = "completed"
 	UpdateStatusFailed    UpdateStatus = "failed"
 	UpdateStatusCancelled UpdateStatus = "cancelled"
+	// UpdateStatusRolledBack marks an update that failed verification
+	// and was undone by reinstalling the previous version.
+	UpdateStatusRolledBack UpdateStatus = "rolled_back"
 )
 
+// UpdateAction is the kind of change an UpdateEvent records.
+type UpdateAction string
+
+const (
+	UpdateActionInstall   UpdateAction = "install"
+	UpdateActionUpdate    UpdateAction = "update"
+	UpdateActionUninstall UpdateAction = "uninstall"
+	UpdateActionRollback  UpdateAction = "rollback"
+)
+
+// RegistryEventRecord is a stored agent.RegistryEvent: an installation
+// that detection found added, updated or removed since the previous run
+// without agentmgr having done it.
+type RegistryEventRecord struct {
+	ID             int64
+	Type           agent.RegistryEventType
+	Key            string
+	AgentID        string
+	AgentName      string
+	InstallMethod  string
+	ExecutablePath string
+	FromVersion    string
+	ToVersion      string
+	DetectedAt     time.Time
+}
+
+// NewRegistryEventRecord returns an unsaved record of event, detected at.
+// The installation details come from the event's installation, or from
+// the previous one for a removal.
+func NewRegistryEventRecord(event agent.RegistryEvent, at time.Time) *RegistryEventRecord {
+	inst := event.Installation
+	if inst == nil {
+		inst = event.Previous
+	}
+
+	record := &RegistryEventRecord{
+		Type:           event.Type,
+		Key:            inst.Key(),
+		AgentID:        inst.AgentID,
+		AgentName:      inst.AgentName,
+		InstallMethod:  string(inst.Method),
+		ExecutablePath: inst.ExecutablePath,
+		DetectedAt:     at,
+	}
+	if event.Previous != nil {
+		record.FromVersion = event.Previous.InstalledVersion.String()
+	}
+	if event.Installation != nil {
+		record.ToVersion = event.Installation.InstalledVersion.String()
+	}
+	return record
+}
+
 // InstallationRecord represents a stored installation record.
 type InstallationRecord struct {
 	Key              string
@@ -17,6 +17,7 @@ func TestUpdateStatusConstants(t *testing.T) {
 		{UpdateStatusCompleted, "completed"},
 		{UpdateStatusFailed, "failed"},
 		{UpdateStatusCancelled, "cancelled"},
+		{UpdateStatusRolledBack, "rolled_back"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,50 @@
+// Package versionparse extracts agent versions from the output of their
+// version commands. Detection and update verification share it.
+package versionparse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kevinelliott/agentmanager/pkg/agent"
+)
+
+// commonPatterns match the usual ways a tool prints its version, tried in
+// order.
+var commonPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`v?(\d+\.\d+\.\d+(?:-[a-zA-Z0-9.]+)?)`),
+	regexp.MustCompile(`version\s+v?(\d+\.\d+\.\d+)`),
+	regexp.MustCompile(`(\d+\.\d+\.\d+)`),
+}
+
+// Parse returns the version in output, the output of an agent's version
+// command. With pattern, the catalog's version_regex, the version is its
+// first submatch; otherwise the first version-like string is taken. It
+// returns the zero Version when none is found.
+func Parse(output, pattern string) agent.Version {
+	versionStr := strings.TrimSpace(output)
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err == nil {
+			matches := re.FindStringSubmatch(versionStr)
+			if len(matches) > 1 {
+				versionStr = matches[1]
+			}
+		}
+	} else {
+		versionStr = Extract(versionStr)
+	}
+
+	version, _ := agent.ParseVersion(versionStr)
+	return version
+}
+
+// Extract returns the first version number in output, or "".
+func Extract(output string) string {
+	for _, re := range commonPatterns {
+		if matches := re.FindStringSubmatch(output); len(matches) > 1 {
+			return matches[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package versionparse
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"version 1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"1.2.3", "1.2.3"},
+		{"claude-code version 1.0.5", "1.0.5"},
+		{"aider v0.50.0", "0.50.0"},
+		{"Version: 2.0.0-beta.1", "2.0.0-beta.1"},
+		{"some text v3.4.5 more text", "3.4.5"},
+		{"no version here", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			result := Extract(tt.output)
+			if result != tt.expected {
+				t.Errorf("Extract(%q) = %q, want %q", tt.output, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtract_EdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"version with v prefix", "v1.2.3", "1.2.3"},
+		{"version without v prefix", "1.2.3", "1.2.3"},
+		{"version word prefix", "version 1.2.3", "1.2.3"},
+		{"Version capitalized", "Version: 1.2.3", "1.2.3"},
+		{"prerelease version", "v1.0.0-alpha.1", "1.0.0-alpha.1"},
+		{"beta version", "2.0.0-beta.5", "2.0.0-beta.5"},
+		{"rc version", "3.0.0-rc.1", "3.0.0-rc.1"},
+		{"multiline output first line", "v1.2.3\nsome other text", "1.2.3"},
+		{"multiline output middle", "some text\nv1.2.3\nmore text", "1.2.3"},
+		{"version in sentence", "claude-code version 1.0.5 is installed", "1.0.5"},
+		{"version with extra info", "aider v0.50.0 (Python 3.11)", "0.50.0"},
+		{"no version number", "installed successfully", ""},
+		{"only text", "hello world", ""},
+		{"empty string", "", ""},
+		{"whitespace only", "   \n  \t  ", ""},
+		{"version at end", "Package version is 4.5.6", "4.5.6"},
+		{"version with build metadata", "1.0.0-beta.1+build.123", "1.0.0-beta.1"},
+		{"two digit version", "v10.20.30", "10.20.30"},
+		{"version with leading zeros", "v01.02.03", "01.02.03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Extract(tt.output)
+			if result != tt.expected {
+				t.Errorf("Extract(%q) = %q, want %q", tt.output, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		pattern  string
+		expected string
+	}{
+		{"common pattern", "aider v0.50.0\n", "", "0.50.0"},
+		{"version regex", "build 7 (release 1.4.2)", `release (\d+\.\d+\.\d+)`, "1.4.2"},
+		{"no version", "unknown", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.output, tt.pattern)
+			if tt.expected == "" {
+				if !got.IsZero() {
+					t.Errorf("Parse(%q) = %s, want zero version", tt.output, got)
+				}
+				return
+			}
+			if got.String() != tt.expected {
+				t.Errorf("Parse(%q, %q) = %s, want %s", tt.output, tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}